@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/config"
+)
+
+// configAliases reads the aliases: section from the config file selected by
+// a --config before the command name, or the default one.
+func configAliases(args []string) map[string]string {
+	_, configFile, ok := commandIndex(args)
+	if !ok {
+		return nil
+	}
+	cfgFile = configFile
+	initConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	return cfg.Aliases
+}
+
+// expandAlias replaces a leading alias from the config's aliases: section
+// with its command line, so "buck ship feature/x" runs
+// "buck create --with-pr -g backend feature/x". Built-in commands win over
+// aliases of the same name, and an expansion is not expanded again.
+func expandAlias(args []string, aliases map[string]string) ([]string, error) {
+	i, _, ok := commandIndex(args)
+	if !ok || isBuiltinCommand(args[i]) {
+		return args, nil
+	}
+	line, ok := aliases[args[i]]
+	if !ok {
+		return args, nil
+	}
+
+	words, err := splitCommandLine(line)
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", args[i], err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("alias %q is empty", args[i])
+	}
+
+	expanded := make([]string, 0, len(args)+len(words))
+	expanded = append(expanded, args[:i]...)
+	expanded = append(expanded, words...)
+	return append(expanded, args[i+1:]...), nil
+}
+
+// splitCommandLine splits s into words at spaces, keeping single- or
+// double-quoted text together, e.g. `pr comment -m "Code freeze"`.
+func splitCommandLine(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
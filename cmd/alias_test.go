@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"ship":   "create --with-pr -g backend",
+		"freeze": `pr comment -m "Code freeze at 17:00"`,
+		"list":   "list --sort updated", // shadowed by the built-in
+		"broken": `pr comment -m "oops`,
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"ship", "feature/x"}, []string{"create", "--with-pr", "-g", "backend", "feature/x"}},
+		{[]string{"--config", "x.yaml", "ship"}, []string{"--config", "x.yaml", "create", "--with-pr", "-g", "backend"}},
+		{[]string{"freeze", "release/1.4"}, []string{"pr", "comment", "-m", "Code freeze at 17:00", "release/1.4"}},
+		{[]string{"list"}, []string{"list"}},
+		{[]string{"unknown"}, []string{"unknown"}},
+		{[]string{"--verbose", "ship"}, []string{"--verbose", "ship"}},
+		{[]string{}, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := expandAlias(tt.args, aliases)
+		if err != nil {
+			t.Errorf("expandAlias(%v) error: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandAlias(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := expandAlias([]string{"broken"}, aliases); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	got, err := splitCommandLine(`pr  comment -m 'it''s "done"' --title a\ b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"pr", "comment", "-m", `its "done"`, "--title", "a b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
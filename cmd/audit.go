@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/audit"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+var (
+	auditFlagGroup       string
+	auditFlagRepos       string
+	auditFlagInteractive bool
+
+	auditBranchesFlagFormat    string
+	auditBranchesFlagStaleDays int
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Read-only reports across repos (permissions, branches)",
+}
+
+var auditPermissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "List user and group permissions per repo and flag inconsistencies",
+	Long: `List the explicit user and group permissions of the selected repos. Users
+and groups whose access differs between repos (including access to some repos
+but not others) are flagged with the repos at each level.`,
+	Args: cobra.NoArgs,
+	RunE: runAuditPermissions,
+}
+
+var auditBranchesCmd = &cobra.Command{
+	Use:   "branches <branch1,branch2,...>",
+	Short: "Show which repos have which branches (exists / missing / stale)",
+	Long: `Build a repo × branch matrix for the selected repos. Each cell is exists,
+missing, or stale when the branch's last commit is older than --stale-days.
+Use --format csv to export the matrix, including each branch's last commit date.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuditBranches,
+}
+
+func init() {
+	// Shared flags available to all audit subcommands
+	auditCmd.PersistentFlags().StringVarP(&auditFlagGroup, "group", "g", "", "repo group from config")
+	auditCmd.PersistentFlags().StringVarP(&auditFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	auditCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	auditCmd.PersistentFlags().BoolVarP(&auditFlagInteractive, "interactive", "i", false, "select repos interactively")
+
+	_ = auditCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = auditCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	auditBranchesCmd.Flags().StringVar(&auditBranchesFlagFormat, "format", "table", "output format: table or csv")
+	auditBranchesCmd.Flags().IntVar(&auditBranchesFlagStaleDays, "stale-days", 90, "days without commits after which a branch is stale (0 disables)")
+	_ = auditBranchesCmd.RegisterFlagCompletionFunc("format", completeStaticValues([]string{"table", "csv"}))
+
+	auditCmd.AddCommand(auditPermissionsCmd)
+	auditCmd.AddCommand(auditBranchesCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAuditPermissions(cmd *cobra.Command, args []string) error {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.PermissionService](ctx.cfg, ctx.client, "audit permissions")
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(auditFlagRepos, auditFlagGroup, auditFlagInteractive); err != nil {
+		return err
+	}
+
+	color.New(color.Bold).Printf("Auditing permissions across %d repos...\n", len(ctx.repos))
+	audit.PrintPermissions(audit.AuditPermissions(service, ctx.cfg.Workspace, ctx.repos))
+	return nil
+}
+
+func runAuditBranches(cmd *cobra.Command, args []string) error {
+	var branches []string
+	for _, b := range strings.Split(args[0], ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			branches = append(branches, b)
+		}
+	}
+	if len(branches) == 0 {
+		return fmt.Errorf("no branches given")
+	}
+	if auditBranchesFlagFormat != "table" && auditBranchesFlagFormat != "csv" {
+		return fmt.Errorf("invalid --format %q (valid: table, csv)", auditBranchesFlagFormat)
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(auditFlagRepos, auditFlagGroup, auditFlagInteractive); err != nil {
+		return err
+	}
+
+	staleAfter := time.Duration(auditBranchesFlagStaleDays) * 24 * time.Hour
+	if auditBranchesFlagFormat == "csv" {
+		rows := audit.AuditBranches(ctx.client, ctx.cfg.Workspace, ctx.repos, branches, staleAfter)
+		return audit.WriteBranchCSV(os.Stdout, rows, branches)
+	}
+
+	color.New(color.Bold).Printf("Checking %d branches across %d repos...\n", len(branches), len(ctx.repos))
+	audit.PrintBranchMatrix(audit.AuditBranches(ctx.client, ctx.cfg.Workspace, ctx.repos, branches, staleAfter), branches)
+	return nil
+}
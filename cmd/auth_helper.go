@@ -2,12 +2,109 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"os"
+	"strconv"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/chinhstringee/buck/internal/auth"
 	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/github"
+	"github.com/chinhstringee/buck/internal/gitlab"
+	"github.com/chinhstringee/buck/internal/httpx"
+	"github.com/chinhstringee/buck/internal/provider"
 )
 
+// buildProvider creates the hosting provider client selected by the config.
+func buildProvider(cfg *config.Config) (provider.Provider, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.ProviderName() {
+	case provider.Bitbucket:
+		auth.HTTPClient = httpClient
+		authApplier := bitbucket.AuthApplier(func(*http.Request) error { return nil })
+		if flagReplay == "" { // a replay needs no credentials
+			if authApplier, err = buildAuthApplier(cfg); err != nil {
+				return nil, err
+			}
+		}
+		client := bitbucket.NewClientWithHTTPClient(httpClient, authApplier)
+		client.SetMaxPages(cfg.HTTP.MaxPages)
+		return client, nil
+
+	case provider.GitHub:
+		if cfg.GitHub.Token == "" && flagReplay == "" {
+			return nil, fmt.Errorf("GitHub token not configured.\nSet github.token in .buck.yaml, e.g. token: ${GITHUB_TOKEN}")
+		}
+		client := github.NewClientWithHTTPClient(httpClient, cfg.GitHub.BaseURL, cfg.GitHub.Token)
+		client.SetMaxPages(cfg.HTTP.MaxPages)
+		return client, nil
+
+	case provider.GitLab:
+		if cfg.GitLab.Token == "" && flagReplay == "" {
+			return nil, fmt.Errorf("GitLab token not configured.\nSet gitlab.token in .buck.yaml, e.g. token: ${GITLAB_TOKEN}")
+		}
+		client := gitlab.NewClientWithHTTPClient(httpClient, cfg.GitLab.BaseURL, cfg.GitLab.Token)
+		client.SetMaxPages(cfg.HTTP.MaxPages)
+		return client, nil
+
+	default:
+		return nil, fmt.Errorf("unknown provider %q. Use \"bitbucket\", \"github\" or \"gitlab\"", cfg.ProviderName())
+	}
+}
+
+// newHTTPClient returns the HTTP client for the provider backends, with the
+// http settings from config; --timeout/--total-timeout override the timeouts.
+// With --verbose every request is logged to stderr.
+func newHTTPClient(cfg *config.Config) (*http.Client, error) {
+	opts := httpx.Options{
+		Timeout:      cfg.HTTP.Timeout,
+		TotalTimeout: cfg.HTTP.TotalTimeout,
+		Proxy:        cfg.HTTP.Proxy,
+		CABundle:     cfg.HTTP.CABundle,
+		RateLimit:    cfg.HTTP.RateLimit,
+		BreakAfter:   httpx.DefaultBreakAfter,
+		Retry: httpx.RetryPolicy{
+			MaxAttempts: cfg.HTTP.Retry.MaxAttempts,
+			BaseDelay:   cfg.HTTP.Retry.BaseDelay,
+			Jitter:      cfg.HTTP.Retry.Jitter,
+			StatusCodes: cfg.HTTP.Retry.StatusCodes,
+		},
+		Stats:  runStats,
+		Record: flagRecord,
+		Replay: flagReplay,
+	}
+	if rootCmd.PersistentFlags().Changed("timeout") {
+		opts.Timeout = flagTimeout
+	}
+	if rootCmd.PersistentFlags().Changed("total-timeout") {
+		opts.TotalTimeout = flagTotalTimeout
+	}
+	if verbose {
+		opts.Log = os.Stderr
+	}
+	return httpx.NewClient(opts)
+}
+
+// requireCapability returns client as T, or an error when the configured
+// provider does not support the feature behind command.
+func requireCapability[T any](cfg *config.Config, client provider.Provider, command string) (T, error) {
+	c, ok := client.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("'buck %s' is not supported for provider %q", command, cfg.ProviderName())
+	}
+	return c, nil
+}
+
+// authPrompts allows asking to log in again when the OAuth refresh token is
+// rejected. Shell completion turns it off: nobody would see the prompt.
+var authPrompts = true
+
 // buildAuthApplier creates the appropriate AuthApplier based on config.
 func buildAuthApplier(cfg *config.Config) (bitbucket.AuthApplier, error) {
 	switch cfg.AuthMethod() {
@@ -17,16 +114,49 @@ func buildAuthApplier(cfg *config.Config) (bitbucket.AuthApplier, error) {
 		}
 		return bitbucket.BasicAuth(cfg.ApiToken.Email, cfg.ApiToken.Token), nil
 
+	case "app_password":
+		if cfg.AppPassword.Username == "" || cfg.AppPassword.Password == "" {
+			return nil, fmt.Errorf("app_password credentials not configured.\nSet app_password.username and app_password.password in .buck.yaml")
+		}
+		return bitbucket.BasicAuth(cfg.AppPassword.Username, cfg.AppPassword.Password), nil
+
 	case "oauth":
 		if cfg.OAuth.ClientID == "" || cfg.OAuth.ClientSecret == "" {
 			return nil, fmt.Errorf("OAuth credentials not configured.\nSet them in .buck.yaml or via environment variables:\n  BITBUCKET_OAUTH_CLIENT_ID\n  BITBUCKET_OAUTH_CLIENT_SECRET")
 		}
+		if err := applyOAuthSettings(cfg); err != nil {
+			return nil, err
+		}
+		if authPrompts && term.IsTerminal(os.Stdin.Fd()) {
+			auth.ReloginPrompt = func() bool {
+				return confirmAction("Your Bitbucket login has expired. Log in again now?")
+			}
+		}
+		auth.Warm(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret)
 		tokenFn := func() (string, error) {
 			return auth.GetToken(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret)
 		}
 		return bitbucket.BearerAuth(tokenFn), nil
 
 	default:
-		return nil, fmt.Errorf("unknown auth method %q. Use \"oauth\" or \"api_token\"", cfg.AuthMethod())
+		return nil, fmt.Errorf("unknown auth method %q. Use \"api_token\", \"oauth\" or \"app_password\"", cfg.AuthMethod())
+	}
+}
+
+// applyOAuthSettings passes the oauth.profile, oauth.callback_port and
+// oauth.callback_page settings to the login flow.
+func applyOAuthSettings(cfg *config.Config) error {
+	auth.Profile = cfg.OAuth.Profile
+	if cfg.OAuth.CallbackPort != 0 {
+		auth.CallbackPort = strconv.Itoa(cfg.OAuth.CallbackPort)
+	}
+	if cfg.OAuth.CallbackPage == "" {
+		return nil
+	}
+	page, err := auth.LoadCallbackPage(cfg.OAuth.CallbackPage)
+	if err != nil {
+		return err
 	}
+	auth.CallbackPage = page
+	return nil
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/creator"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+var (
+	backportFlagGroup       string
+	backportFlagRepos       string
+	backportFlagFrom        string
+	backportFlagOnto        string
+	backportFlagDryRun      bool
+	backportFlagInteractive bool
+	backportFlagYes         bool
+)
+
+var backportCmd = &cobra.Command{
+	Use:   "backport <branch-name>",
+	Short: "Branch from a tag or commit and open PRs into a maintenance branch",
+	Long: `Create <branch-name> from a tag or commit (--from) across repos, then open
+pull requests from it into a maintenance branch (--onto), e.g. release/1.x.
+PRs are only opened in repos where the branch was created.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackport,
+}
+
+func init() {
+	backportCmd.Flags().StringVarP(&backportFlagGroup, "group", "g", "", "repo group from config")
+	backportCmd.Flags().StringVarP(&backportFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	backportCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	backportCmd.Flags().StringVarP(&backportFlagFrom, "from", "f", "", "tag or commit to branch from (required)")
+	backportCmd.Flags().StringVar(&backportFlagOnto, "onto", "", "maintenance branch the PRs target, e.g. release/1.x (required)")
+	backportCmd.Flags().BoolVar(&backportFlagDryRun, "dry-run", false, "preview actions without executing")
+	backportCmd.Flags().BoolVarP(&backportFlagInteractive, "interactive", "i", false, "select repos interactively")
+	backportCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
+	backportCmd.Flags().BoolVarP(&backportFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	_ = backportCmd.MarkFlagRequired("from")
+	_ = backportCmd.MarkFlagRequired("onto")
+
+	_ = backportCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = backportCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+	_ = backportCmd.RegisterFlagCompletionFunc("onto", completeBranchNames)
+
+	rootCmd.AddCommand(backportCmd)
+}
+
+func runBackport(cmd *cobra.Command, args []string) error {
+	branchName := args[0]
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	branchName = prefixBranch(ctx.cfg, branchName)
+	if err := ctx.selectRepos(backportFlagRepos, backportFlagGroup, backportFlagInteractive); err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if backportFlagDryRun {
+		bold.Printf("Dry run: would create branch %q from %q and open PRs into %q in:\n", branchName, backportFlagFrom, backportFlagOnto)
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun(fmt.Sprintf("backport %q into %q", backportFlagFrom, backportFlagOnto), ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), backportFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Creating branch %q from %q across %d repos...\n", branchName, backportFlagFrom, len(ctx.repos))
+	branches := creator.NewBranchCreator(ctx.client).CreateBranchesAt(ctx.cfg.Workspace, ctx.repos, branchName, backportFlagFrom)
+	creator.PrintResults(branches)
+
+	var created []string
+	for _, r := range branches {
+		if r.Success {
+			created = append(created, r.RepoSlug)
+		}
+	}
+	if len(created) == 0 {
+		return fmt.Errorf("branch %q was not created in any repo, no PRs opened", branchName)
+	}
+
+	fmt.Println()
+	bold.Printf("Opening PRs from %q into %q across %d repos...\n", branchName, backportFlagOnto, len(created))
+	// --onto is explicit, so configured per-repo destinations do not apply
+	opts := prCreatorOptions(ctx.cfg)
+	opts.Destinations = nil
+	prs := pullrequest.NewPRCreator(ctx.client, opts).CreatePRs(ctx.cfg.Workspace, created, branchName, backportFlagOnto)
+	pullrequest.PrintResults(prs)
+	return nil
+}
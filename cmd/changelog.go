@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/changelog"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+var (
+	changelogFlagGroup       string
+	changelogFlagRepos       string
+	changelogFlagInteractive bool
+	changelogFlagFrom        string
+	changelogFlagTo          string
+	changelogFlagDescribe    string
+	changelogFlagOut         string
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog --from <tag> [--to <tag>]",
+	Short: "Write one markdown changelog of the commits between two tags across repos",
+	Long: `Collect the commits between --from and --to (tags, branches or commits) in
+each repo and write them as one markdown document with a section per repo.
+Conventional commits are grouped into Features, Fixes and Chores as in PR
+descriptions; merge commits are left out. Without --to, the changes up to each
+repo's development branch are listed.`,
+	Example: `  buck changelog --from v1.2.0 --to v1.3.0 -g backend > CHANGELOG-1.3.0.md
+  buck changelog --from v1.3.0 -g backend --describe hashes,authors`,
+	Args: cobra.NoArgs,
+	RunE: runChangelog,
+}
+
+func init() {
+	changelogCmd.Flags().StringVarP(&changelogFlagGroup, "group", "g", "", "repo group from config")
+	changelogCmd.Flags().StringVarP(&changelogFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	changelogCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	changelogCmd.Flags().BoolVarP(&changelogFlagInteractive, "interactive", "i", false, "select repos interactively")
+	changelogCmd.Flags().StringVar(&changelogFlagFrom, "from", "", "tag or commit the changelog starts after (required)")
+	changelogCmd.Flags().StringVar(&changelogFlagTo, "to", "", "tag or commit the changelog ends at (default: each repo's development branch)")
+	changelogCmd.Flags().StringVar(&changelogFlagDescribe, "describe", "", "commit list options: hashes,authors,bodies,by-author")
+	changelogCmd.Flags().StringVar(&changelogFlagOut, "out", "", "write the changelog to this file instead of stdout")
+	_ = changelogCmd.MarkFlagRequired("from")
+
+	_ = changelogCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = changelogCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+	_ = changelogCmd.RegisterFlagCompletionFunc("describe", completeStaticValues([]string{"hashes", "authors", "bodies", "by-author"}))
+
+	rootCmd.AddCommand(changelogCmd)
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	opts, err := pullrequest.ParseDescriptionOptions(changelogFlagDescribe)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(changelogFlagRepos, changelogFlagGroup, changelogFlagInteractive); err != nil {
+		return err
+	}
+
+	// Progress goes to stderr so stdout carries only the markdown
+	color.New(color.Bold).Fprintf(os.Stderr, "Collecting commits since %s across %d repos...\n", changelogFlagFrom, len(ctx.repos))
+	changes := changelog.NewCollector(ctx.client).Collect(ctx.cfg.Workspace, ctx.repos, changelogFlagFrom, changelogFlagTo)
+
+	failed := 0
+	for _, r := range changes {
+		if r.Error != "" {
+			failed++
+			fmt.Fprintf(os.Stderr, "Warning: %s left out: %s\n", r.RepoSlug, r.Error)
+		}
+	}
+	if failed == len(changes) {
+		return fmt.Errorf("no changelog written: none of the %d repos could be read", len(changes))
+	}
+
+	doc := changelog.Render(changes, changelogFlagFrom, changelogFlagTo, opts)
+	if changelogFlagOut == "" {
+		fmt.Print(doc)
+		return nil
+	}
+	if err := os.WriteFile(changelogFlagOut, []byte(doc), 0644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	color.New(color.FgGreen).Fprintf(os.Stderr, "✓ Changelog for %d repos written to %s\n", len(changes)-failed, changelogFlagOut)
+	return nil
+}
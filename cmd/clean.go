@@ -5,7 +5,6 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/cleanup"
 	"github.com/chinhstringee/buck/internal/config"
 	"github.com/chinhstringee/buck/internal/gitutil"
@@ -30,9 +29,11 @@ var cleanCmd = &cobra.Command{
 func init() {
 	cleanCmd.Flags().StringVarP(&cleanFlagGroup, "group", "g", "", "repo group from config")
 	cleanCmd.Flags().StringVarP(&cleanFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	cleanCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
 	cleanCmd.Flags().BoolVarP(&cleanFlagInteractive, "interactive", "i", false, "select repos interactively")
 	cleanCmd.Flags().BoolVar(&cleanFlagDryRun, "dry-run", false, "preview actions without executing")
 	cleanCmd.Flags().BoolVarP(&cleanFlagYes, "yes", "y", false, "skip confirmation prompt")
+	cleanCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
 	cleanCmd.Flags().BoolVar(&cleanFlagMerged, "merged", false, "delete all branches with merged PRs")
 
 	_ = cleanCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
@@ -54,12 +55,13 @@ func runClean(cmd *cobra.Command, args []string) error {
 	var repos []string
 	var workspace string
 
-	autoDetect := cleanFlagRepos == "" && cleanFlagGroup == "" && !cleanFlagInteractive
+	autoDetect := cleanFlagRepos == "" && flagReposFile == "" && cleanFlagGroup == "" && !cleanFlagInteractive
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	branchName = prefixBranch(cfg, branchName)
 
 	if autoDetect {
 		ws, repoSlug, gitErr := gitutil.ParseBitbucketRemote()
@@ -78,13 +80,11 @@ func runClean(cmd *cobra.Command, args []string) error {
 		workspace = cfg.Workspace
 	}
 
-	authApplier, err := buildAuthApplier(cfg)
+	client, err := buildProvider(cfg)
 	if err != nil {
 		return err
 	}
 
-	client := bitbucket.NewClient(authApplier)
-
 	if len(repos) == 0 {
 		repos, err = resolveTargetRepos(cleanFlagRepos, cleanFlagGroup, cleanFlagInteractive, cfg, client)
 		if err != nil {
@@ -109,7 +109,7 @@ func runCleanBranch(bold *color.Color, cleaner *cleanup.BranchCleaner, workspace
 	if cleanFlagDryRun {
 		bold.Printf("Dry run: would delete branch %q from:\n", branchName)
 		for _, r := range repos {
-			fmt.Printf("  - %s/%s\n", workspace, r)
+			fmt.Printf("  - %s\n", repoPath(workspace, r))
 		}
 		return nil
 	}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/compare"
+)
+
+var (
+	compareFlagGroup       string
+	compareFlagRepos       string
+	compareFlagDestination string
+	compareFlagInteractive bool
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <branch-name>",
+	Short: "Show how far a branch is ahead of and behind its destination per repo",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCompare,
+}
+
+func init() {
+	compareCmd.Flags().StringVarP(&compareFlagGroup, "group", "g", "", "repo group from config")
+	compareCmd.Flags().StringVarP(&compareFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	compareCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	compareCmd.Flags().StringVarP(&compareFlagDestination, "destination", "d", "", "branch to compare against (default: each repo's development branch)")
+	compareCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
+	compareCmd.Flags().BoolVarP(&compareFlagInteractive, "interactive", "i", false, "select repos interactively")
+
+	_ = compareCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = compareCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+	_ = compareCmd.RegisterFlagCompletionFunc("destination", completeBranchNames)
+
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	branchName := args[0]
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	branchName = prefixBranch(ctx.cfg, branchName)
+	if err := ctx.selectRepos(compareFlagRepos, compareFlagGroup, compareFlagInteractive); err != nil {
+		return err
+	}
+
+	color.New(color.Bold).Printf("Comparing %q across %d repos...\n", branchName, len(ctx.repos))
+	results := compare.NewComparer(ctx.client).Compare(ctx.cfg.Workspace, ctx.repos, branchName, compareFlagDestination)
+	compare.PrintResults(results, branchName)
+	return nil
+}
@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repocache"
 	"github.com/spf13/cobra"
 )
 
@@ -83,29 +87,64 @@ func completeGroupNames(cmd *cobra.Command, args []string, toComplete string) ([
 	return names, cobra.ShellCompDirectiveNoFileComp
 }
 
-// completeRepoSlugs returns unique repo slugs from all groups for shell completion.
+// completeRepoSlugs completes the last entry of a comma-separated --repos
+// value from the workspace repos cached by the last listing, plus group
+// entries. An entry starting "workspace/" completes from that workspace.
 func completeRepoSlugs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	cfg, err := config.Load()
-	if err != nil || cfg.Groups == nil {
+	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
+	// Complete only the entry being typed, keeping the ones before it
+	done, current := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		done, current = toComplete[:i+1], toComplete[i+1:]
+	}
 	seen := make(map[string]bool)
+	for _, entry := range strings.Split(done, ",") {
+		seen[entry] = true
+	}
+
 	var slugs []string
+	add := func(slug string) {
+		if !seen[slug] && strings.HasPrefix(slug, current) {
+			seen[slug] = true
+			slugs = append(slugs, done+slug)
+		}
+	}
+	for _, slug := range repocache.Slugs(cfg.Workspace) {
+		add(slug)
+	}
+	// "workspace/" completes from that workspace's cached repos
+	if i := strings.LastIndex(current, "/"); i > 0 {
+		ws := current[:i]
+		for _, slug := range repocache.Slugs(ws) {
+			add(ws + "/" + slug)
+		}
+	}
 	for _, repos := range cfg.Groups {
 		for _, slug := range repos {
-			if !seen[slug] && strings.HasPrefix(slug, toComplete) {
-				seen[slug] = true
-				slugs = append(slugs, slug)
-			}
+			add(slug)
 		}
 	}
+	sort.Strings(slugs)
 	return slugs, cobra.ShellCompDirectiveNoFileComp
 }
 
-// completeBranchNames returns common branch names for shell completion.
+// completionTimeout bounds the API call behind a completion, so a slow
+// network cannot hang the shell.
+const completionTimeout = 3 * time.Second
+
+// completeBranchNames completes branch names. When --repos names a single
+// repo, its branches are fetched from the API; otherwise, or if that fails,
+// common branch names are offered.
 func completeBranchNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	branches := []string{"main", "master", "develop"}
+	if names, ok := repoBranchNames(cmd); ok {
+		branches = names
+	}
+
 	var results []string
 	for _, b := range branches {
 		if strings.HasPrefix(b, toComplete) {
@@ -115,6 +154,40 @@ func completeBranchNames(cmd *cobra.Command, args []string, toComplete string) (
 	return results, cobra.ShellCompDirectiveNoFileComp
 }
 
+// repoBranchNames lists the branches of the single repo given with --repos.
+func repoBranchNames(cmd *cobra.Command) ([]string, bool) {
+	flag := cmd.Flags().Lookup("repos")
+	if flag == nil || !singleRepo(flag.Value.String()) {
+		return nil, false
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg.Workspace == "" {
+		return nil, false
+	}
+	cfg.HTTP.Timeout = completionTimeout
+	cfg.HTTP.TotalTimeout = completionTimeout
+	authPrompts = false
+	client, err := buildProvider(cfg)
+	if err != nil {
+		return nil, false
+	}
+	branches, err := client.ListBranches(provider.SplitRepo(cfg.Workspace, flag.Value.String()))
+	if err != nil {
+		return nil, false
+	}
+	names := make([]string, len(branches))
+	for i, b := range branches {
+		names[i] = b.Name
+	}
+	return names, true
+}
+
+// singleRepo reports whether a --repos value names exactly one repo, rather
+// than a list, a glob, an exclusion or stdin.
+func singleRepo(value string) bool {
+	return value != "" && value != "-" && !strings.ContainsAny(value, ",*?[!")
+}
+
 // completeStaticValues returns a completion function for a fixed set of values.
 func completeStaticValues(values []string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/chinhstringee/buck/internal/repocache"
 )
 
 // resetViper resets Viper state between tests to avoid test pollution.
@@ -208,6 +210,88 @@ func TestCompleteRepoSlugs_EmptyConfig(t *testing.T) {
 	}
 }
 
+// TestCompleteRepoSlugs_CachedWorkspace verifies slugs from the last listing are offered.
+func TestCompleteRepoSlugs_CachedWorkspace(t *testing.T) {
+	resetViper()
+	defer resetViper()
+	t.Setenv("HOME", t.TempDir())
+
+	viper.Set("workspace", "ws")
+	viper.Set("groups", map[string]interface{}{"backend": []string{"repo-a"}})
+	if err := repocache.Save("ws", []string{"repo-a", "repo-z", "web"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	results, _ := completeRepoSlugs(&cobra.Command{}, []string{}, "repo")
+
+	if want := []string{"repo-a", "repo-z"}; !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+func TestCompleteRepoSlugs_QualifiedWorkspace(t *testing.T) {
+	resetViper()
+	defer resetViper()
+	t.Setenv("HOME", t.TempDir())
+
+	viper.Set("workspace", "ws")
+	if err := repocache.Save("ops", []string{"infra", "api-gateway"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	results, _ := completeRepoSlugs(&cobra.Command{}, []string{}, "api,ops/in")
+
+	if want := []string{"api,ops/infra"}; !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+// TestCompleteRepoSlugs_CommaSeparated verifies only the last entry is completed.
+func TestCompleteRepoSlugs_CommaSeparated(t *testing.T) {
+	resetViper()
+	defer resetViper()
+	t.Setenv("HOME", t.TempDir())
+
+	viper.Set("groups", map[string]interface{}{"backend": []string{"repo-a", "repo-b", "web"}})
+
+	results, _ := completeRepoSlugs(&cobra.Command{}, []string{}, "repo-a,re")
+
+	if want := []string{"repo-a,repo-b"}; !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+// TestCompleteBranchNames_RepoListFallsBack verifies common names are offered
+// when --repos names more than one repo.
+func TestCompleteBranchNames_RepoListFallsBack(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("repos", "r", "", "")
+	_ = cmd.Flags().Set("repos", "api,web")
+
+	results, _ := completeBranchNames(cmd, []string{}, "ma")
+
+	if want := []string{"main", "master"}; !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+func TestSingleRepo(t *testing.T) {
+	tests := map[string]bool{
+		"api":      true,
+		"":         false,
+		"api,web":  false,
+		"api-*":    false,
+		"!legacy":  false,
+		"svc-[ab]": false,
+		"-":        false,
+	}
+	for value, want := range tests {
+		if got := singleRepo(value); got != want {
+			t.Errorf("singleRepo(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
 // TestCompleteBranchNames_NoPrefix returns all branch names.
 func TestCompleteBranchNames_NoPrefix(t *testing.T) {
 	cmd := &cobra.Command{}
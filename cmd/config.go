@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/chinhstringee/buck/internal/config"
+)
+
+var (
+	configMigrateFlagDryRun             bool
+	configMigrateFlagYes                bool
+	configMigrateFlagReplaceAppPassword bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Maintain the .buck.yaml config file",
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade an old config file to the current format",
+	Long: `Upgrade settings written for older versions of buck and rewrite the config
+file: top-level keys such as client_id or source_branch move under their
+sections. With --replace-app-password, app_password credentials are replaced
+by an api_token section. The changes are shown as a diff and confirmed
+before the file is written; the old file is kept next to it with a .bak
+suffix. Comments are kept.`,
+	Example: `  buck config migrate --dry-run
+  buck config migrate --config ~/work/.buck.yaml --yes
+  buck config migrate --replace-app-password`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+func init() {
+	configMigrateCmd.Flags().BoolVar(&configMigrateFlagDryRun, "dry-run", false, "show the changes without writing the file")
+	configMigrateCmd.Flags().BoolVarP(&configMigrateFlagYes, "yes", "y", false, "write the changes without asking")
+	configMigrateCmd.Flags().BoolVar(&configMigrateFlagReplaceAppPassword, "replace-app-password", false, "switch app_password credentials to an api_token section")
+	configCmd.AddCommand(configMigrateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return fmt.Errorf("no config file found; pass --config or run from the directory with .buck.yaml")
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	after, notes, left, err := config.Migrate(before, configMigrateFlagReplaceAppPassword)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		fmt.Printf("%s is up to date.\n", path)
+		printLeftKeys(left)
+		return nil
+	}
+
+	fmt.Printf("Changes to %s:\n\n", path)
+	red, green := color.New(color.FgRed), color.New(color.FgGreen)
+	for _, line := range strings.SplitAfter(config.Diff(before, after, 2), "\n") {
+		switch {
+		case strings.HasPrefix(line, "- "):
+			red.Print(line)
+		case strings.HasPrefix(line, "+ "):
+			green.Print(line)
+		default:
+			fmt.Print(line)
+		}
+	}
+	fmt.Println()
+	for _, note := range notes {
+		fmt.Printf("  • %s\n", note)
+	}
+	fmt.Println()
+	printLeftKeys(left)
+
+	if configMigrateFlagDryRun {
+		fmt.Println("Dry run: nothing written.")
+		return nil
+	}
+	if !configMigrateFlagYes && !confirmAction("Rewrite the config file?") {
+		return fmt.Errorf("migration cancelled")
+	}
+
+	backup := path + ".bak"
+	if err := os.WriteFile(backup, before, 0o600); err != nil {
+		return fmt.Errorf("failed to back up config: %w", err)
+	}
+	if err := config.WriteFile(path, after); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s (previous version saved as %s).\n", path, backup)
+	return nil
+}
+
+// printLeftKeys lists old keys that Migrate could not move, for the user to
+// remove by hand.
+func printLeftKeys(left []string) {
+	if len(left) == 0 {
+		return
+	}
+	yellow := color.New(color.FgYellow)
+	for _, note := range left {
+		yellow.Printf("  ! %s; remove it by hand\n", note)
+	}
+	fmt.Println()
+}
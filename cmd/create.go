@@ -5,19 +5,38 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
 	"github.com/chinhstringee/buck/internal/creator"
+	"github.com/chinhstringee/buck/internal/hooks"
+	"github.com/chinhstringee/buck/internal/render"
 )
 
 var (
 	flagGroup       string
 	flagRepos       string
 	flagFrom        string
+	flagAt          string
 	flagDryRun      bool
+	flagCheck       bool
+	flagWithPR      bool
 	flagInteractive bool
+	flagYes         bool
 )
 
+// flagNoPrefix is the --no-prefix flag, shared by every command that takes a
+// branch name.
+var flagNoPrefix bool
+
+// prefixBranch applies defaults.branch_prefix to a branch name given on the
+// command line, unless --no-prefix is set, so every command finds the branch
+// 'buck create' made from the same name.
+func prefixBranch(cfg *config.Config, name string) string {
+	if flagNoPrefix || name == "" {
+		return name
+	}
+	return cfg.Defaults.PrefixBranch(name)
+}
+
 var createCmd = &cobra.Command{
 	Use:   "create <branch-name>",
 	Short: "Create a branch across multiple Bitbucket repos",
@@ -28,13 +47,20 @@ var createCmd = &cobra.Command{
 func init() {
 	createCmd.Flags().StringVarP(&flagGroup, "group", "g", "", "repo group from config")
 	createCmd.Flags().StringVarP(&flagRepos, "repos", "r", "", "comma-separated repo slugs")
-	createCmd.Flags().StringVarP(&flagFrom, "from", "f", "", "source branch (default: from config or master)")
+	createCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	createCmd.Flags().StringVarP(&flagFrom, "from", "f", "", "source branch (default: from config or each repo's development branch)")
+	createCmd.Flags().StringVar(&flagAt, "at", "", "tag or commit to create the branch at, instead of a branch tip")
 	createCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "preview actions without executing")
+	createCmd.Flags().BoolVar(&flagCheck, "check", false, "with --dry-run, verify repos, source and branch with read-only API calls")
+	createCmd.Flags().BoolVar(&flagWithPR, "with-pr", false, "open a PR from the new branch in each repo where it was created")
 	createCmd.Flags().BoolVarP(&flagInteractive, "interactive", "i", false, "select repos interactively")
+	createCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	createCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
 
 	_ = createCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
 	_ = createCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
 	_ = createCmd.RegisterFlagCompletionFunc("from", completeBranchNames)
+	createCmd.MarkFlagsMutuallyExclusive("from", "at")
 
 	rootCmd.AddCommand(createCmd)
 }
@@ -51,13 +77,13 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("workspace not configured in .buck.yaml")
 	}
 
-	authApplier, err := buildAuthApplier(cfg)
+	branchName = prefixBranch(cfg, branchName)
+
+	client, err := buildProvider(cfg)
 	if err != nil {
 		return err
 	}
 
-	client := bitbucket.NewClient(authApplier)
-
 	// Resolve target repos
 	repos, err := resolveTargetRepos(flagRepos, flagGroup, flagInteractive, cfg, client)
 	if err != nil {
@@ -68,29 +94,75 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no repositories selected")
 	}
 
-	// Resolve source branch
+	// Resolve source branch; empty means each repo's development branch
 	sourceBranch := cfg.Defaults.SourceBranch
 	if flagFrom != "" {
 		sourceBranch = flagFrom
 	}
+	sourceLabel := fmt.Sprintf("%q", sourceBranch)
+	if flagAt != "" {
+		sourceBranch = flagAt
+		sourceLabel = fmt.Sprintf("commit %q", flagAt)
+	} else if sourceBranch == "" {
+		sourceLabel = "each repo's development branch"
+	}
+
+	// PRs go back into the branch the new one was cut from; a pinned
+	// commit has no such branch, so PRs use the usual destinations
+	prDestination := sourceBranch
+	if flagAt != "" {
+		prDestination = ""
+	}
+	action := fmt.Sprintf("create branch %q", branchName)
+	if flagWithPR {
+		action += " and open PRs"
+	}
 
 	bold := color.New(color.Bold)
 
 	// Dry run — show plan and exit
 	if flagDryRun {
-		bold.Printf("Dry run: would create branch %q from %q in:\n", branchName, sourceBranch)
+		bold.Printf("Dry run: would %s from %s in:\n", action, sourceLabel)
+		if flagCheck {
+			render.PrintPlan(creator.Plan(creator.NewBranchCreator(client).Preflight(cfg.Workspace, repos, branchName, sourceBranch)))
+			return nil
+		}
 		for _, r := range repos {
 			fmt.Printf("  - %s\n", r)
 		}
 		return nil
 	}
 
-	bold.Printf("Creating branch %q from %q across %d repos...\n", branchName, sourceBranch, len(repos))
+	if !confirmLargeRun(action, cfg.Workspace, repos, cfg.Defaults.ConfirmLimit(), flagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	payload := hooks.Payload{Command: "create", Workspace: cfg.Workspace, Branch: branchName, Source: sourceBranch, Repos: repos}
+	if err := runPreHooks(cfg, payload); err != nil {
+		return err
+	}
+
+	bold.Printf("Creating branch %q from %s across %d repos...\n", branchName, sourceLabel, len(repos))
 
 	bc := creator.NewBranchCreator(client)
-	results := bc.CreateBranches(cfg.Workspace, repos, branchName, sourceBranch)
-	creator.PrintResults(results)
+	var results []creator.Result
+	if flagAt != "" {
+		results = bc.CreateBranchesAt(cfg.Workspace, repos, branchName, flagAt)
+	} else {
+		results = bc.CreateBranches(cfg.Workspace, repos, branchName, sourceBranch)
+	}
+	// With --with-pr, branch results are printed together with the PRs
+	if !flagWithPR {
+		creator.PrintResults(results)
+	}
 
+	payload.Results = branchHookResults(results)
+	runPostHooks(cfg, payload)
+
+	if flagWithPR {
+		return openPRsForBranches(cfg, client, cfg.Workspace, prCreatorOptions(cfg), results, branchName, prDestination)
+	}
 	return nil
 }
 
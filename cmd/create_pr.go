@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/creator"
+	"github.com/chinhstringee/buck/internal/hooks"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+// openPRsForBranches opens PRs from branchName in the repos of workspace
+// where the branch was created, into destination (empty: the destinations
+// of opts, then each repo's development branch), and prints one combined
+// table for both steps.
+func openPRsForBranches(cfg *config.Config, client provider.Provider, workspace string, opts pullrequest.Options, branches []creator.Result, branchName, destination string) error {
+	var created []string
+	for _, r := range branches {
+		if r.Success {
+			created = append(created, r.RepoSlug)
+		}
+	}
+	if len(created) == 0 {
+		printShipResults(branches, nil)
+		return fmt.Errorf("branch %q was not created in any repo, no PRs opened", branchName)
+	}
+
+	payload := hooks.Payload{Command: "pr", Workspace: workspace, Branch: branchName, Destination: destination, Repos: created}
+	if err := runPreHooks(cfg, payload); err != nil {
+		printShipResults(branches, nil)
+		return err
+	}
+
+	color.New(color.Bold).Printf("Opening PRs from %q across %d repos...\n", branchName, len(created))
+	prs := pullrequest.NewPRCreator(client, opts).CreatePRs(workspace, created, branchName, destination)
+	printShipResults(branches, prs)
+
+	payload.Results = prHookResults(prs)
+	runPostHooks(cfg, payload)
+	return nil
+}
+
+// shipResult is one repo's row of printShipResults in JSON output.
+type shipResult struct {
+	Repo   string              `json:"repo"`
+	Branch creator.Result      `json:"branch"`
+	PR     *pullrequest.Result `json:"pr,omitempty"`
+}
+
+// printShipResults displays branch and PR results as one row per repo.
+// A repo succeeds only if both its branch and its PR were created.
+func printShipResults(branches []creator.Result, prs []pullrequest.Result) {
+	prBySlug := make(map[string]pullrequest.Result, len(prs))
+	for _, pr := range prs {
+		prBySlug[pr.RepoSlug] = pr
+	}
+
+	rows := make([]render.Row, len(branches))
+	data := make([]shipResult, len(branches))
+	for i, b := range branches {
+		data[i] = shipResult{Repo: b.RepoSlug, Branch: b}
+		if !b.Success {
+			rows[i] = creator.Row(b)
+			rows[i].Message = "branch: " + b.Error
+			continue
+		}
+		pr, ok := prBySlug[b.RepoSlug]
+		switch {
+		case !ok:
+			rows[i] = render.Row{
+				Repo:     b.RepoSlug,
+				Status:   render.Failed,
+				Message:  fmt.Sprintf("branch created from %s (%s), no PR opened", b.Source, b.CommitHash),
+				Category: provider.ErrOther,
+			}
+		case !pr.Success:
+			data[i].PR = &pr
+			rows[i] = pullrequest.FailedRow(pr)
+			rows[i].Message = fmt.Sprintf("branch created from %s (%s), PR: %s", b.Source, b.CommitHash, pr.Error)
+		default:
+			data[i].PR = &pr
+			rows[i] = render.Row{
+				Repo:    b.RepoSlug,
+				Message: fmt.Sprintf("branch from %s (%s), PR #%d", b.Source, b.CommitHash, pr.PRID),
+				Link:    pr.PRURL,
+				Notes:   pullrequest.WarningNotes(pr),
+			}
+		}
+	}
+	render.Print(render.Report{Rows: rows, Data: data, LinkBlock: true})
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var (
+	defaultBranchFlagGroup       string
+	defaultBranchFlagRepos       string
+	defaultBranchFlagInteractive bool
+	defaultBranchFlagDryRun      bool
+	defaultBranchFlagYes         bool
+)
+
+var defaultBranchCmd = &cobra.Command{
+	Use:   "default-branch",
+	Short: "Manage the default branch across repos",
+}
+
+var defaultBranchSetCmd = &cobra.Command{
+	Use:   "set <branch-name>",
+	Short: "Change the default branch across repos",
+	Long: `Change the default (main) branch across repos. Repos where the branch
+does not exist are reported as failures and left unchanged.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDefaultBranchSet,
+}
+
+func init() {
+	defaultBranchSetCmd.Flags().StringVarP(&defaultBranchFlagGroup, "group", "g", "", "repo group from config")
+	defaultBranchSetCmd.Flags().StringVarP(&defaultBranchFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	defaultBranchSetCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	defaultBranchSetCmd.Flags().BoolVarP(&defaultBranchFlagInteractive, "interactive", "i", false, "select repos interactively")
+	defaultBranchSetCmd.Flags().BoolVar(&defaultBranchFlagDryRun, "dry-run", false, "check branches and preview changes without executing")
+	defaultBranchSetCmd.Flags().BoolVarP(&defaultBranchFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+
+	_ = defaultBranchSetCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = defaultBranchSetCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	defaultBranchCmd.AddCommand(defaultBranchSetCmd)
+	rootCmd.AddCommand(defaultBranchCmd)
+}
+
+func runDefaultBranchSet(cmd *cobra.Command, args []string) error {
+	branchName := args[0]
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.DefaultBranchService](ctx.cfg, ctx.client, "default-branch set")
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(defaultBranchFlagRepos, defaultBranchFlagGroup, defaultBranchFlagInteractive); err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+	setter := repoadmin.NewDefaultBranchSetter(ctx.client, service)
+
+	if defaultBranchFlagDryRun {
+		bold.Printf("Dry run: checking %q as default branch in %d repos...\n", branchName, len(ctx.repos))
+		repoadmin.PrintResults(setter.Preview(ctx.cfg.Workspace, ctx.repos, branchName))
+		return nil
+	}
+
+	if !confirmLargeRun(fmt.Sprintf("set default branch to %q", branchName), ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), defaultBranchFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Setting default branch to %q across %d repos...\n", branchName, len(ctx.repos))
+	repoadmin.PrintResults(setter.Set(ctx.cfg.Workspace, ctx.repos, branchName))
+	return nil
+}
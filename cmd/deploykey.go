@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var (
+	deployKeyFlagGroup       string
+	deployKeyFlagRepos       string
+	deployKeyFlagInteractive bool
+	deployKeyFlagDryRun      bool
+	deployKeyFlagYes         bool
+	deployKeyFlagLabel       string
+	deployKeyFlagKeyFile     string
+)
+
+var deployKeyCmd = &cobra.Command{
+	Use:   "deploy-key",
+	Short: "Manage repository deploy keys across repos",
+}
+
+var deployKeyAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a deploy key across repos",
+	Args:  cobra.NoArgs,
+	RunE:  runDeployKeyAdd,
+}
+
+var deployKeyRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Replace the deploy key with a label across repos",
+	Long: `Add the new key under --label, then remove every other key with that label.
+The new key is added first, so a repo is never left without a working key.`,
+	Args: cobra.NoArgs,
+	RunE: runDeployKeyRotate,
+}
+
+var deployKeyRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the deploy keys with a label across repos",
+	Args:  cobra.NoArgs,
+	RunE:  runDeployKeyRemove,
+}
+
+func init() {
+	// Shared flags available to all deploy-key subcommands
+	deployKeyCmd.PersistentFlags().StringVarP(&deployKeyFlagGroup, "group", "g", "", "repo group from config")
+	deployKeyCmd.PersistentFlags().StringVarP(&deployKeyFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	deployKeyCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	deployKeyCmd.PersistentFlags().BoolVarP(&deployKeyFlagInteractive, "interactive", "i", false, "select repos interactively")
+	deployKeyCmd.PersistentFlags().BoolVar(&deployKeyFlagDryRun, "dry-run", false, "preview actions without executing")
+	deployKeyCmd.PersistentFlags().BoolVarP(&deployKeyFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	deployKeyCmd.PersistentFlags().StringVar(&deployKeyFlagLabel, "label", "", "deploy key label (required)")
+	_ = deployKeyCmd.MarkPersistentFlagRequired("label")
+
+	for _, c := range []*cobra.Command{deployKeyAddCmd, deployKeyRotateCmd} {
+		c.Flags().StringVar(&deployKeyFlagKeyFile, "key-file", "", "SSH public key file (required)")
+		_ = c.MarkFlagRequired("key-file")
+	}
+
+	_ = deployKeyCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = deployKeyCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	deployKeyCmd.AddCommand(deployKeyAddCmd, deployKeyRotateCmd, deployKeyRemoveCmd)
+	rootCmd.AddCommand(deployKeyCmd)
+}
+
+// runDeployKeyAction resolves the target repos, confirms and runs fn.
+func runDeployKeyAction(command, action string, fn func(m *repoadmin.DeployKeyManager, workspace string, repos []string) []repoadmin.Result) error {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.DeployKeyService](ctx.cfg, ctx.client, command)
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(deployKeyFlagRepos, deployKeyFlagGroup, deployKeyFlagInteractive); err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if deployKeyFlagDryRun {
+		bold.Printf("Dry run: would %s in:\n", action)
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun(action, ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), deployKeyFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Running %s across %d repos...\n", command, len(ctx.repos))
+	repoadmin.PrintResults(fn(repoadmin.NewDeployKeyManager(service), ctx.cfg.Workspace, ctx.repos))
+	return nil
+}
+
+func runDeployKeyAdd(cmd *cobra.Command, args []string) error {
+	key, err := repoadmin.LoadPublicKey(deployKeyFlagKeyFile)
+	if err != nil {
+		return err
+	}
+	return runDeployKeyAction("deploy-key add", fmt.Sprintf("add deploy key %q", deployKeyFlagLabel),
+		func(m *repoadmin.DeployKeyManager, workspace string, repos []string) []repoadmin.Result {
+			return m.Add(workspace, repos, deployKeyFlagLabel, key)
+		})
+}
+
+func runDeployKeyRotate(cmd *cobra.Command, args []string) error {
+	key, err := repoadmin.LoadPublicKey(deployKeyFlagKeyFile)
+	if err != nil {
+		return err
+	}
+	return runDeployKeyAction("deploy-key rotate", fmt.Sprintf("rotate deploy key %q", deployKeyFlagLabel),
+		func(m *repoadmin.DeployKeyManager, workspace string, repos []string) []repoadmin.Result {
+			return m.Rotate(workspace, repos, deployKeyFlagLabel, key)
+		})
+}
+
+func runDeployKeyRemove(cmd *cobra.Command, args []string) error {
+	return runDeployKeyAction("deploy-key remove", fmt.Sprintf("remove deploy key %q", deployKeyFlagLabel),
+		func(m *repoadmin.DeployKeyManager, workspace string, repos []string) []repoadmin.Result {
+			return m.Remove(workspace, repos, deployKeyFlagLabel)
+		})
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var (
+	forkFlagGroup       string
+	forkFlagRepos       string
+	forkFlagInteractive bool
+	forkFlagDryRun      bool
+	forkFlagYes         bool
+	forkFlagTo          string
+)
+
+var forkCmd = &cobra.Command{
+	Use:   "fork",
+	Short: "Fork repos into another workspace",
+	Long: `Fork the selected repos of the configured workspace into another workspace
+(--to), e.g. your personal workspace. Repos already present in the target are
+left alone.`,
+	Args: cobra.NoArgs,
+	RunE: runFork,
+}
+
+func init() {
+	forkCmd.Flags().StringVarP(&forkFlagGroup, "group", "g", "", "repo group from config")
+	forkCmd.Flags().StringVarP(&forkFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	forkCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	forkCmd.Flags().BoolVarP(&forkFlagInteractive, "interactive", "i", false, "select repos interactively")
+	forkCmd.Flags().BoolVar(&forkFlagDryRun, "dry-run", false, "preview actions without executing")
+	forkCmd.Flags().BoolVarP(&forkFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	forkCmd.Flags().StringVar(&forkFlagTo, "to", "", "workspace (org or group) to fork into (required)")
+	_ = forkCmd.MarkFlagRequired("to")
+
+	_ = forkCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = forkCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	rootCmd.AddCommand(forkCmd)
+}
+
+func runFork(cmd *cobra.Command, args []string) error {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(forkFlagTo, ctx.cfg.Workspace) {
+		return fmt.Errorf("--to must be a different workspace than %q", ctx.cfg.Workspace)
+	}
+	service, err := requireCapability[provider.ForkService](ctx.cfg, ctx.client, "fork")
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(forkFlagRepos, forkFlagGroup, forkFlagInteractive); err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+	forker := repoadmin.NewForker(ctx.client, service)
+
+	if forkFlagDryRun {
+		bold.Printf("Dry run: checking %d repos to fork into %q...\n", len(ctx.repos), forkFlagTo)
+		repoadmin.PrintResults(forker.Preview(ctx.cfg.Workspace, ctx.repos, forkFlagTo))
+		return nil
+	}
+
+	if !confirmLargeRun(fmt.Sprintf("fork repos into %q", forkFlagTo), ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), forkFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Forking %d repos into %q...\n", len(ctx.repos), forkFlagTo)
+	repoadmin.PrintResults(forker.Fork(ctx.cfg.Workspace, ctx.repos, forkFlagTo))
+	return nil
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/audit"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/matcher"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+var groupsCmd = &cobra.Command{
+	Use:   "groups",
+	Short: "Inspect and edit the repo groups defined in .buck.yaml",
+	Long: `Inspect and edit the repo groups defined in .buck.yaml. Edits change only
+the groups section; comments, key order and the rest of the file are kept.`,
+}
+
+var groupsListCmd = &cobra.Command{
+	Use:               "list [group]",
+	Short:             "List groups and their repos",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeFirstArg(completeGroupNames),
+	RunE:              runGroupsList,
+}
+
+var groupsAddCmd = &cobra.Command{
+	Use:   "add <group> <repo>...",
+	Short: "Add repos to a group, creating it if needed",
+	Example: `  buck groups add backend repo-billing repo-ledger
+  buck groups add legacy 'legacy-*'`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeGroupThenRepos,
+	RunE:              runGroupsAdd,
+}
+
+var groupsRemoveCmd = &cobra.Command{
+	Use:   "remove <group> [repo...]",
+	Short: "Remove repos from a group, or the whole group",
+	Long: `Remove the given repos from a group. Without repos, the group itself is
+removed from .buck.yaml.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeGroupThenRepos,
+	RunE:              runGroupsRemove,
+}
+
+var groupsRenameCmd = &cobra.Command{
+	Use:               "rename <old> <new>",
+	Short:             "Rename a group, keeping its repos",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeFirstArg(completeGroupNames),
+	RunE:              runGroupsRename,
+}
+
+var groupsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Verify every repo in every group still exists in the workspace",
+	Long: `Check each configured group against the repos of the workspaces it names;
+entries without a "workspace/" prefix belong to the default workspace. Slugs
+that no longer exist (deleted or renamed, with a suggested new name when one is
+close) and patterns that match nothing are reported. Exits non-zero when any
+group has problems, so it can run in CI.`,
+	Args: cobra.NoArgs,
+	RunE: runGroupsCheck,
+}
+
+func init() {
+	groupsCmd.AddCommand(groupsListCmd, groupsAddCmd, groupsRemoveCmd, groupsRenameCmd, groupsCheckCmd)
+	rootCmd.AddCommand(groupsCmd)
+}
+
+func runGroupsCheck(cmd *cobra.Command, args []string) error {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	if len(ctx.cfg.Groups) == 0 {
+		fmt.Println("No groups configured in .buck.yaml.")
+		return nil
+	}
+
+	candidates := make(map[string][]matcher.Candidate)
+	total := 0
+	for _, ws := range audit.GroupWorkspaces(ctx.cfg.Groups, ctx.cfg.Workspace) {
+		fmt.Printf("Fetching repos from workspace %q...\n", ws)
+		repos, err := listWorkspaceRepositories(ctx.cfg, ctx.client, ws)
+		if err != nil {
+			return fmt.Errorf("failed to list repos in workspace %q: %w", ws, err)
+		}
+		candidates[ws] = repoCandidates(repos)
+		total += len(repos)
+	}
+
+	color.New(color.Bold).Printf("Checking %d groups against %d repos...\n", len(ctx.cfg.Groups), total)
+	if broken := audit.PrintGroupChecks(audit.CheckGroups(ctx.cfg.Groups, ctx.cfg.Workspace, candidates)); broken > 0 {
+		return fmt.Errorf("%d groups have problems", broken)
+	}
+	return nil
+}
+
+func runGroupsList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	groups := cfg.Groups
+	if len(args) == 1 {
+		repos, err := cfg.GetReposForGroup(args[0])
+		if err != nil {
+			return err
+		}
+		groups = map[string][]string{args[0]: repos}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch render.Output {
+	case render.JSON:
+		return render.WriteJSON(groups)
+	case render.Quiet:
+		for _, name := range names {
+			fmt.Fprintln(render.Stdout, name)
+		}
+		return nil
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No groups configured in .buck.yaml. Add one with 'buck groups add <group> <repo>...'.")
+		return nil
+	}
+	bold := color.New(color.Bold)
+	for _, name := range names {
+		bold.Printf("%s", name)
+		fmt.Printf(" (%d)\n", len(groups[name]))
+		for _, repo := range groups[name] {
+			fmt.Printf("  %s\n", repo)
+		}
+	}
+	return nil
+}
+
+func runGroupsAdd(cmd *cobra.Command, args []string) error {
+	name, repos := args[0], args[1:]
+	if err := matcher.Validate(repos); err != nil {
+		return err
+	}
+
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	added, err := config.AddToGroup(path, name, repos)
+	if err != nil {
+		return err
+	}
+
+	if len(added) == 0 {
+		fmt.Printf("Group %q already contains %s.\n", name, strings.Join(repos, ", "))
+		return nil
+	}
+	color.New(color.FgGreen).Printf("✓ Added %s to group %q in %s\n", strings.Join(added, ", "), name, path)
+	return nil
+}
+
+func runGroupsRemove(cmd *cobra.Command, args []string) error {
+	name, repos := args[0], args[1:]
+
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen)
+	if len(repos) == 0 {
+		if err := config.DeleteGroup(path, name); err != nil {
+			return err
+		}
+		green.Printf("✓ Removed group %q from %s\n", name, path)
+		return nil
+	}
+
+	removed, err := config.RemoveFromGroup(path, name, repos)
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return fmt.Errorf("group %q does not contain %s", name, strings.Join(repos, ", "))
+	}
+	green.Printf("✓ Removed %s from group %q in %s\n", strings.Join(removed, ", "), name, path)
+	return nil
+}
+
+func runGroupsRename(cmd *cobra.Command, args []string) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if err := config.RenameGroup(path, args[0], args[1]); err != nil {
+		return err
+	}
+	color.New(color.FgGreen).Printf("✓ Renamed group %q to %q in %s\n", args[0], args[1], path)
+	return nil
+}
+
+// completeFirstArg completes the first positional argument with fn and
+// offers nothing after it.
+func completeFirstArg(fn func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// completeGroupThenRepos completes a group name, then repo slugs.
+func completeGroupThenRepos(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeGroupNames(cmd, args, toComplete)
+	}
+	return completeRepoSlugs(cmd, args, toComplete)
+}
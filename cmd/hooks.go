@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/creator"
+	"github.com/chinhstringee/buck/internal/hooks"
+	"github.com/chinhstringee/buck/internal/httpx"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+// runPreHooks runs the pre_<command> hooks; an error aborts the command.
+func runPreHooks(cfg *config.Config, p hooks.Payload) error {
+	p.Event = "pre_" + p.Command
+	if err := runHooks(cfg, p); err != nil {
+		return fmt.Errorf("%s: %w\n  Hint: nothing was changed", p.Event, err)
+	}
+	return nil
+}
+
+// runPostHooks runs the post_<command> hooks. Failures are reported but do not
+// fail the command, since the changes have already been made.
+func runPostHooks(cfg *config.Config, p hooks.Payload) {
+	p.Event = "post_" + p.Command
+	if err := runHooks(cfg, p); err != nil {
+		color.New(color.FgYellow).Printf("Warning: %s: %v\n", p.Event, err)
+	}
+}
+
+// runHooks runs the hooks of p.Event. URL hooks are sent with the http
+// settings from config, so they go through http.proxy and trust
+// http.ca_bundle like API requests.
+func runHooks(cfg *config.Config, p hooks.Payload) error {
+	if len(cfg.Hooks[p.Event]) == 0 {
+		return nil
+	}
+	client, err := httpx.NewClient(httpx.Options{
+		Timeout:  cfg.HTTP.Timeout,
+		Proxy:    cfg.HTTP.Proxy,
+		CABundle: cfg.HTTP.CABundle,
+	})
+	if err != nil {
+		return err
+	}
+	hooks.HTTPClient = client
+	return hooks.Run(cfg.Hooks[p.Event], p)
+}
+
+// branchHookResults converts branch creation results for post hooks.
+func branchHookResults(results []creator.Result) []hooks.Result {
+	out := make([]hooks.Result, len(results))
+	for i, r := range results {
+		out[i] = hooks.Result{
+			Repo: r.RepoSlug, Success: r.Success, Error: r.Error, URL: r.BranchURL,
+			ErrorCategory: string(r.ErrorCategory), StatusCode: r.StatusCode,
+			StartedAt: r.StartedAt, FinishedAt: r.FinishedAt, Duration: r.Duration,
+		}
+	}
+	return out
+}
+
+// prHookResults converts PR results for post hooks.
+func prHookResults(results []pullrequest.Result) []hooks.Result {
+	out := make([]hooks.Result, len(results))
+	for i, r := range results {
+		out[i] = hooks.Result{
+			Repo: r.RepoSlug, Success: r.Success, Error: r.Error, URL: r.PRURL,
+			ErrorCategory: string(r.ErrorCategory), StatusCode: r.StatusCode,
+			StartedAt: r.StartedAt, FinishedAt: r.FinishedAt, Duration: r.Duration,
+		}
+	}
+	return out
+}
@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/render"
 )
 
+var listFlagSort string
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List repositories in the configured workspace",
@@ -22,39 +27,61 @@ var listCmd = &cobra.Command{
 			return fmt.Errorf("workspace not configured in .buck.yaml")
 		}
 
-		authApplier, err := buildAuthApplier(cfg)
+		client, err := buildProvider(cfg)
 		if err != nil {
 			return err
 		}
 
-		client := bitbucket.NewClient(authApplier)
-
 		fmt.Printf("Fetching repos from workspace %q...\n\n", cfg.Workspace)
 
-		repos, err := client.ListRepositories(cfg.Workspace)
+		repos, err := listRepositories(cfg, client)
 		if err != nil {
 			return err
 		}
 
+		switch listFlagSort {
+		case "name":
+			sort.SliceStable(repos, func(i, j int) bool { return repos[i].Slug < repos[j].Slug })
+		case "updated":
+			sort.SliceStable(repos, func(i, j int) bool { return repos[i].UpdatedOn.After(repos[j].UpdatedOn) })
+		default:
+			return fmt.Errorf("invalid --sort %q: use name or updated", listFlagSort)
+		}
+
+		switch render.Output {
+		case render.JSON:
+			return render.WriteJSON(repos)
+		case render.Quiet:
+			for _, r := range repos {
+				fmt.Fprintln(render.Stdout, r.Slug)
+			}
+			return nil
+		}
+
 		bold := color.New(color.Bold)
 		dim := color.New(color.Faint)
 
-		bold.Printf("%-30s %-15s %s\n", "REPO", "DEFAULT BRANCH", "UPDATED")
-		fmt.Println("─────────────────────────────────────────────────────────────")
-
-		for _, r := range repos {
-			branch := "n/a"
+		slugs := make([]string, len(repos))
+		branches := make([]string, len(repos))
+		for i, r := range repos {
+			slugs[i] = r.Slug
+			branches[i] = "n/a"
 			if r.MainBranch != nil {
-				branch = r.MainBranch.Name
+				branches[i] = r.MainBranch.Name
 			}
+		}
+		slugWidth := render.SlugWidth(slugs)
+		branchWidth := render.ColumnWidth(branches, len("DEFAULT BRANCH"), 30)
 
-			// Truncate updated_on to date only
-			updated := r.UpdatedOn
-			if len(updated) > 10 {
-				updated = updated[:10]
-			}
+		bold.Printf("%s %s %s\n", render.Cell("REPO", slugWidth), render.Cell("DEFAULT BRANCH", branchWidth), "UPDATED")
+		fmt.Println(strings.Repeat("─", slugWidth+branchWidth+len(" ")*2+len("11 months ago")))
 
-			fmt.Printf("%-30s %-15s %s\n", r.Slug, branch, dim.Sprint(updated))
+		for i, r := range repos {
+			fmt.Printf("%s %s %s\n",
+				render.Cell(r.Slug, slugWidth),
+				render.Cell(branches[i], branchWidth),
+				dim.Sprint(relativeTime(r.UpdatedOn, time.Now())),
+			)
 		}
 
 		fmt.Printf("\nTotal: %d repositories\n", len(repos))
@@ -63,5 +90,37 @@ var listCmd = &cobra.Command{
 }
 
 func init() {
+	listCmd.Flags().StringVar(&listFlagSort, "sort", "name", "sort order: name or updated (most recent first)")
+	_ = listCmd.RegisterFlagCompletionFunc("sort", completeStaticValues([]string{"name", "updated"}))
 	rootCmd.AddCommand(listCmd)
 }
+
+// relativeTime describes t relative to now, e.g. "3 days ago".
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "n/a"
+	}
+	d := now.Sub(t)
+	day := 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute") + " ago"
+	case d < day:
+		return plural(int(d/time.Hour), "hour") + " ago"
+	case d < 30*day:
+		return plural(int(d/day), "day") + " ago"
+	case d < 365*day:
+		return plural(int(d/(30*day)), "month") + " ago"
+	}
+	return plural(int(d/(365*day)), "year") + " ago"
+}
+
+// plural formats a count with its noun, e.g. "1 day" or "3 days".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -11,21 +11,33 @@ import (
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with Bitbucket via OAuth 2.0",
-	Long:  "Opens your browser to authorize buck with your Bitbucket account.\nNot needed when using app_password auth method.",
+	Long:  "Opens your browser to authorize buck with your Bitbucket account.\nNot needed when using the api_token or app_password auth method.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		if cfg.AuthMethod() == "api_token" {
+		switch cfg.AuthMethod() {
+		case "api_token":
 			return fmt.Errorf("login is not needed for API token auth.\nRun 'buck setup' to configure your credentials")
+		case "app_password":
+			return fmt.Errorf("login is not needed for app password auth.\nSet app_password.username and app_password.password in .buck.yaml")
 		}
 
 		if cfg.OAuth.ClientID == "" || cfg.OAuth.ClientSecret == "" {
 			return fmt.Errorf("OAuth credentials not configured.\nSet them in .buck.yaml or via environment variables:\n  BITBUCKET_OAUTH_CLIENT_ID\n  BITBUCKET_OAUTH_CLIENT_SECRET")
 		}
 
+		httpClient, err := newHTTPClient(cfg)
+		if err != nil {
+			return err
+		}
+		auth.HTTPClient = httpClient
+		if err := applyOAuthSettings(cfg); err != nil {
+			return err
+		}
+
 		return auth.Login(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret)
 	},
 }
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/creator"
+	"github.com/chinhstringee/buck/internal/hooks"
+	"github.com/chinhstringee/buck/internal/plan"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+var (
+	planFlagGroup       string
+	planFlagRepos       string
+	planFlagFrom        string
+	planFlagAt          string
+	planFlagWithPR      bool
+	planFlagInteractive bool
+	planFlagOut         string
+	planFlagSkipFlagged bool
+
+	applyFlagYes bool
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan <branch-name>",
+	Short: "Check a branch creation and save it as a plan for 'buck apply'",
+	Long: `Resolve the repos, the commit each new branch would start at and, with
+--with-pr, each PR destination, check them like 'buck create --dry-run --check',
+and write the result to a plan file. The file can be reviewed and approved,
+then 'buck apply' carries out exactly that plan.`,
+	Example: `  buck plan feature/x -g backend --with-pr --out release.plan.json
+  buck apply release.plan.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlan,
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Create the branches (and PRs) of a plan written by 'buck plan'",
+	Long: `Create the planned branch in each repo of the plan, at the commit recorded in
+it even if the source branch has moved on since, and with a plan made with
+--with-pr open PRs into the recorded destinations. Nothing is resolved again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+func init() {
+	planCmd.Flags().StringVarP(&planFlagGroup, "group", "g", "", "repo group from config")
+	planCmd.Flags().StringVarP(&planFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	planCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	planCmd.Flags().StringVarP(&planFlagFrom, "from", "f", "", "source branch (default: from config or each repo's development branch)")
+	planCmd.Flags().StringVar(&planFlagAt, "at", "", "tag or commit to create the branch at, instead of a branch tip")
+	planCmd.Flags().BoolVar(&planFlagWithPR, "with-pr", false, "also plan a PR from the new branch in each repo")
+	planCmd.Flags().BoolVarP(&planFlagInteractive, "interactive", "i", false, "select repos interactively")
+	planCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
+	planCmd.Flags().StringVar(&planFlagOut, "out", "buck.plan.json", "plan file to write")
+	planCmd.Flags().BoolVar(&planFlagSkipFlagged, "skip-flagged", false, "leave repos that fail the checks out of the plan instead of stopping")
+
+	_ = planCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = planCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+	_ = planCmd.RegisterFlagCompletionFunc("from", completeBranchNames)
+	planCmd.MarkFlagsMutuallyExclusive("from", "at")
+
+	applyCmd.Flags().BoolVarP(&applyFlagYes, "yes", "y", false, "apply without asking")
+
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	branchName := args[0]
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	branchName = prefixBranch(ctx.cfg, branchName)
+	if err := ctx.selectRepos(planFlagRepos, planFlagGroup, planFlagInteractive); err != nil {
+		return err
+	}
+
+	// Same source and PR destination rules as 'buck create'
+	sourceBranch := ctx.cfg.Defaults.SourceBranch
+	if planFlagFrom != "" {
+		sourceBranch = planFlagFrom
+	}
+	prDestination := sourceBranch
+	if planFlagAt != "" {
+		sourceBranch = planFlagAt
+		prDestination = ""
+	}
+
+	color.New(color.Bold).Printf("Checking %d repos for branch %q...\n", len(ctx.repos), branchName)
+	items := creator.NewBranchCreator(ctx.client).Preflight(ctx.cfg.Workspace, ctx.repos, branchName, sourceBranch)
+	if flagged := render.PrintPlan(creator.Plan(items)); flagged > 0 && !planFlagSkipFlagged {
+		return fmt.Errorf("%d repos failed the checks; fix them or rerun with --skip-flagged to leave them out of the plan", flagged)
+	}
+
+	p := &plan.Plan{
+		Version:   plan.Version,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Provider:  ctx.cfg.ProviderName(),
+		Workspace: ctx.cfg.Workspace,
+		Branch:    branchName,
+		WithPR:    planFlagWithPR,
+	}
+	pc := pullrequest.NewPRCreator(ctx.client, prCreatorOptions(ctx.cfg))
+	for _, item := range items {
+		if len(item.Warnings) > 0 {
+			continue
+		}
+		r := plan.Repo{Repo: item.RepoSlug, Source: item.Source, Commit: item.Commit}
+		if planFlagWithPR {
+			dest, err := pc.DestinationFor(ctx.cfg.Workspace, item.RepoSlug, prDestination)
+			if err != nil {
+				return fmt.Errorf("%s: %w", item.RepoSlug, err)
+			}
+			r.Destination = dest
+		}
+		p.Repos = append(p.Repos, r)
+	}
+	if len(p.Repos) == 0 {
+		return fmt.Errorf("no repos passed the checks, no plan written")
+	}
+
+	if err := plan.Write(planFlagOut, p); err != nil {
+		return err
+	}
+	color.New(color.FgGreen).Printf("\n✓ Plan for %d repos saved to %s\n", len(p.Repos), planFlagOut)
+	fmt.Printf("Review it, then run 'buck apply %s'.\n", planFlagOut)
+	return nil
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	p, err := plan.Read(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	if ctx.cfg.ProviderName() != p.Provider {
+		return fmt.Errorf("plan is for provider %q, but the config uses %q", p.Provider, ctx.cfg.ProviderName())
+	}
+
+	bold := color.New(color.Bold)
+	action := fmt.Sprintf("create branch %q", p.Branch)
+	if p.WithPR {
+		action += " and open PRs"
+	}
+	bold.Printf("Plan from %s: %s in %s:\n", p.CreatedAt.Local().Format("2006-01-02 15:04"), action, p.Workspace)
+	for _, r := range p.Repos {
+		line := fmt.Sprintf("  - %s from %s (%s)", r.Repo, r.Source, shortHash(r.Commit))
+		if p.WithPR {
+			line += " → " + r.Destination
+		}
+		fmt.Println(line)
+	}
+	if !applyFlagYes && !confirmAction("Apply this plan?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	repos := make([]string, len(p.Repos))
+	targets := make([]creator.Target, len(p.Repos))
+	destinations := make(map[string]string, len(p.Repos))
+	for i, r := range p.Repos {
+		repos[i] = r.Repo
+		targets[i] = creator.Target{Repo: r.Repo, Source: r.Source, Commit: r.Commit}
+		destinations[strings.ToLower(r.Repo)] = r.Destination
+	}
+
+	payload := hooks.Payload{Command: "create", Workspace: p.Workspace, Branch: p.Branch, Repos: repos}
+	if err := runPreHooks(ctx.cfg, payload); err != nil {
+		return err
+	}
+
+	bold.Printf("Creating branch %q across %d repos...\n", p.Branch, len(p.Repos))
+	results := creator.NewBranchCreator(ctx.client).CreateBranchesFromTargets(p.Workspace, targets, p.Branch)
+	if !p.WithPR {
+		creator.PrintResults(results)
+	}
+
+	payload.Results = branchHookResults(results)
+	runPostHooks(ctx.cfg, payload)
+
+	if p.WithPR {
+		// The planned destinations replace pr.destinations and the development branch
+		opts := prCreatorOptions(ctx.cfg)
+		opts.Destinations = destinations
+		return openPRsForBranches(ctx.cfg, ctx.client, p.Workspace, opts, results, p.Branch, "")
+	}
+	return nil
+}
+
+// shortHash abbreviates a commit hash for display.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/chinhstringee/buck/internal/auth"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// pluginPrefix names plugin executables: "buck foo" runs "buck-foo" from PATH.
+const pluginPrefix = "buck-"
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List plugins (buck-<name> executables on PATH)",
+	Args:  cobra.NoArgs,
+	RunE:  runPlugins,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+}
+
+func runPlugins(cmd *cobra.Command, args []string) error {
+	plugins := listPlugins(os.Getenv("PATH"))
+	if len(plugins) == 0 {
+		fmt.Printf("No plugins found. Put an executable named %s<name> on your PATH to add 'buck <name>'.\n", pluginPrefix)
+		return nil
+	}
+
+	bold := color.New(color.Bold)
+	bold.Printf("%-20s %s\n", "COMMAND", "PATH")
+	for _, p := range plugins {
+		fmt.Printf("%-20s %s\n", p.name, p.path)
+	}
+	return nil
+}
+
+// plugin is a discovered buck-<name> executable.
+type plugin struct {
+	name string
+	path string
+}
+
+// listPlugins scans pathEnv for buck-<name> executables. Earlier PATH entries
+// shadow later ones, as with command lookup.
+func listPlugins(pathEnv string) []plugin {
+	seen := make(map[string]bool)
+	var plugins []plugin
+
+	for _, dir := range filepath.SplitList(pathEnv) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasPrefix(name, pluginPrefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, ".exe")
+			}
+			name = strings.TrimPrefix(name, pluginPrefix)
+			if name == "" || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, plugin{name: name, path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].name < plugins[j].name
+	})
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// findPlugin returns the plugin to run for args, git-style: the first
+// positional argument is neither a built-in command nor a cobra internal, and
+// buck-<name> is on PATH. Only --config may precede the plugin name.
+func findPlugin(args []string) (path string, configFile string, pluginArgs []string, ok bool) {
+	i, configFile, ok := commandIndex(args)
+	if !ok || isBuiltinCommand(args[i]) {
+		return "", "", nil, false
+	}
+	path, err := exec.LookPath(pluginPrefix + args[i])
+	if err != nil {
+		return "", "", nil, false
+	}
+	return path, configFile, args[i+1:], true
+}
+
+// commandIndex returns the position of the command name in args and the
+// --config value before it. Only --config may precede the name of a plugin
+// or alias; any other flag first means neither applies.
+func commandIndex(args []string) (index int, configFile string, ok bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--config" && i+1 < len(args):
+			configFile = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--config="):
+			configFile = strings.TrimPrefix(arg, "--config=")
+		case strings.HasPrefix(arg, "-"):
+			return 0, "", false
+		default:
+			return i, configFile, true
+		}
+	}
+	return 0, "", false
+}
+
+// isBuiltinCommand reports whether name is a buck command, alias or cobra's own.
+func isBuiltinCommand(name string) bool {
+	if name == "help" || strings.HasPrefix(name, "__") {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// runPlugin executes a plugin with the resolved config and credentials in its
+// environment and returns its exit code.
+func runPlugin(path, configFile string, args []string) int {
+	cfgFile = configFile
+	initConfig()
+
+	c := exec.Command(path, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), pluginEnv()...)
+
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "failed to run plugin %s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
+
+// pluginEnv exposes the loaded config and the active provider's credentials
+// as BUCK_* variables, so plugins need not parse .buck.yaml or log in again.
+func pluginEnv() []string {
+	env := []string{"BUCK_CONFIG_FILE=" + viper.ConfigFileUsed()}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return env
+	}
+
+	env = append(env,
+		"BUCK_WORKSPACE="+cfg.Workspace,
+		"BUCK_PROVIDER="+cfg.ProviderName(),
+	)
+
+	switch cfg.ProviderName() {
+	case provider.Bitbucket:
+		env = append(env, "BUCK_AUTH_METHOD="+cfg.AuthMethod())
+		switch cfg.AuthMethod() {
+		case "api_token":
+			env = append(env,
+				"BUCK_BITBUCKET_EMAIL="+cfg.ApiToken.Email,
+				"BUCK_BITBUCKET_API_TOKEN="+cfg.ApiToken.Token,
+			)
+		case "app_password":
+			env = append(env,
+				"BUCK_BITBUCKET_USERNAME="+cfg.AppPassword.Username,
+				"BUCK_BITBUCKET_APP_PASSWORD="+cfg.AppPassword.Password,
+			)
+		case "oauth":
+			auth.Profile = cfg.OAuth.Profile
+			if token, err := auth.GetToken(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret); err == nil {
+				env = append(env, "BUCK_BITBUCKET_ACCESS_TOKEN="+token)
+			}
+		}
+	case provider.GitHub:
+		env = append(env,
+			"BUCK_GITHUB_TOKEN="+cfg.GitHub.Token,
+			"BUCK_GITHUB_BASE_URL="+cfg.GitHub.BaseURL,
+		)
+	case provider.GitLab:
+		env = append(env,
+			"BUCK_GITLAB_TOKEN="+cfg.GitLab.Token,
+			"BUCK_GITLAB_BASE_URL="+cfg.GitLab.BaseURL,
+		)
+	}
+	return env
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// writePlugin creates an executable script in dir.
+func writePlugin(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on executable bits")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	hello := writePlugin(t, first, "buck-hello")
+	writePlugin(t, second, "buck-hello") // shadowed
+	writePlugin(t, second, "buck-audit")
+	os.WriteFile(filepath.Join(second, "buck-notexec"), []byte("x"), 0644)
+	writePlugin(t, second, "other-tool")
+
+	plugins := listPlugins(first + string(os.PathListSeparator) + second)
+	if len(plugins) != 2 {
+		t.Fatalf("plugins = %+v, want 2", plugins)
+	}
+	if plugins[0].name != "audit" || plugins[1].name != "hello" || plugins[1].path != hello {
+		t.Errorf("plugins = %+v", plugins)
+	}
+}
+
+func TestFindPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on executable bits")
+	}
+	dir := t.TempDir()
+	hello := writePlugin(t, dir, "buck-hello")
+	writePlugin(t, dir, "buck-list") // built-in commands win
+	t.Setenv("PATH", dir)
+
+	path, configFile, args, ok := findPlugin([]string{"--config", "x.yaml", "hello", "--flag", "arg"})
+	if !ok || path != hello || configFile != "x.yaml" || strings.Join(args, " ") != "--flag arg" {
+		t.Errorf("findPlugin = %q %q %v %v", path, configFile, args, ok)
+	}
+
+	for _, args := range [][]string{{"list"}, {"help"}, {"missing"}, {"--version"}, {}} {
+		if _, _, _, ok := findPlugin(args); ok {
+			t.Errorf("findPlugin(%v) matched, want no plugin", args)
+		}
+	}
+}
+
+func TestPluginEnv_GitHub(t *testing.T) {
+	resetViper()
+	defer resetViper()
+	viper.Set("workspace", "acme")
+	viper.Set("provider", "github")
+	viper.Set("github.token", "ghp_x")
+
+	env := strings.Join(pluginEnv(), "\n")
+	for _, want := range []string{"BUCK_WORKSPACE=acme", "BUCK_PROVIDER=github", "BUCK_GITHUB_TOKEN=ghp_x"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("env missing %q:\n%s", want, env)
+		}
+	}
+	if strings.Contains(env, "BUCK_BITBUCKET") {
+		t.Errorf("env should not contain Bitbucket credentials:\n%s", env)
+	}
+}
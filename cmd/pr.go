@@ -2,13 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
 	"github.com/chinhstringee/buck/internal/gitutil"
+	"github.com/chinhstringee/buck/internal/hooks"
 	"github.com/chinhstringee/buck/internal/pullrequest"
+	"github.com/chinhstringee/buck/internal/render"
 )
 
 var (
@@ -17,6 +19,15 @@ var (
 	prFlagDryRun      bool
 	prFlagDestination string
 	prFlagInteractive bool
+	prFlagReview      bool
+	prFlagYes         bool
+	prFlagDescribe    string
+	prFlagNoTasks     bool
+	prFlagURLsOut     string
+	prFlagCopy        bool
+
+	// flagPorcelain is --porcelain, shared by pr and pr merge
+	flagPorcelain string
 )
 
 var prCmd = &cobra.Command{
@@ -31,15 +42,26 @@ func init() {
 	// Shared flags available to all pr subcommands
 	prCmd.PersistentFlags().StringVarP(&prFlagGroup, "group", "g", "", "repo group from config")
 	prCmd.PersistentFlags().StringVarP(&prFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	prCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
 	prCmd.PersistentFlags().BoolVar(&prFlagDryRun, "dry-run", false, "preview actions without executing")
 	prCmd.PersistentFlags().BoolVarP(&prFlagInteractive, "interactive", "i", false, "select repos interactively")
 
 	// Create-only flag
-	prCmd.Flags().StringVarP(&prFlagDestination, "destination", "d", "", "destination branch (default: master)")
+	prCmd.Flags().StringVarP(&prFlagDestination, "destination", "d", "", "destination branch, plus optional repo:branch overrides (e.g. develop,legacy-app:master; default: each repo's development branch)")
+	prCmd.Flags().BoolVar(&prFlagReview, "review", false, "review and edit each PR before creating it")
+	prCmd.Flags().BoolVarP(&prFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	prCmd.Flags().StringVar(&prFlagDescribe, "describe", "", "description options, overriding config: hashes,authors,bodies,by-author")
+	prCmd.Flags().BoolVar(&prFlagNoTasks, "no-tasks", false, "do not add the pr.tasks checklist from config")
+	prCmd.Flags().StringVar(&prFlagURLsOut, "urls-out", "", "write the created PR URLs to this file, one per line")
+	prCmd.Flags().BoolVar(&prFlagCopy, "copy", false, "copy the created PR URLs to the clipboard")
+	prCmd.Flags().StringVar(&flagPorcelain, "porcelain", "", "machine-readable output instead of the table: urls (one created PR URL per line)")
+	prCmd.PersistentFlags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
 
 	_ = prCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
 	_ = prCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
 	_ = prCmd.RegisterFlagCompletionFunc("destination", completeBranchNames)
+	_ = prCmd.RegisterFlagCompletionFunc("porcelain", completeStaticValues(render.PorcelainModes))
+	_ = prCmd.RegisterFlagCompletionFunc("describe", completeStaticValues([]string{"hashes", "authors", "bodies", "by-author"}))
 
 	rootCmd.AddCommand(prCmd)
 }
@@ -48,9 +70,11 @@ func runPR(cmd *cobra.Command, args []string) error {
 	var branchName string
 	var repos []string
 	var workspace string
+	var detected bool
+	var err error
 
 	// Auto-detect mode: no args and no --repos/--group flags
-	autoDetect := len(args) == 0 && prFlagRepos == "" && prFlagGroup == "" && !prFlagInteractive
+	autoDetect := len(args) == 0 && prFlagRepos == "" && flagReposFile == "" && prFlagGroup == "" && !prFlagInteractive
 
 	if autoDetect {
 		hint := "\n  Hint: use 'buck pr <branch> --repos <repo>' to specify explicitly"
@@ -67,10 +91,14 @@ func runPR(cmd *cobra.Command, args []string) error {
 		workspace = ws
 		repos = []string{repoSlug}
 	} else {
-		if len(args) == 0 {
-			return fmt.Errorf("branch name required when using --repos, --group, or --interactive")
+		var arg string
+		if len(args) == 1 {
+			arg = args[0]
+		}
+		branchName, detected, err = branchOrCurrent(arg)
+		if err != nil {
+			return err
 		}
-		branchName = args[0]
 	}
 
 	cfg, err := config.Load()
@@ -78,21 +106,23 @@ func runPR(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Use remote workspace in auto-detect mode, config workspace otherwise
+	// Use remote workspace in auto-detect mode, config workspace otherwise.
+	// A detected branch is the real one, so only a typed name gets the prefix.
 	if !autoDetect {
 		if cfg.Workspace == "" {
 			return fmt.Errorf("workspace not configured in .buck.yaml")
 		}
 		workspace = cfg.Workspace
+		if !detected {
+			branchName = prefixBranch(cfg, branchName)
+		}
 	}
 
-	authApplier, err := buildAuthApplier(cfg)
+	client, err := buildProvider(cfg)
 	if err != nil {
 		return err
 	}
 
-	client := bitbucket.NewClient(authApplier)
-
 	if !autoDetect {
 		repos, err = resolveTargetRepos(prFlagRepos, prFlagGroup, prFlagInteractive, cfg, client)
 		if err != nil {
@@ -103,25 +133,87 @@ func runPR(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	destination, overrides, err := pullrequest.ParseDestinations(prFlagDestination)
+	if err != nil {
+		return err
+	}
+	opts := prCreatorOptions(cfg)
+	for repo, branch := range overrides {
+		opts.Destinations[strings.ToLower(repo)] = branch
+	}
+
 	bold := color.New(color.Bold)
 
 	if prFlagDryRun {
-		dest := prFlagDestination
-		if dest == "" {
-			dest = "master"
+		dest := fmt.Sprintf("%q", destination)
+		if destination == "" {
+			dest = "each repo's development branch"
 		}
-		bold.Printf("Dry run: would create PRs from %q to %q in:\n", branchName, dest)
-		for _, r := range repos {
-			fmt.Printf("  - %s/%s\n", workspace, r)
+		bold.Printf("Dry run: would create PRs from %q to %s in workspace %q:\n", branchName, dest, workspace)
+		render.PrintPlan(pullrequest.NewPRCreator(client, opts).Preflight(workspace, repos, branchName, destination))
+		return nil
+	}
+
+	if cmd.Flags().Changed("describe") {
+		opts.Description, err = pullrequest.ParseDescriptionOptions(prFlagDescribe)
+		if err != nil {
+			return err
 		}
+	}
+	if prFlagNoTasks {
+		opts.Tasks = nil
+	}
+	pc := pullrequest.NewPRCreator(client, opts)
+
+	// --review already confirms every repo individually
+	if !prFlagReview && !confirmLargeRun(fmt.Sprintf("create PRs from %q", branchName), workspace, repos, cfg.Defaults.ConfirmLimit(), prFlagYes) {
+		fmt.Println("Aborted.")
 		return nil
 	}
 
+	if prFlagReview {
+		drafts := pc.PrepareDrafts(workspace, repos, branchName, destination)
+		drafts, err = reviewDrafts(workspace, drafts)
+		if err != nil {
+			return err
+		}
+		if len(drafts) == 0 {
+			fmt.Println("All repos skipped, nothing to create.")
+			return nil
+		}
+
+		reviewed := make([]string, len(drafts))
+		for i, d := range drafts {
+			reviewed[i] = d.RepoSlug
+		}
+		payload := hooks.Payload{Command: "pr", Workspace: workspace, Branch: branchName, Destination: destination, Repos: reviewed}
+		if err := runPreHooks(cfg, payload); err != nil {
+			return err
+		}
+
+		bold.Printf("Creating PRs from %q across %d repos...\n", branchName, len(drafts))
+		results := pc.CreateFromDrafts(workspace, branchName, drafts)
+		pullrequest.PrintResults(results)
+		exportPRURLs(results, prFlagURLsOut, prFlagCopy)
+
+		payload.Results = prHookResults(results)
+		runPostHooks(cfg, payload)
+		return nil
+	}
+
+	payload := hooks.Payload{Command: "pr", Workspace: workspace, Branch: branchName, Destination: destination, Repos: repos}
+	if err := runPreHooks(cfg, payload); err != nil {
+		return err
+	}
+
 	bold.Printf("Creating PRs from %q across %d repos...\n", branchName, len(repos))
 
-	pc := pullrequest.NewPRCreator(client)
-	results := pc.CreatePRs(workspace, repos, branchName, prFlagDestination)
+	results := pc.CreatePRs(workspace, repos, branchName, destination)
 	pullrequest.PrintResults(results)
+	exportPRURLs(results, prFlagURLsOut, prFlagCopy)
+
+	payload.Results = prHookResults(results)
+	runPostHooks(cfg, payload)
 
 	return nil
 }
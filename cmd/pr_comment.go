@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+var (
+	prCommentFlagMessage string
+	prCommentFlagYes     bool
+)
+
+var prCommentCmd = &cobra.Command{
+	Use:   "comment [branch-name] -m <message>",
+	Short: "Post the same comment on pull requests by branch name across repos",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runPRComment,
+}
+
+func init() {
+	prCommentCmd.Flags().StringVarP(&prCommentFlagMessage, "message", "m", "", "comment text (markdown)")
+	prCommentCmd.Flags().BoolVarP(&prCommentFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	_ = prCommentCmd.MarkFlagRequired("message")
+
+	prCmd.AddCommand(prCommentCmd)
+}
+
+func runPRComment(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(prCommentFlagMessage) == "" {
+		return fmt.Errorf("comment message cannot be empty")
+	}
+
+	var branchArg string
+	if len(args) > 0 {
+		branchArg = args[0]
+	}
+
+	ctx, err := resolvePRContext(branchArg)
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if prFlagDryRun {
+		bold.Printf("Dry run: would comment on PRs from branch %q in:\n", ctx.branchName)
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", repoPath(ctx.workspace, r))
+		}
+		return nil
+	}
+
+	if !confirmLargeRun(fmt.Sprintf("comment on PRs from %q", ctx.branchName), ctx.workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), prCommentFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Commenting on PRs from %q across %d repos...\n", ctx.branchName, len(ctx.repos))
+
+	mgr := pullrequest.NewPRManager(ctx.client)
+	results := mgr.CommentPRs(ctx.workspace, ctx.repos, ctx.branchName, prCommentFlagMessage)
+	pullrequest.PrintCommentResults(results)
+
+	return nil
+}
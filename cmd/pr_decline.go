@@ -38,7 +38,7 @@ func runPRDecline(cmd *cobra.Command, args []string) error {
 	if prFlagDryRun {
 		bold.Printf("Dry run: would decline PRs from branch %q in:\n", ctx.branchName)
 		for _, r := range ctx.repos {
-			fmt.Printf("  - %s/%s\n", ctx.workspace, r)
+			fmt.Printf("  - %s\n", repoPath(ctx.workspace, r))
 		}
 		return nil
 	}
@@ -3,12 +3,15 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
-	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/fatih/color"
 	"github.com/chinhstringee/buck/internal/config"
 	"github.com/chinhstringee/buck/internal/gitutil"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/pullrequest"
 )
 
 // prContext holds the resolved context for a PR subcommand.
@@ -16,7 +19,7 @@ type prContext struct {
 	workspace  string
 	repos      []string
 	branchName string
-	client     *bitbucket.Client
+	client     provider.Provider
 	cfg        *config.Config
 }
 
@@ -26,8 +29,9 @@ func resolvePRContext(branchArg string) (*prContext, error) {
 	var branchName string
 	var repos []string
 	var workspace string
+	var detected bool
 
-	autoDetect := branchArg == "" && prFlagRepos == "" && prFlagGroup == "" && !prFlagInteractive
+	autoDetect := branchArg == "" && prFlagRepos == "" && flagReposFile == "" && prFlagGroup == "" && !prFlagInteractive
 
 	if autoDetect {
 		hint := "\n  Hint: use 'buck pr <cmd> <branch> --repos <repo>' to specify explicitly"
@@ -44,10 +48,11 @@ func resolvePRContext(branchArg string) (*prContext, error) {
 		workspace = ws
 		repos = []string{repoSlug}
 	} else {
-		if branchArg == "" {
-			return nil, fmt.Errorf("branch name required when using --repos, --group, or --interactive")
+		branch, d, err := branchOrCurrent(branchArg)
+		if err != nil {
+			return nil, err
 		}
-		branchName = branchArg
+		branchName, detected = branch, d
 	}
 
 	cfg, err := config.Load()
@@ -60,15 +65,17 @@ func resolvePRContext(branchArg string) (*prContext, error) {
 			return nil, fmt.Errorf("workspace not configured in .buck.yaml")
 		}
 		workspace = cfg.Workspace
+		// A detected branch is the real one, so only a typed name gets the prefix
+		if !detected {
+			branchName = prefixBranch(cfg, branchName)
+		}
 	}
 
-	authApplier, err := buildAuthApplier(cfg)
+	client, err := buildProvider(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	client := bitbucket.NewClient(authApplier)
-
 	if !autoDetect {
 		repos, err = resolveTargetRepos(prFlagRepos, prFlagGroup, prFlagInteractive, cfg, client)
 		if err != nil {
@@ -88,11 +95,84 @@ func resolvePRContext(branchArg string) (*prContext, error) {
 	}, nil
 }
 
+// branchOrCurrent returns branchArg, or when it is empty the branch checked out
+// in the working directory, e.g. the one just pushed to every repo of a group.
+// detected reports the latter: a detected name is the real branch, so it must
+// not get defaults.branch_prefix.
+func branchOrCurrent(branchArg string) (name string, detected bool, err error) {
+	if branchArg != "" {
+		return branchArg, false, nil
+	}
+	branch, err := gitutil.CurrentBranch()
+	if err != nil {
+		return "", false, fmt.Errorf("no branch name given and none checked out here: %w", err)
+	}
+	color.New(color.Faint).Printf("Using the checked-out branch %q\n", branch)
+	return branch, true, nil
+}
+
+// confirmLargeRun lists the target repos and asks for confirmation when more than
+// threshold repos are about to be changed. Returns true if the run should proceed.
+func confirmLargeRun(action, workspace string, repos []string, threshold int, yes bool) bool {
+	if yes || threshold < 0 || len(repos) <= threshold {
+		return true
+	}
+
+	color.New(color.Bold).Printf("About to %s in %d repos:\n", action, len(repos))
+	for _, r := range repos {
+		fmt.Printf("  - %s\n", repoPath(workspace, r))
+	}
+	return confirmAction("Proceed?")
+}
+
+// repoPath returns the "workspace/slug" path of a --repos or group entry.
+func repoPath(workspace, repo string) string {
+	ws, slug := provider.SplitRepo(workspace, repo)
+	return ws + "/" + slug
+}
+
 // confirmAction prompts the user for confirmation. Returns true if confirmed.
+// When stdin carried the repo list (--repos -), the answer is read from the
+// terminal instead.
 func confirmAction(prompt string) bool {
+	var in io.Reader = os.Stdin
+	if stdinRepos {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			fmt.Printf("%s Cannot ask: stdin was used for --repos -; pass --yes to proceed.\n", prompt)
+			return false
+		}
+		defer tty.Close()
+		in = tty
+	}
+
 	fmt.Printf("%s [y/N]: ", prompt)
-	reader := bufio.NewReader(os.Stdin)
+	reader := bufio.NewReader(in)
 	line, _ := reader.ReadString('\n')
 	line = strings.TrimSpace(strings.ToLower(line))
 	return line == "y" || line == "yes"
 }
+
+// prCreatorOptions maps the pr config section to creator options.
+func prCreatorOptions(cfg *config.Config) pullrequest.Options {
+	d := cfg.PR.Description
+	return pullrequest.Options{
+		Description: pullrequest.DescriptionOptions{
+			Hashes:        d.Hashes,
+			Authors:       d.Authors,
+			Bodies:        d.Bodies,
+			GroupByAuthor: d.GroupByAuthor,
+		},
+		Tasks:        cfg.PR.Tasks,
+		Destinations: lowerKeys(cfg.PR.Destinations),
+	}
+}
+
+// lowerKeys returns a copy of m with lowercased keys.
+func lowerKeys(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
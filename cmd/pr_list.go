@@ -5,7 +5,6 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
 	"github.com/chinhstringee/buck/internal/dashboard"
 	"github.com/chinhstringee/buck/internal/gitutil"
@@ -38,7 +37,7 @@ func runPRList(cmd *cobra.Command, args []string) error {
 	var repos []string
 	var workspace string
 
-	autoDetect := prFlagRepos == "" && prFlagGroup == "" && !prFlagInteractive
+	autoDetect := prFlagRepos == "" && flagReposFile == "" && prFlagGroup == "" && !prFlagInteractive
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -62,13 +61,11 @@ func runPRList(cmd *cobra.Command, args []string) error {
 		workspace = cfg.Workspace
 	}
 
-	authApplier, err := buildAuthApplier(cfg)
+	client, err := buildProvider(cfg)
 	if err != nil {
 		return err
 	}
 
-	client := bitbucket.NewClient(authApplier)
-
 	if len(repos) == 0 {
 		repos, err = resolveTargetRepos(prFlagRepos, prFlagGroup, prFlagInteractive, cfg, client)
 		if err != nil {
@@ -2,17 +2,25 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/hooks"
 	"github.com/chinhstringee/buck/internal/pullrequest"
+	"github.com/chinhstringee/buck/internal/render"
 )
 
 var (
 	prMergeFlagStrategy     string
 	prMergeFlagCloseBranch  bool
 	prMergeFlagYes          bool
+	prMergeFlagWhenGreen    bool
+	prMergeFlagWaitTimeout  time.Duration
+	prMergeFlagInterval     time.Duration
+	prMergeFlagMinApprovals int
+	prMergeFlagBuildGrace   time.Duration
 )
 
 var prMergeCmd = &cobra.Command{
@@ -26,6 +34,13 @@ func init() {
 	prMergeCmd.Flags().StringVar(&prMergeFlagStrategy, "strategy", "merge_commit", "merge strategy: merge_commit, squash, fast_forward")
 	prMergeCmd.Flags().BoolVar(&prMergeFlagCloseBranch, "close-branch", false, "close source branch after merge")
 	prMergeCmd.Flags().BoolVarP(&prMergeFlagYes, "yes", "y", false, "skip confirmation prompt")
+	prMergeCmd.Flags().StringVar(&flagPorcelain, "porcelain", "", "machine-readable output instead of the table: urls (one merged PR URL per line)")
+	_ = prMergeCmd.RegisterFlagCompletionFunc("porcelain", completeStaticValues(render.PorcelainModes))
+	prMergeCmd.Flags().BoolVar(&prMergeFlagWhenGreen, "when-green", false, "wait for passing builds and approvals, then merge each PR as soon as it is ready")
+	prMergeCmd.Flags().DurationVar(&prMergeFlagWaitTimeout, "wait-timeout", 30*time.Minute, "with --when-green: give up on PRs not ready by then")
+	prMergeCmd.Flags().DurationVar(&prMergeFlagInterval, "interval", 30*time.Second, "with --when-green: time between status checks")
+	prMergeCmd.Flags().IntVar(&prMergeFlagMinApprovals, "min-approvals", 1, "with --when-green: approvals required before merging")
+	prMergeCmd.Flags().DurationVar(&prMergeFlagBuildGrace, "build-grace", 2*time.Minute, "with --when-green: how long to wait for builds to start before a PR without builds counts as green")
 
 	_ = prMergeCmd.RegisterFlagCompletionFunc("strategy", completeStaticValues([]string{"merge_commit", "squash", "fast_forward"}))
 
@@ -53,7 +68,7 @@ func runPRMerge(cmd *cobra.Command, args []string) error {
 	if prFlagDryRun {
 		bold.Printf("Dry run: would merge PRs from branch %q in:\n", ctx.branchName)
 		for _, r := range ctx.repos {
-			fmt.Printf("  - %s/%s\n", ctx.workspace, r)
+			fmt.Printf("  - %s\n", repoPath(ctx.workspace, r))
 		}
 		return nil
 	}
@@ -66,15 +81,43 @@ func runPRMerge(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	bold.Printf("Merging PRs from %q across %d repos...\n", ctx.branchName, len(ctx.repos))
+	payload := hooks.Payload{Command: "merge", Workspace: ctx.workspace, Branch: ctx.branchName, Repos: ctx.repos}
+	if err := runPreHooks(ctx.cfg, payload); err != nil {
+		return err
+	}
 
 	mgr := pullrequest.NewPRManager(ctx.client)
 	req := bitbucket.MergePRRequest{
 		MergeStrategy:     prMergeFlagStrategy,
 		CloseSourceBranch: prMergeFlagCloseBranch,
 	}
-	results := mgr.MergePRs(ctx.workspace, ctx.repos, ctx.branchName, req)
+
+	var results []pullrequest.Result
+	if prMergeFlagWhenGreen {
+		bold.Printf("Waiting to merge PRs from %q across %d repos (timeout %s)...\n", ctx.branchName, len(ctx.repos), prMergeFlagWaitTimeout)
+		results = mgr.MergeWhenGreen(ctx.workspace, ctx.repos, ctx.branchName, req, pullrequest.WhenGreenOptions{
+			Timeout:      prMergeFlagWaitTimeout,
+			Interval:     prMergeFlagInterval,
+			MinApprovals: prMergeFlagMinApprovals,
+			BuildGrace:   prMergeFlagBuildGrace,
+			OnUpdate:     printMergeProgress,
+		})
+	} else {
+		bold.Printf("Merging PRs from %q across %d repos...\n", ctx.branchName, len(ctx.repos))
+		results = mgr.MergePRs(ctx.workspace, ctx.repos, ctx.branchName, req)
+	}
 	pullrequest.PrintActionResults("Merge", results)
 
+	payload.Results = prHookResults(results)
+	runPostHooks(ctx.cfg, payload)
+
 	return nil
 }
+
+// printMergeProgress prints a timestamped status line for --when-green.
+func printMergeProgress(repoSlug, status string) {
+	if status == "merged" {
+		status = color.GreenString(status)
+	}
+	fmt.Printf("  %s %-30s %s\n", color.New(color.Faint).Sprint(time.Now().Format("15:04:05")), repoSlug, status)
+}
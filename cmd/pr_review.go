@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+// reviewDrafts walks through each PR draft and lets the user edit or skip it.
+// Returns only the drafts the user chose to keep.
+func reviewDrafts(workspace string, drafts []pullrequest.Draft) ([]pullrequest.Draft, error) {
+	kept := make([]pullrequest.Draft, 0, len(drafts))
+
+	for i := range drafts {
+		d := drafts[i]
+		include := true
+
+		note := huh.NewNote().
+			Title(fmt.Sprintf("%s (%d of %d)", repoPath(workspace, d.RepoSlug), i+1, len(drafts)))
+		if d.Err != nil {
+			note.Description(fmt.Sprintf("%v — enter the destination branch", d.Err))
+		}
+		form := huh.NewForm(
+			huh.NewGroup(
+				note,
+				huh.NewInput().
+					Title("Title").
+					Value(&d.Title).
+					Validate(requiredValidator("title")),
+				huh.NewText().
+					Title("Description").
+					Value(&d.Description),
+				huh.NewInput().
+					Title("Destination branch").
+					Value(&d.Destination).
+					Validate(requiredValidator("destination")),
+				huh.NewConfirm().
+					Title("Create this PR?").
+					Affirmative("Create").
+					Negative("Skip").
+					Value(&include),
+			),
+		)
+
+		if err := form.Run(); err != nil {
+			return nil, fmt.Errorf("review cancelled")
+		}
+		if include {
+			// The destination is required above, so any lookup error is resolved
+			d.Err = nil
+			kept = append(kept, d)
+		}
+	}
+
+	return kept, nil
+}
@@ -20,7 +20,7 @@ var prReviewersCmd = &cobra.Command{
 }
 
 func init() {
-	prReviewersCmd.Flags().StringVar(&prReviewersFlagAdd, "add", "", "comma-separated account IDs or UUIDs to add as reviewers")
+	prReviewersCmd.Flags().StringVar(&prReviewersFlagAdd, "add", "", "comma-separated account IDs or UUIDs to add as reviewers (GitHub: logins, GitLab: user IDs)")
 	prCmd.AddCommand(prReviewersCmd)
 }
 
@@ -64,7 +64,7 @@ func runPRReviewers(cmd *cobra.Command, args []string) error {
 	if prFlagDryRun {
 		bold.Printf("Dry run: would add %d reviewers to PRs from branch %q in:\n", len(reviewers), ctx.branchName)
 		for _, r := range ctx.repos {
-			fmt.Printf("  - %s/%s\n", ctx.workspace, r)
+			fmt.Printf("  - %s\n", repoPath(ctx.workspace, r))
 		}
 		return nil
 	}
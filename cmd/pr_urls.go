@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+// writeClipboard copies text to the system clipboard.
+var writeClipboard = clipboard.WriteAll
+
+// exportPRURLs writes the links of the created PRs to path (--urls-out) and
+// the clipboard (--copy), one per line. Failures only warn: the PRs exist.
+func exportPRURLs(results []pullrequest.Result, path string, toClipboard bool) {
+	if path == "" && !toClipboard {
+		return
+	}
+	urls := pullrequest.URLs(results)
+	text := strings.Join(urls, "\n")
+	if len(urls) > 0 {
+		text += "\n"
+	}
+
+	green := color.New(color.FgGreen)
+	warn := color.New(color.FgYellow)
+
+	// The file is written even when empty, so a script never reads a stale list
+	if path != "" {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			warn.Printf("Warning: could not write PR URLs: %v\n", err)
+		} else {
+			green.Printf("✓ Wrote %d PR URLs to %s\n", len(urls), path)
+		}
+	}
+
+	if toClipboard {
+		switch {
+		case len(urls) == 0:
+			fmt.Println("No PRs created, clipboard left unchanged.")
+		case clipboard.Unsupported:
+			warn.Println("Warning: no clipboard available (on Linux, install xclip, xsel or wl-clipboard)")
+		default:
+			if err := writeClipboard(text); err != nil {
+				warn.Printf("Warning: could not copy PR URLs: %v\n", err)
+			} else {
+				green.Printf("✓ Copied %d PR URLs to the clipboard\n", len(urls))
+			}
+		}
+	}
+}
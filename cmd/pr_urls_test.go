@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/atotto/clipboard"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+func TestExportPRURLs(t *testing.T) {
+	defer func(w func(string) error, unsupported bool) {
+		writeClipboard, clipboard.Unsupported = w, unsupported
+	}(writeClipboard, clipboard.Unsupported)
+	var copied string
+	writeClipboard = func(s string) error { copied = s; return nil }
+	clipboard.Unsupported = false
+
+	results := []pullrequest.Result{
+		{RepoSlug: "api", Success: true, PRURL: "https://bitbucket.org/ws/api/pull-requests/1"},
+		{RepoSlug: "web", Error: "conflict"},
+		{RepoSlug: "worker", Success: true, PRURL: "https://bitbucket.org/ws/worker/pull-requests/4"},
+	}
+	path := filepath.Join(t.TempDir(), "prs.txt")
+
+	exportPRURLs(results, path, true)
+
+	want := "https://bitbucket.org/ws/api/pull-requests/1\nhttps://bitbucket.org/ws/worker/pull-requests/4\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+	if copied != want {
+		t.Errorf("clipboard = %q, want %q", copied, want)
+	}
+}
+
+func TestExportPRURLs_NoneCreatedTruncatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prs.txt")
+	os.WriteFile(path, []byte("stale\n"), 0644)
+
+	exportPRURLs([]pullrequest.Result{{RepoSlug: "api", Error: "conflict"}}, path, false)
+
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Errorf("file = %q, want empty", data)
+	}
+}
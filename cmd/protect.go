@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	protectFlagGroup         string
+	protectFlagRepos         string
+	protectFlagInteractive   bool
+	protectFlagDryRun        bool
+	protectFlagYes           bool
+	protectFlagNoForcePush   bool
+	protectFlagNoDelete      bool
+	protectFlagApprovals     int
+	protectFlagPassingBuilds int
+	protectFlagRestrictMerge bool
+	protectFlagMergeUsers    string
+)
+
+var protectCmd = &cobra.Command{
+	Use:   "protect <branch-pattern>",
+	Short: "Apply branch restrictions across repos",
+	Long: `Apply branch permissions to a branch name or glob (e.g. "release/*") across repos.
+Existing restrictions of the same kind and pattern are updated; others are left untouched.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProtect,
+}
+
+func init() {
+	protectCmd.Flags().StringVarP(&protectFlagGroup, "group", "g", "", "repo group from config")
+	protectCmd.Flags().StringVarP(&protectFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	protectCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	protectCmd.Flags().BoolVarP(&protectFlagInteractive, "interactive", "i", false, "select repos interactively")
+	protectCmd.Flags().BoolVar(&protectFlagDryRun, "dry-run", false, "preview actions without executing")
+	protectCmd.Flags().BoolVarP(&protectFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+	protectCmd.Flags().BoolVar(&protectFlagNoForcePush, "no-force-push", false, "block force pushes")
+	protectCmd.Flags().BoolVar(&protectFlagNoDelete, "no-delete", false, "block branch deletion")
+	protectCmd.Flags().IntVar(&protectFlagApprovals, "approvals", 0, "require N approvals to merge")
+	protectCmd.Flags().IntVar(&protectFlagPassingBuilds, "passing-builds", 0, "require N passing builds to merge")
+	protectCmd.Flags().BoolVar(&protectFlagRestrictMerge, "restrict-merge", false, "only allow --merge-users to merge")
+	protectCmd.Flags().StringVar(&protectFlagMergeUsers, "merge-users", "", "comma-separated user UUIDs allowed to merge (with --restrict-merge)")
+
+	_ = protectCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = protectCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	rootCmd.AddCommand(protectCmd)
+}
+
+func runProtect(cmd *cobra.Command, args []string) error {
+	pattern := args[0]
+
+	rules, err := protectRules(pattern)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.Workspace == "" {
+		return fmt.Errorf("workspace not configured in .buck.yaml")
+	}
+
+	client, err := buildProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	restrictions, err := requireCapability[provider.BranchRestrictionService](cfg, client, "protect")
+	if err != nil {
+		return err
+	}
+
+	repos, err := resolveTargetRepos(protectFlagRepos, protectFlagGroup, protectFlagInteractive, cfg, client)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories selected")
+	}
+
+	bold := color.New(color.Bold)
+
+	if protectFlagDryRun {
+		bold.Printf("Dry run: would apply to %q:\n", pattern)
+		for _, r := range rules {
+			fmt.Printf("  * %s\n", describeRestriction(r))
+		}
+		fmt.Println("in:")
+		for _, r := range repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun(fmt.Sprintf("protect %q", pattern), cfg.Workspace, repos, cfg.Defaults.ConfirmLimit(), protectFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Applying %d restrictions to %q across %d repos...\n", len(rules), pattern, len(repos))
+
+	results := repoadmin.NewProtector(restrictions).Apply(cfg.Workspace, repos, rules)
+	repoadmin.PrintResults(results)
+
+	return nil
+}
+
+// protectRules builds the branch restrictions requested by the flags.
+func protectRules(pattern string) ([]bitbucket.BranchRestriction, error) {
+	rule := func(kind string) bitbucket.BranchRestriction {
+		return bitbucket.BranchRestriction{Kind: kind, BranchMatchKind: "glob", Pattern: pattern}
+	}
+
+	var rules []bitbucket.BranchRestriction
+	if protectFlagNoForcePush {
+		rules = append(rules, rule("force"))
+	}
+	if protectFlagNoDelete {
+		rules = append(rules, rule("delete"))
+	}
+	if protectFlagApprovals > 0 {
+		r := rule("require_approvals_to_merge")
+		r.Value = &protectFlagApprovals
+		rules = append(rules, r)
+	}
+	if protectFlagPassingBuilds > 0 {
+		r := rule("require_passing_builds_to_merge")
+		r.Value = &protectFlagPassingBuilds
+		rules = append(rules, r)
+	}
+	if protectFlagRestrictMerge {
+		r := rule("restrict_merges")
+		for _, u := range strings.Split(protectFlagMergeUsers, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				r.Users = append(r.Users, bitbucket.UserRef{UUID: u})
+			}
+		}
+		rules = append(rules, r)
+	} else if protectFlagMergeUsers != "" {
+		return nil, fmt.Errorf("--merge-users requires --restrict-merge")
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no restrictions given\n  Hint: use --no-force-push, --no-delete, --approvals, --passing-builds or --restrict-merge")
+	}
+	return rules, nil
+}
+
+// describeRestriction returns a one-line human description of a restriction.
+func describeRestriction(r bitbucket.BranchRestriction) string {
+	switch r.Kind {
+	case "force":
+		return "no force pushes"
+	case "delete":
+		return "no deletion"
+	case "require_approvals_to_merge":
+		return fmt.Sprintf("require %d approvals to merge", *r.Value)
+	case "require_passing_builds_to_merge":
+		return fmt.Sprintf("require %d passing builds to merge", *r.Value)
+	case "restrict_merges":
+		if len(r.Users) == 0 {
+			return "restrict merges (no users exempt)"
+		}
+		return fmt.Sprintf("restrict merges to %d users", len(r.Users))
+	}
+	return r.Kind
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/github"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+func TestProtectRules(t *testing.T) {
+	defer func() {
+		protectFlagNoForcePush, protectFlagApprovals, protectFlagRestrictMerge, protectFlagMergeUsers = false, 0, false, ""
+	}()
+
+	if _, err := protectRules("main"); err == nil || !strings.Contains(err.Error(), "no restrictions given") {
+		t.Errorf("err = %v, want no restrictions given", err)
+	}
+
+	protectFlagNoForcePush = true
+	protectFlagApprovals = 2
+	protectFlagRestrictMerge = true
+	protectFlagMergeUsers = "{a}, {b}"
+	rules, err := protectRules("release/*")
+	if err != nil {
+		t.Fatalf("protectRules error: %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("rules = %+v, want 3", rules)
+	}
+	if rules[1].Kind != "require_approvals_to_merge" || *rules[1].Value != 2 || rules[1].Pattern != "release/*" {
+		t.Errorf("rules[1] = %+v", rules[1])
+	}
+	if len(rules[2].Users) != 2 || rules[2].Users[1].UUID != "{b}" {
+		t.Errorf("rules[2].Users = %+v", rules[2].Users)
+	}
+}
+
+func TestRequireCapability_Unsupported(t *testing.T) {
+	cfg := &config.Config{Provider: "github"}
+	_, err := requireCapability[provider.BranchRestrictionService](cfg, github.NewClient("", ""), "protect")
+	if err == nil || !strings.Contains(err.Error(), `'buck protect' is not supported for provider "github"`) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/changelog"
+)
+
+var (
+	releaseNotesFlagGroup       string
+	releaseNotesFlagRepos       string
+	releaseNotesFlagInteractive bool
+	releaseNotesFlagSince       string
+	releaseNotesFlagInto        string
+	releaseNotesFlagTitle       string
+	releaseNotesFlagTitlesOnly  bool
+	releaseNotesFlagOut         string
+)
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes [branch-name]",
+	Short: "Write combined release notes from the merged PRs across repos",
+	Long: `Collect merged PRs across repos and write their titles and descriptions as
+one markdown document with a section per repo. With a branch name, the PR
+merged from that branch in each repo is used, e.g. after 'buck pr merge';
+with --since, every PR merged in that window (--into limits it to one
+destination branch).`,
+	Example: `  buck release-notes feature/SPT-1298 -g backend
+  buck release-notes --since 14d --into main -g backend --out RELEASE.md
+  buck release-notes --since 2026-03-01 --titles-only`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReleaseNotes,
+}
+
+func init() {
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesFlagGroup, "group", "g", "", "repo group from config")
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	releaseNotesCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	releaseNotesCmd.Flags().BoolVarP(&releaseNotesFlagInteractive, "interactive", "i", false, "select repos interactively")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesFlagSince, "since", "", "PRs merged within this window (e.g. 14d, 2w) or since a date (YYYY-MM-DD)")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesFlagInto, "into", "", "with --since: only PRs merged into this branch")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesFlagTitle, "title", "", "document heading (default: \"Release notes\" with the branch or window)")
+	releaseNotesCmd.Flags().BoolVar(&releaseNotesFlagTitlesOnly, "titles-only", false, "one line per PR, without descriptions")
+	releaseNotesCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesFlagOut, "out", "", "write the notes to this file instead of stdout")
+
+	_ = releaseNotesCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = releaseNotesCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+	_ = releaseNotesCmd.RegisterFlagCompletionFunc("into", completeBranchNames)
+
+	rootCmd.AddCommand(releaseNotesCmd)
+}
+
+func runReleaseNotes(cmd *cobra.Command, args []string) error {
+	var q changelog.PRQuery
+	title := "Release notes"
+	switch {
+	case len(args) == 1 && releaseNotesFlagSince != "":
+		return fmt.Errorf("give either a branch name or --since, not both")
+	case len(args) == 1:
+		if releaseNotesFlagInto != "" {
+			return fmt.Errorf("--into only applies with --since")
+		}
+		q.Branch = args[0]
+	case releaseNotesFlagSince != "":
+		since, err := parseSince(releaseNotesFlagSince, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		q.Since = since
+		q.Destination = releaseNotesFlagInto
+		title += " since " + since.Format("2006-01-02")
+	default:
+		return fmt.Errorf("give a branch name or --since, e.g. 'buck release-notes --since 14d'")
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	if q.Branch != "" {
+		q.Branch = prefixBranch(ctx.cfg, q.Branch)
+		title = "Release notes: " + q.Branch
+	}
+	if releaseNotesFlagTitle != "" {
+		title = releaseNotesFlagTitle
+	}
+	if err := ctx.selectRepos(releaseNotesFlagRepos, releaseNotesFlagGroup, releaseNotesFlagInteractive); err != nil {
+		return err
+	}
+
+	// Progress goes to stderr so stdout carries only the markdown
+	color.New(color.Bold).Fprintf(os.Stderr, "Collecting merged PRs across %d repos...\n", len(ctx.repos))
+	results := changelog.NewCollector(ctx.client).CollectMergedPRs(ctx.cfg.Workspace, ctx.repos, q)
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			fmt.Fprintf(os.Stderr, "Warning: %s left out: %s\n", r.RepoSlug, r.Error)
+		}
+	}
+	if failed == len(results) {
+		return fmt.Errorf("no release notes written: none of the %d repos could be read", len(results))
+	}
+
+	doc := changelog.RenderNotes(results, changelog.NotesOptions{Title: title, TitlesOnly: releaseNotesFlagTitlesOnly})
+	if releaseNotesFlagOut == "" {
+		fmt.Print(doc)
+		return nil
+	}
+	if err := os.WriteFile(releaseNotesFlagOut, []byte(doc), 0644); err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
+	}
+	color.New(color.FgGreen).Fprintf(os.Stderr, "✓ Release notes for %d repos written to %s\n", len(results)-failed, releaseNotesFlagOut)
+	return nil
+}
+
+// parseSince parses a window such as 14d back from now, or a date.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	age, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (YYYY-MM-DD) nor a duration such as 14d", s)
+	}
+	return now.Add(-age), nil
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	if got, err := parseSince("14d", now); err != nil || !got.Equal(now.Add(-14*24*time.Hour)) {
+		t.Errorf("parseSince(14d) = %v, %v", got, err)
+	}
+	if got, err := parseSince("2026-03-01", now); err != nil || got.Format("2006-01-02") != "2026-03-01" {
+		t.Errorf("parseSince(2026-03-01) = %v, %v", got, err)
+	}
+	if _, err := parseSince("last week", now); err == nil {
+		t.Error("parseSince(last week) succeeded, want error")
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/rename"
+)
+
+var (
+	renameFlagGroup       string
+	renameFlagRepos       string
+	renameFlagDryRun      bool
+	renameFlagInteractive bool
+	renameFlagYes         bool
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <old-branch> <new-branch>",
+	Short: "Rename a branch across repos",
+	Long: `Rename a branch across repos. In each repo the new branch is created at the
+old branch's head, open PRs targeting the old branch are retargeted, and the
+old branch is deleted. If a step fails, that repo's changes are rolled back.
+Repos with an open PR from the old branch are skipped.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRename,
+}
+
+func init() {
+	renameCmd.Flags().StringVarP(&renameFlagGroup, "group", "g", "", "repo group from config")
+	renameCmd.Flags().StringVarP(&renameFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	renameCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	renameCmd.Flags().BoolVar(&renameFlagDryRun, "dry-run", false, "preview actions without executing")
+	renameCmd.Flags().BoolVarP(&renameFlagInteractive, "interactive", "i", false, "select repos interactively")
+	renameCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
+	renameCmd.Flags().BoolVarP(&renameFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+
+	_ = renameCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = renameCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	rootCmd.AddCommand(renameCmd)
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	oldName, newName = prefixBranch(ctx.cfg, oldName), prefixBranch(ctx.cfg, newName)
+	if oldName == newName {
+		return fmt.Errorf("old and new branch names are the same")
+	}
+	if err := ctx.selectRepos(renameFlagRepos, renameFlagGroup, renameFlagInteractive); err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if renameFlagDryRun {
+		bold.Printf("Dry run: would rename %q to %q in:\n", oldName, newName)
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun(fmt.Sprintf("rename branch %q to %q", oldName, newName), ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), renameFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Renaming %q to %q across %d repos...\n", oldName, newName, len(ctx.repos))
+	results := rename.NewRenamer(ctx.client).Rename(ctx.cfg.Workspace, ctx.repos, oldName, newName)
+	rename.PrintResults(results, oldName, newName)
+	return nil
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var (
+	repoFlagGroup       string
+	repoFlagRepos       string
+	repoFlagInteractive bool
+	repoFlagDryRun      bool
+	repoFlagYes         bool
+
+	repoSettingsFlagFile string
+	repoCreateFlagFile   string
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Repository administration across repos",
+}
+
+var repoSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Manage repository settings",
+}
+
+var repoSettingsApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply settings from a YAML file across repos",
+	Long: `Apply repository settings from a YAML file across repos. Supported keys:
+fork_policy, private, has_issues, has_wiki, language, default_merge_strategy,
+delete_branch_on_merge. Keys left out are not changed.`,
+	Args: cobra.NoArgs,
+	RunE: runRepoSettingsApply,
+}
+
+var repoCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create repositories from a YAML spec",
+	Long: `Create new repositories in the workspace from a YAML spec. Each repo takes
+slug, description, project, private, main_branch and seed (a directory whose
+files are committed as the first commit); keys under defaults apply to every
+repo that does not set them.`,
+	Args: cobra.NoArgs,
+	RunE: runRepoCreate,
+}
+
+func init() {
+	// Shared flags available to all repo subcommands
+	repoCmd.PersistentFlags().StringVarP(&repoFlagGroup, "group", "g", "", "repo group from config")
+	repoCmd.PersistentFlags().StringVarP(&repoFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	repoCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	repoCmd.PersistentFlags().BoolVarP(&repoFlagInteractive, "interactive", "i", false, "select repos interactively")
+	repoCmd.PersistentFlags().BoolVar(&repoFlagDryRun, "dry-run", false, "preview actions without executing")
+	repoCmd.PersistentFlags().BoolVarP(&repoFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+
+	repoSettingsApplyCmd.Flags().StringVarP(&repoSettingsFlagFile, "file", "f", "", "settings YAML file (required)")
+	_ = repoSettingsApplyCmd.MarkFlagRequired("file")
+	repoCreateCmd.Flags().StringVarP(&repoCreateFlagFile, "file", "f", "", "repository spec YAML file (required)")
+	_ = repoCreateCmd.MarkFlagRequired("file")
+
+	_ = repoCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = repoCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	repoSettingsCmd.AddCommand(repoSettingsApplyCmd)
+	repoCmd.AddCommand(repoSettingsCmd)
+	repoCmd.AddCommand(repoCreateCmd)
+	rootCmd.AddCommand(repoCmd)
+}
+
+// repoContext holds the resolved config, provider and target repos for an admin command.
+type repoContext struct {
+	cfg    *config.Config
+	client provider.Provider
+	repos  []string
+}
+
+// newRepoContext loads config and builds the provider. Call selectRepos after
+// checking the provider supports the command, so unsupported commands fail
+// before any interactive prompt.
+func newRepoContext() (*repoContext, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Workspace == "" {
+		return nil, fmt.Errorf("workspace not configured in .buck.yaml")
+	}
+
+	client, err := buildProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &repoContext{cfg: cfg, client: client}, nil
+}
+
+// selectRepos resolves the target repos from --repos, --group or the picker.
+func (ctx *repoContext) selectRepos(reposFlag, groupFlag string, interactive bool) error {
+	repos, err := resolveTargetRepos(reposFlag, groupFlag, interactive, ctx.cfg, ctx.client)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories selected")
+	}
+	ctx.repos = repos
+	return nil
+}
+
+func runRepoSettingsApply(cmd *cobra.Command, args []string) error {
+	settings, err := repoadmin.LoadSettings(repoSettingsFlagFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.RepositorySettingsService](ctx.cfg, ctx.client, "repo settings apply")
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(repoFlagRepos, repoFlagGroup, repoFlagInteractive); err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+	applier := repoadmin.NewSettingsApplier(service)
+
+	if repoFlagDryRun {
+		bold.Printf("Dry run: checking settings from %s against %d repos...\n", repoSettingsFlagFile, len(ctx.repos))
+		repoadmin.PrintResults(applier.Preview(ctx.cfg.Workspace, ctx.repos, settings))
+		return nil
+	}
+
+	if !confirmLargeRun("apply repository settings", ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), repoFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Applying settings from %s across %d repos...\n", repoSettingsFlagFile, len(ctx.repos))
+	repoadmin.PrintResults(applier.Apply(ctx.cfg.Workspace, ctx.repos, settings))
+	return nil
+}
+
+func runRepoCreate(cmd *cobra.Command, args []string) error {
+	specs, err := repoadmin.LoadRepoSpecs(repoCreateFlagFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.RepositoryCreationService](ctx.cfg, ctx.client, "repo create")
+	if err != nil {
+		return err
+	}
+	branches, err := requireCapability[provider.DefaultBranchService](ctx.cfg, ctx.client, "repo create")
+	if err != nil {
+		return err
+	}
+
+	slugs := make([]string, len(specs))
+	for i, s := range specs {
+		slugs[i] = s.Slug
+	}
+
+	bold := color.New(color.Bold)
+	provisioner := repoadmin.NewRepoCreator(ctx.client, service, branches)
+
+	if repoFlagDryRun {
+		bold.Printf("Dry run: checking %d repos from %s...\n", len(specs), repoCreateFlagFile)
+		repoadmin.PrintResults(provisioner.Preview(ctx.cfg.Workspace, specs))
+		return nil
+	}
+
+	if !confirmLargeRun("create repositories", ctx.cfg.Workspace, slugs, ctx.cfg.Defaults.ConfirmLimit(), repoFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Creating %d repos from %s in %s...\n", len(specs), repoCreateFlagFile, ctx.cfg.Workspace)
+	repoadmin.PrintResults(provisioner.Create(ctx.cfg.Workspace, specs))
+	return nil
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var repoRetireFlagConfirm string
+
+var repoArchiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Archive repos (make them read-only)",
+	Long: `Archive the selected repos. The plan is always checked and shown first, then
+the workspace name must be typed to proceed (or passed with --confirm). When
+ws/repo entries reach other workspaces, every workspace must be named,
+comma-separated. GitHub and GitLab only; Bitbucket Cloud has no archive API.`,
+	Args: cobra.NoArgs,
+	RunE: runRepoArchive,
+}
+
+var repoDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Permanently delete repos",
+	Long: `Permanently delete the selected repos. The plan is always checked and shown
+first, then the workspace name must be typed to proceed (or passed with
+--confirm). When ws/repo entries reach other workspaces, every workspace must
+be named, comma-separated. --yes does not skip this.`,
+	Args: cobra.NoArgs,
+	RunE: runRepoDelete,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{repoArchiveCmd, repoDeleteCmd} {
+		c.Flags().StringVar(&repoRetireFlagConfirm, "confirm", "", "workspace name (comma-separated when repos span several), to confirm without the prompt (for scripts)")
+		repoCmd.AddCommand(c)
+	}
+}
+
+func runRepoArchive(cmd *cobra.Command, args []string) error {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.RepositoryArchiveService](ctx.cfg, ctx.client, "repo archive")
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(repoFlagRepos, repoFlagGroup, repoFlagInteractive); err != nil {
+		return err
+	}
+
+	archiver := repoadmin.NewRepoArchiver(ctx.client, service)
+	repos, err := confirmRetire(ctx.cfg.Workspace, "archive", archiver.Preview(ctx.cfg.Workspace, ctx.repos))
+	if err != nil || repos == nil {
+		return err
+	}
+
+	color.New(color.Bold).Printf("Archiving %d repos...\n", len(repos))
+	repoadmin.PrintResults(archiver.Archive(ctx.cfg.Workspace, repos))
+	return nil
+}
+
+func runRepoDelete(cmd *cobra.Command, args []string) error {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return err
+	}
+	service, err := requireCapability[provider.RepositoryDeletionService](ctx.cfg, ctx.client, "repo delete")
+	if err != nil {
+		return err
+	}
+	if err := ctx.selectRepos(repoFlagRepos, repoFlagGroup, repoFlagInteractive); err != nil {
+		return err
+	}
+
+	deleter := repoadmin.NewRepoDeleter(ctx.client, service)
+	repos, err := confirmRetire(ctx.cfg.Workspace, "permanently delete", deleter.Preview(ctx.cfg.Workspace, ctx.repos))
+	if err != nil || repos == nil {
+		return err
+	}
+
+	color.New(color.Bold).Printf("Deleting %d repos...\n", len(repos))
+	repoadmin.PrintResults(deleter.Delete(ctx.cfg.Workspace, repos))
+	return nil
+}
+
+// confirmRetire prints the preview and, unless this is a dry run, asks for
+// the name of every workspace the repos are in. It returns the repos that
+// passed the preview, or nil when nothing should be done.
+func confirmRetire(workspace, action string, preview []repoadmin.Result) ([]string, error) {
+	color.New(color.Bold).Printf("Checking %d repos to %s...\n", len(preview), action)
+	repoadmin.PrintResults(preview)
+
+	var repos []string
+	for _, r := range preview {
+		if r.Success {
+			repos = append(repos, r.RepoSlug)
+		}
+	}
+	if repoFlagDryRun {
+		return nil, nil
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repos to %s", action)
+	}
+
+	workspaces := retireWorkspaces(workspace, repos)
+	names := strings.Join(workspaces, ",")
+	fmt.Println()
+	if repoRetireFlagConfirm != "" {
+		if !namesAll(repoRetireFlagConfirm, workspaces) {
+			return nil, fmt.Errorf("--confirm %q does not match the workspaces %q", repoRetireFlagConfirm, names)
+		}
+		return repos, nil
+	}
+	color.New(color.FgRed, color.Bold).Printf("This will %s %d repos in %s.\n", action, len(repos), strings.Join(workspaces, ", "))
+	if len(workspaces) == 1 {
+		fmt.Printf("Type the workspace name to continue: ")
+	} else {
+		fmt.Printf("Type the workspace names, comma-separated (%s), to continue: ", names)
+	}
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if !namesAll(line, workspaces) {
+		fmt.Println("Aborted.")
+		return nil, nil
+	}
+	return repos, nil
+}
+
+// retireWorkspaces returns the distinct workspaces of repos, sorted; ws/repo
+// entries may reach beyond the configured workspace.
+func retireWorkspaces(workspace string, repos []string) []string {
+	seen := make(map[string]bool)
+	var workspaces []string
+	for _, r := range repos {
+		ws, _ := provider.SplitRepo(workspace, r)
+		if !seen[ws] {
+			seen[ws] = true
+			workspaces = append(workspaces, ws)
+		}
+	}
+	sort.Strings(workspaces)
+	return workspaces
+}
+
+// namesAll reports whether the comma-separated answer names exactly the
+// given workspaces, in any order.
+func namesAll(answer string, workspaces []string) bool {
+	named := make(map[string]bool)
+	for _, name := range strings.Split(answer, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			named[name] = true
+		}
+	}
+	if len(named) != len(workspaces) {
+		return false
+	}
+	for _, ws := range workspaces {
+		if !named[ws] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRetireWorkspaces(t *testing.T) {
+	got := retireWorkspaces("acme", []string{"api", "ops/ledger", "web", "ops/infra"})
+	if want := []string{"acme", "ops"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("retireWorkspaces = %v, want %v", got, want)
+	}
+}
+
+func TestNamesAll(t *testing.T) {
+	workspaces := []string{"acme", "ops"}
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{"acme,ops", true},
+		{" ops, acme\n", true},
+		{"acme", false},
+		{"acme,ops,other", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := namesAll(tt.answer, workspaces); got != tt.want {
+			t.Errorf("namesAll(%q) = %v, want %v", tt.answer, got, tt.want)
+		}
+	}
+	if !namesAll("acme\n", []string{"acme"}) {
+		t.Error("expected the single workspace to match")
+	}
+}
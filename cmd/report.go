@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/compare"
+	"github.com/chinhstringee/buck/internal/dashboard"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+var (
+	reportFlagGroup       string
+	reportFlagRepos       string
+	reportFlagInteractive bool
+	reportFlagFormat      string
+
+	reportPRsFlagOlderThan string
+
+	reportBranchesFlagBehind int
+	reportBranchesFlagBase   string
+	reportBranchesFlagMatch  string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Workspace reports for leads (PR aging, branch divergence)",
+}
+
+var reportPRsCmd = &cobra.Command{
+	Use:   "prs",
+	Short: "List open PRs by age, author and review state",
+	Long: `List the open PRs across the workspace, or the selected repos, oldest first,
+with their author and where the review stands (approved, changes requested,
+waiting on reviewers, no reviewers). --older-than keeps only PRs opened longer
+ago than that, e.g. 7d or 36h. Use --format csv or -o json to export the list.`,
+	Example: `  buck report prs --older-than 7d
+  buck report prs -g backend --older-than 2w --format csv > stale-prs.csv`,
+	Args: cobra.NoArgs,
+	RunE: runReportPRs,
+}
+
+var reportBranchesCmd = &cobra.Command{
+	Use:   "branches",
+	Short: "List branches that have fallen behind their repo's main branch",
+	Long: `List the branches that are more than --behind commits behind the main
+branch of their repo (or --base), most behind first, so long-lived feature
+branches can be rebased before their PR. Branches with no commits of their own
+are left out. --match limits the report to branches matching a glob.`,
+	Example: `  buck report branches -g backend --behind 50
+  buck report branches --match 'feature/*' --base develop --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runReportBranches,
+}
+
+func init() {
+	// Shared flags available to all report subcommands
+	reportCmd.PersistentFlags().StringVarP(&reportFlagGroup, "group", "g", "", "repo group from config (default: every repo in the workspace)")
+	reportCmd.PersistentFlags().StringVarP(&reportFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	reportCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	reportCmd.PersistentFlags().BoolVarP(&reportFlagInteractive, "interactive", "i", false, "select repos interactively")
+	reportCmd.PersistentFlags().StringVar(&reportFlagFormat, "format", "table", "output format: table or csv (use -o json for JSON)")
+
+	_ = reportCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = reportCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+	_ = reportCmd.RegisterFlagCompletionFunc("format", completeStaticValues([]string{"table", "csv"}))
+
+	reportPRsCmd.Flags().StringVar(&reportPRsFlagOlderThan, "older-than", "0", "only PRs opened longer ago than this, e.g. 7d, 2w or 36h")
+
+	reportBranchesCmd.Flags().IntVar(&reportBranchesFlagBehind, "behind", 20, "list branches more than this many commits behind")
+	reportBranchesCmd.Flags().StringVar(&reportBranchesFlagBase, "base", "", "branch to compare against (default: each repo's main branch)")
+	reportBranchesCmd.Flags().StringVar(&reportBranchesFlagMatch, "match", "", "only branches matching this glob, e.g. 'feature/*'")
+	_ = reportBranchesCmd.RegisterFlagCompletionFunc("base", completeBranchNames)
+
+	reportCmd.AddCommand(reportPRsCmd)
+	reportCmd.AddCommand(reportBranchesCmd)
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReportPRs(cmd *cobra.Command, args []string) error {
+	olderThan, err := parseAge(reportPRsFlagOlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	ctx, err := newReportContext()
+	if err != nil {
+		return err
+	}
+
+	if reportFlagFormat == "table" {
+		color.New(color.Bold).Printf("Fetching open PRs across %d repos...\n", len(ctx.repos))
+	}
+	results := dashboard.NewFetcher(ctx.client).FetchAllPRs(ctx.cfg.Workspace, ctx.repos, dashboard.PRFilters{})
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", r.RepoSlug, r.Error)
+		}
+	}
+
+	prs := dashboard.AgePRs(results, olderThan, time.Now())
+	switch {
+	case render.Output == render.JSON:
+		return dashboard.WriteAgingJSON(render.Stdout, prs)
+	case reportFlagFormat == "csv":
+		return dashboard.WriteAgingCSV(render.Stdout, prs)
+	}
+	dashboard.PrintAgingReport(prs)
+	return nil
+}
+
+func runReportBranches(cmd *cobra.Command, args []string) error {
+	if reportBranchesFlagBehind < 0 {
+		return fmt.Errorf("--behind must not be negative")
+	}
+	if _, err := path.Match(reportBranchesFlagMatch, ""); err != nil {
+		return fmt.Errorf("invalid --match pattern %q: %w", reportBranchesFlagMatch, err)
+	}
+	ctx, err := newReportContext()
+	if err != nil {
+		return err
+	}
+
+	if reportFlagFormat == "table" {
+		color.New(color.Bold).Printf("Checking branches across %d repos...\n", len(ctx.repos))
+	}
+	results := compare.NewComparer(ctx.client).Diverged(ctx.cfg.Workspace, ctx.repos, compare.DivergenceOptions{
+		MinBehind: reportBranchesFlagBehind,
+		Base:      reportBranchesFlagBase,
+		Pattern:   reportBranchesFlagMatch,
+	})
+	switch {
+	case render.Output == render.JSON:
+		return render.WriteJSON(results)
+	case reportFlagFormat == "csv":
+		return compare.WriteDivergenceCSV(render.Stdout, results)
+	}
+	compare.PrintDivergence(results, reportBranchesFlagBehind)
+	return nil
+}
+
+// newReportContext checks --format and resolves the repos of a report: the
+// selected ones, or unlike most commands every repo in the workspace.
+func newReportContext() (*repoContext, error) {
+	switch reportFlagFormat {
+	case "table", "csv":
+	default:
+		return nil, fmt.Errorf("invalid --format %q (valid: table, csv; use -o json for JSON)", reportFlagFormat)
+	}
+	if reportFlagFormat == "csv" && render.Output == render.JSON {
+		return nil, fmt.Errorf("--format csv cannot be combined with -o json")
+	}
+
+	ctx, err := newRepoContext()
+	if err != nil {
+		return nil, err
+	}
+	if reportFlagRepos != "" || reportFlagGroup != "" || flagReposFile != "" || reportFlagInteractive {
+		if err := ctx.selectRepos(reportFlagRepos, reportFlagGroup, reportFlagInteractive); err != nil {
+			return nil, err
+		}
+		return ctx, nil
+	}
+
+	repos, err := listRepositories(ctx.cfg, ctx.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	for _, r := range repos {
+		ctx.repos = append(ctx.repos, r.Slug)
+	}
+	if len(ctx.repos) == 0 {
+		return nil, fmt.Errorf("no repositories in workspace %q", ctx.cfg.Workspace)
+	}
+	return ctx, nil
+}
+
+// parseAge parses a duration that may also be given in days or weeks
+// ("7d", "2w"), which time.ParseDuration does not accept.
+func parseAge(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%q is not a duration such as 7d, 2w or 36h", s)
+}
@@ -1,23 +1,93 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/x/term"
 	"github.com/fatih/color"
 	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/gitutil"
 	"github.com/chinhstringee/buck/internal/matcher"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repocache"
+	"github.com/chinhstringee/buck/internal/selection"
 )
 
+// flagReposFile is the --repos-file flag, shared by every command that
+// takes --repos.
+var flagReposFile string
+
+// repoInput is where "--repos -" reads its list from.
+var repoInput io.Reader = os.Stdin
+
+// stdinRepos is set once "--repos -" has consumed stdin, so prompts read
+// their answer from the terminal instead.
+var stdinRepos bool
+
+// assumeYes is set when the running command was given --yes, so resolving
+// its repos must not stop at a prompt either.
+var assumeYes bool
+
+// canPrompt reports whether an optional prompt may open while resolving
+// repos: stdin is a terminal, did not carry the repo list, and --yes was not
+// given. Scripts and CI get every match instead.
+func canPrompt() bool {
+	return !assumeYes && !stdinRepos && term.IsTerminal(os.Stdin.Fd())
+}
+
+// readOnlyWorkspace returns the workspace from config or, when none is set,
+// that of the origin remote if run inside a Bitbucket checkout, so read-only
+// commands work there without a .buck.yaml. Commands that change repos must
+// not use it: a stray checkout would pick their workspace. A detected
+// workspace is announced and stored in cfg.
+func readOnlyWorkspace(cfg *config.Config) (string, error) {
+	if cfg.Workspace == "" && cfg.ProviderName() == provider.Bitbucket {
+		if ws, _, err := gitutil.ParseBitbucketRemote(); err == nil {
+			color.New(color.Faint).Printf("Using workspace %q from the origin remote\n", ws)
+			cfg.Workspace = ws
+		}
+	}
+	if cfg.Workspace == "" {
+		return "", fmt.Errorf("workspace not configured in .buck.yaml")
+	}
+	return cfg.Workspace, nil
+}
+
 // resolveTargetRepos determines which repos to target based on the given flags.
-func resolveTargetRepos(reposFlag, groupFlag string, interactive bool, cfg *config.Config, client *bitbucket.Client) ([]string, error) {
+func resolveTargetRepos(reposFlag, groupFlag string, interactive bool, cfg *config.Config, client provider.Provider) ([]string, error) {
 	// --interactive flag forces interactive selection
 	if interactive {
 		return selectInteractively(cfg, client)
 	}
 
+	// --repos-file reads one entry per line, like a group kept outside the config
+	if flagReposFile != "" {
+		if reposFlag != "" || groupFlag != "" {
+			return nil, fmt.Errorf("--repos-file cannot be combined with --repos or --group")
+		}
+		return resolveRepoFile(cfg, client, flagReposFile)
+	}
+
+	// --repos - reads one entry per line from stdin, e.g. piped from jq
+	if reposFlag == "-" {
+		entries, err := readRepoList(repoInput)
+		stdinRepos = true
+		if err != nil {
+			return nil, fmt.Errorf("failed to read repos from stdin: %w", err)
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no repos given on stdin")
+		}
+		return expandGroupPatterns(cfg, client, entries)
+	}
+
 	// Explicit --repos flag takes priority — fuzzy match against workspace repos
 	if reposFlag != "" {
 		return resolveWithFuzzyMatch(cfg, client, reposFlag)
@@ -25,18 +95,62 @@ func resolveTargetRepos(reposFlag, groupFlag string, interactive bool, cfg *conf
 
 	// --group flag
 	if groupFlag != "" {
-		return cfg.GetReposForGroup(groupFlag)
+		repos, err := cfg.GetReposForGroup(groupFlag)
+		if err != nil {
+			return nil, err
+		}
+		return expandGroupPatterns(cfg, client, repos)
 	}
 
 	// Default: interactive mode (core use case)
 	return selectInteractively(cfg, client)
 }
 
+// resolveRepoFile resolves the slugs and patterns listed in a --repos-file.
+func resolveRepoFile(cfg *config.Config, client provider.Provider, path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repos file: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := readRepoList(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repos file %s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no repos listed in %s", path)
+	}
+	return expandGroupPatterns(cfg, client, entries)
+}
+
+// readRepoList reads one repo slug or pattern per line. Blank lines and
+// "#" comments are skipped, and JSON string quotes are removed so
+// unquoted and quoted jq output both work.
+func readRepoList(r io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if len(line) >= 2 && line[0] == '"' && line[len(line)-1] == '"' {
+			line = line[1 : len(line)-1]
+		}
+		if line != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries, scanner.Err()
+}
+
 // selectInteractively fetches workspace repos and shows a multi-select.
-func selectInteractively(cfg *config.Config, client *bitbucket.Client) ([]string, error) {
+func selectInteractively(cfg *config.Config, client provider.Provider) ([]string, error) {
 	fmt.Printf("Fetching repos from workspace %q...\n", cfg.Workspace)
 
-	repos, err := client.ListRepositories(cfg.Workspace)
+	repos, err := listRepositories(cfg, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list repos: %w", err)
 	}
@@ -45,14 +159,41 @@ func selectInteractively(cfg *config.Config, client *bitbucket.Client) ([]string
 		return nil, fmt.Errorf("no repositories found in workspace %q", cfg.Workspace)
 	}
 
-	// Build options for multi-select
+	// Preselect whatever was picked last time in this workspace
+	previous := make(map[string]bool)
+	for _, slug := range selection.Last(cfg.Workspace) {
+		previous[slug] = true
+	}
+
+	selected, err := pickRepos(repos, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(selected) > 0 {
+		if err := selection.Save(cfg.Workspace, selected); err != nil {
+			color.New(color.FgYellow).Printf("Warning: could not remember selection: %v\n", err)
+		}
+		// Offer each new selection once: not again when it is picked as
+		// it was last time, or when a group already holds it
+		if !sameRepos(selected, previous) && groupWithRepos(cfg, selected) == "" {
+			offerSaveAsGroup(cfg, selected)
+		}
+	}
+
+	return selected, nil
+}
+
+// pickRepos shows a filterable multi-select of repos, with the slugs in
+// preselected already checked.
+func pickRepos(repos []bitbucket.Repository, preselected map[string]bool) ([]string, error) {
 	options := make([]huh.Option[string], 0, len(repos))
 	for _, r := range repos {
 		label := r.Slug
 		if r.MainBranch != nil {
 			label = fmt.Sprintf("%s (%s)", r.Slug, r.MainBranch.Name)
 		}
-		options = append(options, huh.NewOption(label, r.Slug))
+		options = append(options, huh.NewOption(label, r.Slug).Selected(preselected[r.Slug]))
 	}
 
 	var selected []string
@@ -60,6 +201,7 @@ func selectInteractively(cfg *config.Config, client *bitbucket.Client) ([]string
 		huh.NewGroup(
 			huh.NewMultiSelect[string]().
 				Title("Select repositories (type to filter)").
+				Description("ctrl+a selects all visible repos, press again to clear").
 				Options(options...).
 				Filterable(true).
 				Value(&selected),
@@ -69,26 +211,341 @@ func selectInteractively(cfg *config.Config, client *bitbucket.Client) ([]string
 	if err := form.Run(); err != nil {
 		return nil, fmt.Errorf("selection cancelled")
 	}
-
 	return selected, nil
 }
 
-// resolveWithFuzzyMatch fetches workspace repos and fuzzy-matches patterns.
-func resolveWithFuzzyMatch(cfg *config.Config, client *bitbucket.Client, reposFlag string) ([]string, error) {
-	patterns := strings.Split(reposFlag, ",")
+// offerSaveAsGroup asks whether to store an interactive selection as a new
+// named group in the config file. Names of existing groups are refused.
+func offerSaveAsGroup(cfg *config.Config, selected []string) {
+	var (
+		save bool
+		name string
+	)
 
-	fmt.Printf("Fetching repos from workspace %q...\n", cfg.Workspace)
-	repos, err := client.ListRepositories(cfg.Workspace)
+	confirm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Save this selection as a group?").
+				Value(&save),
+		),
+	)
+	if err := confirm.Run(); err != nil || !save {
+		return
+	}
+
+	input := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Group name").
+				Value(&name).
+				Validate(func(s string) error {
+					if err := requiredValidator("group name")(s); err != nil {
+						return err
+					}
+					if groupExists(cfg, s) {
+						return fmt.Errorf("group %q already exists; pick another name", strings.TrimSpace(s))
+					}
+					return nil
+				}),
+		),
+	)
+	if err := input.Run(); err != nil {
+		return
+	}
+	name = strings.TrimSpace(name)
+
+	path, err := configFilePath()
+	if err == nil {
+		_, err = config.AddToGroup(path, name, selected)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to list repos: %w", err)
+		color.New(color.FgYellow).Printf("Warning: could not save group: %v\n", err)
+		return
+	}
+
+	if cfg.Groups == nil {
+		cfg.Groups = make(map[string][]string)
+	}
+	cfg.Groups[name] = selected
+	color.New(color.FgGreen).Printf("✓ Saved group %q to %s\n", name, path)
+}
+
+// groupExists reports whether cfg has a group called name. Config keys are
+// case-insensitive, so "Backend" and "backend" are the same group.
+func groupExists(cfg *config.Config, name string) bool {
+	name = strings.TrimSpace(name)
+	for g := range cfg.Groups {
+		if strings.EqualFold(g, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupWithRepos returns the name of a group holding exactly repos, or "".
+func groupWithRepos(cfg *config.Config, repos []string) string {
+	for name, entries := range cfg.Groups {
+		set := make(map[string]bool, len(entries))
+		for _, e := range entries {
+			set[e] = true
+		}
+		if sameRepos(repos, set) {
+			return name
+		}
+	}
+	return ""
+}
+
+// sameRepos reports whether repos holds exactly the slugs in set.
+func sameRepos(repos []string, set map[string]bool) bool {
+	seen := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		if !set[r] {
+			return false
+		}
+		seen[r] = true
+	}
+	return len(seen) == len(set)
+}
+
+// expandGroupPatterns resolves regex/glob entries in a group definition against workspace repos.
+// Plain entries are kept as literal slugs; a workspace is only listed when a pattern names it.
+func expandGroupPatterns(cfg *config.Config, client provider.Provider, entries []string) ([]string, error) {
+	var patterns []string
+	for _, e := range entries {
+		if matcher.IsExplicit(e) {
+			patterns = append(patterns, e)
+		}
+	}
+	if len(patterns) == 0 {
+		return entries, nil
+	}
+
+	if err := matcher.Validate(patterns); err != nil {
+		return nil, err
+	}
+
+	byWorkspace := make(map[string][]string)
+	for _, p := range patterns {
+		ws, _ := provider.SplitPattern(cfg.Workspace, p)
+		byWorkspace[ws] = append(byWorkspace[ws], p)
+	}
+	candidates := make(map[string][]matcher.Candidate, len(byWorkspace))
+	for ws := range byWorkspace {
+		repos, err := listWorkspaceRepositories(cfg, client, ws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos in workspace %q: %w", ws, err)
+		}
+		candidates[ws] = repoCandidates(repos)
+	}
+
+	seen := make(map[string]bool)
+	var result, exclusions []string
+	add := func(slug string) {
+		if !seen[slug] {
+			seen[slug] = true
+			result = append(result, slug)
+		}
+	}
+
+	for _, e := range entries {
+		if matcher.IsNegative(e) {
+			exclusions = append(exclusions, e)
+			continue
+		}
+		if !matcher.IsExplicit(e) {
+			add(e)
+			continue
+		}
+		matched := matchWorkspaces(cfg.Workspace, candidates, []string{e})
+		if len(matched.Matched) == 0 {
+			color.New(color.FgYellow).Printf("Warning: no repos matched group pattern %q\n", e)
+		}
+		for _, s := range matched.Matched {
+			add(s)
+		}
+	}
+
+	// Exclusions apply to literal and pattern entries alike, each within
+	// the workspace it names
+	if len(exclusions) > 0 && len(result) > 0 {
+		selected := make(map[string][]matcher.Candidate)
+		for _, entry := range result {
+			ws, slug := provider.SplitRepo(cfg.Workspace, entry)
+			c, ok := findCandidate(candidates[ws], slug)
+			if !ok {
+				c = matcher.Candidate{Slug: slug}
+			}
+			selected[ws] = append(selected[ws], c)
+		}
+		kept := make(map[string]bool)
+		for ws, cands := range selected {
+			var own []string
+			for _, e := range exclusions {
+				if exWS, _ := provider.SplitPattern(cfg.Workspace, e); exWS == ws {
+					own = append(own, e)
+				}
+			}
+			if len(own) == 0 {
+				for _, c := range cands {
+					kept[provider.QualifyRepo(cfg.Workspace, ws, c.Slug)] = true
+				}
+				continue
+			}
+			only := map[string][]matcher.Candidate{ws: cands}
+			for _, s := range matchWorkspaces(cfg.Workspace, only, own).Matched {
+				kept[s] = true
+			}
+		}
+		filtered := result[:0]
+		for _, entry := range result {
+			ws, slug := provider.SplitRepo(cfg.Workspace, entry)
+			if kept[provider.QualifyRepo(cfg.Workspace, ws, slug)] {
+				filtered = append(filtered, entry)
+			}
+		}
+		result = filtered
+	}
+
+	return result, nil
+}
+
+// findCandidate returns the candidate with the given slug.
+func findCandidate(candidates []matcher.Candidate, slug string) (matcher.Candidate, bool) {
+	for _, c := range candidates {
+		if c.Slug == slug {
+			return c, true
+		}
 	}
+	return matcher.Candidate{}, false
+}
+
+// listRepositories lists the default workspace's repos and remembers their
+// slugs for shell completion.
+func listRepositories(cfg *config.Config, client provider.Provider) ([]bitbucket.Repository, error) {
+	return listWorkspaceRepositories(cfg, client, cfg.Workspace)
+}
 
+// listWorkspaceRepositories lists the repos of workspace and remembers their
+// slugs for shell completion.
+func listWorkspaceRepositories(cfg *config.Config, client provider.Provider, workspace string) ([]bitbucket.Repository, error) {
+	repos, err := client.ListRepositories(workspace)
+	if err != nil {
+		return nil, err
+	}
 	slugs := make([]string, len(repos))
 	for i, r := range repos {
 		slugs[i] = r.Slug
 	}
+	// Best effort: completion falls back to group entries without it
+	_ = repocache.Save(workspace, slugs)
+	return repos, nil
+}
+
+// repoCandidates converts workspace repos into matcher candidates.
+func repoCandidates(repos []bitbucket.Repository) []matcher.Candidate {
+	candidates := make([]matcher.Candidate, len(repos))
+	for i, r := range repos {
+		c := matcher.Candidate{Slug: r.Slug, Name: r.Name, Description: r.Description}
+		if r.Project != nil {
+			c.ProjectKey = r.Project.Key
+			c.ProjectName = r.Project.Name
+		}
+		candidates[i] = c
+	}
+	return candidates
+}
+
+// patternWorkspaces returns the workspaces whose repos patterns must be
+// matched against, default first. Exclusions alone only list the default
+// workspace, matching what they do without any workspace prefix.
+func patternWorkspaces(defaultWorkspace string, patterns []string) []string {
+	var workspaces []string
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		if matcher.IsNegative(p) {
+			continue
+		}
+		if ws, _ := provider.SplitPattern(defaultWorkspace, p); !seen[ws] {
+			seen[ws] = true
+			workspaces = append(workspaces, ws)
+		}
+	}
+	if len(workspaces) == 0 {
+		return []string{defaultWorkspace}
+	}
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		return workspaces[i] == defaultWorkspace && workspaces[j] != defaultWorkspace
+	})
+	return workspaces
+}
+
+// matchWorkspaces matches each pattern against the candidates of the
+// workspace it names, exclusions included. Slugs and patterns outside the
+// default workspace are reported qualified as "workspace/repo".
+func matchWorkspaces(defaultWorkspace string, candidates map[string][]matcher.Candidate, patterns []string) matcher.MatchResult {
+	var (
+		order    []string
+		scoped   = make(map[string][]string)
+		original = make(map[string]map[string]string) // workspace → scoped pattern → entry
+	)
+	for _, p := range patterns {
+		ws, scopedPattern := provider.SplitPattern(defaultWorkspace, p)
+		if _, ok := scoped[ws]; !ok {
+			order = append(order, ws)
+			original[ws] = make(map[string]string)
+		}
+		scoped[ws] = append(scoped[ws], scopedPattern)
+		original[ws][scopedPattern] = strings.TrimSpace(p)
+	}
+
+	var out matcher.MatchResult
+	for _, ws := range order {
+		cands, ok := candidates[ws]
+		if !ok {
+			// Only exclusions name this workspace, and nothing from it was selected
+			for _, p := range scoped[ws] {
+				out.Unmatched = append(out.Unmatched, original[ws][p])
+			}
+			continue
+		}
+		r := matcher.MatchRepos(cands, scoped[ws])
+		for _, s := range r.Matched {
+			out.Matched = append(out.Matched, provider.QualifyRepo(defaultWorkspace, ws, s))
+		}
+		for _, p := range r.Unmatched {
+			out.Unmatched = append(out.Unmatched, original[ws][p])
+		}
+		for _, pm := range r.Patterns {
+			qualified := matcher.PatternMatch{Pattern: original[ws][pm.Pattern]}
+			for _, s := range pm.Slugs {
+				qualified.Slugs = append(qualified.Slugs, provider.QualifyRepo(defaultWorkspace, ws, s))
+			}
+			out.Patterns = append(out.Patterns, qualified)
+		}
+	}
+	return out
+}
+
+// resolveWithFuzzyMatch fetches the repos of each workspace the patterns
+// name and fuzzy-matches the patterns against them.
+func resolveWithFuzzyMatch(cfg *config.Config, client provider.Provider, reposFlag string) ([]string, error) {
+	patterns := strings.Split(reposFlag, ",")
+	if err := matcher.Validate(patterns); err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string][]matcher.Candidate)
+	for _, ws := range patternWorkspaces(cfg.Workspace, patterns) {
+		fmt.Printf("Fetching repos from workspace %q...\n", ws)
+		repos, err := listWorkspaceRepositories(cfg, client, ws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos in workspace %q: %w", ws, err)
+		}
+		candidates[ws] = repoCandidates(repos)
+	}
 
-	result := matcher.Match(slugs, patterns)
+	result := matchWorkspaces(cfg.Workspace, candidates, patterns)
 
 	warn := color.New(color.FgYellow)
 	bold := color.New(color.Bold)
@@ -97,12 +554,78 @@ func resolveWithFuzzyMatch(cfg *config.Config, client *bitbucket.Client, reposFl
 		warn.Printf("Warning: no repos matched pattern %q\n", p)
 	}
 
-	if len(result.Matched) > 0 {
+	matched := result.Matched
+	if threshold := cfg.Defaults.AmbiguityThreshold; threshold >= 0 && canPrompt() {
+		var err error
+		matched, err = narrowAmbiguous(result, threshold)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(matched) > 0 {
 		bold.Println("Matched repos:")
-		for _, s := range result.Matched {
+		for _, s := range matched {
 			fmt.Printf("  - %s\n", s)
 		}
 	}
 
-	return result.Matched, nil
+	return matched, nil
+}
+
+// narrowAmbiguous prompts the user to confirm or narrow any pattern that matched more than
+// threshold repos. Candidates are listed best match first, all preselected.
+func narrowAmbiguous(result matcher.MatchResult, threshold int) ([]string, error) {
+	dropped := make(map[string]bool)
+	kept := make(map[string]bool)
+
+	for _, pm := range result.Patterns {
+		if len(pm.Slugs) <= threshold {
+			for _, s := range pm.Slugs {
+				kept[s] = true
+			}
+			continue
+		}
+
+		options := make([]huh.Option[string], 0, len(pm.Slugs))
+		for _, s := range pm.Slugs {
+			options = append(options, huh.NewOption(s, s).Selected(true))
+		}
+
+		var selected []string
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewMultiSelect[string]().
+					Title(fmt.Sprintf("Pattern %q matched %d repos — confirm or narrow", pm.Pattern, len(pm.Slugs))).
+					Description("Best matches first. Deselect repos to leave them out.").
+					Options(options...).
+					Filterable(true).
+					Value(&selected),
+			),
+		)
+
+		if err := form.Run(); err != nil {
+			return nil, fmt.Errorf("selection cancelled")
+		}
+
+		chosen := make(map[string]bool, len(selected))
+		for _, s := range selected {
+			chosen[s] = true
+			kept[s] = true
+		}
+		for _, s := range pm.Slugs {
+			if !chosen[s] {
+				dropped[s] = true
+			}
+		}
+	}
+
+	// A repo dropped from one ambiguous pattern stays if another pattern kept it
+	narrowed := make([]string, 0, len(result.Matched))
+	for _, s := range result.Matched {
+		if kept[s] || !dropped[s] {
+			narrowed = append(narrowed, s)
+		}
+	}
+	return narrowed, nil
 }
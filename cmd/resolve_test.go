@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/matcher"
+)
+
+func TestPatternWorkspaces(t *testing.T) {
+	got := patternWorkspaces("acme", []string{"ops/infra", "api", "!ops/legacy", "data/etl"})
+	if want := []string{"acme", "ops", "data"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("patternWorkspaces = %v, want %v", got, want)
+	}
+	if got := patternWorkspaces("acme", []string{"!legacy"}); !reflect.DeepEqual(got, []string{"acme"}) {
+		t.Errorf("exclusions only = %v, want [acme]", got)
+	}
+}
+
+func TestMatchWorkspaces(t *testing.T) {
+	candidates := map[string][]matcher.Candidate{
+		"acme": {{Slug: "api"}, {Slug: "web"}},
+		"ops":  {{Slug: "api-gateway"}, {Slug: "infra"}, {Slug: "infra-legacy"}},
+	}
+
+	got := matchWorkspaces("acme", candidates, []string{"api", "ops/infra*", "!ops/*legacy", "ops/missing"})
+
+	if want := []string{"api", "ops/infra"}; !reflect.DeepEqual(got.Matched, want) {
+		t.Errorf("Matched = %v, want %v", got.Matched, want)
+	}
+	if want := []string{"ops/missing"}; !reflect.DeepEqual(got.Unmatched, want) {
+		t.Errorf("Unmatched = %v, want %v", got.Unmatched, want)
+	}
+	if len(got.Patterns) != 2 || got.Patterns[1].Pattern != "ops/infra*" || !reflect.DeepEqual(got.Patterns[1].Slugs, []string{"ops/infra"}) {
+		t.Errorf("Patterns = %+v", got.Patterns)
+	}
+}
+
+func TestMatchWorkspaces_ExclusionForUnlistedWorkspace(t *testing.T) {
+	candidates := map[string][]matcher.Candidate{"acme": {{Slug: "api"}}}
+
+	got := matchWorkspaces("acme", candidates, []string{"api", "!ops/legacy"})
+
+	if !reflect.DeepEqual(got.Matched, []string{"api"}) || !reflect.DeepEqual(got.Unmatched, []string{"!ops/legacy"}) {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestReadRepoList(t *testing.T) {
+	input := `api
+"web"
+
+# legacy services
+worker   # keeps running
+  ops/infra
+`
+	got, err := readRepoList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readRepoList error: %v", err)
+	}
+	if want := []string{"api", "web", "worker", "ops/infra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readRepoList = %v, want %v", got, want)
+	}
+}
+
+func TestResolveTargetRepos_Stdin(t *testing.T) {
+	defer func(r io.Reader) { repoInput, stdinRepos = r, false }(repoInput)
+	repoInput = strings.NewReader("api\nweb\n")
+
+	got, err := resolveTargetRepos("-", "", false, &config.Config{Workspace: "acme"}, nil)
+	if err != nil {
+		t.Fatalf("resolveTargetRepos error: %v", err)
+	}
+	if want := []string{"api", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+	if !stdinRepos {
+		t.Error("stdinRepos not set after reading stdin")
+	}
+
+	repoInput = strings.NewReader("\n# nothing\n")
+	if _, err := resolveTargetRepos("-", "", false, &config.Config{Workspace: "acme"}, nil); err == nil {
+		t.Error("expected an error for an empty list")
+	}
+}
+
+func TestResolveTargetRepos_ReposFile(t *testing.T) {
+	defer func() { flagReposFile = "" }()
+	path := filepath.Join(t.TempDir(), "targets.txt")
+	os.WriteFile(path, []byte("# payments team\napi\nops/ledger\n"), 0644)
+	flagReposFile = path
+	cfg := &config.Config{Workspace: "acme"}
+
+	got, err := resolveTargetRepos("", "", false, cfg, nil)
+	if err != nil {
+		t.Fatalf("resolveTargetRepos error: %v", err)
+	}
+	if want := []string{"api", "ops/ledger"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+
+	if _, err := resolveTargetRepos("web", "", false, cfg, nil); err == nil {
+		t.Error("expected an error when combined with --repos")
+	}
+	flagReposFile = filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := resolveTargetRepos("", "", false, cfg, nil); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestReadOnlyWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", dir},
+		{"-C", dir, "remote", "add", "origin", "git@bitbucket.org:acme/api.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v: %s", err, out)
+		}
+	}
+	t.Chdir(dir)
+
+	cfg := &config.Config{}
+	ws, err := readOnlyWorkspace(cfg)
+	if err != nil || ws != "acme" || cfg.Workspace != "acme" {
+		t.Errorf("readOnlyWorkspace = %q, %v (cfg %q), want the origin's workspace", ws, err, cfg.Workspace)
+	}
+
+	if ws, _ := readOnlyWorkspace(&config.Config{Workspace: "configured"}); ws != "configured" {
+		t.Errorf("readOnlyWorkspace = %q, want the configured workspace", ws)
+	}
+	if _, err := readOnlyWorkspace(&config.Config{Provider: "github"}); err == nil {
+		t.Error("expected an error for a GitHub config without a workspace")
+	}
+
+	t.Chdir(t.TempDir())
+	if _, err := readOnlyWorkspace(&config.Config{}); err == nil {
+		t.Error("expected an error outside a checkout")
+	}
+}
+
+func TestSaveAsGroupChecks(t *testing.T) {
+	cfg := &config.Config{Groups: map[string][]string{"backend": {"api", "worker"}}}
+
+	if !groupExists(cfg, " Backend ") || groupExists(cfg, "web") {
+		t.Error("groupExists should match existing names case-insensitively only")
+	}
+	if got := groupWithRepos(cfg, []string{"worker", "api"}); got != "backend" {
+		t.Errorf("groupWithRepos = %q, want backend", got)
+	}
+	if got := groupWithRepos(cfg, []string{"api"}); got != "" {
+		t.Errorf("groupWithRepos = %q, want none for a subset", got)
+	}
+	if !sameRepos([]string{"api", "web"}, map[string]bool{"web": true, "api": true}) {
+		t.Error("sameRepos should ignore order")
+	}
+	if sameRepos([]string{"api"}, map[string]bool{"api": true, "web": true}) {
+		t.Error("sameRepos should notice a dropped repo")
+	}
+}
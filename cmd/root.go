@@ -3,13 +3,34 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/chinhstringee/buck/internal/httpx"
+	"github.com/chinhstringee/buck/internal/render"
+	"github.com/chinhstringee/buck/internal/tracing"
 )
 
 var (
-	cfgFile string
+	cfgFile    string
+	verbose    bool
+	flagOutput string
+
+	flagTimeout      time.Duration
+	flagTotalTimeout time.Duration
+	flagStats        bool
+	flagRecord       string
+	flagReplay       string
+
+	// runStats collects the API requests of the run when --stats is set.
+	runStats  *httpx.Stats
+	startedAt time.Time
+
+	// endTrace ends the run's span once the command returned.
+	endTrace = func(error) {}
 
 	// Version is set via ldflags at build time.
 	Version = "dev"
@@ -20,11 +41,74 @@ var rootCmd = &cobra.Command{
 	Short:   "Create git branches across multiple Bitbucket repos",
 	Long:    "A CLI tool to create branches across multiple Bitbucket Cloud repositories simultaneously.",
 	Version: Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if flagRecord != "" && flagReplay != "" {
+			return fmt.Errorf("--record cannot be combined with --replay")
+		}
+		if flagStats {
+			runStats, startedAt = httpx.NewStats(), time.Now()
+		}
+		if end, err := tracing.StartRun(cmd.CommandPath()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			endTrace = end
+		}
+		mode, err := render.ParseMode(flagOutput)
+		if err != nil {
+			return err
+		}
+		if porcelain := cmd.Flags().Lookup("porcelain"); porcelain != nil && porcelain.Changed {
+			if cmd.Flags().Changed("output") {
+				return fmt.Errorf("--porcelain cannot be combined with --output")
+			}
+			if mode, err = render.ParsePorcelain(porcelain.Value.String()); err != nil {
+				return err
+			}
+		}
+		render.Output = mode
+		if mode != render.Table {
+			// Commands print progress with fmt.Printf; send it to stderr so
+			// stdout carries only the report.
+			render.Stdout = os.Stdout
+			os.Stdout = os.Stderr
+			color.Output = color.Error
+		}
+		if yes := cmd.Flags().Lookup("yes"); yes != nil {
+			assumeYes = yes.Value.String() == "true"
+		}
+		warnInlineSecrets(cmd)
+		if ci := render.DetectCI(os.Getenv); ci != render.NoCI && os.Getenv("BUCK_CI_ANNOTATIONS") != "off" {
+			render.Annotations = &render.Annotator{
+				CI:        ci,
+				Title:     cmd.CommandPath(),
+				Out:       os.Stdout,
+				ReportDir: filepath.Join(os.Getenv("BITBUCKET_CLONE_DIR"), "test-results"),
+			}
+		}
+		return nil
+	},
 }
 
 // Execute runs the root command.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	args, err := expandAlias(os.Args[1:], configAliases(os.Args[1:]))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if path, configFile, pluginArgs, ok := findPlugin(args); ok {
+		os.Exit(runPlugin(path, configFile, pluginArgs))
+	}
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	endTrace(err)
+	if runStats != nil {
+		fmt.Fprintln(os.Stderr)
+		runStats.Write(os.Stderr, time.Since(startedAt))
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -33,22 +117,73 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: .buck.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "table", "result format: table, json or quiet (only failures)")
+	_ = rootCmd.RegisterFlagCompletionFunc("output", completeStaticValues(render.Modes))
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "log every API request with its request IDs to stderr")
+	rootCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 0, "timeout per API request, e.g. 90s (overrides http.timeout, default 30s)")
+	rootCmd.PersistentFlags().DurationVar(&flagTotalTimeout, "total-timeout", 0, "time limit for all API requests of the command, e.g. 10m (overrides http.total_timeout)")
+	rootCmd.PersistentFlags().StringVar(&flagRecord, "record", "", "write every API request and response of the run to a cassette file")
+	rootCmd.PersistentFlags().StringVar(&flagReplay, "replay", "", "answer API requests from a cassette file recorded with --record instead of the network")
+	rootCmd.PersistentFlags().BoolVar(&flagStats, "stats", false, "print API call counts, bytes transferred, request latency and run time to stderr at the end")
 }
 
+// configFiles lists the config files read by initConfig, in the order they
+// were merged.
+var configFiles []string
+
+// initConfig reads the --config file, or else ~/.buck.yaml and then
+// ./.buck.yaml, whose settings override the home file's. Credentials shared
+// by every project can so live in the home directory and groups in each
+// project's own file.
 func initConfig() {
+	configFiles = nil
 	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
+		configFiles = []string{cfgFile}
 	} else {
-		viper.SetConfigName(".buck")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
-
-		home, err := os.UserHomeDir()
-		if err == nil {
-			viper.AddConfigPath(home)
+		if home, err := os.UserHomeDir(); err == nil {
+			configFiles = appendConfigFile(configFiles, filepath.Join(home, ".buck.yaml"))
 		}
+		configFiles = appendConfigFile(configFiles, ".buck.yaml")
 	}
 
 	// Silently ignore missing config — login/config init don't need it
-	viper.ReadInConfig()
+	for i, path := range configFiles {
+		viper.SetConfigFile(path)
+		if i == 0 {
+			viper.ReadInConfig()
+		} else {
+			viper.MergeInConfig()
+		}
+	}
+}
+
+// appendConfigFile adds path to files when it exists and is not already
+// listed, e.g. when the working directory is the home directory.
+func appendConfigFile(files []string, path string) []string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return files
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return files
+	}
+	for _, f := range files {
+		if f == abs {
+			return files
+		}
+	}
+	return append(files, abs)
+}
+
+// configFilePath returns the config file in use (./.buck.yaml when both it
+// and ~/.buck.yaml exist), or ~/.buck.yaml when none was found.
+func configFilePath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".buck.yaml"), nil
 }
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/config"
+)
+
+var (
+	secretsMigrateFlagDryRun bool
+	secretsMigrateFlagYes    bool
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Keep credentials out of the config file",
+}
+
+var secretsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move plaintext credentials from the config file into the OS keyring",
+	Long: `Store the tokens, passwords and client secrets written out in the config
+file in the OS keyring (Keychain on macOS, Secret Service on Linux, Credential
+Manager on Windows) and replace them with references such as
+${keyring:acme/api_token.token}. Values taken from environment variables are
+left alone.`,
+	Example: `  buck secrets migrate --dry-run
+  buck secrets migrate --config ~/work/.buck.yaml --yes`,
+	Args: cobra.NoArgs,
+	RunE: runSecretsMigrate,
+}
+
+func init() {
+	secretsMigrateCmd.Flags().BoolVar(&secretsMigrateFlagDryRun, "dry-run", false, "list the credentials that would move without storing them")
+	secretsMigrateCmd.Flags().BoolVarP(&secretsMigrateFlagYes, "yes", "y", false, "move the credentials without asking")
+	secretsCmd.AddCommand(secretsMigrateCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+func runSecretsMigrate(cmd *cobra.Command, args []string) error {
+	if len(configFiles) == 0 {
+		return fmt.Errorf("no config file found; pass --config or run from the directory with .buck.yaml")
+	}
+	found := inlineSecrets()
+	if len(found) == 0 {
+		fmt.Printf("%s has no plaintext credentials.\n", strings.Join(configFiles, " and "))
+		return nil
+	}
+
+	for _, f := range found {
+		fmt.Printf("Plaintext credentials in %s:\n", f.path)
+		for _, key := range f.keys {
+			fmt.Printf("  • %s\n", key)
+		}
+	}
+	fmt.Println()
+	if secretsMigrateFlagDryRun {
+		fmt.Println("Dry run: nothing stored.")
+		return nil
+	}
+	if !secretsMigrateFlagYes && !confirmAction("Move them to the OS keyring?") {
+		return fmt.Errorf("migration cancelled")
+	}
+
+	for _, f := range found {
+		before, err := os.ReadFile(f.path)
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+		after, moved, err := config.MigrateSecrets(before)
+		if err != nil {
+			return err
+		}
+		if len(moved) == 0 {
+			continue
+		}
+		// No .bak copy: it would keep the secrets on disk
+		if err := config.WriteFile(f.path, after); err != nil {
+			return err
+		}
+		fmt.Printf("Moved %s to the keyring; %s now refers to them.\n", strings.Join(moved, ", "), f.path)
+	}
+	return nil
+}
+
+// fileSecrets names the plaintext credentials in one config file.
+type fileSecrets struct {
+	path string
+	keys []string
+}
+
+// inlineSecrets returns the plaintext credentials of each config file read.
+// Files that cannot be read or parsed are skipped; loading reports them.
+func inlineSecrets() []fileSecrets {
+	var found []fileSecrets
+	for _, path := range configFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if keys, err := config.InlineSecrets(data); err == nil && len(keys) > 0 {
+			found = append(found, fileSecrets{path: path, keys: keys})
+		}
+	}
+	return found
+}
+
+// warnInlineSecrets points out credentials written out in the config files,
+// unless the command is the one that fixes it.
+func warnInlineSecrets(cmd *cobra.Command) {
+	if cmd == secretsCmd || cmd.Parent() == secretsCmd || strings.HasPrefix(cmd.Name(), cobra.ShellCompRequestCmd) {
+		return
+	}
+	for _, f := range inlineSecrets() {
+		fmt.Fprintf(os.Stderr, "Warning: %s has plaintext credentials (%s); run 'buck secrets migrate' to move them to the OS keyring.\n",
+			f.path, strings.Join(f.keys, ", "))
+	}
+}
@@ -3,29 +3,53 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/charmbracelet/huh"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/auth"
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/gitutil"
 	"go.yaml.in/yaml/v3"
 )
 
+var setupFlagGlobal bool
+
 var setupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Configure buck with your Bitbucket credentials",
-	Long:  "Interactive setup that prompts for API token credentials and writes .buck.yaml.",
-	RunE:  runSetup,
+	Long: `Interactive setup that writes .buck.yaml in the current directory. It asks
+for an auth method and credentials, checks them against the API, offers the
+workspaces you belong to, and can create your first repo group.
+
+With --global, only the credentials and workspace are written, to
+~/.buck.yaml, where every project finds them; groups and defaults stay in each
+project's own .buck.yaml, whose settings override the home file's.`,
+	Example: `  buck setup
+  buck setup --global`,
+	Args: cobra.NoArgs,
+	RunE: runSetup,
 }
 
 func init() {
+	setupCmd.Flags().BoolVar(&setupFlagGlobal, "global", false, "write the credentials and workspace to ~/.buck.yaml")
 	rootCmd.AddCommand(setupCmd)
 }
 
 // setupConfig represents the YAML structure written by the setup command.
 type setupConfig struct {
-	Workspace string        `yaml:"workspace"`
-	ApiToken  setupApiToken `yaml:"api_token"`
-	Defaults  setupDefaults `yaml:"defaults"`
+	Workspace   string            `yaml:"workspace"`
+	Auth        *setupAuth        `yaml:"auth,omitempty"`
+	ApiToken    *setupApiToken    `yaml:"api_token,omitempty"`
+	AppPassword *setupAppPassword `yaml:"app_password,omitempty"`
+	OAuth       *setupOAuth       `yaml:"oauth,omitempty"`
+	Defaults    setupDefaults     `yaml:"defaults,omitempty"`
+}
+
+type setupAuth struct {
+	Method string `yaml:"method"`
 }
 
 type setupApiToken struct {
@@ -33,61 +57,39 @@ type setupApiToken struct {
 	Token string `yaml:"token"`
 }
 
-type setupDefaults struct {
-	SourceBranch string `yaml:"source_branch"`
+type setupAppPassword struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
 }
 
-func runSetup(cmd *cobra.Command, args []string) error {
-	var (
-		workspace    string
-		email        string
-		token        string
-		sourceBranch string
-	)
-
-	sourceBranch = "master"
-
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("Workspace slug").
-				Description("Your Bitbucket workspace identifier").
-				Value(&workspace).
-				Validate(requiredValidator("workspace")),
-			huh.NewInput().
-				Title("Bitbucket email").
-				Description("Email associated with your API token").
-				Value(&email).
-				Validate(requiredValidator("email")),
-			huh.NewInput().
-				Title("API token").
-				Description("Create at: Bitbucket > Personal settings > App passwords").
-				EchoMode(huh.EchoModePassword).
-				Value(&token).
-				Validate(requiredValidator("API token")),
-			huh.NewInput().
-				Title("Default source branch").
-				Value(&sourceBranch),
-		),
-	)
+type setupOAuth struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+}
 
-	if err := form.Run(); err != nil {
-		return fmt.Errorf("setup cancelled")
-	}
+type setupDefaults struct {
+	SourceBranch string `yaml:"source_branch,omitempty"`
+}
 
-	home, err := os.UserHomeDir()
+func runSetup(cmd *cobra.Command, args []string) error {
+	configPath, err := setupConfigPath()
 	if err != nil {
-		return fmt.Errorf("failed to determine home directory: %w", err)
+		return err
 	}
-	configPath := home + "/.buck.yaml"
 
-	// Check if config already exists
+	// Check if config already exists before asking for anything
 	if _, err := os.Stat(configPath); err == nil {
+		title := configPath + " already exists. Replace its credentials and workspace?"
+		description := "Setup writes this project's .buck.yaml; its groups, hooks and other settings are kept. Use --global for ~/.buck.yaml."
+		if setupFlagGlobal {
+			description = "Groups and other settings in the file are kept."
+		}
 		var overwrite bool
 		confirm := huh.NewForm(
 			huh.NewGroup(
 				huh.NewConfirm().
-					Title(configPath + " already exists. Overwrite?").
+					Title(title).
+					Description(description).
 					Value(&overwrite),
 			),
 		)
@@ -100,43 +102,354 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Default to "master" if user cleared the field
-	if sourceBranch == "" {
-		sourceBranch = "master"
+	// Before asking for credentials, make sure git will not pick them up
+	if err := guardGitignore(configPath); err != nil {
+		return err
+	}
+
+	cfg, err := promptCredentials()
+	if err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	// A client is only kept when the credentials work
+	client, err := setupClient(cfg)
+	if err == nil {
+		var user *bitbucket.User
+		if user, err = client.GetCurrentUser(); err == nil {
+			green.Printf("✓ Authenticated as %s\n", user.DisplayName)
+		}
+	}
+	if err != nil {
+		client = nil
+		red.Printf("✗ Could not authenticate: %v\n", err)
+		save := false
+		confirm := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("Save the configuration anyway?").
+					Description("Fix the credentials in " + configPath + " later").
+					Value(&save),
+			),
+		)
+		if err := confirm.Run(); err != nil || !save {
+			return fmt.Errorf("setup cancelled")
+		}
+	}
+
+	if cfg.Workspace, err = promptWorkspace(client); err != nil {
+		return err
+	}
+
+	// Defaults are project settings: a global setup leaves them out
+	var sourceBranch string
+	if !setupFlagGlobal {
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("Default source branch").
+					Description("Leave empty to use each repo's development branch").
+					Value(&sourceBranch),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return fmt.Errorf("setup cancelled")
+		}
 	}
 
-	cfg := setupConfig{
-		Workspace: workspace,
-		ApiToken: setupApiToken{
-			Email: email,
-			Token: token,
-		},
-		Defaults: setupDefaults{
-			SourceBranch: sourceBranch,
-		},
+	out := setupConfig{
+		Workspace: cfg.Workspace,
+		Defaults:  setupDefaults{SourceBranch: sourceBranch},
+	}
+	switch cfg.AuthMethod() {
+	case "oauth":
+		out.Auth = &setupAuth{Method: "oauth"}
+		out.OAuth = &setupOAuth{ClientID: cfg.OAuth.ClientID, ClientSecret: cfg.OAuth.ClientSecret}
+	case "app_password":
+		out.Auth = &setupAuth{Method: "app_password"}
+		out.AppPassword = &setupAppPassword{Username: cfg.AppPassword.Username, Password: cfg.AppPassword.Password}
+	default:
+		out.ApiToken = &setupApiToken{Email: cfg.ApiToken.Email, Token: cfg.ApiToken.Token}
+		// Replaces the method of an earlier setup kept in the file
+		out.Auth = &setupAuth{Method: "api_token"}
 	}
 
-	content, err := yaml.Marshal(&cfg)
+	content, err := yaml.Marshal(&out)
 	if err != nil {
 		return fmt.Errorf("failed to generate config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, content, 0600); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	// Keep the groups, hooks and other settings of an existing config
+	if err := config.MergeFile(configPath, content); err != nil {
+		return err
 	}
 
 	bold := color.New(color.Bold)
-	green := color.New(color.FgGreen, color.Bold)
+	color.New(color.FgGreen, color.Bold).Println("✓ Configuration saved to " + configPath)
+
+	if client != nil && !setupFlagGlobal {
+		offerFirstGroup(cfg, client, configPath)
+	}
 
-	green.Println("✓ Configuration saved to " + configPath)
 	fmt.Println()
 	bold.Println("Next steps:")
+	if setupFlagGlobal {
+		fmt.Println("  add groups and defaults to a .buck.yaml in each project")
+	}
 	fmt.Println("  buck list              — list workspace repos")
 	fmt.Println("  buck create <branch>   — create a branch across repos")
+	fmt.Println("  buck secrets migrate   — move the credentials to the OS keyring")
+
+	return nil
+}
+
+// setupConfigPath returns the file setup writes: ~/.buck.yaml with --global,
+// else .buck.yaml in the current directory.
+func setupConfigPath() (string, error) {
+	if setupFlagGlobal {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		return filepath.Join(home, ".buck.yaml"), nil
+	}
+	path, err := filepath.Abs(".buck.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine config path: %w", err)
+	}
+	return path, nil
+}
+
+// guardGitignore keeps the credentials about to be written to configPath out
+// of git: when the file would land in a work tree without being ignored, it
+// offers to add it to the repo's .gitignore and refuses otherwise.
+func guardGitignore(configPath string) error {
+	root, tracked := gitutil.UnignoredRepo(configPath)
+	if root == "" {
+		return nil
+	}
+	if tracked {
+		return fmt.Errorf("%s is tracked by the git repository at %s; writing credentials to it could commit them.\nRun 'git rm --cached' on it and add it to .gitignore first", configPath, root)
+	}
 
+	add := true
+	confirm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(configPath + " is inside the git repository " + root).
+				Description("It will contain your credentials. Add it to .gitignore?").
+				Value(&add),
+		),
+	)
+	if err := confirm.Run(); err != nil || !add {
+		hint := ""
+		if !setupFlagGlobal {
+			hint = "\nRun 'buck setup --global' to keep the credentials in ~/.buck.yaml instead"
+		}
+		return fmt.Errorf("setup cancelled: %s would not be ignored by git, so the credentials could be committed%s", configPath, hint)
+	}
+	if err := gitutil.AddToGitignore(root, configPath); err != nil {
+		return err
+	}
+	color.New(color.FgGreen).Printf("✓ Added %s to %s\n", filepath.Base(configPath), filepath.Join(root, ".gitignore"))
 	return nil
 }
 
+// promptCredentials asks for the auth method and its credentials.
+func promptCredentials() (*config.Config, error) {
+	// Carry over http settings (proxy, CA bundle) from any loaded config
+	cfg := &config.Config{}
+	if loaded, err := config.Load(); err == nil {
+		cfg.HTTP = loaded.HTTP
+	}
+
+	method := "api_token"
+	choose := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Authentication method").
+				Options(
+					huh.NewOption("API token (recommended, no login needed)", "api_token"),
+					huh.NewOption("OAuth 2.0 (browser login with an OAuth consumer)", "oauth"),
+					huh.NewOption("App password (legacy)", "app_password"),
+				).
+				Value(&method),
+		),
+	)
+	if err := choose.Run(); err != nil {
+		return nil, fmt.Errorf("setup cancelled")
+	}
+	cfg.Auth.Method = method
+
+	var form *huh.Form
+	switch method {
+	case "oauth":
+		form = huh.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("OAuth client ID").
+					Description("Key of an OAuth consumer: Workspace settings > OAuth consumers").
+					Value(&cfg.OAuth.ClientID).
+					Validate(requiredValidator("client ID")),
+				huh.NewInput().
+					Title("OAuth client secret").
+					EchoMode(huh.EchoModePassword).
+					Value(&cfg.OAuth.ClientSecret).
+					Validate(requiredValidator("client secret")),
+			),
+		)
+	case "app_password":
+		form = huh.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("Bitbucket username").
+					Description("Your username, not your email: Personal settings > Account settings").
+					Value(&cfg.AppPassword.Username).
+					Validate(requiredValidator("username")),
+				huh.NewInput().
+					Title("App password").
+					Description("Needs repository read and write permissions").
+					EchoMode(huh.EchoModePassword).
+					Value(&cfg.AppPassword.Password).
+					Validate(requiredValidator("app password")),
+			),
+		)
+	default:
+		form = huh.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("Bitbucket email").
+					Description("Email associated with your API token").
+					Value(&cfg.ApiToken.Email).
+					Validate(requiredValidator("email")),
+				huh.NewInput().
+					Title("API token").
+					Description("Create at: Bitbucket > Personal settings > API tokens").
+					EchoMode(huh.EchoModePassword).
+					Value(&cfg.ApiToken.Token).
+					Validate(requiredValidator("API token")),
+			),
+		)
+	}
+	if err := form.Run(); err != nil {
+		return nil, fmt.Errorf("setup cancelled")
+	}
+	return cfg, nil
+}
+
+// setupClient builds a Bitbucket client for the entered credentials,
+// logging in first for OAuth.
+func setupClient(cfg *config.Config) (*bitbucket.Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	auth.HTTPClient = httpClient
+
+	if cfg.AuthMethod() == "oauth" {
+		if err := auth.Login(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret); err != nil {
+			return nil, err
+		}
+	}
+
+	authApplier, err := buildAuthApplier(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := bitbucket.NewClientWithHTTPClient(httpClient, authApplier)
+	client.SetMaxPages(cfg.HTTP.MaxPages)
+	return client, nil
+}
+
+// promptWorkspace offers the workspaces the user belongs to, or asks for a
+// slug when they cannot be listed (client is nil when credentials failed).
+func promptWorkspace(client *bitbucket.Client) (string, error) {
+	var workspaces []bitbucket.Workspace
+	if client != nil {
+		var err error
+		if workspaces, err = client.ListWorkspaces(); err != nil {
+			color.New(color.FgYellow).Printf("Warning: could not list your workspaces: %v\n", err)
+		}
+	}
+
+	var workspace string
+	var field huh.Field
+	switch len(workspaces) {
+	case 0:
+		field = huh.NewInput().
+			Title("Workspace slug").
+			Description("Your Bitbucket workspace identifier").
+			Value(&workspace).
+			Validate(requiredValidator("workspace"))
+	case 1:
+		fmt.Printf("Using workspace %q (%s)\n", workspaces[0].Slug, workspaces[0].Name)
+		return workspaces[0].Slug, nil
+	default:
+		options := make([]huh.Option[string], len(workspaces))
+		for i, w := range workspaces {
+			options[i] = huh.NewOption(fmt.Sprintf("%s (%s)", w.Slug, w.Name), w.Slug)
+		}
+		field = huh.NewSelect[string]().
+			Title("Workspace").
+			Options(options...).
+			Value(&workspace)
+	}
+
+	if err := huh.NewForm(huh.NewGroup(field)).Run(); err != nil {
+		return "", fmt.Errorf("setup cancelled")
+	}
+	return workspace, nil
+}
+
+// offerFirstGroup optionally walks through picking repos for a first group
+// and saves it to the new config. Failures only warn: the config is written.
+func offerFirstGroup(cfg *config.Config, client *bitbucket.Client, configPath string) {
+	create := false
+	name := "backend"
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Create a repo group now?").
+				Description("Groups name sets of repos for --group, e.g. buck create feature/x -g backend").
+				Value(&create),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Group name").
+				Value(&name).
+				Validate(requiredValidator("group name")),
+		).WithHideFunc(func() bool { return !create }),
+	)
+	if err := form.Run(); err != nil || !create {
+		return
+	}
+
+	warn := color.New(color.FgYellow)
+	fmt.Printf("Fetching repos from workspace %q...\n", cfg.Workspace)
+	repos, err := listRepositories(cfg, client)
+	if err != nil {
+		warn.Printf("Warning: could not list repos: %v\n", err)
+		return
+	}
+	if len(repos) == 0 {
+		warn.Printf("Warning: no repositories found in workspace %q\n", cfg.Workspace)
+		return
+	}
+
+	selected, err := pickRepos(repos, nil)
+	if err != nil || len(selected) == 0 {
+		return
+	}
+	if err := config.SaveGroup(configPath, name, selected); err != nil {
+		warn.Printf("Warning: could not save group: %v\n", err)
+		return
+	}
+	color.New(color.FgGreen).Printf("✓ Saved group %q with %d repos\n", name, len(selected))
+}
+
 func requiredValidator(field string) func(string) error {
 	return func(s string) error {
 		if s == "" {
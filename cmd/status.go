@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"time"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/chinhstringee/buck/internal/bitbucket"
 	"github.com/chinhstringee/buck/internal/config"
 	"github.com/chinhstringee/buck/internal/dashboard"
 	"github.com/chinhstringee/buck/internal/gitutil"
@@ -17,20 +21,32 @@ var (
 	statusFlagInteractive bool
 	statusFlagMine        bool
 	statusFlagAuthor      string
+	statusFlagWatch       bool
+	statusFlagInterval    time.Duration
 )
 
 var statusCmd = &cobra.Command{
-	Use:   "status",
+	Use:   "status [branch-name]",
 	Short: "Show open PR status across repos",
-	RunE:  runStatus,
+	Long: `Show the open PRs across repos. With a branch name, show one row per repo
+instead: whether the branch exists, its PR and the PR's builds. --watch keeps
+that view refreshing in place until every PR is merged or you press Ctrl+C.`,
+	Example: `  buck status -g backend
+  buck status release/1.4 -g backend --watch --interval 1m`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatus,
 }
 
 func init() {
 	statusCmd.Flags().StringVarP(&statusFlagGroup, "group", "g", "", "repo group from config")
 	statusCmd.Flags().StringVarP(&statusFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	statusCmd.Flags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
 	statusCmd.Flags().BoolVarP(&statusFlagInteractive, "interactive", "i", false, "select repos interactively")
 	statusCmd.Flags().BoolVar(&statusFlagMine, "mine", false, "show only my PRs")
 	statusCmd.Flags().StringVar(&statusFlagAuthor, "author", "", "filter by author nickname")
+	statusCmd.Flags().BoolVar(&flagNoPrefix, "no-prefix", false, "use the branch name as given, without defaults.branch_prefix")
+	statusCmd.Flags().BoolVar(&statusFlagWatch, "watch", false, "with a branch name: refresh until every PR is merged")
+	statusCmd.Flags().DurationVar(&statusFlagInterval, "interval", 30*time.Second, "with --watch: time between refreshes")
 
 	_ = statusCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
 	_ = statusCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
@@ -39,11 +55,18 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	if statusFlagWatch && len(args) == 0 {
+		return fmt.Errorf("--watch needs a branch name, e.g. 'buck status release/1.4 --watch'")
+	}
+	if statusFlagWatch && statusFlagInterval < 5*time.Second {
+		return fmt.Errorf("--interval must be at least 5s")
+	}
+
 	var repos []string
 	var workspace string
 
 	// Auto-detect mode: no flags
-	autoDetect := statusFlagRepos == "" && statusFlagGroup == "" && !statusFlagInteractive
+	autoDetect := statusFlagRepos == "" && flagReposFile == "" && statusFlagGroup == "" && !statusFlagInteractive
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -63,19 +86,16 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("no workspace configured and not in a Bitbucket repo\n  Hint: use 'buck status --repos <repo>' or configure .buck.yaml")
 		}
 	} else {
-		if cfg.Workspace == "" {
-			return fmt.Errorf("workspace not configured in .buck.yaml")
+		if workspace, err = readOnlyWorkspace(cfg); err != nil {
+			return err
 		}
-		workspace = cfg.Workspace
 	}
 
-	authApplier, err := buildAuthApplier(cfg)
+	client, err := buildProvider(cfg)
 	if err != nil {
 		return err
 	}
 
-	client := bitbucket.NewClient(authApplier)
-
 	// Resolve repos if not auto-detected from CWD
 	if len(repos) == 0 {
 		repos, err = resolveTargetRepos(statusFlagRepos, statusFlagGroup, statusFlagInteractive, cfg, client)
@@ -88,9 +108,19 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	bold := color.New(color.Bold)
-	bold.Printf("Fetching open PRs across %d repos...\n", len(repos))
-
 	fetcher := dashboard.NewFetcher(client)
+
+	if len(args) == 1 {
+		branchName := prefixBranch(cfg, args[0])
+		if statusFlagWatch {
+			return watchBranchStatus(fetcher, workspace, repos, branchName, statusFlagInterval)
+		}
+		bold.Printf("Checking branch %q across %d repos...\n", branchName, len(repos))
+		dashboard.PrintBranchStatus(fetcher.FetchBranchStatus(workspace, repos, branchName))
+		return nil
+	}
+
+	bold.Printf("Fetching open PRs across %d repos...\n", len(repos))
 	filters := dashboard.PRFilters{
 		Author: statusFlagAuthor,
 		Mine:   statusFlagMine,
@@ -101,3 +131,35 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// watchBranchStatus redraws the branch status every interval until all PRs
+// are merged or the user interrupts. Output that is not a terminal gets each
+// refresh appended instead of redrawn.
+func watchBranchStatus(fetcher *dashboard.Fetcher, workspace string, repos []string, branchName string, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	redraw := term.IsTerminal(os.Stdout.Fd())
+	bold := color.New(color.Bold)
+	for {
+		rows := fetcher.FetchBranchStatus(workspace, repos, branchName)
+		if redraw {
+			fmt.Print("\033[H\033[2J")
+		}
+		bold.Printf("Branch %q across %d repos at %s (every %s, Ctrl+C to stop)\n",
+			branchName, len(repos), time.Now().Format("15:04:05"), interval)
+		dashboard.PrintBranchStatus(rows)
+
+		if dashboard.AllMerged(rows) {
+			color.New(color.FgGreen).Println("\n✓ All PRs merged.")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var (
+	varsFlagGroup       string
+	varsFlagRepos       string
+	varsFlagInteractive bool
+	varsFlagDryRun      bool
+	varsFlagYes         bool
+
+	varsSetFlagSecured bool
+)
+
+var varsCmd = &cobra.Command{
+	Use:   "vars",
+	Short: "Manage repository pipeline variables across repos",
+}
+
+var varsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pipeline variables across repos",
+	Args:  cobra.NoArgs,
+	RunE:  runVarsList,
+}
+
+var varsSetCmd = &cobra.Command{
+	Use:   "set <KEY=VALUE>...",
+	Short: "Create or update pipeline variables across repos",
+	Long: `Create or update repository pipeline variables across repos.
+A bare KEY takes its value from the environment variable of the same name,
+which keeps secrets out of shell history.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runVarsSet,
+}
+
+var varsDeleteCmd = &cobra.Command{
+	Use:   "delete <KEY>...",
+	Short: "Delete pipeline variables across repos",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runVarsDelete,
+}
+
+func init() {
+	// Shared flags available to all vars subcommands
+	varsCmd.PersistentFlags().StringVarP(&varsFlagGroup, "group", "g", "", "repo group from config")
+	varsCmd.PersistentFlags().StringVarP(&varsFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	varsCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	varsCmd.PersistentFlags().BoolVarP(&varsFlagInteractive, "interactive", "i", false, "select repos interactively")
+	varsCmd.PersistentFlags().BoolVar(&varsFlagDryRun, "dry-run", false, "preview actions without executing")
+	varsCmd.PersistentFlags().BoolVarP(&varsFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+
+	varsSetCmd.Flags().BoolVar(&varsSetFlagSecured, "secured", false, "store values as secured (hidden) variables")
+
+	_ = varsCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = varsCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	varsCmd.AddCommand(varsListCmd, varsSetCmd, varsDeleteCmd)
+	rootCmd.AddCommand(varsCmd)
+}
+
+// newVarsContext resolves config, the variables capability and target repos.
+func newVarsContext(command string) (*repoContext, provider.PipelineVariableService, error) {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	service, err := requireCapability[provider.PipelineVariableService](ctx.cfg, ctx.client, command)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ctx.selectRepos(varsFlagRepos, varsFlagGroup, varsFlagInteractive); err != nil {
+		return nil, nil, err
+	}
+	return ctx, service, nil
+}
+
+func runVarsList(cmd *cobra.Command, args []string) error {
+	ctx, service, err := newVarsContext("vars list")
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+	red := color.New(color.FgRed).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	for _, r := range repoadmin.NewVariableManager(service).List(ctx.cfg.Workspace, ctx.repos) {
+		fmt.Println()
+		bold.Println(r.RepoSlug)
+		switch {
+		case r.Error != "":
+			fmt.Printf("  %s %s\n", red("✗"), r.Error)
+		case len(r.Variables) == 0:
+			fmt.Printf("  %s\n", dim("no variables"))
+		default:
+			for _, v := range r.Variables {
+				value := v.Value
+				if v.Secured {
+					value = dim("(secured)")
+				}
+				fmt.Printf("  %-30s %s\n", v.Key, value)
+			}
+		}
+	}
+	return nil
+}
+
+func runVarsSet(cmd *cobra.Command, args []string) error {
+	vars, err := repoadmin.ParseVariables(args, varsSetFlagSecured, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(vars))
+	for i, v := range vars {
+		keys[i] = v.Key
+	}
+
+	ctx, service, err := newVarsContext("vars set")
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if varsFlagDryRun {
+		bold.Printf("Dry run: would set %s in:\n", strings.Join(keys, ", "))
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun("set pipeline variables", ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), varsFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Setting %s across %d repos...\n", strings.Join(keys, ", "), len(ctx.repos))
+	repoadmin.PrintResults(repoadmin.NewVariableManager(service).Set(ctx.cfg.Workspace, ctx.repos, vars))
+	return nil
+}
+
+func runVarsDelete(cmd *cobra.Command, args []string) error {
+	ctx, service, err := newVarsContext("vars delete")
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if varsFlagDryRun {
+		bold.Printf("Dry run: would delete %s from:\n", strings.Join(args, ", "))
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun("delete pipeline variables", ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), varsFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Deleting %s across %d repos...\n", strings.Join(args, ", "), len(ctx.repos))
+	repoadmin.PrintResults(repoadmin.NewVariableManager(service).Delete(ctx.cfg.Workspace, ctx.repos, args))
+	return nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/render"
+	"github.com/chinhstringee/buck/internal/update"
+)
+
+// updateCheckTimeout keeps 'buck version' quick when GitHub is unreachable.
+const updateCheckTimeout = 5 * time.Second
+
+var versionFlagNoCheck bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build details, and check for a newer release",
+	Long: `Show the version of buck, the commit and Go version it was built with, and
+compare it with the latest release on GitHub. The check is skipped for
+development builds, with --no-check, or when update_check is false in .buck.yaml.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionFlagNoCheck, "no-check", false, "do not look up the latest release")
+	rootCmd.AddCommand(versionCmd)
+}
+
+// buildDetails describes the running binary.
+type buildDetails struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	BuiltAt   string `json:"built_at,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	Latest    string `json:"latest,omitempty"` // newest release, when checked
+}
+
+// readBuildDetails combines the ldflags version with the VCS stamps Go
+// embeds in every binary built from a checkout.
+func readBuildDetails() buildDetails {
+	d := buildDetails{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return d
+	}
+	// 'go install ...@v1.2.3' sets no ldflags but records the module version
+	if d.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		d.Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			d.Commit = s.Value
+			if len(d.Commit) > 12 {
+				d.Commit = d.Commit[:12]
+			}
+		case "vcs.time":
+			d.BuiltAt = s.Value
+		case "vcs.modified":
+			d.Modified = s.Value == "true"
+		}
+	}
+	return d
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	d := readBuildDetails()
+
+	var checkErr error
+	checked := !versionFlagNoCheck && update.IsRelease(d.Version)
+	if checked {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		checked = cfg.UpdateCheckEnabled()
+		if checked {
+			d.Latest, checkErr = latestRelease(cfg)
+		}
+	}
+
+	if render.Output == render.JSON {
+		return render.WriteJSON(d)
+	}
+
+	fmt.Fprintf(render.Stdout, "buck %s\n", d.Version)
+	if d.Commit != "" {
+		modified := ""
+		if d.Modified {
+			modified = " (modified)"
+		}
+		fmt.Fprintf(render.Stdout, "  commit:  %s%s\n", d.Commit, modified)
+	}
+	if d.BuiltAt != "" {
+		fmt.Fprintf(render.Stdout, "  built:   %s\n", d.BuiltAt)
+	}
+	fmt.Fprintf(render.Stdout, "  go:      %s %s\n", d.GoVersion, d.Platform)
+
+	switch {
+	case !checked:
+	case checkErr != nil:
+		fmt.Fprintf(os.Stderr, "\nCould not check for updates: %v\n", checkErr)
+	default:
+		switch update.Compare(d.Version, d.Latest) {
+		case update.Outdated:
+			color.New(color.FgYellow).Fprintf(os.Stderr, "\nWarning: buck %s is out (you have %s).\n", d.Latest, d.Version)
+			fmt.Fprintln(os.Stderr, "Upgrade with 'brew upgrade buck' or 'go install github.com/chinhstringee/buck@latest'.")
+		case update.PatchBehind:
+			fmt.Fprintf(os.Stderr, "\nbuck %s is available with bug fixes.\n", d.Latest)
+		default:
+			fmt.Fprintln(render.Stdout, "\nThis is the latest release.")
+		}
+	}
+	return nil
+}
+
+// latestRelease looks up the newest release with the http settings from config.
+func latestRelease(cfg *config.Config) (string, error) {
+	cfg.HTTP.Timeout = updateCheckTimeout
+	cfg.HTTP.Retry.MaxAttempts = 1
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return "", err
+	}
+	return update.Latest(client)
+}
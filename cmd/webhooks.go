@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/config"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/repoadmin"
+)
+
+var (
+	webhooksFlagGroup       string
+	webhooksFlagRepos       string
+	webhooksFlagInteractive bool
+	webhooksFlagDryRun      bool
+	webhooksFlagYes         bool
+
+	webhooksFlagURL         string
+	webhooksFlagEvents      string
+	webhooksFlagDescription string
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:     "webhooks",
+	Aliases: []string{"hooks"},
+	Short:   "Manage repository webhooks across repos",
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks across repos",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksList,
+}
+
+var webhooksCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a webhook across repos (updates one with the same URL)",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksCreate,
+}
+
+var webhooksDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the webhook with a URL across repos",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksDelete,
+}
+
+var webhooksSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Ensure the webhooks from config exist on every repo",
+	Long: `Ensure each webhook under 'webhooks' in .buck.yaml exists on every target repo.
+Webhooks are matched by URL; one with different events or description is updated.`,
+	Args: cobra.NoArgs,
+	RunE: runWebhooksSync,
+}
+
+func init() {
+	// Shared flags available to all webhooks subcommands
+	webhooksCmd.PersistentFlags().StringVarP(&webhooksFlagGroup, "group", "g", "", "repo group from config")
+	webhooksCmd.PersistentFlags().StringVarP(&webhooksFlagRepos, "repos", "r", "", "comma-separated repo slugs")
+	webhooksCmd.PersistentFlags().StringVar(&flagReposFile, "repos-file", "", "file listing repo slugs or patterns, one per line")
+	webhooksCmd.PersistentFlags().BoolVarP(&webhooksFlagInteractive, "interactive", "i", false, "select repos interactively")
+	webhooksCmd.PersistentFlags().BoolVar(&webhooksFlagDryRun, "dry-run", false, "preview actions without executing")
+	webhooksCmd.PersistentFlags().BoolVarP(&webhooksFlagYes, "yes", "y", false, "skip confirmation prompt for large runs")
+
+	for _, c := range []*cobra.Command{webhooksCreateCmd, webhooksDeleteCmd} {
+		c.Flags().StringVar(&webhooksFlagURL, "url", "", "webhook URL (required)")
+		_ = c.MarkFlagRequired("url")
+	}
+	webhooksCreateCmd.Flags().StringVar(&webhooksFlagEvents, "events", "repo:push", "comma-separated events, e.g. repo:push,pullrequest:created")
+	webhooksCreateCmd.Flags().StringVar(&webhooksFlagDescription, "description", "", "webhook title")
+
+	_ = webhooksCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	_ = webhooksCmd.RegisterFlagCompletionFunc("repos", completeRepoSlugs)
+
+	webhooksCmd.AddCommand(webhooksListCmd, webhooksCreateCmd, webhooksDeleteCmd, webhooksSyncCmd)
+	rootCmd.AddCommand(webhooksCmd)
+}
+
+// newWebhooksContext resolves config, the webhook capability and target repos.
+func newWebhooksContext(command string) (*repoContext, provider.WebhookService, error) {
+	ctx, err := newRepoContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	service, err := requireCapability[provider.WebhookService](ctx.cfg, ctx.client, command)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ctx.selectRepos(webhooksFlagRepos, webhooksFlagGroup, webhooksFlagInteractive); err != nil {
+		return nil, nil, err
+	}
+	return ctx, service, nil
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	ctx, service, err := newWebhooksContext("webhooks list")
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+	red := color.New(color.FgRed).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	for _, r := range repoadmin.NewWebhookManager(service).List(ctx.cfg.Workspace, ctx.repos) {
+		fmt.Println()
+		bold.Println(r.RepoSlug)
+		switch {
+		case r.Error != "":
+			fmt.Printf("  %s %s\n", red("✗"), r.Error)
+		case len(r.Webhooks) == 0:
+			fmt.Printf("  %s\n", dim("no webhooks"))
+		default:
+			for _, h := range r.Webhooks {
+				state := ""
+				if !h.Active {
+					state = dim(" (inactive)")
+				}
+				fmt.Printf("  %s%s\n    %s\n", h.URL, state, dim(strings.Join(h.Events, ", ")))
+			}
+		}
+	}
+	return nil
+}
+
+func runWebhooksCreate(cmd *cobra.Command, args []string) error {
+	var events []string
+	for _, e := range strings.Split(webhooksFlagEvents, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("--events must list at least one event")
+	}
+	hook := bitbucket.Webhook{URL: webhooksFlagURL, Description: webhooksFlagDescription, Events: events}
+	return syncWebhooks("webhooks create", []bitbucket.Webhook{hook})
+}
+
+func runWebhooksSync(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(cfg.Webhooks) == 0 {
+		return fmt.Errorf("no webhooks configured\n  Hint: add a 'webhooks' list with url and events to .buck.yaml")
+	}
+
+	hooks := make([]bitbucket.Webhook, 0, len(cfg.Webhooks))
+	for _, w := range cfg.Webhooks {
+		if w.URL == "" || len(w.Events) == 0 {
+			return fmt.Errorf("webhook %q needs both url and events", w.Description)
+		}
+		hooks = append(hooks, bitbucket.Webhook{URL: w.URL, Description: w.Description, Events: w.Events})
+	}
+	return syncWebhooks("webhooks sync", hooks)
+}
+
+// syncWebhooks ensures hooks exist on the target repos.
+func syncWebhooks(command string, hooks []bitbucket.Webhook) error {
+	ctx, service, err := newWebhooksContext(command)
+	if err != nil {
+		return err
+	}
+
+	urls := make([]string, len(hooks))
+	for i, h := range hooks {
+		urls[i] = h.URL
+	}
+
+	bold := color.New(color.Bold)
+
+	if webhooksFlagDryRun {
+		bold.Printf("Dry run: would ensure webhook %s in:\n", strings.Join(urls, ", "))
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun("sync webhooks", ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), webhooksFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Syncing %d webhook(s) across %d repos...\n", len(hooks), len(ctx.repos))
+	repoadmin.PrintResults(repoadmin.NewWebhookManager(service).Sync(ctx.cfg.Workspace, ctx.repos, hooks))
+	return nil
+}
+
+func runWebhooksDelete(cmd *cobra.Command, args []string) error {
+	ctx, service, err := newWebhooksContext("webhooks delete")
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	if webhooksFlagDryRun {
+		bold.Printf("Dry run: would delete webhook %s from:\n", webhooksFlagURL)
+		for _, r := range ctx.repos {
+			fmt.Printf("  - %s\n", r)
+		}
+		return nil
+	}
+
+	if !confirmLargeRun("delete webhooks", ctx.cfg.Workspace, ctx.repos, ctx.cfg.Defaults.ConfirmLimit(), webhooksFlagYes) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	bold.Printf("Deleting webhook %s across %d repos...\n", webhooksFlagURL, len(ctx.repos))
+	repoadmin.PrintResults(repoadmin.NewWebhookManager(service).Delete(ctx.cfg.Workspace, ctx.repos, webhooksFlagURL))
+	return nil
+}
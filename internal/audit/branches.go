@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// Branch states in a BranchRow.
+const (
+	BranchExists  = "exists"
+	BranchMissing = "missing"
+	BranchStale   = "stale"
+	BranchError   = "error"
+)
+
+// BranchCell is the state of one branch in one repo.
+type BranchCell struct {
+	State      string
+	LastCommit time.Time // zero when unknown
+	Error      string
+}
+
+// BranchRow is the state of each requested branch in one repo.
+type BranchRow struct {
+	RepoSlug string
+	Cells    map[string]BranchCell // branch name → state
+}
+
+// AuditBranches looks up each branch in each repo concurrently. A branch whose
+// last commit is older than staleAfter is reported as stale; staleAfter <= 0
+// turns that off.
+func AuditBranches(client provider.BranchService, workspace string, repos, branches []string, staleAfter time.Duration) []BranchRow {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		rows []BranchRow
+	)
+	now := time.Now()
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			row := BranchRow{RepoSlug: repoSlug, Cells: make(map[string]BranchCell, len(branches))}
+			for _, name := range branches {
+				row.Cells[name] = branchCell(client, ws, slug, name, staleAfter, now)
+			}
+
+			mu.Lock()
+			rows = append(rows, row)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].RepoSlug < rows[j].RepoSlug
+	})
+	return rows
+}
+
+func branchCell(client provider.BranchService, workspace, repoSlug, name string, staleAfter time.Duration, now time.Time) BranchCell {
+	branch, err := client.GetBranch(workspace, repoSlug, name)
+	if err != nil {
+		if provider.Classify(err) == provider.ErrNotFound {
+			return BranchCell{State: BranchMissing}
+		}
+		return BranchCell{State: BranchError, Error: err.Error()}
+	}
+
+	cell := BranchCell{State: BranchExists}
+	if t, err := time.Parse(time.RFC3339, branch.Target.Date); err == nil {
+		cell.LastCommit = t
+		if staleAfter > 0 && now.Sub(t) > staleAfter {
+			cell.State = BranchStale
+		}
+	}
+	return cell
+}
+
+// PrintBranchMatrix displays a repo × branch table followed by a per-branch tally.
+func PrintBranchMatrix(rows []BranchRow, branches []string) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	bold := color.New(color.Bold)
+
+	widths := make([]int, len(branches))
+	for i, b := range branches {
+		widths[i] = max(len(b), len("stale 9999d"))
+	}
+
+	fmt.Println()
+	bold.Printf("  %-30s", "REPO")
+	for i, b := range branches {
+		bold.Printf(" %-*s", widths[i], b)
+	}
+	fmt.Println()
+
+	var failures []string
+	for _, row := range rows {
+		fmt.Printf("  %-30s", row.RepoSlug)
+		for i, b := range branches {
+			cell := row.Cells[b]
+			text := cell.State
+			if cell.State == BranchStale {
+				text = fmt.Sprintf("stale %dd", int(time.Since(cell.LastCommit).Hours()/24))
+			}
+			// Pad before coloring, since escape codes would break the alignment
+			text = fmt.Sprintf("%-*s", widths[i], text)
+			switch cell.State {
+			case BranchExists:
+				text = green(text)
+			case BranchStale:
+				text = yellow(text)
+			default:
+				text = red(text)
+			}
+			fmt.Printf(" %s", text)
+			if cell.Error != "" {
+				failures = append(failures, fmt.Sprintf("%s %s: %s", row.RepoSlug, b, cell.Error))
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	for _, b := range branches {
+		counts := make(map[string]int)
+		for _, row := range rows {
+			counts[row.Cells[b].State]++
+		}
+		line := fmt.Sprintf("%d exist, %d missing", counts[BranchExists]+counts[BranchStale], counts[BranchMissing])
+		if counts[BranchStale] > 0 {
+			line += fmt.Sprintf(" (%d stale)", counts[BranchStale])
+		}
+		if counts[BranchError] > 0 {
+			line += fmt.Sprintf(", %d errors", counts[BranchError])
+		}
+		fmt.Printf("  %s %s\n", bold.Sprint(b+":"), line)
+	}
+	for _, e := range failures {
+		fmt.Printf("  %s %s\n", red("✗"), e)
+	}
+}
+
+// WriteBranchCSV writes the matrix as CSV: a repo column, then a state and a
+// last-commit date column per branch.
+func WriteBranchCSV(w io.Writer, rows []BranchRow, branches []string) error {
+	out := csv.NewWriter(w)
+	header := []string{"repo"}
+	for _, b := range branches {
+		header = append(header, b, b+" last commit")
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{row.RepoSlug}
+		for _, b := range branches {
+			cell := row.Cells[b]
+			date := ""
+			if !cell.LastCommit.IsZero() {
+				date = cell.LastCommit.Format("2006-01-02")
+			}
+			record = append(record, cell.State, date)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestAuditBranches(t *testing.T) {
+	recent := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	old := time.Now().Add(-200 * 24 * time.Hour).Format(time.RFC3339)
+	branches := map[string]string{ // repo/branch → tip commit date
+		"api/main":    recent,
+		"api/develop": old,
+		"web/main":    recent,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// parts: [2.0, repositories, {ws}, {slug}, refs, branches, {name}]
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		slug, name := parts[3], parts[6]
+		if slug == "locked" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "Access denied"}})
+			return
+		}
+		date, ok := branches[slug+"/"+name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "Branch not found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(bitbucket.Branch{Name: name, Target: bitbucket.BranchTarget{Hash: "abc", Date: date}})
+	}))
+	defer srv.Close()
+
+	names := []string{"main", "develop"}
+	rows := AuditBranches(newClientForServer(srv), "ws", []string{"web", "locked", "api"}, names, 90*24*time.Hour)
+	if len(rows) != 3 || rows[0].RepoSlug != "api" {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	want := map[string][2]string{
+		"api":    {BranchExists, BranchStale},
+		"locked": {BranchError, BranchError},
+		"web":    {BranchExists, BranchMissing},
+	}
+	for _, row := range rows {
+		got := [2]string{row.Cells["main"].State, row.Cells["develop"].State}
+		if got != want[row.RepoSlug] {
+			t.Errorf("%s = %v, want %v", row.RepoSlug, got, want[row.RepoSlug])
+		}
+	}
+	if !strings.Contains(rows[1].Cells["main"].Error, "Access denied") {
+		t.Errorf("locked error = %q", rows[1].Cells["main"].Error)
+	}
+
+	// Staleness off: old branches just exist
+	rows = AuditBranches(newClientForServer(srv), "ws", []string{"api"}, names, 0)
+	if rows[0].Cells["develop"].State != BranchExists {
+		t.Errorf("develop with staleness off = %+v", rows[0].Cells["develop"])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBranchCSV(&buf, rows, names); err != nil {
+		t.Fatalf("WriteBranchCSV error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "repo,main,main last commit,develop,develop last commit" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "api,exists,"+recent[:10]+",exists,") {
+		t.Errorf("row = %q", lines[1])
+	}
+}
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/matcher"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// GroupCheck lists the problems found in one configured group.
+type GroupCheck struct {
+	Name     string
+	Entries  int
+	Problems []string
+}
+
+// GroupWorkspaces returns the workspaces named by group entries, default
+// first, so their repos can be listed once for CheckGroups. Exclusions are
+// skipped: they are harmless when they match nothing.
+func GroupWorkspaces(groups map[string][]string, defaultWorkspace string) []string {
+	seen := make(map[string]bool)
+	var workspaces []string
+	for _, entries := range groups {
+		for _, entry := range entries {
+			if matcher.IsNegative(entry) {
+				continue
+			}
+			if ws, _ := provider.SplitPattern(defaultWorkspace, entry); !seen[ws] {
+				seen[ws] = true
+				workspaces = append(workspaces, ws)
+			}
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		if (workspaces[i] == defaultWorkspace) != (workspaces[j] == defaultWorkspace) {
+			return workspaces[i] == defaultWorkspace
+		}
+		return workspaces[i] < workspaces[j]
+	})
+	return workspaces
+}
+
+// CheckGroups verifies every group entry against the repos of the workspace
+// it names: literal slugs must exist and patterns must match at least one
+// repo. Entries without a "workspace/" prefix belong to defaultWorkspace.
+// For missing slugs the closest existing repo is suggested, since the usual
+// cause is a rename. Groups are returned sorted by name.
+func CheckGroups(groups map[string][]string, defaultWorkspace string, candidates map[string][]matcher.Candidate) []GroupCheck {
+	exists := make(map[string]map[string]bool, len(candidates))
+	for ws, cands := range candidates {
+		exists[ws] = make(map[string]bool, len(cands))
+		for _, c := range cands {
+			exists[ws][strings.ToLower(c.Slug)] = true
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]GroupCheck, 0, len(names))
+	for _, name := range names {
+		check := GroupCheck{Name: name, Entries: len(groups[name])}
+		for _, entry := range groups[name] {
+			ws, pattern := provider.SplitPattern(defaultWorkspace, entry)
+			switch {
+			case matcher.IsNegative(pattern):
+				// Exclusions matching nothing are harmless
+			case matcher.IsExplicit(pattern):
+				if err := matcher.Validate([]string{pattern}); err != nil {
+					check.Problems = append(check.Problems, err.Error())
+				} else if len(matcher.MatchRepos(candidates[ws], []string{pattern}).Matched) == 0 {
+					check.Problems = append(check.Problems, fmt.Sprintf("pattern %q matches nothing", entry))
+				}
+			case !exists[ws][strings.ToLower(pattern)]:
+				problem := fmt.Sprintf("%s not found", entry)
+				if s := suggestRepo(candidates[ws], pattern); s != "" {
+					problem += fmt.Sprintf(" (renamed to %s?)", provider.QualifyRepo(defaultWorkspace, ws, s))
+				}
+				check.Problems = append(check.Problems, problem)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// suggestRepo returns the best match for a missing slug: first the slug as a
+// fuzzy pattern, then all of its words together, e.g. "billing-api" finds
+// "billing-service-api".
+func suggestRepo(candidates []matcher.Candidate, slug string) string {
+	words := strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	for _, pattern := range []string{slug, strings.Join(words, " ")} {
+		if pattern == "" {
+			continue
+		}
+		if m := matcher.MatchRepos(candidates, []string{pattern}).Matched; len(m) > 0 {
+			return m[0]
+		}
+	}
+	return ""
+}
+
+// PrintGroupChecks displays each group with its problems and returns the
+// number of groups that have any.
+func PrintGroupChecks(checks []GroupCheck) int {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	broken := 0
+	fmt.Println()
+	for _, c := range checks {
+		if len(c.Problems) == 0 {
+			fmt.Printf("  %s %-30s %d entries\n", green("✓"), c.Name, c.Entries)
+			continue
+		}
+		broken++
+		fmt.Printf("  %s %-30s %s\n", red("✗"), c.Name, c.Problems[0])
+		for _, p := range c.Problems[1:] {
+			fmt.Printf("    %-30s %s\n", "", p)
+		}
+	}
+
+	fmt.Printf("\n%s %s ok, %s with problems\n",
+		bold("Summary:"),
+		green(fmt.Sprintf("%d", len(checks)-broken)),
+		red(fmt.Sprintf("%d", broken)),
+	)
+	return broken
+}
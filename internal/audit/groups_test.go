@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/matcher"
+)
+
+func TestCheckGroups(t *testing.T) {
+	candidates := map[string][]matcher.Candidate{"acme": {
+		{Slug: "billing-service-api"},
+		{Slug: "web-app"},
+		{Slug: "infra-terraform"},
+	}}
+	groups := map[string][]string{
+		"web":     {"web-app", "!legacy-*"},
+		"billing": {"billing-api", "web-app", "gone-forever"},
+		"infra":   {"infra-*", "ops-*"},
+	}
+
+	checks := CheckGroups(groups, "acme", candidates)
+	if len(checks) != 3 {
+		t.Fatalf("checks = %+v", checks)
+	}
+	byName := make(map[string]GroupCheck)
+	for _, c := range checks {
+		byName[c.Name] = c
+	}
+	if checks[0].Name != "billing" || checks[2].Name != "web" {
+		t.Errorf("groups not sorted: %+v", checks)
+	}
+
+	if p := byName["web"].Problems; len(p) != 0 {
+		t.Errorf("web problems = %v", p)
+	}
+	want := []string{"billing-api not found (renamed to billing-service-api?)", "gone-forever not found"}
+	if p := byName["billing"].Problems; !reflect.DeepEqual(p, want) {
+		t.Errorf("billing problems = %v, want %v", p, want)
+	}
+	want = []string{`pattern "ops-*" matches nothing`}
+	if p := byName["infra"].Problems; !reflect.DeepEqual(p, want) {
+		t.Errorf("infra problems = %v, want %v", p, want)
+	}
+}
+
+func TestCheckGroups_OtherWorkspace(t *testing.T) {
+	candidates := map[string][]matcher.Candidate{
+		"acme": {{Slug: "web-app"}},
+		"ops":  {{Slug: "infra-terraform"}, {Slug: "deploy-scripts"}},
+	}
+	groups := map[string][]string{
+		"platform": {"web-app", "ops/infra-terraform", "ops/deploy-*", "ops/re:^deploy-"},
+		"broken":   {"ops/web-app", "ops/deploy-script", "ops/web-*"},
+	}
+
+	checks := CheckGroups(groups, "acme", candidates)
+	if len(checks) != 2 || checks[1].Name != "platform" {
+		t.Fatalf("checks = %+v", checks)
+	}
+	if p := checks[1].Problems; len(p) != 0 {
+		t.Errorf("platform problems = %v", p)
+	}
+	want := []string{
+		"ops/web-app not found",
+		"ops/deploy-script not found (renamed to ops/deploy-scripts?)",
+		`pattern "ops/web-*" matches nothing`,
+	}
+	if p := checks[0].Problems; !reflect.DeepEqual(p, want) {
+		t.Errorf("broken problems = %v, want %v", p, want)
+	}
+}
+
+func TestGroupWorkspaces(t *testing.T) {
+	groups := map[string][]string{
+		"a": {"web", "ops/infra", "!legacy/*"},
+		"b": {"data/re:^etl-", "ops/deploy"},
+	}
+	got := GroupWorkspaces(groups, "acme")
+	if want := []string{"acme", "data", "ops"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupWorkspaces = %v, want %v", got, want)
+	}
+}
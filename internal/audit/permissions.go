@@ -0,0 +1,161 @@
+// Package audit reports on the state of many repos at once (permissions,
+// branches) for reviews and migrations. Nothing in it changes a repo.
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// PermissionReport is the explicit access of every user and group across
+// the audited repos.
+type PermissionReport struct {
+	Repos      []string          // repos that could be read, sorted
+	Errors     map[string]string // repo → error, for repos that could not be read
+	Principals []Principal       // users first, then groups, each sorted by name
+}
+
+// Principal is a user's or group's access across the audited repos.
+type Principal struct {
+	Kind   string // "user" or "group"
+	Name   string
+	Access map[string]string // repo → permission; absent means no explicit access
+}
+
+// Inconsistent reports whether the principal's access differs between repos,
+// including having access to some repos and none to others.
+func (p Principal) Inconsistent(repos []string) bool {
+	for _, repo := range repos {
+		if p.Access[repo] != p.Access[repos[0]] {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditPermissions reads the explicit permissions of each repo concurrently.
+func AuditPermissions(client provider.PermissionService, workspace string, repos []string) PermissionReport {
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		report     = PermissionReport{Errors: make(map[string]string)}
+		principals = make(map[string]*Principal)
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			perms, err := client.ListRepoPermissions(provider.SplitRepo(workspace, repoSlug))
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Errors[repoSlug] = err.Error()
+				return
+			}
+			report.Repos = append(report.Repos, repoSlug)
+			for _, perm := range perms {
+				key := perm.Kind + "\x00" + perm.Name
+				p, ok := principals[key]
+				if !ok {
+					p = &Principal{Kind: perm.Kind, Name: perm.Name, Access: make(map[string]string)}
+					principals[key] = p
+				}
+				p.Access[repoSlug] = perm.Permission
+			}
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Strings(report.Repos)
+	for _, p := range principals {
+		report.Principals = append(report.Principals, *p)
+	}
+	sort.Slice(report.Principals, func(i, j int) bool {
+		a, b := report.Principals[i], report.Principals[j]
+		if a.Kind != b.Kind {
+			return a.Kind == "user"
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	return report
+}
+
+// PrintPermissions displays each principal's access, flagging those whose
+// access differs between repos, and returns the number flagged.
+func PrintPermissions(report PermissionReport) int {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	flagged := 0
+	fmt.Println()
+	for _, p := range report.Principals {
+		label := fmt.Sprintf("%s (%s)", p.Name, p.Kind)
+		if !p.Inconsistent(report.Repos) {
+			fmt.Printf("  %s %-35s %s in all %d repos\n", green("✓"), label, p.Access[report.Repos[0]], len(report.Repos))
+			continue
+		}
+		flagged++
+		fmt.Printf("  %s %-35s %s\n", yellow("!"), label, accessSummary(p, report.Repos))
+	}
+
+	failed := make([]string, 0, len(report.Errors))
+	for repo := range report.Errors {
+		failed = append(failed, repo)
+	}
+	sort.Strings(failed)
+	for _, repo := range failed {
+		fmt.Printf("  %s %-35s %s\n", red("✗"), repo, report.Errors[repo])
+	}
+
+	fmt.Printf("\n%s %d principals across %d repos, %s inconsistent, %s errors\n",
+		bold("Summary:"),
+		len(report.Principals), len(report.Repos),
+		yellow(fmt.Sprintf("%d", flagged)),
+		red(fmt.Sprintf("%d", len(report.Errors))),
+	)
+	return flagged
+}
+
+// accessSummary groups repos by permission, highest first, e.g.
+// "admin: api · write: web, worker · none: legacy".
+func accessSummary(p Principal, repos []string) string {
+	byLevel := make(map[string][]string)
+	for _, repo := range repos {
+		level := p.Access[repo]
+		if level == "" {
+			level = "none"
+		}
+		byLevel[level] = append(byLevel[level], repo)
+	}
+
+	var parts []string
+	for _, level := range []string{bitbucket.PermissionAdmin, bitbucket.PermissionWrite, bitbucket.PermissionRead, "none"} {
+		if names, ok := byLevel[level]; ok {
+			parts = append(parts, level+": "+strings.Join(names, ", "))
+			delete(byLevel, level)
+		}
+	}
+	// Levels this tool does not know about, if the API adds any
+	var other []string
+	for level := range byLevel {
+		other = append(other, level)
+	}
+	sort.Strings(other)
+	for _, level := range other {
+		parts = append(parts, level+": "+strings.Join(byLevel[level], ", "))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// hostRewriteTransport sends every request to the test server.
+type hostRewriteTransport struct {
+	base    http.RoundTripper
+	srvHost string
+}
+
+func (t *hostRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.URL.Scheme = "http"
+	cloned.URL.Host = t.srvHost
+	return t.base.RoundTrip(cloned)
+}
+
+// newClientForServer returns a Bitbucket client whose requests go to srv.
+func newClientForServer(srv *httptest.Server) *bitbucket.Client {
+	transport := &hostRewriteTransport{base: http.DefaultTransport, srvHost: srv.Listener.Addr().String()}
+	authApplier := bitbucket.BearerAuth(func() (string, error) { return "test-token", nil })
+	return bitbucket.NewClientWithHTTPClient(&http.Client{Transport: transport}, authApplier)
+}
+
+func TestAuditPermissions(t *testing.T) {
+	users := map[string]string{
+		"api":    `{"values":[{"permission":"admin","user":{"display_name":"Alice"}},{"permission":"write","user":{"display_name":"Bob"}}]}`,
+		"web":    `{"values":[{"permission":"admin","user":{"display_name":"Alice"}},{"permission":"admin","user":{"display_name":"Bob"}}]}`,
+		"worker": `{"values":[{"permission":"admin","user":{"display_name":"Alice"}}]}`,
+	}
+	groups := `{"values":[{"permission":"write","group":{"slug":"devs","name":"Developers"}}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// parts: [2.0, repositories, {ws}, {slug}, permissions-config, users|groups]
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		slug := parts[3]
+		if slug == "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"message":"Access denied"}}`))
+			return
+		}
+		if parts[5] == "groups" {
+			w.Write([]byte(groups))
+			return
+		}
+		w.Write([]byte(users[slug]))
+	}))
+	defer srv.Close()
+
+	report := AuditPermissions(newClientForServer(srv), "ws", []string{"worker", "secret", "web", "api"})
+
+	if strings.Join(report.Repos, ",") != "api,web,worker" {
+		t.Errorf("Repos = %v", report.Repos)
+	}
+	if !strings.Contains(report.Errors["secret"], "Access denied") {
+		t.Errorf("Errors = %v", report.Errors)
+	}
+	if len(report.Principals) != 3 {
+		t.Fatalf("Principals = %+v", report.Principals)
+	}
+	alice, bob, devs := report.Principals[0], report.Principals[1], report.Principals[2]
+	if alice.Name != "Alice" || alice.Inconsistent(report.Repos) {
+		t.Errorf("Alice = %+v, want consistent admin", alice)
+	}
+	if bob.Name != "Bob" || !bob.Inconsistent(report.Repos) {
+		t.Errorf("Bob = %+v, want inconsistent", bob)
+	}
+	if got := accessSummary(bob, report.Repos); got != "admin: web · write: api · none: worker" {
+		t.Errorf("accessSummary(Bob) = %q", got)
+	}
+	if devs.Kind != "group" || devs.Name != "Developers" || devs.Inconsistent(report.Repos) {
+		t.Errorf("Developers = %+v, want consistent group", devs)
+	}
+}
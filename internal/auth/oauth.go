@@ -6,27 +6,53 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
+	"html/template"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
 const (
 	authorizeURL = "https://bitbucket.org/site/oauth2/authorize"
 	tokenURL     = "https://bitbucket.org/site/oauth2/access_token"
-	callbackPort = "9876"
 	callbackPath = "/callback"
-	redirectURI  = "http://localhost:" + callbackPort + callbackPath
 )
 
+// CallbackPort is the local port Login receives the authorization redirect
+// on. The OAuth consumer's callback URL must point at it.
+var CallbackPort = "9876"
+
+// redirectURI returns the callback URL for CallbackPort.
+func redirectURI() string {
+	return "http://localhost:" + CallbackPort + callbackPath
+}
+
+// HTTPClient sends token requests. Replace it to apply proxy and CA settings.
+var HTTPClient = http.DefaultClient
+
+// ErrInvalidGrant is returned when the token endpoint rejects a grant, e.g.
+// a refresh token that was revoked or has expired.
+var ErrInvalidGrant = errors.New("invalid_grant")
+
+// ReloginPrompt, when set, is asked whether to log in again after a refresh
+// token was rejected. Leave it nil where nobody can answer, e.g. in CI.
+var ReloginPrompt func() bool
+
+// login runs the browser flow for a re-login; replaced in tests.
+var login = Login
+
 // Token represents stored OAuth tokens.
 type Token struct {
 	AccessToken  string    `json:"access_token"`
@@ -34,13 +60,33 @@ type Token struct {
 	ExpiresAt    time.Time `json:"expires_at"`
 }
 
-// tokenFilePath returns ~/.buck/token.json
-func tokenFilePath() (string, error) {
+// Profile, when set, names the token file instead of the client ID, e.g. to
+// keep two accounts logged in through the same OAuth consumer apart.
+var Profile string
+
+// tokenKey returns what the token of clientID is stored under.
+func tokenKey(clientID string) string {
+	if Profile != "" {
+		return Profile
+	}
+	return clientID
+}
+
+// unsafeKeyChars are replaced in token file names.
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// tokenFilePath returns ~/.buck/token-<key>.json, or ~/.buck/token.json for
+// an empty key, where tokens were stored before they were keyed.
+func tokenFilePath(key string) (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("cannot find home directory: %w", err)
 	}
-	return filepath.Join(home, ".buck", "token.json"), nil
+	name := "token.json"
+	if key != "" {
+		name = "token-" + unsafeKeyChars.ReplaceAllString(key, "_") + ".json"
+	}
+	return filepath.Join(home, ".buck", name), nil
 }
 
 // Login performs OAuth 2.0 Authorization Code + PKCE flow.
@@ -56,11 +102,18 @@ func Login(clientID, clientSecret string) error {
 	hash := sha256.Sum256([]byte(codeVerifier))
 	codeChallenge := base64.RawURLEncoding.EncodeToString(hash[:])
 
+	// Bind the callback port before sending the user to the browser, so a
+	// busy port fails now instead of after they authorized
+	listener, err := listenCallback()
+	if err != nil {
+		return err
+	}
+
 	// Build authorize URL
 	params := url.Values{
 		"response_type":         {"code"},
 		"client_id":             {clientID},
-		"redirect_uri":          {redirectURI},
+		"redirect_uri":          {redirectURI()},
 		"code_challenge":        {codeChallenge},
 		"code_challenge_method": {"S256"},
 	}
@@ -72,23 +125,9 @@ func Login(clientID, clientSecret string) error {
 
 	// Start local HTTP server for callback
 	mux := http.NewServeMux()
-	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errMsg := r.URL.Query().Get("error_description")
-			if errMsg == "" {
-				errMsg = "no authorization code received"
-			}
-			fmt.Fprintf(w, "<html><body><h2>Authorization failed</h2><p>%s</p></body></html>", html.EscapeString(errMsg))
-			errCh <- fmt.Errorf("authorization failed: %s", errMsg)
-			return
-		}
-		fmt.Fprint(w, "<html><body><h2>Authorization successful!</h2><p>You can close this tab.</p></body></html>")
-		codeCh <- code
-	})
+	mux.HandleFunc(callbackPath, callbackHandler(codeCh, errCh))
 
 	server := &http.Server{
-		Addr:              ":" + callbackPort,
 		Handler:           mux,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       10 * time.Second,
@@ -96,7 +135,7 @@ func Login(clientID, clientSecret string) error {
 	}
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errCh <- fmt.Errorf("callback server failed: %w", err)
 		}
 	}()
@@ -128,7 +167,7 @@ func Login(clientID, clientSecret string) error {
 	}
 
 	// Save token
-	if err := saveToken(token); err != nil {
+	if err := saveToken(tokenKey(clientID), token); err != nil {
 		return err
 	}
 
@@ -136,38 +175,156 @@ func Login(clientID, clientSecret string) error {
 	return nil
 }
 
-var tokenMu sync.Mutex
+// listenCallback listens on CallbackPort, explaining what to do when another
+// program holds it.
+func listenCallback() (net.Listener, error) {
+	listener, err := net.Listen("tcp", ":"+CallbackPort)
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return nil, fmt.Errorf("cannot receive the login callback: port %s is already in use.\n"+
+			"Stop the program using it (find it with 'lsof -i :%s'), or set oauth.callback_port to a free port\n"+
+			"and change the OAuth consumer's callback URL to http://localhost:<port>%s", CallbackPort, CallbackPort, callbackPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server on port %s: %w", CallbackPort, err)
+	}
+	return listener, nil
+}
+
+var (
+	tokenMu         sync.Mutex
+	reloginDeclined bool
+)
 
 // GetToken loads the stored token, refreshing if expired. Safe for concurrent use.
+// When the refresh token is rejected and ReloginPrompt agrees, it logs in again
+// in place; a declined prompt is not repeated for the rest of the run.
 func GetToken(clientID, clientSecret string) (string, error) {
+	return getToken(clientID, clientSecret, 30*time.Second)
+}
+
+// refreshAhead is how close to expiry Warm refreshes a token.
+const refreshAhead = 5 * time.Minute
+
+// Warm refreshes the stored token once if it expires within a few minutes.
+// Commands call it before fanning out, so their concurrent requests start
+// with a token that outlives the run instead of reaching expiry mid-run.
+// Errors are left for GetToken to report on the first request.
+func Warm(clientID, clientSecret string) {
+	_, _ = getToken(clientID, clientSecret, refreshAhead)
+}
+
+// getToken returns the stored access token, refreshing it first when it
+// expires within margin.
+func getToken(clientID, clientSecret string, margin time.Duration) (string, error) {
 	tokenMu.Lock()
 	defer tokenMu.Unlock()
 
-	token, err := loadToken()
+	key := tokenKey(clientID)
+	token, err := loadToken(key)
+	if errors.Is(err, os.ErrNotExist) {
+		// Logged in before tokens were keyed
+		token, err = loadToken("")
+	}
 	if err != nil {
 		return "", fmt.Errorf("not logged in. Run 'buck login' first: %w", err)
 	}
 
-	// Refresh if expired (with 30s buffer)
-	if time.Now().After(token.ExpiresAt.Add(-30 * time.Second)) {
-		token, err = refreshToken(clientID, clientSecret, token.RefreshToken)
+	if time.Now().After(token.ExpiresAt.Add(-margin)) {
+		refreshed, err := refreshToken(clientID, clientSecret, token.RefreshToken)
+		if errors.Is(err, ErrInvalidGrant) && ReloginPrompt != nil && !reloginDeclined {
+			return relogin(clientID, clientSecret, err)
+		}
 		if err != nil {
 			return "", fmt.Errorf("token refresh failed, run 'buck login' again: %w", err)
 		}
-		if err := saveToken(token); err != nil {
+		if err := saveToken(key, refreshed); err != nil {
 			return "", err
 		}
+		token = refreshed
 	}
 
 	return token.AccessToken, nil
 }
 
+// relogin offers to log in again after refreshErr and returns the new access
+// token. Called with tokenMu held, so concurrent callers wait for the login.
+func relogin(clientID, clientSecret string, refreshErr error) (string, error) {
+	if !ReloginPrompt() {
+		reloginDeclined = true
+		return "", fmt.Errorf("token refresh failed, run 'buck login' again: %w", refreshErr)
+	}
+	if err := login(clientID, clientSecret); err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+	token, err := loadToken(tokenKey(clientID))
+	if err != nil {
+		return "", fmt.Errorf("failed to load new token: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// CallbackData is what the callback page template is rendered with.
+type CallbackData struct {
+	Success     bool
+	Message     string        // why authorization failed; empty on success
+	CloseScript template.HTML // closes the tab a few seconds after it loads
+}
+
+const closeScript = `<script>setTimeout(function () { window.close() }, 3000)</script>`
+
+const defaultCallbackPage = `<html><body>
+{{if .Success}}<h2>Authorization successful!</h2><p>You can close this tab.</p>
+{{else}}<h2>Authorization failed</h2><p>{{.Message}}</p>
+{{end}}{{.CloseScript}}
+</body></html>`
+
+// CallbackPage renders the browser page at the end of Login. Replace it with
+// LoadCallbackPage to show a branded page.
+var CallbackPage = template.Must(template.New("callback").Parse(defaultCallbackPage))
+
+// LoadCallbackPage parses an HTML template file for the callback page. It is
+// rendered with CallbackData.
+func LoadCallbackPage(path string) (*template.Template, error) {
+	tmpl, err := template.ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OAuth callback page: %w", err)
+	}
+	return tmpl, nil
+}
+
+// callbackHandler receives the authorization redirect, shows CallbackPage and
+// passes on the code or the failure.
+func callbackHandler(codeCh chan<- string, errCh chan<- error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := CallbackData{Success: true, CloseScript: closeScript}
+		code := r.URL.Query().Get("code")
+		if code == "" {
+			data.Success = false
+			data.Message = r.URL.Query().Get("error_description")
+			if data.Message == "" {
+				data.Message = "no authorization code received"
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := CallbackPage.Execute(w, data); err != nil {
+			fmt.Fprintf(w, "%s", html.EscapeString(err.Error()))
+		}
+
+		if !data.Success {
+			errCh <- fmt.Errorf("authorization failed: %s", data.Message)
+			return
+		}
+		codeCh <- code
+	}
+}
+
 // exchangeCode trades the authorization code for tokens.
 func exchangeCode(clientID, clientSecret, code, codeVerifier string) (*Token, error) {
 	data := url.Values{
 		"grant_type":    {"authorization_code"},
 		"code":          {code},
-		"redirect_uri":  {redirectURI},
+		"redirect_uri":  {redirectURI()},
 		"code_verifier": {codeVerifier},
 	}
 
@@ -200,7 +357,7 @@ func refreshToken(clientID, clientSecret, refresh string) (*Token, error) {
 
 // doTokenRequest executes a token endpoint request and parses the response.
 func doTokenRequest(req *http.Request) (*Token, error) {
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("token request failed: %w", err)
 	}
@@ -212,6 +369,9 @@ func doTokenRequest(req *http.Request) (*Token, error) {
 			Description string `json:"error_description"`
 		}
 		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == ErrInvalidGrant.Error() {
+			return nil, fmt.Errorf("token exchange failed (%d): %w - %s", resp.StatusCode, ErrInvalidGrant, errResp.Description)
+		}
 		return nil, fmt.Errorf("token exchange failed (%d): %s - %s", resp.StatusCode, errResp.Error, errResp.Description)
 	}
 
@@ -231,8 +391,8 @@ func doTokenRequest(req *http.Request) (*Token, error) {
 	}, nil
 }
 
-func saveToken(token *Token) error {
-	path, err := tokenFilePath()
+func saveToken(key string, token *Token) error {
+	path, err := tokenFilePath(key)
 	if err != nil {
 		return err
 	}
@@ -249,8 +409,8 @@ func saveToken(token *Token) error {
 	return os.WriteFile(path, data, 0600)
 }
 
-func loadToken() (*Token, error) {
-	path, err := tokenFilePath()
+func loadToken(key string) (*Token, error) {
+	path, err := tokenFilePath(key)
 	if err != nil {
 		return nil, err
 	}
@@ -4,10 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -16,7 +20,7 @@ import (
 // ---------- tokenFilePath ----------
 
 func TestTokenFilePath_ContainsBuck(t *testing.T) {
-	path, err := tokenFilePath()
+	path, err := tokenFilePath("")
 	if err != nil {
 		t.Fatalf("tokenFilePath() error: %v", err)
 	}
@@ -78,7 +82,7 @@ func TestLoadToken_MissingFile(t *testing.T) {
 	dir := t.TempDir()
 	t.Setenv("HOME", dir)
 
-	_, err := loadToken()
+	_, err := loadToken("")
 	if err == nil {
 		t.Fatal("expected error loading non-existent token, got nil")
 	}
@@ -96,7 +100,7 @@ func TestLoadToken_InvalidJSON(t *testing.T) {
 		t.Fatalf("write: %v", err)
 	}
 
-	_, err := loadToken()
+	_, err := loadToken("")
 	if err == nil {
 		t.Fatal("expected error for malformed JSON, got nil")
 	}
@@ -111,7 +115,7 @@ func TestSaveToken_CreatesDirectory(t *testing.T) {
 		RefreshToken: "new-refresh",
 		ExpiresAt:    time.Now().Add(1 * time.Hour),
 	}
-	if err := saveToken(tok); err != nil {
+	if err := saveToken("", tok); err != nil {
 		t.Fatalf("saveToken() error: %v", err)
 	}
 
@@ -126,7 +130,7 @@ func TestSaveToken_FilePermissions(t *testing.T) {
 	t.Setenv("HOME", dir)
 
 	tok := &Token{AccessToken: "tok", ExpiresAt: time.Now().Add(time.Hour)}
-	if err := saveToken(tok); err != nil {
+	if err := saveToken("", tok); err != nil {
 		t.Fatalf("saveToken() error: %v", err)
 	}
 
@@ -281,7 +285,7 @@ func TestGetToken_ValidToken_NoRefresh(t *testing.T) {
 		RefreshToken: "refresh-tok",
 		ExpiresAt:    time.Now().Add(10 * time.Minute), // not expired
 	}
-	if err := saveToken(tok); err != nil {
+	if err := saveToken("", tok); err != nil {
 		t.Fatalf("saveToken: %v", err)
 	}
 
@@ -317,7 +321,7 @@ func TestGetToken_ExpiredToken_TriesRefresh(t *testing.T) {
 		RefreshToken: "my-refresh-token",
 		ExpiresAt:    time.Now().Add(-5 * time.Minute), // already expired
 	}
-	if err := saveToken(tok); err != nil {
+	if err := saveToken("", tok); err != nil {
 		t.Fatalf("saveToken: %v", err)
 	}
 
@@ -392,3 +396,246 @@ func TestToken_JSONRoundTrip(t *testing.T) {
 		t.Errorf("ExpiresAt = %v, want %v", decoded.ExpiresAt, original.ExpiresAt)
 	}
 }
+
+// ---------- re-login on rejected refresh token ----------
+
+type rewriteTransport struct{ target string }
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, _ := url.Parse(rt.target)
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// withRejectedRefresh stores an expired token and points token requests at a
+// server that answers every refresh with invalid_grant.
+func withRejectedRefresh(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	if err := saveToken("", &Token{AccessToken: "old", RefreshToken: "revoked", ExpiresAt: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "Invalid refresh_token"})
+	}))
+	t.Cleanup(srv.Close)
+
+	oldClient, oldPrompt, oldLogin := HTTPClient, ReloginPrompt, login
+	HTTPClient = &http.Client{Transport: rewriteTransport{srv.URL}}
+	t.Cleanup(func() {
+		HTTPClient, ReloginPrompt, login = oldClient, oldPrompt, oldLogin
+		reloginDeclined = false
+	})
+}
+
+func TestGetToken_InvalidGrantWithoutPromptErrors(t *testing.T) {
+	withRejectedRefresh(t)
+	ReloginPrompt = nil
+
+	_, err := GetToken("id", "secret")
+	if !errors.Is(err, ErrInvalidGrant) {
+		t.Fatalf("err = %v, want ErrInvalidGrant", err)
+	}
+	if !strings.Contains(err.Error(), "run 'buck login' again") {
+		t.Errorf("err = %q, want login hint", err)
+	}
+}
+
+func TestGetToken_InvalidGrantLogsInAgain(t *testing.T) {
+	withRejectedRefresh(t)
+	ReloginPrompt = func() bool { return true }
+	logins := 0
+	login = func(clientID, clientSecret string) error {
+		logins++
+		return saveToken(tokenKey(clientID), &Token{AccessToken: "fresh", RefreshToken: "r", ExpiresAt: time.Now().Add(time.Hour)})
+	}
+
+	got, err := GetToken("id", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "fresh" || logins != 1 {
+		t.Errorf("token = %q after %d logins, want fresh after 1", got, logins)
+	}
+}
+
+func TestGetToken_DeclinedReloginIsNotAskedAgain(t *testing.T) {
+	withRejectedRefresh(t)
+	prompts := 0
+	ReloginPrompt = func() bool { prompts++; return false }
+
+	for i := 0; i < 3; i++ {
+		if _, err := GetToken("id", "secret"); err == nil {
+			t.Fatal("expected an error after declining")
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("prompted %d times, want 1", prompts)
+	}
+}
+
+// ---------- callback page ----------
+
+func TestCallbackHandler_SuccessPageClosesTab(t *testing.T) {
+	codeCh, errCh := make(chan string, 1), make(chan error, 1)
+	rec := httptest.NewRecorder()
+	callbackHandler(codeCh, errCh)(rec, httptest.NewRequest("GET", "/callback?code=abc", nil))
+
+	if got := <-codeCh; got != "abc" {
+		t.Errorf("code = %q, want abc", got)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Authorization successful!") || !strings.Contains(body, "window.close()") {
+		t.Errorf("unexpected page:\n%s", body)
+	}
+}
+
+func TestCallbackHandler_CustomFailurePage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	page := `{{if .Success}}ok{{else}}<h1>ACME login failed: {{.Message}}</h1>{{end}}`
+	if err := os.WriteFile(path, []byte(page), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadCallbackPage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := CallbackPage
+	CallbackPage = tmpl
+	t.Cleanup(func() { CallbackPage = old })
+
+	codeCh, errCh := make(chan string, 1), make(chan error, 1)
+	rec := httptest.NewRecorder()
+	callbackHandler(codeCh, errCh)(rec, httptest.NewRequest("GET", "/callback?error_description=%3Cdenied%3E", nil))
+
+	if err := <-errCh; !strings.Contains(err.Error(), "<denied>") {
+		t.Errorf("err = %v", err)
+	}
+	if want := "<h1>ACME login failed: &lt;denied&gt;</h1>"; rec.Body.String() != want {
+		t.Errorf("page = %q, want %q", rec.Body.String(), want)
+	}
+}
+
+func TestLoadCallbackPage_MissingFile(t *testing.T) {
+	if _, err := LoadCallbackPage(filepath.Join(t.TempDir(), "nope.html")); err == nil {
+		t.Fatal("expected an error for a missing template")
+	}
+}
+
+// ---------- callback port ----------
+
+func TestListenCallback_PortInUse(t *testing.T) {
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	old := CallbackPort
+	CallbackPort = strconv.Itoa(busy.Addr().(*net.TCPAddr).Port)
+	t.Cleanup(func() { CallbackPort = old })
+
+	_, err = listenCallback()
+	if err == nil {
+		t.Fatal("expected an error for a busy port")
+	}
+	for _, want := range []string{"port " + CallbackPort + " is already in use", "oauth.callback_port"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+}
+
+func TestRedirectURI_UsesCallbackPort(t *testing.T) {
+	old := CallbackPort
+	CallbackPort = "8123"
+	t.Cleanup(func() { CallbackPort = old })
+
+	if got := redirectURI(); got != "http://localhost:8123/callback" {
+		t.Errorf("redirectURI() = %q", got)
+	}
+}
+
+// ---------- proactive refresh ----------
+
+func TestWarm_RefreshesTokenNearExpiryOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := saveToken("", &Token{AccessToken: "old", RefreshToken: "r", ExpiresAt: time.Now().Add(2 * time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	refreshes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "new", "refresh_token": "r2", "expires_in": 3600})
+	}))
+	defer srv.Close()
+	old := HTTPClient
+	HTTPClient = &http.Client{Transport: rewriteTransport{srv.URL}}
+	t.Cleanup(func() { HTTPClient = old })
+
+	// Two minutes left is fine for a single request
+	if got, _ := GetToken("id", "secret"); got != "old" || refreshes != 0 {
+		t.Fatalf("GetToken = %q after %d refreshes, want old without refresh", got, refreshes)
+	}
+
+	Warm("id", "secret")
+	Warm("id", "secret")
+
+	if got, _ := GetToken("id", "secret"); got != "new" || refreshes != 1 {
+		t.Errorf("GetToken = %q after %d refreshes, want new after 1", got, refreshes)
+	}
+}
+
+// ---------- keyed token files ----------
+
+func TestTokenFilePath_KeyedByClientIDOrProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path, _ := tokenFilePath(tokenKey("AbC123"))
+	if filepath.Base(path) != "token-AbC123.json" {
+		t.Errorf("client ID path = %q", path)
+	}
+
+	old := Profile
+	Profile = "work/acme"
+	t.Cleanup(func() { Profile = old })
+	path, _ = tokenFilePath(tokenKey("AbC123"))
+	if filepath.Base(path) != "token-work_acme.json" {
+		t.Errorf("profile path = %q", path)
+	}
+}
+
+func TestGetToken_SeparateTokensPerClientID(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	later := time.Now().Add(time.Hour)
+	saveToken("consumer-a", &Token{AccessToken: "token-a", ExpiresAt: later})
+	saveToken("consumer-b", &Token{AccessToken: "token-b", ExpiresAt: later})
+
+	for id, want := range map[string]string{"consumer-a": "token-a", "consumer-b": "token-b"} {
+		if got, err := GetToken(id, "secret"); err != nil || got != want {
+			t.Errorf("GetToken(%s) = %q, %v; want %q", id, got, err, want)
+		}
+	}
+}
+
+func TestGetToken_FallsBackToLegacyFileAndMigratesOnRefresh(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	saveToken("", &Token{AccessToken: "legacy", RefreshToken: "r", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "refreshed", "refresh_token": "r2", "expires_in": 3600})
+	}))
+	defer srv.Close()
+	old := HTTPClient
+	HTTPClient = &http.Client{Transport: rewriteTransport{srv.URL}}
+	t.Cleanup(func() { HTTPClient = old })
+
+	if got, err := GetToken("consumer-a", "secret"); err != nil || got != "refreshed" {
+		t.Fatalf("GetToken = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".buck", "token-consumer-a.json")); err != nil {
+		t.Errorf("refreshed token not stored under the client ID: %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package bitbucket
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// Repository administration endpoints (branch restrictions, settings, keys, ...).
+
+// ListBranchRestrictions returns all branch restrictions of a repository (handles pagination).
+func (c *Client) ListBranchRestrictions(workspace, repoSlug string) ([]BranchRestriction, error) {
+	var all []BranchRestriction
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/branch-restrictions?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedBranchRestrictions
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list branch restrictions: %w", err)
+		}
+		all = append(all, page.Values...)
+		nextURL = page.Next
+	}
+	return all, nil
+}
+
+// CreateBranchRestriction adds a branch restriction to a repository.
+func (c *Client) CreateBranchRestriction(workspace, repoSlug string, r BranchRestriction) (*BranchRestriction, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/branch-restrictions",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	r.ID = 0
+	var result BranchRestriction
+	if err := c.doRequest("POST", reqURL, r, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateBranchRestriction replaces an existing branch restriction.
+func (c *Client) UpdateBranchRestriction(workspace, repoSlug string, id int, r BranchRestriction) (*BranchRestriction, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/branch-restrictions/%d",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), id)
+	r.ID = 0
+	var result BranchRestriction
+	if err := c.doRequest("PUT", reqURL, r, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRepositorySettings returns the current settings of a repository.
+func (c *Client) GetRepositorySettings(workspace, repoSlug string) (*RepositorySettings, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	var settings RepositorySettings
+	if err := c.doRequest("GET", reqURL, nil, &settings); err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %w", repoSlug, err)
+	}
+	return &settings, nil
+}
+
+// UpdateRepositorySettings updates the non-nil settings of a repository.
+func (c *Client) UpdateRepositorySettings(workspace, repoSlug string, settings RepositorySettings) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	return c.doRequest("PUT", reqURL, settings, nil)
+}
+
+// SetDefaultBranch changes the main branch of a repository.
+func (c *Client) SetDefaultBranch(workspace, repoSlug, branchName string) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	body := map[string]any{"mainbranch": BranchRef{Name: branchName, Type: "branch"}}
+	return c.doRequest("PUT", reqURL, body, nil)
+}
+
+// ListPipelineVariables returns the repository-level Pipelines variables (handles pagination).
+func (c *Client) ListPipelineVariables(workspace, repoSlug string) ([]PipelineVariable, error) {
+	var all []PipelineVariable
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/pipelines_config/variables?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedPipelineVariables
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list pipeline variables: %w", err)
+		}
+		all = append(all, page.Values...)
+		nextURL = page.Next
+	}
+	return all, nil
+}
+
+// CreatePipelineVariable adds a repository-level Pipelines variable.
+func (c *Client) CreatePipelineVariable(workspace, repoSlug string, v PipelineVariable) (*PipelineVariable, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/pipelines_config/variables",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	v.UUID = ""
+	var result PipelineVariable
+	if err := c.doRequest("POST", reqURL, v, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePipelineVariable replaces the value of an existing Pipelines variable.
+func (c *Client) UpdatePipelineVariable(workspace, repoSlug, variableUUID string, v PipelineVariable) (*PipelineVariable, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/pipelines_config/variables/%s",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), url.PathEscape(variableUUID))
+	v.UUID = ""
+	var result PipelineVariable
+	if err := c.doRequest("PUT", reqURL, v, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePipelineVariable removes a Pipelines variable.
+func (c *Client) DeletePipelineVariable(workspace, repoSlug, variableUUID string) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/pipelines_config/variables/%s",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), url.PathEscape(variableUUID))
+	return c.doRequest("DELETE", reqURL, nil, nil)
+}
+
+// ListDeployKeys returns the deploy keys of a repository (handles pagination).
+func (c *Client) ListDeployKeys(workspace, repoSlug string) ([]DeployKey, error) {
+	var all []DeployKey
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/deploy-keys?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedDeployKeys
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list deploy keys: %w", err)
+		}
+		all = append(all, page.Values...)
+		nextURL = page.Next
+	}
+	return all, nil
+}
+
+// AddDeployKey adds a deploy key to a repository.
+func (c *Client) AddDeployKey(workspace, repoSlug string, key DeployKey) (*DeployKey, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/deploy-keys",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	key.ID = 0
+	var result DeployKey
+	if err := c.doRequest("POST", reqURL, key, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteDeployKey removes a deploy key from a repository.
+func (c *Client) DeleteDeployKey(workspace, repoSlug string, keyID int) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/deploy-keys/%d",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), keyID)
+	return c.doRequest("DELETE", reqURL, nil, nil)
+}
+
+// ListWebhooks returns the webhooks of a repository (handles pagination).
+func (c *Client) ListWebhooks(workspace, repoSlug string) ([]Webhook, error) {
+	var all []Webhook
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/hooks?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedWebhooks
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+		all = append(all, page.Values...)
+		nextURL = page.Next
+	}
+	return all, nil
+}
+
+// CreateWebhook adds a webhook to a repository.
+func (c *Client) CreateWebhook(workspace, repoSlug string, hook Webhook) (*Webhook, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/hooks",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	hook.UUID = ""
+	var result Webhook
+	if err := c.doRequest("POST", reqURL, hook, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateWebhook replaces an existing webhook.
+func (c *Client) UpdateWebhook(workspace, repoSlug, hookUUID string, hook Webhook) (*Webhook, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/hooks/%s",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), url.PathEscape(hookUUID))
+	hook.UUID = ""
+	var result Webhook
+	if err := c.doRequest("PUT", reqURL, hook, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteWebhook removes a webhook from a repository.
+func (c *Client) DeleteWebhook(workspace, repoSlug, hookUUID string) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/hooks/%s",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), url.PathEscape(hookUUID))
+	return c.doRequest("DELETE", reqURL, nil, nil)
+}
+
+// CreateRepository creates a git repository in a workspace.
+func (c *Client) CreateRepository(workspace string, repo NewRepository) (*Repository, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s", baseURL, url.PathEscape(workspace), url.PathEscape(repo.Slug))
+	body := map[string]any{"scm": "git", "is_private": repo.IsPrivate}
+	if repo.Description != "" {
+		body["description"] = repo.Description
+	}
+	if repo.ProjectKey != "" {
+		body["project"] = map[string]string{"key": repo.ProjectKey}
+	}
+	var result Repository
+	if err := c.doRequest("POST", reqURL, body, &result); err != nil {
+		return nil, fmt.Errorf("failed to create repository %s: %w", repo.Slug, err)
+	}
+	return &result, nil
+}
+
+// CommitFiles commits files (path → content) to branch through the src API,
+// creating the branch if it does not exist yet.
+func (c *Client) CommitFiles(workspace, repoSlug, branch, message string, files map[string][]byte) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/src", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	_ = form.WriteField("message", message)
+	_ = form.WriteField("branch", branch)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		part, err := form.CreateFormFile(path, path)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(files[path]); err != nil {
+			return err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", reqURL, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if err := c.send(req, nil); err != nil {
+		return fmt.Errorf("failed to commit files to %s: %w", repoSlug, err)
+	}
+	return nil
+}
+
+// ForkRepository forks a repository into targetWorkspace, keeping its slug.
+func (c *Client) ForkRepository(workspace, repoSlug, targetWorkspace string) (*Repository, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/forks", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	body := map[string]any{"workspace": map[string]string{"slug": targetWorkspace}}
+	var result Repository
+	if err := c.doRequest("POST", reqURL, body, &result); err != nil {
+		return nil, fmt.Errorf("failed to fork %s: %w", repoSlug, err)
+	}
+	return &result, nil
+}
+
+// DeleteRepository permanently deletes a repository.
+func (c *Client) DeleteRepository(workspace, repoSlug string) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	if err := c.doRequest("DELETE", reqURL, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", repoSlug, err)
+	}
+	return nil
+}
+
+// ListRepoPermissions returns the explicit user and group permissions on a
+// repository (handles pagination). Users come before groups.
+func (c *Client) ListRepoPermissions(workspace, repoSlug string) ([]RepoPermission, error) {
+	var all []RepoPermission
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/permissions-config/users?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedUserPermissions
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list user permissions: %w", err)
+		}
+		for _, v := range page.Values {
+			all = append(all, RepoPermission{Kind: "user", Name: v.User.DisplayName, Permission: v.Permission})
+		}
+		nextURL = page.Next
+	}
+
+	nextURL = fmt.Sprintf("%s/repositories/%s/%s/permissions-config/groups?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedGroupPermissions
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list group permissions: %w", err)
+		}
+		for _, v := range page.Values {
+			name := v.Group.Name
+			if name == "" {
+				name = v.Group.Slug
+			}
+			all = append(all, RepoPermission{Kind: "group", Name: name, Permission: v.Permission})
+		}
+		nextURL = page.Next
+	}
+	return all, nil
+}
@@ -9,6 +9,10 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/chinhstringee/buck/internal/httpx"
+	"github.com/chinhstringee/buck/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const baseURL = "https://api.bitbucket.org/2.0"
@@ -40,6 +44,7 @@ func BasicAuth(username, password string) AuthApplier {
 type Client struct {
 	httpClient  *http.Client
 	authApplier AuthApplier
+	maxPages    int // page cap for ListRepositories
 }
 
 // NewClient creates a new Bitbucket API client.
@@ -49,25 +54,34 @@ func NewClient(authApplier AuthApplier) *Client {
 			Timeout: 30 * time.Second,
 		},
 		authApplier: authApplier,
+		maxPages:    httpx.DefaultMaxPages,
 	}
 }
 
-// NewClientWithHTTPClient creates a Bitbucket API client with a custom http.Client.
-// Intended for testing with httptest servers.
+// NewClientWithHTTPClient creates a Bitbucket API client with a custom http.Client,
+// e.g. one with a logging transport or pointed at an httptest server.
 func NewClientWithHTTPClient(httpClient *http.Client, authApplier AuthApplier) *Client {
 	return &Client{
 		httpClient:  httpClient,
 		authApplier: authApplier,
+		maxPages:    httpx.DefaultMaxPages,
+	}
+}
+
+// SetMaxPages sets how many pages ListRepositories fetches; n <= 0 keeps the current cap.
+func (c *Client) SetMaxPages(n int) {
+	if n > 0 {
+		c.maxPages = n
 	}
 }
 
 // ListRepositories returns all repos in a workspace (handles pagination).
+// It warns when the page cap cuts the list short.
 func (c *Client) ListRepositories(workspace string) ([]Repository, error) {
-	const maxPages = 50
 	var allRepos []Repository
 	nextURL := fmt.Sprintf("%s/repositories/%s?pagelen=100", baseURL, url.PathEscape(workspace))
 
-	for i := 0; nextURL != "" && i < maxPages; i++ {
+	for i := 0; nextURL != "" && i < c.maxPages; i++ {
 		var page PaginatedResponse
 		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
 			return nil, fmt.Errorf("failed to list repositories: %w", err)
@@ -75,6 +89,9 @@ func (c *Client) ListRepositories(workspace string) ([]Repository, error) {
 		allRepos = append(allRepos, page.Values...)
 		nextURL = page.Next
 	}
+	if nextURL != "" {
+		httpx.WarnTruncated(c.maxPages, len(allRepos))
+	}
 
 	return allRepos, nil
 }
@@ -89,6 +106,16 @@ func (c *Client) GetRepository(workspace, repoSlug string) (*Repository, error)
 	return &repo, nil
 }
 
+// GetBranchingModel returns the effective branching model of a repository.
+func (c *Client) GetBranchingModel(workspace, repoSlug string) (*BranchingModel, error) {
+	url := fmt.Sprintf("%s/repositories/%s/%s/effective-branching-model", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
+	var model BranchingModel
+	if err := c.doRequest("GET", url, nil, &model); err != nil {
+		return nil, fmt.Errorf("failed to get branching model for %s: %w", repoSlug, err)
+	}
+	return &model, nil
+}
+
 // CreateBranch creates a new branch in a repository.
 func (c *Client) CreateBranch(workspace, repoSlug, branchName, sourceBranch string) (*Branch, error) {
 	url := fmt.Sprintf("%s/repositories/%s/%s/refs/branches", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
@@ -104,6 +131,11 @@ func (c *Client) CreateBranch(workspace, repoSlug, branchName, sourceBranch stri
 	return &branch, nil
 }
 
+// BranchURL returns the web URL of a branch.
+func (c *Client) BranchURL(workspace, repoSlug, branchName string) string {
+	return fmt.Sprintf("https://bitbucket.org/%s/%s/branch/%s", workspace, repoSlug, branchName)
+}
+
 // CreatePullRequest creates a pull request in a repository.
 func (c *Client) CreatePullRequest(workspace, repoSlug string, pr CreatePullRequestRequest) (*PullRequest, error) {
 	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests", baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug))
@@ -115,17 +147,53 @@ func (c *Client) CreatePullRequest(workspace, repoSlug string, pr CreatePullRequ
 	return &result, nil
 }
 
-// ListCommits returns commits reachable from include but not from exclude.
+// ResolveCommit returns the commit hash a tag, branch or (short) commit hash points to.
+func (c *Client) ResolveCommit(workspace, repoSlug, ref string) (string, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/commit/%s",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), url.PathEscape(ref))
+	var commit Commit
+	if err := c.doRequest("GET", reqURL, nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", ref, err)
+	}
+	return commit.Hash, nil
+}
+
+// ListCommits returns commits reachable from include but not from exclude
+// (handles pagination, up to 2000 commits).
 func (c *Client) ListCommits(workspace, repoSlug, include, exclude string) ([]Commit, error) {
-	reqURL := fmt.Sprintf("%s/repositories/%s/%s/commits?include=%s&exclude=%s",
+	commits, _, err := c.listCommits(workspace, repoSlug, include, exclude, -1)
+	return commits, err
+}
+
+// CountCommits counts the commits reachable from include but not from
+// exclude, and stops paging once more than atLeast are found (a negative
+// atLeast reads up to the 2000-commit cap). capped reports that more commits
+// remain, so n is a lower bound.
+func (c *Client) CountCommits(workspace, repoSlug, include, exclude string, atLeast int) (n int, capped bool, err error) {
+	commits, more, err := c.listCommits(workspace, repoSlug, include, exclude, atLeast)
+	return len(commits), more, err
+}
+
+// listCommits pages through the commits until more than stopAfter are read
+// (never when stopAfter is negative) or the page cap is hit. more reports
+// that pages were left unread.
+func (c *Client) listCommits(workspace, repoSlug, include, exclude string, stopAfter int) (commits []Commit, more bool, err error) {
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/commits?include=%s&exclude=%s&pagelen=100",
 		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug),
 		url.QueryEscape(include), url.QueryEscape(exclude))
 
-	var page PaginatedCommits
-	if err := c.doRequest("GET", reqURL, nil, &page); err != nil {
-		return nil, fmt.Errorf("failed to list commits: %w", err)
+	for i := 0; nextURL != "" && i < 20; i++ {
+		if stopAfter >= 0 && len(commits) > stopAfter {
+			break
+		}
+		var page PaginatedCommits
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, false, fmt.Errorf("failed to list commits: %w", err)
+		}
+		commits = append(commits, page.Values...)
+		nextURL = page.Next
 	}
-	return page.Values, nil
+	return commits, nextURL != "", nil
 }
 
 // ListPullRequests returns PRs for a repo filtered by state (default: OPEN).
@@ -158,6 +226,24 @@ func (c *Client) GetCurrentUser() (*User, error) {
 	return &user, nil
 }
 
+// ListWorkspaces returns the workspaces the authenticated user belongs to
+// (handles pagination).
+func (c *Client) ListWorkspaces() ([]Workspace, error) {
+	var workspaces []Workspace
+	nextURL := fmt.Sprintf("%s/user/permissions/workspaces?pagelen=100", baseURL)
+	for i := 0; nextURL != "" && i < c.maxPages; i++ {
+		var page PaginatedWorkspaceMemberships
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list workspaces: %w", err)
+		}
+		for _, m := range page.Values {
+			workspaces = append(workspaces, m.Workspace)
+		}
+		nextURL = page.Next
+	}
+	return workspaces, nil
+}
+
 // FindPRByBranch finds a PR by source branch name and state (default: OPEN).
 func (c *Client) FindPRByBranch(workspace, repoSlug, branchName, state string) (*PullRequest, error) {
 	if state == "" {
@@ -176,7 +262,7 @@ func (c *Client) FindPRByBranch(workspace, repoSlug, branchName, state string) (
 		return nil, fmt.Errorf("failed to find PR for branch %q: %w", branchName, err)
 	}
 	if len(page.Values) == 0 {
-		return nil, fmt.Errorf("no %s PR found for branch %q", state, branchName)
+		return nil, NoPRError(state, branchName)
 	}
 	return &page.Values[0], nil
 }
@@ -213,6 +299,54 @@ func (c *Client) UpdatePR(workspace, repoSlug string, prID int, req PRUpdateRequ
 	return &result, nil
 }
 
+// ListPRParticipants returns the reviewers and participants of a pull request
+// with their approval state. The PR list endpoint leaves them out.
+func (c *Client) ListPRParticipants(workspace, repoSlug string, prID int) ([]PRParticipant, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), prID)
+	var pr PullRequest
+	if err := c.doRequest("GET", reqURL, nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get participants of PR #%d: %w", prID, err)
+	}
+	return pr.Participants, nil
+}
+
+// ListPRBuildStatuses returns the build statuses of a pull request's head commit (handles pagination).
+func (c *Client) ListPRBuildStatuses(workspace, repoSlug string, prID int) ([]BuildStatus, error) {
+	var all []BuildStatus
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d/statuses?pagelen=100",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), prID)
+
+	for i := 0; nextURL != "" && i < 10; i++ {
+		var page PaginatedBuildStatuses
+		if err := c.doRequest("GET", nextURL, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list build statuses of PR #%d: %w", prID, err)
+		}
+		all = append(all, page.Values...)
+		nextURL = page.Next
+	}
+	return all, nil
+}
+
+// CommentPR posts a comment on a pull request.
+func (c *Client) CommentPR(workspace, repoSlug string, prID int, text string) error {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d/comments",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), prID)
+	body := map[string]any{"content": map[string]string{"raw": text}}
+	return c.doRequest("POST", reqURL, body, nil)
+}
+
+// CreatePRTask adds a task to a pull request.
+func (c *Client) CreatePRTask(workspace, repoSlug string, prID int, content string) (*PRTask, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d/tasks",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), prID)
+	var result PRTask
+	if err := c.doRequest("POST", reqURL, PRTask{Content: PRTaskContent{Raw: content}}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // DeleteBranch deletes a branch from a repository.
 func (c *Client) DeleteBranch(workspace, repoSlug, branchName string) error {
 	reqURL := fmt.Sprintf("%s/repositories/%s/%s/refs/branches/%s",
@@ -220,6 +354,17 @@ func (c *Client) DeleteBranch(workspace, repoSlug, branchName string) error {
 	return c.doRequest("DELETE", reqURL, nil, nil)
 }
 
+// GetBranch returns a single branch, including the date of its tip commit.
+func (c *Client) GetBranch(workspace, repoSlug, branchName string) (*Branch, error) {
+	reqURL := fmt.Sprintf("%s/repositories/%s/%s/refs/branches/%s",
+		baseURL, url.PathEscape(workspace), url.PathEscape(repoSlug), url.PathEscape(branchName))
+	var branch Branch
+	if err := c.doRequest("GET", reqURL, nil, &branch); err != nil {
+		return nil, fmt.Errorf("failed to get branch %q: %w", branchName, err)
+	}
+	return &branch, nil
+}
+
 // ListBranches returns all branches in a repository (handles pagination).
 func (c *Client) ListBranches(workspace, repoSlug string) ([]Branch, error) {
 	var allBranches []Branch
@@ -270,19 +415,29 @@ func (c *Client) doRequest(method, url string, body any, result any) error {
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.send(req, result)
+}
 
+// send authenticates and executes req, decoding a JSON response into result.
+func (c *Client) send(req *http.Request, result any) (err error) {
 	if err := c.authApplier(req); err != nil {
 		return fmt.Errorf("auth error: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	requestID := httpx.NewRequestID()
+	req.Header.Set(httpx.RequestIDHeader, requestID)
+
+	span := tracing.StartRequest(req, requestID)
+	defer func() { tracing.End(span, err) }()
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("request failed (request id %s): %w", requestID, err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	// Handle 204 No Content (e.g. DELETE responses)
 	if resp.StatusCode == http.StatusNoContent {
@@ -291,17 +446,21 @@ func (c *Client) doRequest(method, url string, body any, result any) error {
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
-		respBody, _ := io.ReadAll(resp.Body)
+		respBody := httpx.ReadErrorBody(resp.Body)
 
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Message: httpx.TruncateMessage(respBody)}
 		var apiErr APIError
 		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error.Message != "" {
-			return formatAPIError(resp.StatusCode, apiErr)
+			oauth := strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ")
+			statusErr = formatAPIError(resp.StatusCode, apiErr, oauth)
 		}
-		return fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+		statusErr.RequestID = requestID
+		statusErr.ServerRequestID = httpx.ServerRequestID(resp)
+		return statusErr
 	}
 
 	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		if err := json.NewDecoder(httpx.LimitBody(resp.Body)).Decode(result); err != nil {
 			return fmt.Errorf("failed to decode response: %w", err)
 		}
 	}
@@ -310,16 +469,15 @@ func (c *Client) doRequest(method, url string, body any, result any) error {
 }
 
 // formatAPIError creates a user-friendly error message from a Bitbucket API error.
-func formatAPIError(statusCode int, apiErr APIError) error {
+// oauth selects the fix suggested for missing scopes.
+func formatAPIError(statusCode int, apiErr APIError, oauth bool) *StatusError {
 	msg := apiErr.Error.Message
 
 	// Try to parse permission scope details from the detail field
 	if apiErr.Error.Detail != nil {
 		var scope ScopeDetail
 		if json.Unmarshal(apiErr.Error.Detail, &scope) == nil && len(scope.Required) > 0 {
-			msg += "\n  Required scopes: " + strings.Join(scope.Required, ", ")
-			msg += "\n  Granted scopes:  " + strings.Join(scope.Granted, ", ")
-			return fmt.Errorf("API error (%d): %s", statusCode, msg)
+			return &StatusError{StatusCode: statusCode, Message: scopeMessage(scope, oauth)}
 		}
 
 		// Detail might be a plain string
@@ -329,5 +487,38 @@ func formatAPIError(statusCode int, apiErr APIError) error {
 		}
 	}
 
-	return fmt.Errorf("API error (%d): %s", statusCode, msg)
+	return &StatusError{StatusCode: statusCode, Message: msg}
+}
+
+// scopeMessage names the scopes a request lacked and how to grant them,
+// e.g. "token is missing scope pullrequest:write (granted: repository)".
+func scopeMessage(scope ScopeDetail, oauth bool) string {
+	granted := make(map[string]bool, len(scope.Granted))
+	for _, s := range scope.Granted {
+		granted[s] = true
+	}
+	var missing []string
+	for _, s := range scope.Required {
+		if !granted[s] {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) == 0 {
+		missing = scope.Required
+	}
+
+	noun := "scope"
+	if len(missing) > 1 {
+		noun = "scopes"
+	}
+	have := "none"
+	if len(scope.Granted) > 0 {
+		have = strings.Join(scope.Granted, ", ")
+	}
+	need := strings.Join(missing, ", ")
+	msg := fmt.Sprintf("token is missing %s %s (granted: %s)", noun, need, have)
+	if oauth {
+		return msg + fmt.Sprintf("\n  Fix: add %s to the OAuth consumer's permissions (workspace settings → OAuth consumers), then run 'buck login' to re-consent", need)
+	}
+	return msg + fmt.Sprintf("\n  Fix: create an API token (or app password) with %s at https://bitbucket.org/account/settings/api-tokens/ and update .buck.yaml", need)
 }
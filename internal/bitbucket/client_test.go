@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+
+	"github.com/chinhstringee/buck/internal/httpx"
 )
 
 // newTestClient returns a Client pointed at the given httptest.Server URL.
@@ -130,6 +133,79 @@ func TestDoRequest_APIError_PlainBody(t *testing.T) {
 	if !strings.Contains(err.Error(), "API error (401)") {
 		t.Errorf("error = %q, want to contain %q", err.Error(), "API error (401)")
 	}
+	if code := StatusCode(err); code != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want 401", code)
+	}
+}
+
+func TestDoRequest_APIError_MissingScope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"type":"error","error":{"message":"Your credentials lack one or more required privilege scopes.",` +
+			`"detail":{"required":["pullrequest:write"],"granted":["repository","account"]}}}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		auth AuthApplier
+		fix  string
+	}{
+		{"oauth", mockAuthApplier("tok"), "run 'buck login' to re-consent"},
+		{"api token", BasicAuth("me@example.com", "tok"), "create an API token (or app password) with pullrequest:write"},
+	}
+	for _, tt := range tests {
+		c := NewClient(tt.auth)
+		err := c.doRequest("GET", srv.URL, nil, nil)
+		if err == nil {
+			t.Fatalf("%s: expected error for 403", tt.name)
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "API error (403): token is missing scope pullrequest:write (granted: repository, account) (request id ") {
+			t.Errorf("%s: error = %q", tt.name, msg)
+		}
+		if !strings.Contains(msg, tt.fix) {
+			t.Errorf("%s: error = %q, want fix %q", tt.name, msg, tt.fix)
+		}
+	}
+}
+
+func TestDoRequest_APIError_RequestIDs(t *testing.T) {
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(httpx.RequestIDHeader)
+		w.Header().Set("X-Request-Id", "bb-123")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIError{Error: APIErrorDetail{Message: "Something went wrong"}})
+	}))
+	defer srv.Close()
+
+	c := NewClient(mockAuthApplier("tok"))
+	err := c.doRequest("GET", srv.URL, nil, nil)
+	if sent == "" {
+		t.Fatal("request ID header not sent")
+	}
+	want := fmt.Sprintf("API error (500): Something went wrong (request id %s, server request id bb-123)", sent)
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+}
+
+func TestDoRequest_APIError_HugeBodyTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(strings.Repeat("<html>", 100000)))
+	}))
+	defer srv.Close()
+
+	c := NewClient(mockAuthApplier("tok"))
+	err := c.doRequest("GET", srv.URL, nil, nil)
+	if err == nil {
+		t.Fatal("expected error for 502")
+	}
+	if len(err.Error()) > 2000 || !strings.Contains(err.Error(), "more bytes)") {
+		t.Errorf("error has %d bytes: %.200s", len(err.Error()), err.Error())
+	}
 }
 
 func TestDoRequest_InvalidJSON_Response(t *testing.T) {
@@ -447,3 +523,147 @@ func TestDoRequest_Headers(t *testing.T) {
 		t.Errorf("Accept = %q, want application/json", gotAccept)
 	}
 }
+
+// ---------- ListCommits ----------
+
+type rewriteTransport struct{ host string }
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.URL.Scheme = "http"
+	cloned.URL.Host = t.host
+	return http.DefaultTransport.RoundTrip(cloned)
+}
+
+func TestListCommits_FollowsNextLinks(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		page := PaginatedCommits{Values: []Commit{{Hash: fmt.Sprintf("c%d", calls)}}}
+		if calls < 3 {
+			page.Next = fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/ws/api/commits?page=%d", calls+1)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithHTTPClient(&http.Client{Transport: rewriteTransport{host: srv.Listener.Addr().String()}}, mockAuthApplier("tok"))
+	commits, err := c.ListCommits("ws", "api", "feature/x", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 3 || commits[2].Hash != "c3" {
+		t.Errorf("commits = %+v, want 3 across pages", commits)
+	}
+}
+
+func TestListCommits_CapsPages(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PaginatedCommits{
+			Values: []Commit{{Hash: "c"}},
+			Next:   "https://api.bitbucket.org/2.0/repositories/ws/api/commits?page=next",
+		})
+	}))
+	defer srv.Close()
+
+	c := NewClientWithHTTPClient(&http.Client{Transport: rewriteTransport{host: srv.Listener.Addr().String()}}, mockAuthApplier("tok"))
+	commits, err := c.ListCommits("ws", "api", "feature/x", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 20 || len(commits) != 20 {
+		t.Errorf("calls = %d, commits = %d, want 20 (page cap)", calls, len(commits))
+	}
+
+	calls = 0
+	n, capped, err := c.CountCommits("ws", "api", "feature/x", "main", -1)
+	if err != nil || n != 20 || !capped {
+		t.Errorf("CountCommits = %d, %v, %v, want 20 capped", n, capped, err)
+	}
+}
+
+func TestCountCommits_StopsOncePastThreshold(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PaginatedCommits{
+			Values: []Commit{{Hash: "a"}, {Hash: "b"}},
+			Next:   "https://api.bitbucket.org/2.0/repositories/ws/api/commits?page=next",
+		})
+	}))
+	defer srv.Close()
+
+	c := NewClientWithHTTPClient(&http.Client{Transport: rewriteTransport{host: srv.Listener.Addr().String()}}, mockAuthApplier("tok"))
+	n, capped, err := c.CountCommits("ws", "api", "main", "feature/x", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || n != 4 || !capped {
+		t.Errorf("calls = %d, n = %d, capped = %v, want 2 pages, 4 commits, capped", calls, n, capped)
+	}
+}
+
+func TestListRepositories_CapsPagesWithWarning(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PaginatedResponse{
+			Values: []Repository{{Slug: fmt.Sprintf("repo-%d", calls)}},
+			Next:   "https://api.bitbucket.org/2.0/repositories/ws?page=next",
+		})
+	}))
+	defer srv.Close()
+
+	var warnings strings.Builder
+	httpx.Warnings = &warnings
+	defer func() { httpx.Warnings = os.Stderr }()
+
+	c := NewClientWithHTTPClient(&http.Client{Transport: rewriteTransport{host: srv.Listener.Addr().String()}}, mockAuthApplier("tok"))
+	c.SetMaxPages(3)
+	repos, err := c.ListRepositories("ws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 || len(repos) != 3 {
+		t.Errorf("calls = %d, repos = %d, want 3 (page cap)", calls, len(repos))
+	}
+	if want := "Warning: results truncated at 3 pages (3 repos); raise http.max_pages to list more\n"; warnings.String() != want {
+		t.Errorf("warning = %q, want %q", warnings.String(), want)
+	}
+}
+
+// ---------- ListWorkspaces ----------
+
+func TestListWorkspaces_FollowsNextLinks(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		page := PaginatedWorkspaceMemberships{Values: []WorkspaceMembership{
+			{Permission: "member", Workspace: Workspace{Slug: fmt.Sprintf("ws-%d", len(paths)), Name: "Team"}},
+		}}
+		if len(paths) == 1 {
+			page.Next = "https://api.bitbucket.org/2.0/user/permissions/workspaces?page=2"
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithHTTPClient(&http.Client{Transport: rewriteTransport{host: srv.Listener.Addr().String()}}, mockAuthApplier("tok"))
+	workspaces, err := c.ListWorkspaces()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(workspaces) != 2 || workspaces[0].Slug != "ws-1" || workspaces[1].Slug != "ws-2" {
+		t.Errorf("workspaces = %+v, want ws-1 and ws-2", workspaces)
+	}
+	if paths[0] != "/2.0/user/permissions/workspaces" {
+		t.Errorf("path = %q", paths[0])
+	}
+}
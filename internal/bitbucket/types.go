@@ -1,14 +1,55 @@
 package bitbucket
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Repository represents a Bitbucket repository.
 type Repository struct {
-	Slug       string     `json:"slug"`
-	Name       string     `json:"name"`
-	FullName   string     `json:"full_name"`
-	MainBranch *BranchRef `json:"mainbranch"`
-	UpdatedOn  string     `json:"updated_on"`
+	Slug        string      `json:"slug"`
+	Name        string      `json:"name"`
+	FullName    string      `json:"full_name"`
+	Description string      `json:"description"`
+	Project     *ProjectRef `json:"project"`
+	MainBranch  *BranchRef  `json:"mainbranch"`
+	UpdatedOn   time.Time   `json:"updated_on"` // zero when unknown
+}
+
+// UnmarshalJSON parses updated_on with ParseTime, so a missing or odd
+// timestamp leaves UpdatedOn zero instead of failing the whole response.
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	type plain Repository
+	aux := struct {
+		*plain
+		UpdatedOn string `json:"updated_on"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.UpdatedOn = ParseTime(aux.UpdatedOn)
+	return nil
+}
+
+// ParseTime parses an API timestamp in RFC 3339 form, with or without
+// fractional seconds; one without a zone is taken as UTC. Anything else
+// gives the zero time.
+func ParseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// ProjectRef is a short project reference (used in Repository.Project).
+type ProjectRef struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
 }
 
 // BranchRef is a short branch reference (used in Repository.MainBranch).
@@ -23,9 +64,10 @@ type Branch struct {
 	Target BranchTarget `json:"target"`
 }
 
-// BranchTarget holds the commit hash a branch points to.
+// BranchTarget holds the commit a branch points to.
 type BranchTarget struct {
 	Hash string `json:"hash"`
+	Date string `json:"date,omitempty"` // commit date; empty when the API response omits it
 }
 
 // CreateBranchRequest is the POST body for creating a branch.
@@ -108,6 +150,24 @@ type User struct {
 	Username    string `json:"username"`
 }
 
+// Workspace is a Bitbucket workspace.
+type Workspace struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// WorkspaceMembership is the authenticated user's permission in a workspace.
+type WorkspaceMembership struct {
+	Permission string    `json:"permission"` // owner, collaborator or member
+	Workspace  Workspace `json:"workspace"`
+}
+
+// PaginatedWorkspaceMemberships wraps paginated workspace permission responses.
+type PaginatedWorkspaceMemberships struct {
+	Values []WorkspaceMembership `json:"values"`
+	Next   string                `json:"next"`
+}
+
 // PaginatedPullRequests wraps paginated PR list responses.
 type PaginatedPullRequests struct {
 	Values []PullRequest `json:"values"`
@@ -143,6 +203,41 @@ type PRUpdateRequest struct {
 	Title       string       `json:"title,omitempty"`
 	Description string       `json:"description,omitempty"`
 	Reviewers   []PRReviewer `json:"reviewers,omitempty"`
+	Destination *PRBranchRef `json:"destination,omitempty"` // retarget to another branch
+}
+
+// Build status states, as reported by Bitbucket.
+const (
+	BuildSuccessful = "SUCCESSFUL"
+	BuildFailed     = "FAILED"
+	BuildInProgress = "INPROGRESS"
+	BuildStopped    = "STOPPED"
+)
+
+// BuildStatus is a CI result reported on a pull request's head commit.
+type BuildStatus struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	State string `json:"state"` // one of the Build* constants
+	URL   string `json:"url"`
+}
+
+// PaginatedBuildStatuses wraps paginated build status responses.
+type PaginatedBuildStatuses struct {
+	Values []BuildStatus `json:"values"`
+	Next   string        `json:"next"`
+}
+
+// PRTask is a task (checklist item) on a pull request.
+type PRTask struct {
+	ID      int           `json:"id,omitempty"`
+	State   string        `json:"state,omitempty"` // UNRESOLVED or RESOLVED
+	Content PRTaskContent `json:"content"`
+}
+
+// PRTaskContent holds a task's text.
+type PRTaskContent struct {
+	Raw string `json:"raw"`
 }
 
 // PaginatedBranches wraps paginated branch list responses.
@@ -163,8 +258,31 @@ type LinkRef struct {
 
 // Commit represents a Bitbucket commit.
 type Commit struct {
-	Hash    string `json:"hash"`
-	Message string `json:"message"`
+	Hash    string       `json:"hash"`
+	Message string       `json:"message"`
+	Date    string       `json:"date"` // ISO 8601
+	Author  CommitAuthor `json:"author"`
+	Links   CommitLinks  `json:"links"`
+}
+
+// CommitAuthor is a commit's author. User is set when the author maps to an account.
+type CommitAuthor struct {
+	Raw  string `json:"raw"` // "Name <email>"
+	User *User  `json:"user,omitempty"`
+}
+
+// Name returns the account display name, or the name part of Raw.
+func (a CommitAuthor) Name() string {
+	if a.User != nil && a.User.DisplayName != "" {
+		return a.User.DisplayName
+	}
+	name, _, _ := strings.Cut(a.Raw, "<")
+	return strings.TrimSpace(name)
+}
+
+// CommitLinks holds a commit's web link.
+type CommitLinks struct {
+	HTML LinkRef `json:"html"`
 }
 
 // PaginatedCommits wraps Bitbucket's paginated commit responses.
@@ -173,6 +291,104 @@ type PaginatedCommits struct {
 	Next   string   `json:"next"`
 }
 
+// BranchRestriction is a branch permission rule, e.g. "no force pushes to release/*".
+type BranchRestriction struct {
+	ID              int       `json:"id,omitempty"`
+	Kind            string    `json:"kind"`              // force, delete, push, restrict_merges, require_approvals_to_merge, require_passing_builds_to_merge, ...
+	BranchMatchKind string    `json:"branch_match_kind"` // glob or branching_model
+	Pattern         string    `json:"pattern,omitempty"`
+	Value           *int      `json:"value,omitempty"` // count for require_* kinds
+	Users           []UserRef `json:"users,omitempty"` // exempt users for push and restrict_merges
+}
+
+// UserRef identifies a user by UUID.
+type UserRef struct {
+	UUID string `json:"uuid"`
+}
+
+// PaginatedBranchRestrictions wraps paginated branch restriction responses.
+type PaginatedBranchRestrictions struct {
+	Values []BranchRestriction `json:"values"`
+	Next   string              `json:"next"`
+}
+
+// NewRepository describes a repository to create.
+type NewRepository struct {
+	Slug        string
+	Description string
+	ProjectKey  string // empty: the workspace's default project
+	IsPrivate   bool
+}
+
+// RepositorySettings holds the updatable repository settings. Nil fields are
+// left unchanged by an update.
+type RepositorySettings struct {
+	ForkPolicy           *string `json:"fork_policy,omitempty"` // allow_forks, no_public_forks, no_forks
+	IsPrivate            *bool   `json:"is_private,omitempty"`
+	HasIssues            *bool   `json:"has_issues,omitempty"`
+	HasWiki              *bool   `json:"has_wiki,omitempty"`
+	Language             *string `json:"language,omitempty"`
+	DefaultMergeStrategy *string `json:"default_merge_strategy,omitempty"` // merge_commit, squash, fast_forward
+	DeleteBranchOnMerge  *bool   `json:"delete_source_branch_on_merge,omitempty"`
+}
+
+// PipelineVariable is a repository-level Pipelines variable.
+// Value is empty in responses for secured variables.
+type PipelineVariable struct {
+	UUID    string `json:"uuid,omitempty"`
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Secured bool   `json:"secured"`
+}
+
+// PaginatedPipelineVariables wraps paginated pipeline variable responses.
+type PaginatedPipelineVariables struct {
+	Values []PipelineVariable `json:"values"`
+	Next   string             `json:"next"`
+}
+
+// DeployKey is a repository access key (read-only SSH deploy key).
+type DeployKey struct {
+	ID    int    `json:"id,omitempty"`
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// PaginatedDeployKeys wraps paginated deploy key responses.
+type PaginatedDeployKeys struct {
+	Values []DeployKey `json:"values"`
+	Next   string      `json:"next"`
+}
+
+// Webhook is a repository webhook subscription.
+type Webhook struct {
+	UUID        string   `json:"uuid,omitempty"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	Events      []string `json:"events"`
+}
+
+// PaginatedWebhooks wraps paginated webhook responses.
+type PaginatedWebhooks struct {
+	Values []Webhook `json:"values"`
+	Next   string    `json:"next"`
+}
+
+// BranchingModel is a repository's effective branching model.
+type BranchingModel struct {
+	Development BranchingModelBranch  `json:"development"`
+	Production  *BranchingModelBranch `json:"production"`
+}
+
+// BranchingModelBranch is the development or production branch of a branching model.
+// Branch is nil when the configured branch does not exist.
+type BranchingModelBranch struct {
+	Name          string     `json:"name"`
+	UseMainBranch bool       `json:"use_mainbranch"`
+	Branch        *BranchRef `json:"branch"`
+}
+
 // APIError represents an error response from Bitbucket.
 type APIError struct {
 	Error   APIErrorDetail `json:"error"`
@@ -187,8 +403,106 @@ type APIErrorDetail struct {
 	Detail  json.RawMessage `json:"detail"`
 }
 
+// StatusError is returned by every backend for an HTTP error response. Its
+// message keeps the "API error (<status>): <message>" form, with the request
+// IDs appended to the first line.
+type StatusError struct {
+	StatusCode      int
+	Message         string
+	RequestID       string // sent by buck
+	ServerRequestID string // returned by the hosting service, if any
+}
+
+func (e *StatusError) Error() string {
+	first, rest, multiline := strings.Cut(e.Message, "\n")
+	if ids := e.requestIDs(); ids != "" {
+		first += " (" + ids + ")"
+	}
+	msg := fmt.Sprintf("API error (%d): %s", e.StatusCode, first)
+	if multiline {
+		msg += "\n" + rest
+	}
+	return msg
+}
+
+func (e *StatusError) requestIDs() string {
+	var ids []string
+	if e.RequestID != "" {
+		ids = append(ids, "request id "+e.RequestID)
+	}
+	if e.ServerRequestID != "" && e.ServerRequestID != e.RequestID {
+		ids = append(ids, "server request id "+e.ServerRequestID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// ErrNoPR matches the error FindPRByBranch returns when the branch has no PR
+// in the requested state, e.g. errors.Is(err, ErrNoPR).
+var ErrNoPR = errors.New("no PR found")
+
+// noPRError is the FindPRByBranch error for a branch without a PR in state.
+type noPRError struct {
+	state, branch string
+}
+
+func (e *noPRError) Error() string {
+	return fmt.Sprintf("no %s PR found for branch %q", e.state, e.branch)
+}
+
+func (e *noPRError) Is(target error) bool { return target == ErrNoPR }
+
+// NoPRError returns the error every backend's FindPRByBranch reports when
+// branchName has no PR in state. It matches ErrNoPR.
+func NoPRError(state, branchName string) error {
+	return &noPRError{state: state, branch: branchName}
+}
+
+// StatusCode returns the HTTP status of the API error in err's chain, or 0.
+func StatusCode(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode
+	}
+	return 0
+}
+
 // ScopeDetail holds required/granted permission scopes from 403 errors.
 type ScopeDetail struct {
 	Required []string `json:"required"`
 	Granted  []string `json:"granted"`
 }
+
+// Permission levels on a repository, lowest to highest.
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+)
+
+// RepoPermission is a user's or group's explicit permission on a repository.
+type RepoPermission struct {
+	Kind       string // "user" or "group"
+	Name       string // user display name or group name
+	Permission string // read, write or admin
+}
+
+// PaginatedUserPermissions wraps paginated repository user permission responses.
+type PaginatedUserPermissions struct {
+	Values []struct {
+		Permission string   `json:"permission"`
+		User       PRAuthor `json:"user"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
+
+// PaginatedGroupPermissions wraps paginated repository group permission responses.
+type PaginatedGroupPermissions struct {
+	Values []struct {
+		Permission string `json:"permission"`
+		Group      struct {
+			Slug string `json:"slug"`
+			Name string `json:"name"`
+		} `json:"group"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
@@ -3,6 +3,7 @@ package bitbucket
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestRepository_JSONDeserialization(t *testing.T) {
@@ -37,8 +38,27 @@ func TestRepository_JSONDeserialization(t *testing.T) {
 	if repo.MainBranch.Type != "branch" {
 		t.Errorf("MainBranch.Type = %q, want %q", repo.MainBranch.Type, "branch")
 	}
-	if repo.UpdatedOn != "2024-01-15T10:00:00Z" {
-		t.Errorf("UpdatedOn = %q, want %q", repo.UpdatedOn, "2024-01-15T10:00:00Z")
+	if want := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC); !repo.UpdatedOn.Equal(want) {
+		t.Errorf("UpdatedOn = %v, want %v", repo.UpdatedOn, want)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC)
+	for _, s := range []string{"2024-01-15T10:30:00.123456+00:00", "2024-01-15T10:30:00.123456Z", "2024-01-15T10:30:00.123456"} {
+		if got := ParseTime(s); !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", s, got, want)
+		}
+	}
+	for _, s := range []string{"", "yesterday"} {
+		if got := ParseTime(s); !got.IsZero() {
+			t.Errorf("ParseTime(%q) = %v, want zero", s, got)
+		}
+	}
+
+	var repo Repository
+	if err := json.Unmarshal([]byte(`{"slug": "a", "updated_on": null}`), &repo); err != nil || repo.Slug != "a" || !repo.UpdatedOn.IsZero() {
+		t.Errorf("null updated_on: %+v, %v", repo, err)
 	}
 }
 
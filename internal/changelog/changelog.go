@@ -0,0 +1,130 @@
+// Package changelog collects what changed across repos, either the commits
+// between two refs or the merged PRs, and renders it as one markdown document.
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+// RepoChanges holds the commits of one repo between the two refs, newest
+// first, without merge commits.
+type RepoChanges struct {
+	RepoSlug string
+	To       string // ref the commits lead up to
+	Commits  []bitbucket.Commit
+	Error    string
+}
+
+// Collector gathers commits across repos.
+type Collector struct {
+	client provider.Provider
+}
+
+// NewCollector creates a new changelog collector.
+func NewCollector(client provider.Provider) *Collector {
+	return &Collector{client: client}
+}
+
+// Collect lists, in each repo concurrently, the commits reachable from to but
+// not from from. If to is empty, each repo's development branch is used.
+func (c *Collector) Collect(workspace string, repos []string, from, to string) []RepoChanges {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []RepoChanges
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			result := c.collectOne(ws, slug, from, to)
+			result.RepoSlug = repoSlug
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+	return results
+}
+
+func (c *Collector) collectOne(workspace, repoSlug, from, to string) RepoChanges {
+	if to == "" {
+		var err error
+		if to, err = provider.DevelopmentBranch(c.client, workspace, repoSlug); err != nil {
+			return RepoChanges{RepoSlug: repoSlug, Error: err.Error()}
+		}
+	}
+	result := RepoChanges{RepoSlug: repoSlug, To: to}
+
+	// Resolve both refs first so a repo without the tag says so, rather than
+	// failing the commit listing with a less clear error
+	for _, ref := range []string{from, to} {
+		if _, err := c.client.ResolveCommit(workspace, repoSlug, ref); err != nil {
+			if provider.Classify(err) == provider.ErrNotFound {
+				result.Error = fmt.Sprintf("%q not found", ref)
+			} else {
+				result.Error = err.Error()
+			}
+			return result
+		}
+	}
+
+	commits, err := c.client.ListCommits(workspace, repoSlug, to, from)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for _, commit := range commits {
+		if !strings.HasPrefix(commit.Message, "Merge ") {
+			result.Commits = append(result.Commits, commit)
+		}
+	}
+	return result
+}
+
+// Render builds the changelog: a section per repo with changes, listing its
+// commits as PR descriptions do (grouped into Features, Fixes and Chores when
+// they are conventional commits), then the repos without changes. Repos that
+// could not be read are left out; the caller reports them.
+func Render(changes []RepoChanges, from, to string, opts pullrequest.DescriptionOptions) string {
+	var b strings.Builder
+	title := fmt.Sprintf("# Changes since %s", from)
+	if to != "" {
+		title = fmt.Sprintf("# Changes from %s to %s", from, to)
+	}
+	b.WriteString(title + "\n")
+
+	var unchanged []string
+	for _, r := range changes {
+		if r.Error != "" {
+			continue
+		}
+		if len(r.Commits) == 0 {
+			unchanged = append(unchanged, r.RepoSlug)
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", r.RepoSlug, pullrequest.FormatCommits(r.Commits, opts))
+	}
+
+	if len(unchanged) > 0 {
+		fmt.Fprintf(&b, "\n## No changes\n\n%s\n", strings.Join(unchanged, ", "))
+	}
+	return b.String()
+}
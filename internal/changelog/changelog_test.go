@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+type hostRewriteTransport struct {
+	base    http.RoundTripper
+	srvHost string
+}
+
+func (t *hostRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.URL.Scheme = "http"
+	cloned.URL.Host = t.srvHost
+	return t.base.RoundTrip(cloned)
+}
+
+func newCollectorForServer(srv *httptest.Server) *Collector {
+	transport := &hostRewriteTransport{base: http.DefaultTransport, srvHost: srv.Listener.Addr().String()}
+	authApplier := bitbucket.BearerAuth(func() (string, error) { return "test-token", nil })
+	return NewCollector(bitbucket.NewClientWithHTTPClient(&http.Client{Transport: transport}, authApplier))
+}
+
+func TestCollectAndRender(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		slug := strings.Split(r.URL.Path, "/")[4]
+		switch {
+		case strings.Contains(r.URL.Path, "/commit/"):
+			// "new" was created after v1.2.0 and has no such tag
+			if slug == "new" && strings.HasSuffix(r.URL.Path, "/v1.2.0") {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"type":"error","error":{"message":"Commit not found"}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(bitbucket.Commit{Hash: "abc"})
+		case strings.HasSuffix(r.URL.Path, "/commits"):
+			if r.URL.Query().Get("include") != "v1.3.0" || r.URL.Query().Get("exclude") != "v1.2.0" {
+				t.Errorf("commits query = %s", r.URL.RawQuery)
+			}
+			var commits []bitbucket.Commit
+			switch slug {
+			case "api":
+				commits = []bitbucket.Commit{
+					{Message: "fix(auth): refresh expired tokens"},
+					{Message: "Merge branch 'develop'"},
+					{Message: "feat: add rate limits\n\nDetails."},
+				}
+			case "web":
+				commits = []bitbucket.Commit{{Message: "Update copy"}}
+			}
+			json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{Values: commits})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	changes := newCollectorForServer(srv).Collect("ws", []string{"web", "new", "docs", "api"}, "v1.2.0", "v1.3.0")
+	if len(changes) != 4 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if len(changes[0].Commits) != 2 {
+		t.Errorf("api commits = %+v, want merge commit left out", changes[0].Commits)
+	}
+	if changes[2].Error != `"v1.2.0" not found` {
+		t.Errorf("new = %+v, want missing tag", changes[2])
+	}
+
+	got := Render(changes, "v1.2.0", "v1.3.0", pullrequest.DescriptionOptions{})
+	want := `# Changes from v1.2.0 to v1.3.0
+
+## api
+
+### Features
+
+* add rate limits
+
+### Fixes
+
+* **auth:** refresh expired tokens
+
+## web
+
+* Update copy
+
+## No changes
+
+docs
+`
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
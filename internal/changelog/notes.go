@@ -0,0 +1,176 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// PRQuery selects the merged PRs for release notes: the PR from Branch, or
+// else every PR merged since Since, optionally only those into Destination.
+type PRQuery struct {
+	Branch      string
+	Since       time.Time
+	Destination string
+}
+
+// RepoPRs holds the merged PRs of one repo, newest first.
+type RepoPRs struct {
+	RepoSlug string
+	PRs      []bitbucket.PullRequest
+	Error    string
+}
+
+// NotesOptions controls how release notes are rendered.
+type NotesOptions struct {
+	Title      string // document heading
+	TitlesOnly bool   // one line per PR, without its description
+}
+
+// CollectMergedPRs finds the merged PRs matching q in each repo concurrently.
+// PRs do not record when they were merged, so the time window applies to
+// their last update, which merging sets.
+func (c *Collector) CollectMergedPRs(workspace string, repos []string, q PRQuery) []RepoPRs {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []RepoPRs
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			result := RepoPRs{RepoSlug: repoSlug}
+			prs, err := c.mergedPRs(ws, slug, q)
+			if err != nil {
+				result.Error = err.Error()
+			}
+			result.PRs = prs
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+	return results
+}
+
+func (c *Collector) mergedPRs(workspace, repoSlug string, q PRQuery) ([]bitbucket.PullRequest, error) {
+	if q.Branch != "" {
+		pr, err := c.client.FindPRByBranch(workspace, repoSlug, q.Branch, "MERGED")
+		if err != nil {
+			// FindPRByBranch reports "none merged" as an error too
+			if errors.Is(err, bitbucket.ErrNoPR) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []bitbucket.PullRequest{*pr}, nil
+	}
+
+	prs, err := c.client.ListPullRequests(workspace, repoSlug, "MERGED")
+	if err != nil {
+		return nil, err
+	}
+	var merged []bitbucket.PullRequest
+	for _, pr := range prs {
+		if q.Destination != "" && pr.Destination.Branch.Name != q.Destination {
+			continue
+		}
+		if bitbucket.ParseTime(pr.UpdatedOn).Before(q.Since) {
+			continue
+		}
+		merged = append(merged, pr)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return bitbucket.ParseTime(merged[i].UpdatedOn).After(bitbucket.ParseTime(merged[j].UpdatedOn))
+	})
+	return merged, nil
+}
+
+// RenderNotes builds release notes with a section per repo listing its merged
+// PRs, each with its description unless opts.TitlesOnly, then the repos
+// without merged PRs. Repos that could not be read are left out; the caller
+// reports them.
+func RenderNotes(results []RepoPRs, opts NotesOptions) string {
+	var b strings.Builder
+	b.WriteString("# " + opts.Title + "\n")
+
+	var empty []string
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		if len(r.PRs) == 0 {
+			empty = append(empty, r.RepoSlug)
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", r.RepoSlug)
+		if opts.TitlesOnly {
+			b.WriteString("\n")
+			for _, pr := range r.PRs {
+				line := fmt.Sprintf("* %s %s", prRef(pr), pr.Title)
+				if name := authorName(pr); name != "" {
+					line += fmt.Sprintf(" (%s)", name)
+				}
+				b.WriteString(line + "\n")
+			}
+			continue
+		}
+		for _, pr := range r.PRs {
+			fmt.Fprintf(&b, "\n### %s %s\n", prRef(pr), pr.Title)
+			if name := authorName(pr); name != "" {
+				fmt.Fprintf(&b, "\n_by %s_\n", name)
+			}
+			if desc := strings.TrimSpace(pr.Description); desc != "" {
+				fmt.Fprintf(&b, "\n%s\n", nestHeadings(desc))
+			}
+		}
+	}
+
+	if len(empty) > 0 {
+		fmt.Fprintf(&b, "\n## No merged PRs\n\n%s\n", strings.Join(empty, ", "))
+	}
+	return b.String()
+}
+
+// headingPattern matches a markdown heading that can go two levels down.
+var headingPattern = regexp.MustCompile(`(?m)^(#{1,4} )`)
+
+// nestHeadings moves the markdown headings of a PR description two levels
+// down, below the PR's own heading.
+func nestHeadings(desc string) string {
+	return headingPattern.ReplaceAllString(desc, "##$1")
+}
+
+// prRef returns "#42", as a markdown link when the PR has one.
+func prRef(pr bitbucket.PullRequest) string {
+	if pr.Links.HTML.Href == "" {
+		return fmt.Sprintf("#%d", pr.ID)
+	}
+	return fmt.Sprintf("[#%d](%s)", pr.ID, pr.Links.HTML.Href)
+}
+
+// authorName returns the PR author's display name, or nickname without one.
+func authorName(pr bitbucket.PullRequest) string {
+	if pr.Author.DisplayName != "" {
+		return pr.Author.DisplayName
+	}
+	return pr.Author.Nickname
+}
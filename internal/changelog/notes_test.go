@@ -0,0 +1,94 @@
+package changelog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func mergedPR(id int, title, into, updated string) bitbucket.PullRequest {
+	pr := bitbucket.PullRequest{ID: id, Title: title, State: "MERGED", UpdatedOn: updated}
+	pr.Destination.Branch.Name = into
+	return pr
+}
+
+func TestCollectMergedPRs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		slug := strings.Split(r.URL.Path, "/")[4]
+		if r.URL.Query().Get("state") != "MERGED" {
+			t.Errorf("state = %q, want MERGED", r.URL.Query().Get("state"))
+		}
+		var prs []bitbucket.PullRequest
+		switch {
+		case strings.Contains(r.URL.Query().Get("q"), "feature/x"):
+			if slug == "api" {
+				prs = []bitbucket.PullRequest{mergedPR(4, "Feature X", "develop", "")}
+			}
+		case slug == "api":
+			prs = []bitbucket.PullRequest{
+				mergedPR(1, "Too old", "main", "2026-02-01T10:00:00+00:00"),
+				mergedPR(2, "Older", "main", "2026-03-05T10:00:00+00:00"),
+				mergedPR(3, "Other branch", "develop", "2026-03-09T10:00:00+00:00"),
+				mergedPR(5, "Newest", "main", "2026-03-10T10:00:00+00:00"),
+			}
+		}
+		json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: prs})
+	}))
+	defer srv.Close()
+	c := newCollectorForServer(srv)
+
+	byBranch := c.CollectMergedPRs("ws", []string{"web", "api"}, PRQuery{Branch: "feature/x"})
+	if len(byBranch) != 2 || len(byBranch[0].PRs) != 1 || byBranch[0].PRs[0].ID != 4 || len(byBranch[1].PRs) != 0 || byBranch[1].Error != "" {
+		t.Errorf("by branch = %+v", byBranch)
+	}
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	window := c.CollectMergedPRs("ws", []string{"api"}, PRQuery{Since: since, Destination: "main"})
+	if got := window[0].PRs; len(got) != 2 || got[0].ID != 5 || got[1].ID != 2 {
+		t.Errorf("window = %+v, want #5 then #2", got)
+	}
+}
+
+func TestRenderNotes(t *testing.T) {
+	pr := bitbucket.PullRequest{ID: 42, Title: "Increase API limit", Description: "### Fixes\n\n* raise limit\n#12 was related"}
+	pr.Author.DisplayName = "Jane Doe"
+	pr.Links.HTML.Href = "https://bitbucket.org/ws/api/pull-requests/42"
+	results := []RepoPRs{
+		{RepoSlug: "api", PRs: []bitbucket.PullRequest{pr}},
+		{RepoSlug: "broken", Error: "forbidden"},
+		{RepoSlug: "docs"},
+	}
+
+	got := RenderNotes(results, NotesOptions{Title: "Release notes: feature/x"})
+	want := `# Release notes: feature/x
+
+## api
+
+### [#42](https://bitbucket.org/ws/api/pull-requests/42) Increase API limit
+
+_by Jane Doe_
+
+##### Fixes
+
+* raise limit
+#12 was related
+
+## No merged PRs
+
+docs
+`
+	if got != want {
+		t.Errorf("RenderNotes() =\n%s\nwant\n%s", got, want)
+	}
+
+	short := RenderNotes(results, NotesOptions{Title: "Notes", TitlesOnly: true})
+	if !strings.Contains(short, "\n* [#42](https://bitbucket.org/ws/api/pull-requests/42) Increase API limit (Jane Doe)\n") || strings.Contains(short, "raise limit") {
+		t.Errorf("titles only =\n%s", short)
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/fatih/color"
-	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
 )
 
 // Default branches that should never be deleted.
@@ -15,22 +15,22 @@ var defaultProtectedBranches = []string{"main", "master", "develop", "staging",
 
 // Result holds the outcome of a branch deletion for one repo.
 type Result struct {
-	RepoSlug   string
-	BranchName string
-	Success    bool
-	Error      string
-	Skipped    bool // true if branch is protected
+	RepoSlug   string `json:"repo"`
+	BranchName string `json:"branch"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	Skipped    bool   `json:"skipped,omitempty"` // true if branch is protected
 }
 
 // BranchCleaner orchestrates parallel branch deletion across repos.
 type BranchCleaner struct {
-	client            *bitbucket.Client
+	client            provider.Provider
 	protectedBranches map[string]bool
 }
 
 // NewBranchCleaner creates a new branch cleaner.
 // extraProtected adds to the default protected branch list.
-func NewBranchCleaner(client *bitbucket.Client, extraProtected []string) *BranchCleaner {
+func NewBranchCleaner(client provider.Provider, extraProtected []string) *BranchCleaner {
 	protected := make(map[string]bool, len(defaultProtectedBranches)+len(extraProtected))
 	for _, b := range defaultProtectedBranches {
 		protected[b] = true
@@ -72,8 +72,9 @@ func (bc *BranchCleaner) DeleteBranch(workspace string, repos []string, branchNa
 		go func(repoSlug string) {
 			defer wg.Done()
 
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
 			result := Result{RepoSlug: repoSlug, BranchName: branchName}
-			err := bc.client.DeleteBranch(workspace, repoSlug, branchName)
+			err := bc.client.DeleteBranch(ws, slug, branchName)
 			if err != nil {
 				errMsg := err.Error()
 				// Treat 404 (already deleted) as a warning, not failure
@@ -111,7 +112,8 @@ func (bc *BranchCleaner) DeleteMergedBranches(workspace string, repos []string)
 		go func(repoSlug string) {
 			defer wg.Done()
 
-			branches, err := bc.client.ListMergedPRBranches(workspace, repoSlug)
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			branches, err := bc.client.ListMergedPRBranches(ws, slug)
 			if err != nil {
 				mu.Lock()
 				results = append(results, Result{
@@ -134,7 +136,7 @@ func (bc *BranchCleaner) DeleteMergedBranches(workspace string, repos []string)
 					continue
 				}
 
-				err := bc.client.DeleteBranch(workspace, repoSlug, branch)
+				err := bc.client.DeleteBranch(ws, slug, branch)
 				if err != nil {
 					errMsg := err.Error()
 					if strings.Contains(errMsg, "404") {
@@ -168,44 +170,26 @@ func sortResults(results []Result) {
 	})
 }
 
-// PrintResults displays a colored summary of branch cleanup results.
+// PrintResults displays a summary of branch cleanup results in the current output mode.
 func PrintResults(results []Result) {
-	green := color.New(color.FgGreen).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	yellow := color.New(color.FgYellow).SprintFunc()
-	bold := color.New(color.Bold).SprintFunc()
-
-	succeeded := 0
-	failed := 0
-	skipped := 0
-
-	fmt.Println()
-	for _, r := range results {
+	rows := make([]render.Row, len(results))
+	for i, r := range results {
 		branchLabel := r.BranchName
 		if branchLabel == "" {
 			branchLabel = "(unknown)"
 		}
-
-		if r.Skipped {
-			skipped++
-			fmt.Printf("  %s %-25s %-30s %s\n", yellow("–"), r.RepoSlug, branchLabel, r.Error)
-		} else if r.Success {
-			succeeded++
-			msg := "deleted"
-			if r.Error != "" {
-				msg = r.Error // "already deleted"
+		row := render.Row{Repo: r.RepoSlug, Ref: branchLabel, Message: r.Error}
+		switch {
+		case r.Skipped:
+			row.Status = render.Skipped
+		case r.Success:
+			if row.Message == "" {
+				row.Message = "deleted" // else "already deleted"
 			}
-			fmt.Printf("  %s %-25s %-30s %s\n", green("✓"), r.RepoSlug, branchLabel, msg)
-		} else {
-			failed++
-			fmt.Printf("  %s %-25s %-30s %s\n", red("✗"), r.RepoSlug, branchLabel, r.Error)
+		default:
+			row.Status = render.Failed
 		}
+		rows[i] = row
 	}
-
-	fmt.Printf("\n%s %s deleted, %s skipped, %s failed\n",
-		bold("Summary:"),
-		green(fmt.Sprintf("%d", succeeded)),
-		yellow(fmt.Sprintf("%d", skipped)),
-		red(fmt.Sprintf("%d", failed)),
-	)
+	render.Print(render.Report{Rows: rows, Done: "deleted", Data: results})
 }
@@ -0,0 +1,149 @@
+// Package compare reports how far a branch is ahead of and behind its
+// destination in each repo.
+package compare
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+// Result holds the ahead/behind counts of a branch in one repo.
+type Result struct {
+	RepoSlug    string `json:"repo"`
+	Destination string `json:"destination,omitempty"`
+	Ahead       int    `json:"ahead"`                 // commits on the branch but not the destination
+	Behind      int    `json:"behind"`                // commits on the destination but not the branch
+	LastCommit  string `json:"last_commit,omitempty"` // date of the newest commit ahead of the destination, empty if none
+	Error       string `json:"error,omitempty"`
+}
+
+// Comparer computes ahead/behind reports across repos.
+type Comparer struct {
+	client provider.Provider
+}
+
+// NewComparer creates a new compare orchestrator.
+func NewComparer(client provider.Provider) *Comparer {
+	return &Comparer{client: client}
+}
+
+// Compare compares branchName with destination in each repo concurrently.
+// If destination is empty, each repo's development branch is used.
+func (c *Comparer) Compare(workspace string, repos []string, branchName, destination string) []Result {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []Result
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			result := c.compareOne(ws, slug, branchName, destination)
+			result.RepoSlug = repoSlug
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+
+	return results
+}
+
+func (c *Comparer) compareOne(workspace, repoSlug, branchName, destination string) Result {
+	dest := destination
+	if dest == "" {
+		var err error
+		if dest, err = provider.DevelopmentBranch(c.client, workspace, repoSlug); err != nil {
+			return Result{RepoSlug: repoSlug, Error: err.Error()}
+		}
+	}
+	result := Result{RepoSlug: repoSlug, Destination: dest}
+
+	ahead, err := c.client.ListCommits(workspace, repoSlug, branchName, dest)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	behind, err := c.client.ListCommits(workspace, repoSlug, dest, branchName)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Ahead = len(ahead)
+	result.Behind = len(behind)
+	if len(ahead) > 0 {
+		// Commits are newest first
+		result.LastCommit = ahead[0].Date
+	}
+	return result
+}
+
+// PrintResults displays the ahead/behind table. Repos where the branch has no
+// commits of its own, or has fallen behind, are highlighted.
+func PrintResults(results []Result, branchName string) {
+	if render.Output != render.Table {
+		var rows []render.Row
+		for _, r := range results {
+			if r.Error != "" {
+				rows = append(rows, render.Row{Repo: r.RepoSlug, Status: render.Failed, Message: r.Error})
+			}
+		}
+		render.Print(render.Report{Rows: rows, Data: results})
+		return
+	}
+
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	bold := color.New(color.Bold)
+
+	fmt.Println()
+	bold.Printf("  %-30s %-20s %6s %6s  %s\n", "REPO", "DESTINATION", "AHEAD", "BEHIND", "LAST COMMIT")
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			fmt.Printf("  %-30s %s\n", r.RepoSlug, red(r.Error))
+			continue
+		}
+
+		// Truncate to date only
+		last := r.LastCommit
+		if len(last) > 10 {
+			last = last[:10]
+		}
+
+		note := ""
+		switch {
+		case r.Ahead == 0:
+			last = "—"
+			note = yellow(fmt.Sprintf("no commits on %s", branchName))
+		case r.Behind > 0:
+			note = yellow(fmt.Sprintf("%s is behind", branchName))
+		}
+
+		fmt.Printf("  %-30s %-20s %6d %6d  %-11s %s\n", r.RepoSlug, r.Destination, r.Ahead, r.Behind, dim(last), note)
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%s %d repos could not be compared\n", red("✗"), failed)
+	}
+}
@@ -0,0 +1,74 @@
+package compare
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+type hostRewriteTransport struct {
+	base    http.RoundTripper
+	srvHost string
+}
+
+func (t *hostRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.URL.Scheme = "http"
+	cloned.URL.Host = t.srvHost
+	return t.base.RoundTrip(cloned)
+}
+
+func newComparerForServer(srv *httptest.Server) *Comparer {
+	transport := &hostRewriteTransport{base: http.DefaultTransport, srvHost: srv.Listener.Addr().String()}
+	authApplier := bitbucket.BearerAuth(func() (string, error) { return "test-token", nil })
+	return NewComparer(bitbucket.NewClientWithHTTPClient(&http.Client{Transport: transport}, authApplier))
+}
+
+func TestCompare(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		slug := strings.Split(r.URL.Path, "/")[4]
+		include := r.URL.Query().Get("include")
+
+		if slug == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","error":{"message":"Commit not found"}}`))
+			return
+		}
+
+		var commits []bitbucket.Commit
+		switch include {
+		case "feature/x":
+			if slug == "api" {
+				commits = []bitbucket.Commit{
+					{Hash: "c2", Date: "2026-03-02T10:00:00+00:00"},
+					{Hash: "c1", Date: "2026-03-01T10:00:00+00:00"},
+				}
+			}
+		case "develop":
+			commits = []bitbucket.Commit{{Hash: "d1"}}
+		}
+		json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{Values: commits})
+	}))
+	defer srv.Close()
+
+	results := newComparerForServer(srv).Compare("ws", []string{"web", "missing", "api"}, "feature/x", "develop")
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+
+	api, missing, web := results[0], results[1], results[2]
+	if api.Ahead != 2 || api.Behind != 1 || api.LastCommit != "2026-03-02T10:00:00+00:00" || api.Destination != "develop" {
+		t.Errorf("api = %+v", api)
+	}
+	if !strings.Contains(missing.Error, "Commit not found") {
+		t.Errorf("missing = %+v", missing)
+	}
+	if web.Ahead != 0 || web.Behind != 1 || web.LastCommit != "" {
+		t.Errorf("web = %+v", web)
+	}
+}
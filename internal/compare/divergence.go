@@ -0,0 +1,267 @@
+package compare
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// maxBranchChecks bounds the branches being compared at once across all repos.
+const maxBranchChecks = 8
+
+// DivergenceOptions selects the branches a divergence report lists.
+type DivergenceOptions struct {
+	MinBehind int    // list branches more than this many commits behind the base
+	Base      string // branch to compare against; empty means each repo's main branch
+	Pattern   string // only branches matching this glob, e.g. "feature/*"; empty means all
+}
+
+// Divergence is a branch that has fallen behind the base branch of its repo.
+// Counting stops once a branch qualifies, so Behind can be a lower bound.
+type Divergence struct {
+	Branch       string `json:"branch"`
+	Ahead        int    `json:"ahead"`                   // commits on the branch but not the base
+	Behind       int    `json:"behind"`                  // commits on the base but not the branch
+	AheadCapped  bool   `json:"ahead_capped,omitempty"`  // Ahead is a lower bound
+	BehindCapped bool   `json:"behind_capped,omitempty"` // Behind is a lower bound
+	LastCommit   string `json:"last_commit,omitempty"`   // date of the branch tip
+}
+
+// RepoDivergence holds the diverged branches of one repo, most behind first.
+type RepoDivergence struct {
+	RepoSlug string       `json:"repo"`
+	Base     string       `json:"base"`
+	Branches []Divergence `json:"branches"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// Diverged finds, in each repo concurrently, the branches more than
+// opts.MinBehind commits behind the base branch. Branches with no commits of
+// their own are left out: they have nothing to rebase. The branches of all
+// repos are compared concurrently, at most maxBranchChecks at a time.
+func (c *Comparer) Diverged(workspace string, repos []string, opts DivergenceOptions) []RepoDivergence {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []RepoDivergence
+	)
+	slots := make(chan struct{}, maxBranchChecks)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			result := c.divergedOne(ws, slug, opts, slots)
+			result.RepoSlug = repoSlug
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+	return results
+}
+
+func (c *Comparer) divergedOne(workspace, repoSlug string, opts DivergenceOptions, slots chan struct{}) RepoDivergence {
+	result := RepoDivergence{RepoSlug: repoSlug, Base: opts.Base}
+	if result.Base == "" {
+		// Only a repo without a main branch is compared against the fallback;
+		// one that cannot be read is reported, not compared against a guess
+		repo, err := c.client.GetRepository(workspace, repoSlug)
+		if err != nil {
+			result.Error = fmt.Sprintf("could not determine the base branch: %v", err)
+			return result
+		}
+		result.Base = provider.FallbackBranch
+		if repo.MainBranch != nil && repo.MainBranch.Name != "" {
+			result.Base = repo.MainBranch.Name
+		}
+	}
+	base := result.Base
+
+	branches, err := c.client.ListBranches(workspace, repoSlug)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, b := range branches {
+		if b.Name == base {
+			continue
+		}
+		if opts.Pattern != "" {
+			if ok, _ := path.Match(opts.Pattern, b.Name); !ok {
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(b bitbucket.Branch) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			d, ok, err := c.divergence(workspace, repoSlug, base, b, opts.MinBehind)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+				}
+			case ok:
+				result.Branches = append(result.Branches, d)
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		result.Error = firstErr.Error()
+		result.Branches = nil
+		return result
+	}
+	sort.Slice(result.Branches, func(i, j int) bool {
+		a, b := result.Branches[i], result.Branches[j]
+		if a.Behind != b.Behind {
+			return a.Behind > b.Behind
+		}
+		return a.Branch < b.Branch
+	})
+	return result
+}
+
+// divergence counts how far b is behind and ahead of base. ok is false when
+// the branch is not more than minBehind behind or has no commits of its own.
+func (c *Comparer) divergence(workspace, repoSlug, base string, b bitbucket.Branch, minBehind int) (d Divergence, ok bool, err error) {
+	// Most branches are close to the base, so count behind first and
+	// only count ahead for the ones that qualify
+	behind, behindCapped, err := c.countCommits(workspace, repoSlug, base, b.Name, minBehind)
+	if err != nil || behind <= minBehind {
+		return d, false, err
+	}
+	ahead, aheadCapped, err := c.countCommits(workspace, repoSlug, b.Name, base, -1)
+	if err != nil || ahead == 0 {
+		return d, false, err
+	}
+	return Divergence{
+		Branch:       b.Name,
+		Ahead:        ahead,
+		Behind:       behind,
+		AheadCapped:  aheadCapped,
+		BehindCapped: behindCapped,
+		LastCommit:   b.Target.Date,
+	}, true, nil
+}
+
+// countCommits counts the commits on include but not exclude. Providers that
+// page their commit lists stop once more than atLeast are found (never when
+// atLeast is negative) and report the count as capped.
+func (c *Comparer) countCommits(workspace, repoSlug, include, exclude string, atLeast int) (int, bool, error) {
+	if counter, ok := c.client.(provider.CommitCountService); ok {
+		return counter.CountCommits(workspace, repoSlug, include, exclude, atLeast)
+	}
+	commits, err := c.client.ListCommits(workspace, repoSlug, include, exclude)
+	return len(commits), false, err
+}
+
+// PrintDivergence displays the diverged branches per repo, followed by a total.
+func PrintDivergence(results []RepoDivergence, minBehind int) {
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	bold := color.New(color.Bold)
+
+	fmt.Println()
+	bold.Printf("  %-30s %-35s %-12s %6s %6s  %s\n", "REPO", "BRANCH", "BASE", "BEHIND", "AHEAD", "LAST COMMIT")
+
+	total, repos, failed := 0, 0, 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			fmt.Printf("  %-30s %s\n", r.RepoSlug, red(r.Error))
+			continue
+		}
+		if len(r.Branches) > 0 {
+			repos++
+		}
+		for _, b := range r.Branches {
+			total++
+			fmt.Printf("  %-30s %-35s %-12s %s %6s  %s\n",
+				r.RepoSlug, b.Branch, r.Base, yellow(fmt.Sprintf("%6s", countLabel(b.Behind, b.BehindCapped))),
+				countLabel(b.Ahead, b.AheadCapped), dim(dateOnly(b.LastCommit)))
+		}
+	}
+
+	if total == 0 {
+		fmt.Printf("  No branches more than %d commits behind.\n", minBehind)
+	} else {
+		fmt.Printf("\n%s %d branches more than %d commits behind in %d repos\n", bold.Sprint("Summary:"), total, minBehind, repos)
+	}
+	if failed > 0 {
+		fmt.Printf("%s %d repos could not be checked\n", red("✗"), failed)
+	}
+}
+
+// WriteDivergenceCSV writes one row per diverged branch. Repos that could not
+// be checked get a row with only the error.
+func WriteDivergenceCSV(w io.Writer, results []RepoDivergence) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"repo", "branch", "base", "behind", "ahead", "last_commit", "error"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			if err := out.Write([]string{r.RepoSlug, "", r.Base, "", "", "", r.Error}); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, b := range r.Branches {
+			record := []string{r.RepoSlug, b.Branch, r.Base, countLabel(b.Behind, b.BehindCapped), countLabel(b.Ahead, b.AheadCapped), dateOnly(b.LastCommit), ""}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// countLabel formats a commit count, marking a lower bound with "+", e.g. "2000+".
+func countLabel(n int, capped bool) string {
+	if capped {
+		return strconv.Itoa(n) + "+"
+	}
+	return strconv.Itoa(n)
+}
+
+// dateOnly truncates an RFC 3339 timestamp to its date.
+func dateOnly(ts string) string {
+	if len(ts) > 10 {
+		return ts[:10]
+	}
+	return ts
+}
@@ -0,0 +1,124 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestDiverged(t *testing.T) {
+	commits := func(n int) []bitbucket.Commit { return make([]bitbucket.Commit, n) }
+	// include/exclude → commits, for repo "api" whose main branch is main
+	counts := map[string]int{
+		"main/feature/old":   30, // behind
+		"feature/old/main":   4,  // ahead
+		"main/feature/fresh": 2,
+		"main/feature/done":  40,
+		"feature/done/main":  0, // merged, nothing to rebase
+		"main/hotfix":        60,
+		"hotfix/main":        1,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/repositories/ws/api"):
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: "api", MainBranch: &bitbucket.BranchRef{Name: "main"}})
+		case strings.HasSuffix(r.URL.Path, "/api/refs/branches"):
+			json.NewEncoder(w).Encode(bitbucket.PaginatedBranches{Values: []bitbucket.Branch{
+				{Name: "main"},
+				{Name: "feature/old", Target: bitbucket.BranchTarget{Date: "2026-01-05T10:00:00+00:00"}},
+				{Name: "feature/fresh"},
+				{Name: "feature/done"},
+				{Name: "hotfix"},
+			}})
+		case strings.HasSuffix(r.URL.Path, "/api/commits"):
+			key := r.URL.Query().Get("include") + "/" + r.URL.Query().Get("exclude")
+			json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{Values: commits(counts[key])})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","error":{"message":"Repository not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := newComparerForServer(srv)
+	results := c.Diverged("ws", []string{"gone", "api"}, DivergenceOptions{MinBehind: 20})
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	api, gone := results[0], results[1]
+	if api.Base != "main" || api.Error != "" || len(api.Branches) != 2 {
+		t.Fatalf("api = %+v, want hotfix and feature/old", api)
+	}
+	if api.Branches[0].Branch != "hotfix" || api.Branches[0].Behind != 60 {
+		t.Errorf("most behind = %+v, want hotfix", api.Branches[0])
+	}
+	if b := api.Branches[1]; b.Branch != "feature/old" || b.Behind != 30 || b.Ahead != 4 || b.LastCommit != "2026-01-05T10:00:00+00:00" {
+		t.Errorf("feature/old = %+v", b)
+	}
+	if gone.Base != "" || !strings.Contains(gone.Error, "Repository not found") {
+		t.Errorf("gone = %+v, want error and no guessed base", gone)
+	}
+
+	matched := c.Diverged("ws", []string{"api"}, DivergenceOptions{MinBehind: 20, Pattern: "feature/*"})
+	if len(matched[0].Branches) != 1 || matched[0].Branches[0].Branch != "feature/old" {
+		t.Errorf("--match feature/* = %+v", matched[0].Branches)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDivergenceCSV(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "repo,branch,base,behind,ahead,last_commit,error\n" +
+		"api,hotfix,main,60,1,,\n" +
+		"api,feature/old,main,30,4,2026-01-05,\n"
+	if got := buf.String(); !strings.HasPrefix(got, wantCSV) || !strings.Contains(got, "gone,,,,,,could not determine the base branch") {
+		t.Errorf("CSV =\n%s", got)
+	}
+}
+
+func TestDiverged_StopsCountingPastThreshold(t *testing.T) {
+	behindPages := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/refs/branches"):
+			json.NewEncoder(w).Encode(bitbucket.PaginatedBranches{Values: []bitbucket.Branch{{Name: "main"}, {Name: "stale"}}})
+		case strings.HasSuffix(r.URL.Path, "/api/commits") && r.URL.Query().Get("include") == "main":
+			behindPages++
+			json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{
+				Values: make([]bitbucket.Commit, 30),
+				Next:   "https://api.bitbucket.org/2.0/repositories/ws/api/commits?include=main&exclude=stale&page=2",
+			})
+		case strings.HasSuffix(r.URL.Path, "/api/commits"):
+			json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{Values: make([]bitbucket.Commit, 2)})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	results := newComparerForServer(srv).Diverged("ws", []string{"api"}, DivergenceOptions{MinBehind: 20, Base: "main"})
+	if len(results) != 1 || len(results[0].Branches) != 1 {
+		t.Fatalf("results = %+v", results)
+	}
+	if behindPages != 1 {
+		t.Errorf("read %d pages of behind commits, want 1", behindPages)
+	}
+	if b := results[0].Branches[0]; b.Behind != 30 || !b.BehindCapped || b.Ahead != 2 || b.AheadCapped {
+		t.Errorf("stale = %+v, want 30+ behind and 2 ahead", b)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDivergenceCSV(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "api,stale,main,30+,2,,") {
+		t.Errorf("CSV =\n%s", buf.String())
+	}
+}
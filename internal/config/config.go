@@ -4,29 +4,46 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config represents the .buck.yaml configuration.
 type Config struct {
-	Workspace string              `mapstructure:"workspace"`
-	Auth      AuthConfig          `mapstructure:"auth"`
-	OAuth     OAuthConfig         `mapstructure:"oauth"`
-	ApiToken  ApiTokenConfig      `mapstructure:"api_token"`
-	Groups    map[string][]string `mapstructure:"groups"`
-	Defaults  Defaults            `mapstructure:"defaults"`
+	Workspace   string              `mapstructure:"workspace"`
+	Provider    string              `mapstructure:"provider"` // "bitbucket" (default), "github" or "gitlab"
+	Auth        AuthConfig          `mapstructure:"auth"`
+	OAuth       OAuthConfig         `mapstructure:"oauth"`
+	ApiToken    ApiTokenConfig      `mapstructure:"api_token"`
+	AppPassword AppPasswordConfig   `mapstructure:"app_password"`
+	GitHub      GitHubConfig        `mapstructure:"github"`
+	GitLab      GitLabConfig        `mapstructure:"gitlab"`
+	HTTP        HTTPConfig          `mapstructure:"http"`
+	Groups      map[string][]string `mapstructure:"groups"`
+	Aliases     map[string]string   `mapstructure:"aliases"` // name → command line, e.g. ship: "create --with-pr -g backend"
+	Hooks       map[string][]Hook   `mapstructure:"hooks"`   // keyed by event, e.g. pre_create, post_pr
+	Webhooks    []Webhook           `mapstructure:"webhooks"`
+	PR          PRConfig            `mapstructure:"pr"`
+	Defaults    Defaults            `mapstructure:"defaults"`
+
+	// UpdateCheck lets 'buck version' look up the latest release; nil: on.
+	UpdateCheck *bool `mapstructure:"update_check"`
 }
 
 // AuthConfig holds the authentication method selection.
 type AuthConfig struct {
-	Method string `mapstructure:"method"` // "oauth" (default) or "api_token"
+	Method string `mapstructure:"method"` // "api_token" (default), "oauth" or "app_password"
 }
 
 // OAuthConfig holds OAuth consumer credentials.
 type OAuthConfig struct {
 	ClientID     string `mapstructure:"client_id"`
 	ClientSecret string `mapstructure:"client_secret"`
+	CallbackPage string `mapstructure:"callback_page"` // HTML template shown after login; empty: built-in page
+	CallbackPort int    `mapstructure:"callback_port"` // local port for the login redirect; 0: 9876
+	Profile      string `mapstructure:"profile"`       // names the token file; empty: the client ID
 }
 
 // ApiTokenConfig holds Bitbucket API token credentials.
@@ -35,10 +52,124 @@ type ApiTokenConfig struct {
 	Token string `mapstructure:"token"`
 }
 
+// AppPasswordConfig holds legacy Bitbucket app password credentials.
+type AppPasswordConfig struct {
+	Username string `mapstructure:"username"` // Bitbucket username, not the account email
+	Password string `mapstructure:"password"`
+}
+
+// GitHubConfig holds GitHub provider settings.
+type GitHubConfig struct {
+	Token   string `mapstructure:"token"`
+	BaseURL string `mapstructure:"base_url"` // GitHub Enterprise API URL, e.g. https://ghe.example.com/api/v3
+}
+
+// GitLabConfig holds GitLab provider settings.
+type GitLabConfig struct {
+	Token   string `mapstructure:"token"`
+	BaseURL string `mapstructure:"base_url"` // self-managed API URL, e.g. https://gitlab.example.com/api/v4
+}
+
+// HTTPConfig holds settings for the API client. Durations use Go syntax, e.g. 90s or 5m.
+type HTTPConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout"`       // per request, including the response body
+	TotalTimeout time.Duration `mapstructure:"total_timeout"` // for the whole command; 0: no limit
+	Proxy        string        `mapstructure:"proxy"`         // proxy URL; empty: HTTPS_PROXY from the environment
+	CABundle     string        `mapstructure:"ca_bundle"`     // PEM file of extra trusted CA certificates
+	MaxPages     int           `mapstructure:"max_pages"`     // pages fetched when listing repos; 0: 50
+	RateLimit    float64       `mapstructure:"rate_limit"`    // API requests per second; 0: no limit
+	Retry        RetryConfig   `mapstructure:"retry"`
+}
+
+// RetryConfig controls how failed GET requests are retried. Zero fields take
+// the Default* values below.
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"` // per request, including the first; 1: no retries
+	BaseDelay   time.Duration `mapstructure:"base_delay"`   // before the first retry, doubling for each further one
+	Jitter      float64       `mapstructure:"jitter"`       // fraction of each delay randomized, 0 to 1; negative: none
+	StatusCodes []int         `mapstructure:"status_codes"` // responses retried; network errors always are
+}
+
+// Hook is a shell command or URL run before or after create/pr/merge.
+type Hook struct {
+	Run string `mapstructure:"run"` // shell command; run context is passed via BUCK_* env vars and JSON on stdin
+	URL string `mapstructure:"url"` // receives the run context as a JSON POST
+}
+
+// Webhook is a repository webhook that 'buck webhooks sync' keeps in place.
+type Webhook struct {
+	Description string   `mapstructure:"description"`
+	URL         string   `mapstructure:"url"`
+	Events      []string `mapstructure:"events"` // e.g. repo:push, pullrequest:created
+}
+
+// PRConfig holds pull request creation settings.
+type PRConfig struct {
+	Description DescriptionConfig `mapstructure:"description"`
+	Tasks       []string          `mapstructure:"tasks"` // checklist added to every created PR
+
+	// Destinations maps repo slugs to the destination branch used instead of
+	// the default. Keys are lowercased when loaded.
+	Destinations map[string]string `mapstructure:"destinations"`
+}
+
+// DescriptionConfig controls how commits are listed in generated PR descriptions.
+type DescriptionConfig struct {
+	Hashes        bool `mapstructure:"hashes"`          // short hash linked to the commit
+	Authors       bool `mapstructure:"authors"`         // author name after each commit
+	Bodies        bool `mapstructure:"bodies"`          // full commit messages
+	GroupByAuthor bool `mapstructure:"group_by_author"` // one section per author
+}
+
 // Defaults holds default branch creation settings.
 type Defaults struct {
-	SourceBranch string `mapstructure:"source_branch"`
-	BranchPrefix string `mapstructure:"branch_prefix"`
+	SourceBranch       string `mapstructure:"source_branch"`       // empty: each repo's development branch
+	BranchPrefix       string `mapstructure:"branch_prefix"`       // prepended to branch names in create and pr, e.g. "feature/"
+	ConfirmThreshold   *int   `mapstructure:"confirm_threshold"`   // prompt before mutating more repos than this; nil: DefaultConfirmThreshold
+	AmbiguityThreshold int    `mapstructure:"ambiguity_threshold"` // prompt when one --repos pattern matches more repos than this
+}
+
+const (
+	// DefaultConfirmThreshold is used when defaults.confirm_threshold is not set.
+	DefaultConfirmThreshold = 5
+	// DefaultAmbiguityThreshold is used when defaults.ambiguity_threshold is not set.
+	DefaultAmbiguityThreshold = 5
+	// DefaultHTTPTimeout is used when http.timeout is not set.
+	DefaultHTTPTimeout = 30 * time.Second
+	// DefaultRetryAttempts is used when http.retry.max_attempts is not set.
+	DefaultRetryAttempts = 3
+	// DefaultRetryBaseDelay is used when http.retry.base_delay is not set.
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// DefaultRetryJitter is used when http.retry.jitter is not set.
+	DefaultRetryJitter = 0.5
+)
+
+// DefaultRetryStatusCodes are retried when http.retry.status_codes is not
+// set: rate limiting and the gateway errors of a busy or restarting backend.
+var DefaultRetryStatusCodes = []int{429, 502, 503, 504}
+
+// ProviderName returns the configured hosting provider, defaulting to "bitbucket".
+func (c *Config) ProviderName() string {
+	if c.Provider == "" {
+		return "bitbucket"
+	}
+	return c.Provider
+}
+
+// UpdateCheckEnabled reports whether 'buck version' may look up the latest
+// release, which it does unless update_check is false.
+func (c *Config) UpdateCheckEnabled() bool {
+	return c.UpdateCheck == nil || *c.UpdateCheck
+}
+
+// ConfirmLimit returns how many repos a run may change without asking:
+// defaults.confirm_threshold, where 0 asks for every run and a negative
+// value never asks, or DefaultConfirmThreshold when it is not set.
+func (d Defaults) ConfirmLimit() int {
+	if d.ConfirmThreshold == nil {
+		return DefaultConfirmThreshold
+	}
+	return *d.ConfirmThreshold
 }
 
 // AuthMethod returns the configured auth method, defaulting to "api_token".
@@ -52,14 +183,19 @@ func (c *Config) AuthMethod() string {
 var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
 
 // expandEnvVars replaces ${VAR} patterns with environment variable values.
+// Keyring references (${keyring:name}) are left for resolveKeyringRefs.
 func expandEnvVars(val string) string {
 	return envVarPattern.ReplaceAllStringFunc(val, func(match string) string {
 		varName := envVarPattern.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(varName, "keyring:") {
+			return match
+		}
 		return os.Getenv(varName)
 	})
 }
 
-// Load reads the config from Viper and expands env vars.
+// Load reads the config from Viper, expands env vars and resolves keyring
+// references.
 func Load() (*Config, error) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
@@ -69,19 +205,82 @@ func Load() (*Config, error) {
 	// Expand env vars in OAuth fields
 	cfg.OAuth.ClientID = expandEnvVars(cfg.OAuth.ClientID)
 	cfg.OAuth.ClientSecret = expandEnvVars(cfg.OAuth.ClientSecret)
+	cfg.OAuth.CallbackPage = expandEnvVars(cfg.OAuth.CallbackPage)
 
 	// Expand env vars in API Token fields
 	cfg.ApiToken.Email = expandEnvVars(cfg.ApiToken.Email)
 	cfg.ApiToken.Token = expandEnvVars(cfg.ApiToken.Token)
 
+	// Without configured API token credentials, fall back to the variables the
+	// example config uses, so no .buck.yaml is needed inside a checkout
+	if cfg.ApiToken.Email == "" && cfg.ApiToken.Token == "" {
+		cfg.ApiToken.Email = os.Getenv("BITBUCKET_EMAIL")
+		cfg.ApiToken.Token = os.Getenv("BITBUCKET_API_TOKEN")
+	}
+
+	// Expand env vars in app password fields
+	cfg.AppPassword.Username = expandEnvVars(cfg.AppPassword.Username)
+	cfg.AppPassword.Password = expandEnvVars(cfg.AppPassword.Password)
+
+	// Expand env vars in GitHub fields
+	cfg.GitHub.Token = expandEnvVars(cfg.GitHub.Token)
+
+	// Expand env vars in GitLab fields
+	cfg.GitLab.Token = expandEnvVars(cfg.GitLab.Token)
+
+	// Expand env vars in HTTP fields (a proxy URL may carry credentials)
+	cfg.HTTP.Proxy = expandEnvVars(cfg.HTTP.Proxy)
+	cfg.HTTP.CABundle = expandEnvVars(cfg.HTTP.CABundle)
+
+	// Look up credentials kept in the OS keyring
+	if err := resolveKeyringRefs(&cfg); err != nil {
+		return nil, err
+	}
+
+	// Expand env vars in hook URLs (they often carry a secret)
+	for _, hooks := range cfg.Hooks {
+		for i := range hooks {
+			hooks[i].URL = expandEnvVars(hooks[i].URL)
+		}
+	}
+
+	// Expand env vars in webhook URLs
+	for i := range cfg.Webhooks {
+		cfg.Webhooks[i].URL = expandEnvVars(cfg.Webhooks[i].URL)
+	}
+
 	// Set defaults
-	if cfg.Defaults.SourceBranch == "" {
-		cfg.Defaults.SourceBranch = "master"
+	if cfg.Defaults.AmbiguityThreshold == 0 {
+		cfg.Defaults.AmbiguityThreshold = DefaultAmbiguityThreshold
+	}
+	if cfg.HTTP.Timeout == 0 {
+		cfg.HTTP.Timeout = DefaultHTTPTimeout
+	}
+	if cfg.HTTP.Retry.MaxAttempts == 0 {
+		cfg.HTTP.Retry.MaxAttempts = DefaultRetryAttempts
+	}
+	if cfg.HTTP.Retry.BaseDelay == 0 {
+		cfg.HTTP.Retry.BaseDelay = DefaultRetryBaseDelay
+	}
+	if cfg.HTTP.Retry.Jitter == 0 {
+		cfg.HTTP.Retry.Jitter = DefaultRetryJitter
+	}
+	if len(cfg.HTTP.Retry.StatusCodes) == 0 {
+		cfg.HTTP.Retry.StatusCodes = DefaultRetryStatusCodes
 	}
 
 	return &cfg, nil
 }
 
+// PrefixBranch prepends the configured branch prefix to name, unless name
+// already starts with it or carries a prefix of its own ("hotfix/x").
+func (d Defaults) PrefixBranch(name string) string {
+	if d.BranchPrefix == "" || strings.HasPrefix(name, d.BranchPrefix) || strings.Contains(name, "/") {
+		return name
+	}
+	return d.BranchPrefix + name
+}
+
 // GetReposForGroup returns repo slugs for a named group.
 func (c *Config) GetReposForGroup(name string) ([]string, error) {
 	repos, ok := c.Groups[name]
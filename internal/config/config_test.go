@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -40,13 +42,14 @@ func TestExpandEnvVars(t *testing.T) {
 func TestLoad_DefaultSourceBranch(t *testing.T) {
 	resetViper()
 
-	// Load with no source_branch set — should default to "master"
+	// Load with no source_branch set — stays empty so each repo's
+	// development branch is used
 	cfg, err := Load()
 	if err != nil {
 		t.Fatalf("Load() error: %v", err)
 	}
-	if cfg.Defaults.SourceBranch != "master" {
-		t.Errorf("default SourceBranch = %q, want %q", cfg.Defaults.SourceBranch, "master")
+	if cfg.Defaults.SourceBranch != "" {
+		t.Errorf("default SourceBranch = %q, want empty", cfg.Defaults.SourceBranch)
 	}
 }
 
@@ -178,6 +181,57 @@ func TestLoad_EnvVarExpansionInApiToken(t *testing.T) {
 	}
 }
 
+func TestLoad_ApiTokenFromEnvWithoutConfig(t *testing.T) {
+	resetViper()
+	t.Setenv("BITBUCKET_EMAIL", "user@example.com")
+	t.Setenv("BITBUCKET_API_TOKEN", "env-token")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.ApiToken.Email != "user@example.com" || cfg.ApiToken.Token != "env-token" {
+		t.Errorf("ApiToken = %+v, want credentials from the environment", cfg.ApiToken)
+	}
+
+	// Configured credentials win
+	viper.Set("api_token.email", "other@example.com")
+	viper.Set("api_token.token", "config-token")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.ApiToken.Token != "config-token" {
+		t.Errorf("Token = %q, want the configured one", cfg.ApiToken.Token)
+	}
+}
+
+func TestLoad_EnvVarExpansionInAppPassword(t *testing.T) {
+	resetViper()
+
+	os.Setenv("BB_APP_PASSWORD", "app-secret")
+	defer os.Unsetenv("BB_APP_PASSWORD")
+
+	viper.Set("auth.method", "app_password")
+	viper.Set("app_password.username", "jdoe")
+	viper.Set("app_password.password", "${BB_APP_PASSWORD}")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.AppPassword.Username != "jdoe" {
+		t.Errorf("Username = %q, want %q", cfg.AppPassword.Username, "jdoe")
+	}
+	if cfg.AppPassword.Password != "app-secret" {
+		t.Errorf("Password = %q, want %q", cfg.AppPassword.Password, "app-secret")
+	}
+	if cfg.AuthMethod() != "app_password" {
+		t.Errorf("AuthMethod() = %q, want %q", cfg.AuthMethod(), "app_password")
+	}
+}
+
 func TestGetReposForGroup_EmptyGroups(t *testing.T) {
 	cfg := &Config{
 		Groups: map[string][]string{},
@@ -188,3 +242,213 @@ func TestGetReposForGroup_EmptyGroups(t *testing.T) {
 		t.Fatal("expected error for empty groups, got nil")
 	}
 }
+
+func TestLoad_DefaultConfirmThreshold(t *testing.T) {
+	resetViper()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := cfg.Defaults.ConfirmLimit(); got != DefaultConfirmThreshold {
+		t.Errorf("ConfirmLimit() = %d, want %d", got, DefaultConfirmThreshold)
+	}
+	if cfg.Defaults.AmbiguityThreshold != DefaultAmbiguityThreshold {
+		t.Errorf("AmbiguityThreshold = %d, want %d", cfg.Defaults.AmbiguityThreshold, DefaultAmbiguityThreshold)
+	}
+}
+
+func TestLoad_KeepsExplicitConfirmThreshold(t *testing.T) {
+	resetViper()
+	viper.Set("defaults.confirm_threshold", 20)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := cfg.Defaults.ConfirmLimit(); got != 20 {
+		t.Errorf("ConfirmLimit() = %d, want 20", got)
+	}
+}
+
+func TestLoad_ZeroConfirmThresholdAsksAlways(t *testing.T) {
+	resetViper()
+	viper.Set("defaults.confirm_threshold", 0)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := cfg.Defaults.ConfirmLimit(); got != 0 {
+		t.Errorf("ConfirmLimit() = %d, want 0 (prompt for any count)", got)
+	}
+}
+
+func TestLoad_HTTPTimeouts(t *testing.T) {
+	resetViper()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.HTTP.Timeout != DefaultHTTPTimeout || cfg.HTTP.TotalTimeout != 0 {
+		t.Errorf("defaults = %+v", cfg.HTTP)
+	}
+
+	viper.Set("http.timeout", "90s")
+	viper.Set("http.total_timeout", "10m")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.HTTP.Timeout != 90*time.Second || cfg.HTTP.TotalTimeout != 10*time.Minute {
+		t.Errorf("HTTP = %+v", cfg.HTTP)
+	}
+}
+
+func TestLoad_HTTPRetry(t *testing.T) {
+	resetViper()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	r := cfg.HTTP.Retry
+	if r.MaxAttempts != DefaultRetryAttempts || r.BaseDelay != DefaultRetryBaseDelay || r.Jitter != DefaultRetryJitter ||
+		fmt.Sprint(r.StatusCodes) != fmt.Sprint(DefaultRetryStatusCodes) {
+		t.Errorf("defaults = %+v", r)
+	}
+
+	viper.Set("http.retry.max_attempts", 5)
+	viper.Set("http.retry.base_delay", "2s")
+	viper.Set("http.retry.jitter", -1)
+	viper.Set("http.retry.status_codes", []int{500, 503})
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	r = cfg.HTTP.Retry
+	if r.MaxAttempts != 5 || r.BaseDelay != 2*time.Second || r.Jitter != -1 || fmt.Sprint(r.StatusCodes) != "[500 503]" {
+		t.Errorf("Retry = %+v", r)
+	}
+}
+
+func TestUpdateCheckEnabled(t *testing.T) {
+	resetViper()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !cfg.UpdateCheckEnabled() {
+		t.Error("update check should default to on")
+	}
+
+	viper.Set("update_check", false)
+	if cfg, _ = Load(); cfg.UpdateCheckEnabled() {
+		t.Error("update_check: false should turn the check off")
+	}
+}
+
+func TestProviderName_DefaultsToBitbucket(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.ProviderName(); got != "bitbucket" {
+		t.Errorf("ProviderName() = %q, want %q", got, "bitbucket")
+	}
+}
+
+func TestLoad_GitHubTokenExpansion(t *testing.T) {
+	resetViper()
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_secret")
+	viper.Set("provider", "github")
+	viper.Set("github.token", "${TEST_GITHUB_TOKEN}")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.ProviderName() != "github" {
+		t.Errorf("ProviderName() = %q, want github", cfg.ProviderName())
+	}
+	if cfg.GitHub.Token != "ghp_secret" {
+		t.Errorf("GitHub.Token = %q, want %q", cfg.GitHub.Token, "ghp_secret")
+	}
+}
+
+func TestLoad_GitLabTokenExpansion(t *testing.T) {
+	resetViper()
+	t.Setenv("TEST_GITLAB_TOKEN", "glpat_secret")
+	viper.Set("provider", "gitlab")
+	viper.Set("gitlab.token", "${TEST_GITLAB_TOKEN}")
+	viper.Set("gitlab.base_url", "https://git.example.com/api/v4")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.GitLab.Token != "glpat_secret" {
+		t.Errorf("GitLab.Token = %q, want %q", cfg.GitLab.Token, "glpat_secret")
+	}
+	if cfg.GitLab.BaseURL != "https://git.example.com/api/v4" {
+		t.Errorf("GitLab.BaseURL = %q", cfg.GitLab.BaseURL)
+	}
+}
+
+func TestLoad_HooksExpandURL(t *testing.T) {
+	resetViper()
+	t.Setenv("TEST_HOOK_TOKEN", "s3cret")
+	viper.Set("hooks", map[string]any{
+		"pre_create": []map[string]any{{"run": "./check.sh"}},
+		"post_pr":    []map[string]any{{"url": "https://hooks.example.com/${TEST_HOOK_TOKEN}"}},
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Hooks["pre_create"]) != 1 || cfg.Hooks["pre_create"][0].Run != "./check.sh" {
+		t.Errorf("pre_create = %+v", cfg.Hooks["pre_create"])
+	}
+	if got := cfg.Hooks["post_pr"][0].URL; got != "https://hooks.example.com/s3cret" {
+		t.Errorf("post_pr URL = %q", got)
+	}
+}
+
+func TestLoad_WebhooksExpandURL(t *testing.T) {
+	resetViper()
+	t.Setenv("TEST_CI_TOKEN", "abc")
+	viper.Set("webhooks", []map[string]any{
+		{"description": "CI", "url": "https://ci.example.com/${TEST_CI_TOKEN}", "events": []string{"repo:push"}},
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Webhooks) != 1 {
+		t.Fatalf("Webhooks = %+v", cfg.Webhooks)
+	}
+	w := cfg.Webhooks[0]
+	if w.URL != "https://ci.example.com/abc" || w.Description != "CI" || len(w.Events) != 1 || w.Events[0] != "repo:push" {
+		t.Errorf("Webhook = %+v", w)
+	}
+}
+
+func TestPrefixBranch(t *testing.T) {
+	d := Defaults{BranchPrefix: "feature/"}
+	tests := []struct{ name, want string }{
+		{"login", "feature/login"},
+		{"feature/login", "feature/login"},
+		{"hotfix/crash", "hotfix/crash"},
+	}
+	for _, tt := range tests {
+		if got := d.PrefixBranch(tt.name); got != tt.want {
+			t.Errorf("PrefixBranch(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := (Defaults{}).PrefixBranch("login"); got != "login" {
+		t.Errorf("no prefix configured = %q, want login", got)
+	}
+	if got := (Defaults{BranchPrefix: "jdoe-"}).PrefixBranch("jdoe-login"); got != "jdoe-login" {
+		t.Errorf("already prefixed = %q, want jdoe-login", got)
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// flatKeys maps settings of the first release, when written flat at the top
+// level of a hand-edited config, to the section buck reads them from. No
+// release read them there, so they were silently ignored; settings added
+// since were only ever documented nested and are not listed.
+var flatKeys = []struct {
+	key  string
+	path []string
+}{
+	{"client_id", []string{"oauth", "client_id"}},
+	{"client_secret", []string{"oauth", "client_secret"}},
+	{"email", []string{"api_token", "email"}},
+	{"source_branch", []string{"defaults", "source_branch"}},
+	{"branch_prefix", []string{"defaults", "branch_prefix"}},
+}
+
+// Migrate upgrades old config shapes in the YAML config data:
+//   - first-release settings written at the top level (client_id,
+//     source_branch, ...) and dotted keys ("oauth.client_id: x") move under their sections
+//   - api_token given as a plain string becomes api_token.token
+//   - with replaceAppPassword, app_password credentials are replaced by an
+//     api_token section
+//
+// It returns the rewritten config and one note per change, or data itself
+// and no notes when nothing needed upgrading. Old keys that could not be
+// moved are reported in left and do not count as changes. Comments are kept.
+func Migrate(data []byte, replaceAppPassword bool) (out []byte, notes, left []string, err error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil, nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	move := func(key string, path []string) bool {
+		note, moved := moveKey(root, key, path)
+		switch {
+		case moved:
+			notes = append(notes, note)
+		case note != "":
+			left = append(left, note)
+		}
+		return moved
+	}
+
+	if token, _ := lookup(root, "api_token"); token != nil && token.Kind == yaml.ScalarNode && token.Value != "" {
+		*token = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "token"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: token.Value, Style: token.Style},
+		}}
+		notes = append(notes, "moved api_token to api_token.token")
+	}
+	for _, f := range flatKeys {
+		move(f.key, f.path)
+	}
+	for i := 0; i+1 < len(root.Content); {
+		key := root.Content[i].Value
+		if !strings.Contains(key, ".") || !move(key, strings.Split(key, ".")) {
+			i += 2 // left in place
+		}
+	}
+	if replaceAppPassword {
+		notes = append(notes, migrateAppPassword(root)...)
+	}
+
+	if len(notes) == 0 {
+		return data, nil, left, nil
+	}
+	if out, err = encodeDocument(doc); err != nil {
+		return nil, nil, nil, err
+	}
+	return out, notes, left, nil
+}
+
+// moveKey moves the top-level key of root to the nested path, keeping its
+// comments, and describes the move. A setting already present at path wins;
+// the old key is then left for the user to remove and moved is false.
+func moveKey(root *yaml.Node, key string, path []string) (note string, moved bool) {
+	value, i := lookup(root, key)
+	if value == nil {
+		return "", false
+	}
+	dest := strings.Join(path, ".")
+	for _, p := range path {
+		if p == "" {
+			return "", false // "a..b" is not a path
+		}
+	}
+
+	parent := root
+	for _, p := range path[:len(path)-1] {
+		if v, _ := lookup(parent, p); v != nil && v.Kind != yaml.MappingNode && v.Tag != "!!null" {
+			return fmt.Sprintf("left %s: %s is not a section", key, p), false
+		}
+		parent = mappingValue(parent, p)
+	}
+	name := path[len(path)-1]
+	if existing, _ := lookup(parent, name); existing != nil {
+		return fmt.Sprintf("left %s: %s is already set", key, dest), false
+	}
+
+	keyNode := root.Content[i]
+	root.Content = append(root.Content[:i], root.Content[i+2:]...)
+	keyNode.Value = name
+	parent.Content = append(parent.Content, keyNode, value)
+	return fmt.Sprintf("moved %s to %s", key, dest), true
+}
+
+// migrateAppPassword replaces app_password credentials, which still work but
+// which Bitbucket is retiring, with an api_token section. An app password
+// cannot be used as an API token, so the token is left as
+// ${BITBUCKET_API_TOKEN} to be filled in.
+func migrateAppPassword(root *yaml.Node) []string {
+	var notes []string
+	if auth, _ := lookup(root, "auth"); auth != nil {
+		if method, _ := lookup(auth, "method"); method != nil && method.Value == "app_password" {
+			method.Value = "api_token"
+			notes = append(notes, "changed auth.method from app_password to api_token")
+		}
+	}
+
+	ap, _ := lookup(root, "app_password")
+	if ap == nil {
+		return notes
+	}
+	token := mappingValue(root, "api_token")
+	if email, _ := lookup(token, "email"); email == nil {
+		value := "${BITBUCKET_EMAIL}"
+		if e, _ := lookup(ap, "email"); e != nil && e.Value != "" {
+			value = e.Value
+		}
+		setMappingValue(token, "email", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+	}
+	if t, _ := lookup(token, "token"); t == nil {
+		setMappingValue(token, "token", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "${BITBUCKET_API_TOKEN}"})
+	}
+	_, i := lookup(root, "app_password") // after api_token may have been added
+	root.Content = append(root.Content[:i], root.Content[i+2:]...)
+	return append(notes, "replaced app_password with api_token: app passwords do not work as API tokens; "+
+		"create one at https://bitbucket.org/account/settings/api-tokens/ and set BITBUCKET_API_TOKEN "+
+		"(and BITBUCKET_EMAIL to your Atlassian account email)")
+}
+
+// Diff returns a line diff of before and after: unchanged lines start with two
+// spaces, removed ones with "- " and added ones with "+ ". Runs of more
+// than context unchanged lines are elided.
+func Diff(before, after []byte, context int) string {
+	a := strings.Split(strings.TrimSuffix(string(before), "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(string(after), "\n"), "\n")
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+
+	// Keep only unchanged lines within context of a change
+	keep := make([]bool, len(lines))
+	for n, l := range lines {
+		if !strings.HasPrefix(l, "  ") {
+			for k := max(0, n-context); k <= min(len(lines)-1, n+context); k++ {
+				keep[k] = true
+			}
+		}
+	}
+	var out strings.Builder
+	skipped := false
+	for n, l := range lines {
+		if !keep[n] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out.WriteString("  ...\n")
+			skipped = false
+		}
+		out.WriteString(l + "\n")
+	}
+	if skipped {
+		out.WriteString("  ...\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMigrate_FlatAndDottedKeys(t *testing.T) {
+	old := `workspace: acme
+# OAuth consumer
+client_id: ${BITBUCKET_OAUTH_CLIENT_ID}
+client_secret: ${BITBUCKET_OAUTH_CLIENT_SECRET}
+source_branch: develop
+http.timeout: 90s
+groups:
+  backend:
+    - api
+`
+	out, notes, _, err := Migrate([]byte(old), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 4 {
+		t.Errorf("notes = %q, want 4", notes)
+	}
+	if !strings.Contains(string(out), "# OAuth consumer") {
+		t.Errorf("comment lost:\n%s", out)
+	}
+
+	resetViper()
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(string(out))); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BITBUCKET_OAUTH_CLIENT_ID", "id")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OAuth.ClientID != "id" || cfg.Defaults.SourceBranch != "develop" ||
+		cfg.HTTP.Timeout.String() != "1m30s" || len(cfg.Groups["backend"]) != 1 {
+		t.Errorf("migrated config = %+v\n%s", cfg, out)
+	}
+}
+
+func TestMigrate_AppPassword(t *testing.T) {
+	old := `auth:
+  method: app_password
+app_password:
+  username: jdoe
+  password: ${BITBUCKET_APP_PASSWORD}
+`
+	out, notes, _, err := Migrate([]byte(old), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "auth:\n  method: api_token\napi_token:\n  email: ${BITBUCKET_EMAIL}\n  token: ${BITBUCKET_API_TOKEN}\n"
+	if string(out) != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+	if len(notes) != 2 || !strings.Contains(notes[1], "create one at") {
+		t.Errorf("notes = %q", notes)
+	}
+}
+
+func TestMigrate_KeepsAppPasswordByDefault(t *testing.T) {
+	old := "auth:\n  method: app_password\napp_password:\n  username: jdoe\n  password: secret\n"
+	out, notes, _, err := Migrate([]byte(old), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != old || len(notes) != 0 {
+		t.Errorf("Migrate() = %q, %q; want config unchanged", out, notes)
+	}
+}
+
+func TestMigrate_KeepsExistingSettings(t *testing.T) {
+	old := "api_token: abc123\nemail: me@example.com\noauth:\n  client_id: new\nclient_id: old\n"
+	out, notes, left, err := Migrate([]byte(old), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "client_id: old") {
+		t.Errorf("conflicting flat key should stay:\n%s", out)
+	}
+	if strings.Join(notes, "; ") != "moved api_token to api_token.token; moved email to api_token.email" {
+		t.Errorf("notes = %q", notes)
+	}
+	if strings.Join(left, "; ") != "left client_id: oauth.client_id is already set" {
+		t.Errorf("left = %q", left)
+	}
+}
+
+func TestMigrate_OnlyLeftKeysIsNoChange(t *testing.T) {
+	old := "oauth:\n  client_id: new\nclient_id: old # stale\n"
+	out, notes, left, err := Migrate([]byte(old), false)
+	if err != nil || notes != nil || string(out) != old {
+		t.Errorf("Migrate = %q, %q, %v; want input unchanged", out, notes, err)
+	}
+	if len(left) != 1 {
+		t.Errorf("left = %q, want the stale client_id reported", left)
+	}
+}
+
+func TestMigrate_CurrentConfigUnchanged(t *testing.T) {
+	current := "workspace: acme\napi_token:\n    email: me@example.com\n"
+	out, notes, _, err := Migrate([]byte(current), false)
+	if err != nil || notes != nil || string(out) != current {
+		t.Errorf("Migrate = %q, %q, %v; want input unchanged", out, notes, err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\n"
+	after := "a\nb\nc\nX\ne\nf\ng\n"
+	want := "  ...\n  c\n- d\n+ X\n  e\n  ...\n"
+	if got := Diff([]byte(before), []byte(after), 1); got != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"go.yaml.in/yaml/v3"
+)
+
+// KeyringService is the service name buck's entries are stored under in the
+// OS keyring (Keychain, Secret Service or Windows Credential Manager).
+const KeyringService = "buck"
+
+// SecretKeys lists the config settings that hold credentials.
+var SecretKeys = []string{
+	"api_token.token",
+	"app_password.password",
+	"oauth.client_secret",
+	"github.token",
+	"gitlab.token",
+}
+
+// keyringRefPattern matches a whole-value keyring reference, e.g.
+// ${keyring:acme/api_token.token}.
+var keyringRefPattern = regexp.MustCompile(`^\$\{keyring:([^}]+)\}$`)
+
+// KeyringRef returns the config value that refers to the keyring entry name.
+func KeyringRef(name string) string {
+	return "${keyring:" + name + "}"
+}
+
+// secretFields returns the credential settings of cfg by key.
+func secretFields(cfg *Config) map[string]*string {
+	return map[string]*string{
+		"api_token.token":       &cfg.ApiToken.Token,
+		"app_password.password": &cfg.AppPassword.Password,
+		"oauth.client_secret":   &cfg.OAuth.ClientSecret,
+		"github.token":          &cfg.GitHub.Token,
+		"gitlab.token":          &cfg.GitLab.Token,
+	}
+}
+
+// resolveKeyringRefs replaces keyring references in the credential settings
+// of cfg with the stored secrets.
+func resolveKeyringRefs(cfg *Config) error {
+	fields := secretFields(cfg)
+	for _, key := range SecretKeys {
+		field := fields[key]
+		m := keyringRefPattern.FindStringSubmatch(*field)
+		if m == nil {
+			continue
+		}
+		secret, err := keyring.Get(KeyringService, m[1])
+		if err != nil {
+			return fmt.Errorf("failed to read %s from the keyring (entry %q): %w", key, m[1], err)
+		}
+		*field = secret
+	}
+	return nil
+}
+
+// InlineSecrets returns the credential settings in the YAML config data
+// whose value is written out rather than taken from an environment variable
+// or the keyring.
+func InlineSecrets(data []byte) ([]string, error) {
+	root, err := parseRoot(data)
+	if root == nil {
+		return nil, err
+	}
+	var keys []string
+	for _, key := range SecretKeys {
+		if secretNode(root, key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// secretNode returns the node of the setting key in root when it holds a
+// plaintext secret, or nil.
+func secretNode(root *yaml.Node, key string) *yaml.Node {
+	section, name, _ := strings.Cut(key, ".")
+	parent, _ := lookup(root, section)
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return nil
+	}
+	value, _ := lookup(parent, name)
+	if value == nil || value.Kind != yaml.ScalarNode || !inlineSecret(value.Value) {
+		return nil
+	}
+	return value
+}
+
+// parseRoot parses YAML config data and returns its top-level mapping, or
+// nil for an empty document.
+func parseRoot(data []byte) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	return doc.Content[0], nil
+}
+
+// inlineSecret reports whether a raw config value is a plaintext secret.
+func inlineSecret(value string) bool {
+	return value != "" && !strings.Contains(value, "${")
+}
+
+// MigrateSecrets stores the plaintext credentials in the YAML config data in
+// the OS keyring and replaces them with references. Entries are named after
+// the setting, prefixed with the workspace when one is set, e.g.
+// "acme/api_token.token". It returns the rewritten config and the keys that
+// were moved, or data itself and no keys when there was nothing to move.
+func MigrateSecrets(data []byte) ([]byte, []string, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+
+	prefix := ""
+	if ws, _ := lookup(root, "workspace"); ws != nil && ws.Kind == yaml.ScalarNode && ws.Value != "" {
+		prefix = ws.Value + "/"
+	}
+
+	var moved []string
+	for _, key := range SecretKeys {
+		value := secretNode(root, key)
+		if value == nil {
+			continue
+		}
+		entry := prefix + key
+		if err := keyring.Set(KeyringService, entry, value.Value); err != nil {
+			return nil, nil, fmt.Errorf("failed to store %s in the keyring: %w", key, err)
+		}
+		value.Value = KeyringRef(entry)
+		value.Style = 0
+		moved = append(moved, key)
+	}
+	if len(moved) == 0 {
+		return data, nil, nil
+	}
+	out, err := encodeDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, moved, nil
+}
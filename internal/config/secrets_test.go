@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+func TestMigrateSecrets(t *testing.T) {
+	keyring.MockInit()
+
+	old := `workspace: acme
+api_token:
+  email: me@example.com
+  token: "s3cret" # from the Bitbucket settings
+github:
+  token: ${GITHUB_TOKEN}
+`
+	out, moved, err := MigrateSecrets([]byte(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `workspace: acme
+api_token:
+  email: me@example.com
+  token: ${keyring:acme/api_token.token} # from the Bitbucket settings
+github:
+  token: ${GITHUB_TOKEN}
+`
+	if string(out) != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+	if len(moved) != 1 || moved[0] != "api_token.token" {
+		t.Errorf("moved = %q, want [api_token.token]", moved)
+	}
+	if got, err := keyring.Get(KeyringService, "acme/api_token.token"); err != nil || got != "s3cret" {
+		t.Errorf("keyring entry = %q, %v; want s3cret", got, err)
+	}
+
+	again, moved, err := MigrateSecrets(out)
+	if err != nil || len(moved) != 0 || string(again) != string(out) {
+		t.Errorf("second MigrateSecrets() = %q, %q, %v; want no changes", again, moved, err)
+	}
+}
+
+func TestLoad_ResolvesKeyringRefs(t *testing.T) {
+	resetViper()
+	keyring.MockInit()
+	if err := keyring.Set(KeyringService, "acme/gitlab.token", "glpat-123"); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("gitlab.token", "${keyring:acme/gitlab.token}")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.GitLab.Token != "glpat-123" {
+		t.Errorf("GitLab.Token = %q, want glpat-123", cfg.GitLab.Token)
+	}
+
+	viper.Set("github.token", "${keyring:acme/github.token}")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "github.token") {
+		t.Errorf("Load() error = %v, want missing github.token entry", err)
+	}
+}
+
+func TestInlineSecrets(t *testing.T) {
+	data := `api_token:
+  token: plain
+oauth:
+  client_secret: ${BITBUCKET_OAUTH_CLIENT_SECRET}
+gitlab:
+  token: ${keyring:gitlab.token}
+github: ""
+`
+	got, err := InlineSecrets([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "api_token.token" {
+		t.Errorf("InlineSecrets() = %q, want [api_token.token]", got)
+	}
+}
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SaveGroup adds or replaces a named group in the YAML config file at path.
+// The file is edited as a YAML node tree so comments and key order survive.
+// A missing file is created.
+func SaveGroup(path, name string, repos []string) error {
+	if name == "" {
+		return fmt.Errorf("group name is required")
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	groups := mappingValue(doc.Content[0], "groups")
+
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, r := range repos {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: r})
+	}
+	setMappingValue(groups, name, seq)
+
+	return writeDocument(path, doc)
+}
+
+// AddToGroup appends repos to the named group in the config file at path,
+// creating the group if needed, and returns the entries that were not
+// already in it.
+func AddToGroup(path, name string, repos []string) ([]string, error) {
+	if name == "" {
+		return nil, fmt.Errorf("group name is required")
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := mappingValue(doc.Content[0], "groups")
+	seq, _ := lookup(groups, name)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setMappingValue(groups, name, seq)
+	}
+
+	present := make(map[string]bool)
+	for _, n := range seq.Content {
+		present[n.Value] = true
+	}
+	var added []string
+	for _, r := range repos {
+		if present[r] {
+			continue
+		}
+		present[r] = true
+		added = append(added, r)
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: r})
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	return added, writeDocument(path, doc)
+}
+
+// RemoveFromGroup removes repos from the named group in the config file at
+// path and returns the entries that were removed. The group itself is kept,
+// even when it ends up empty.
+func RemoveFromGroup(path, name string, repos []string) ([]string, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err := existingGroup(doc, name)
+	if err != nil {
+		return nil, err
+	}
+
+	remove := make(map[string]bool)
+	for _, r := range repos {
+		remove[r] = true
+	}
+	var removed []string
+	kept := seq.Content[:0]
+	for _, n := range seq.Content {
+		if remove[n.Value] {
+			removed = append(removed, n.Value)
+			continue
+		}
+		kept = append(kept, n)
+	}
+	seq.Content = kept
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, writeDocument(path, doc)
+}
+
+// DeleteGroup removes the named group from the config file at path.
+func DeleteGroup(path, name string) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := existingGroup(doc, name); err != nil {
+		return err
+	}
+	groups, _ := lookup(doc.Content[0], "groups")
+	_, i := lookup(groups, name)
+	groups.Content = append(groups.Content[:i], groups.Content[i+2:]...)
+	return writeDocument(path, doc)
+}
+
+// RenameGroup renames a group in the config file at path, keeping its
+// entries, comments and position.
+func RenameGroup(path, oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("group name is required")
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := existingGroup(doc, oldName); err != nil {
+		return err
+	}
+	groups, _ := lookup(doc.Content[0], "groups")
+	if v, _ := lookup(groups, newName); v != nil {
+		return fmt.Errorf("group %q already exists", newName)
+	}
+	_, i := lookup(groups, oldName)
+	groups.Content[i].Value = newName
+	return writeDocument(path, doc)
+}
+
+// MergeFile sets the settings of the YAML data in the config file at path,
+// replacing those already there and keeping the rest of the file with its
+// comments. Sections such as defaults are merged key by key, so settings the
+// data leaves out stay. A missing file is created.
+func MergeFile(path string, data []byte) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+	src := &yaml.Node{}
+	if err := yaml.Unmarshal(data, src); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(src.Content) == 0 || src.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	mergeMapping(doc.Content[0], src.Content[0])
+	return writeDocument(path, doc)
+}
+
+// mergeMapping sets each key of src in dst, merging values that are mappings
+// in both.
+func mergeMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i].Value, src.Content[i+1]
+		if existing, _ := lookup(dst, key); existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeMapping(existing, value)
+			continue
+		}
+		setMappingValue(dst, key, value)
+	}
+}
+
+// existingGroup returns the sequence of the named group, which must exist.
+// A group with no entries ("backend:") is turned into an empty sequence.
+func existingGroup(doc *yaml.Node, name string) (*yaml.Node, error) {
+	groups, _ := lookup(doc.Content[0], "groups")
+	var seq *yaml.Node
+	if groups != nil && groups.Kind == yaml.MappingNode {
+		seq, _ = lookup(groups, name)
+	}
+	if seq == nil {
+		return nil, fmt.Errorf("group %q not found in config", name)
+	}
+	if seq.Kind != yaml.SequenceNode {
+		*seq = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	return seq, nil
+}
+
+// readDocument parses a YAML file into a document node whose root is a mapping.
+func readDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if len(data) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	return doc, nil
+}
+
+// writeDocument encodes a document node back to path with 0600 permissions.
+func writeDocument(path string, doc *yaml.Node) error {
+	data, err := encodeDocument(doc)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data)
+}
+
+// encodeDocument encodes a document node with the two-space indent of
+// hand-written configs.
+func encodeDocument(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to generate config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to generate config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile replaces the config file at path with data, with 0600
+// permissions. The file is replaced atomically, so an interrupted write
+// never leaves a truncated config.
+func WriteFile(path string, data []byte) error {
+	// Replace the target of a symlinked config (e.g. from a dotfiles repo), not the link
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".buck-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the value under key in mapping m and the index of the key
+// node, or nil and -1 if absent.
+func lookup(m *yaml.Node, key string) (*yaml.Node, int) {
+	if m == nil {
+		return nil, -1
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1], i
+		}
+	}
+	return nil, -1
+}
+
+// mappingValue returns the mapping stored under key, creating it if absent.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			if v.Kind != yaml.MappingNode {
+				// e.g. "groups:" with no entries decodes as a null scalar
+				*v = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			return v
+		}
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setMappingValue(m, key, v)
+	return v
+}
+
+// setMappingValue replaces the value under key, or appends the pair if absent.
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
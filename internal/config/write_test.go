@@ -0,0 +1,312 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestSaveGroup_AddsGroupAndKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	original := `workspace: my-ws
+# API token auth
+api_token:
+  email: ${BITBUCKET_EMAIL}
+groups:
+  backend:
+    - repo-api
+`
+	os.WriteFile(path, []byte(original), 0600)
+
+	if err := SaveGroup(path, "picked", []string{"repo-a", "repo-b"}); err != nil {
+		t.Fatalf("SaveGroup error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	out := string(data)
+	if !strings.Contains(out, "# API token auth") {
+		t.Errorf("comment lost:\n%s", out)
+	}
+	if !strings.Contains(out, "${BITBUCKET_EMAIL}") {
+		t.Errorf("env placeholder lost:\n%s", out)
+	}
+
+	var parsed struct {
+		Groups map[string][]string `yaml:"groups"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if len(parsed.Groups["backend"]) != 1 {
+		t.Errorf("backend group = %v, want unchanged", parsed.Groups["backend"])
+	}
+	if got := parsed.Groups["picked"]; len(got) != 2 || got[0] != "repo-a" || got[1] != "repo-b" {
+		t.Errorf("picked group = %v, want [repo-a repo-b]", got)
+	}
+}
+
+func TestSaveGroup_ReplacesExistingGroup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	os.WriteFile(path, []byte("groups:\n  backend:\n    - old-repo\n"), 0600)
+
+	if err := SaveGroup(path, "backend", []string{"new-repo"}); err != nil {
+		t.Fatalf("SaveGroup error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "old-repo") {
+		t.Errorf("old repo still present:\n%s", data)
+	}
+	if !strings.Contains(string(data), "new-repo") {
+		t.Errorf("new repo missing:\n%s", data)
+	}
+}
+
+func TestSaveGroup_CreatesMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+
+	if err := SaveGroup(path, "picked", []string{"repo-a"}); err != nil {
+		t.Fatalf("SaveGroup error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
+
+func TestSaveGroup_EmptyGroupsKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	os.WriteFile(path, []byte("workspace: ws\ngroups:\n"), 0600)
+
+	if err := SaveGroup(path, "picked", []string{"repo-a"}); err != nil {
+		t.Fatalf("SaveGroup error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "picked:") {
+		t.Errorf("group not written:\n%s", data)
+	}
+}
+
+func TestSaveGroup_EmptyName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	if err := SaveGroup(path, "", []string{"repo-a"}); err == nil {
+		t.Fatal("expected error for empty group name")
+	}
+}
+
+const groupsConfig = `workspace: my-ws
+groups:
+  # services owned by the platform team
+  backend:
+    - repo-api # main API
+    - repo-worker
+  frontend:
+    - repo-web
+`
+
+func writeGroupsConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	if err := os.WriteFile(path, []byte(groupsConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readGroups(t *testing.T, path string) map[string][]string {
+	t.Helper()
+	data, _ := os.ReadFile(path)
+	var parsed struct {
+		Groups map[string][]string `yaml:"groups"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	return parsed.Groups
+}
+
+func TestAddToGroup_AppendsNewEntriesAndKeepsFormatting(t *testing.T) {
+	path := writeGroupsConfig(t)
+
+	added, err := AddToGroup(path, "backend", []string{"repo-worker", "repo-billing"})
+	if err != nil {
+		t.Fatalf("AddToGroup error: %v", err)
+	}
+	if len(added) != 1 || added[0] != "repo-billing" {
+		t.Errorf("added = %v, want [repo-billing]", added)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := `workspace: my-ws
+groups:
+  # services owned by the platform team
+  backend:
+    - repo-api # main API
+    - repo-worker
+    - repo-billing
+  frontend:
+    - repo-web
+`
+	if string(data) != want {
+		t.Errorf("config =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestAddToGroup_CreatesGroup(t *testing.T) {
+	path := writeGroupsConfig(t)
+
+	if _, err := AddToGroup(path, "infra", []string{"repo-tf"}); err != nil {
+		t.Fatalf("AddToGroup error: %v", err)
+	}
+	if got := readGroups(t, path)["infra"]; len(got) != 1 || got[0] != "repo-tf" {
+		t.Errorf("infra = %v, want [repo-tf]", got)
+	}
+}
+
+func TestRemoveFromGroup(t *testing.T) {
+	path := writeGroupsConfig(t)
+
+	removed, err := RemoveFromGroup(path, "backend", []string{"repo-api", "repo-missing"})
+	if err != nil {
+		t.Fatalf("RemoveFromGroup error: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "repo-api" {
+		t.Errorf("removed = %v, want [repo-api]", removed)
+	}
+	if got := readGroups(t, path)["backend"]; len(got) != 1 || got[0] != "repo-worker" {
+		t.Errorf("backend = %v, want [repo-worker]", got)
+	}
+
+	if _, err := RemoveFromGroup(path, "missing", []string{"repo-api"}); err == nil {
+		t.Error("expected an error for a missing group")
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	path := writeGroupsConfig(t)
+
+	if err := DeleteGroup(path, "backend"); err != nil {
+		t.Fatalf("DeleteGroup error: %v", err)
+	}
+	groups := readGroups(t, path)
+	if _, ok := groups["backend"]; ok || len(groups["frontend"]) != 1 {
+		t.Errorf("groups = %v, want only frontend", groups)
+	}
+	if err := DeleteGroup(path, "backend"); err == nil {
+		t.Error("expected an error for a missing group")
+	}
+}
+
+func TestRenameGroup_KeepsPositionAndComments(t *testing.T) {
+	path := writeGroupsConfig(t)
+
+	if err := RenameGroup(path, "backend", "platform"); err != nil {
+		t.Fatalf("RenameGroup error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	out := string(data)
+	if !strings.Contains(out, "# services owned by the platform team\n  platform:\n    - repo-api # main API") {
+		t.Errorf("rename lost position or comments:\n%s", out)
+	}
+	if strings.Index(out, "platform:") > strings.Index(out, "frontend:") {
+		t.Errorf("renamed group moved:\n%s", out)
+	}
+
+	if err := RenameGroup(path, "platform", "frontend"); err == nil {
+		t.Error("expected an error when the new name is taken")
+	}
+	if err := RenameGroup(path, "missing", "other"); err == nil {
+		t.Error("expected an error for a missing group")
+	}
+}
+
+func TestWriteDocument_FollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles.yaml")
+	os.WriteFile(target, []byte(groupsConfig), 0600)
+	link := filepath.Join(dir, ".buck.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if err := DeleteGroup(link, "frontend"); err != nil {
+		t.Fatalf("DeleteGroup error: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("config symlink was replaced")
+	}
+	if _, ok := readGroups(t, target)["frontend"]; ok {
+		t.Errorf("target not updated")
+	}
+}
+
+func TestMergeFile_ReplacesSettingsAndKeepsTheRest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	original := `workspace: old-ws
+# OAuth consumer
+auth:
+  method: oauth
+groups:
+  backend:
+    - repo-api
+`
+	os.WriteFile(path, []byte(original), 0600)
+
+	update := "workspace: acme\nauth:\n  method: api_token\napi_token:\n  email: me@example.com\n  token: t\n"
+	if err := MergeFile(path, []byte(update)); err != nil {
+		t.Fatalf("MergeFile error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := `workspace: acme
+# OAuth consumer
+auth:
+  method: api_token
+groups:
+  backend:
+    - repo-api
+api_token:
+  email: me@example.com
+  token: t
+`
+	if string(data) != want {
+		t.Errorf("config =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestMergeFile_MergesSections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".buck.yaml")
+	original := `workspace: old-ws
+defaults:
+  branch_prefix: feature/
+hooks:
+  post-create:
+    - command: ./notify.sh
+`
+	os.WriteFile(path, []byte(original), 0600)
+
+	if err := MergeFile(path, []byte("workspace: acme\ndefaults:\n  source_branch: develop\n")); err != nil {
+		t.Fatalf("MergeFile error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := `workspace: acme
+defaults:
+  branch_prefix: feature/
+  source_branch: develop
+hooks:
+  post-create:
+    - command: ./notify.sh
+`
+	if string(data) != want {
+		t.Errorf("config =\n%s\nwant\n%s", data, want)
+	}
+}
@@ -4,32 +4,162 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+	"github.com/chinhstringee/buck/internal/tracing"
 )
 
 // Result holds the outcome of a branch creation for one repo.
 type Result struct {
-	RepoSlug   string
-	Success    bool
-	Error      string
-	CommitHash string
-	BranchURL  string
+	RepoSlug      string                 `json:"repo"`
+	Success       bool                   `json:"success"`
+	Error         string                 `json:"error,omitempty"`
+	ErrorCategory provider.ErrorCategory `json:"error_category,omitempty"`
+	StatusCode    int                    `json:"status_code,omitempty"` // HTTP status of the failed request
+	CommitHash    string                 `json:"commit,omitempty"`
+	BranchURL     string                 `json:"url,omitempty"`
+	Source        string                 `json:"source"` // branch the new branch was created from
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    time.Time              `json:"finished_at"`
+	Duration      time.Duration          `json:"duration_ns"`
+}
+
+// fail records err on the result.
+func (r *Result) fail(err error) {
+	r.Success = false
+	r.Error = err.Error()
+	r.ErrorCategory = provider.Classify(err)
+	r.StatusCode = bitbucket.StatusCode(err)
+}
+
+// finish stamps the result with its start time and the time elapsed since.
+func (r *Result) finish(start time.Time) {
+	r.StartedAt = start
+	r.FinishedAt = time.Now()
+	r.Duration = r.FinishedAt.Sub(start)
 }
 
 // BranchCreator orchestrates parallel branch creation across repos.
 type BranchCreator struct {
-	client *bitbucket.Client
+	client provider.Provider
 }
 
 // NewBranchCreator creates a new orchestrator.
-func NewBranchCreator(client *bitbucket.Client) *BranchCreator {
+func NewBranchCreator(client provider.Provider) *BranchCreator {
 	return &BranchCreator{client: client}
 }
 
 // CreateBranches creates a branch in multiple repos concurrently.
+// If sourceBranch is empty, each repo's development branch is used.
 func (bc *BranchCreator) CreateBranches(workspace string, repos []string, branchName, sourceBranch string) []Result {
+	return bc.forEachRepo(workspace, repos, func(workspace, repoSlug string) Result {
+		source := sourceBranch
+		if source == "" {
+			var err error
+			if source, err = provider.DevelopmentBranch(bc.client, workspace, repoSlug); err != nil {
+				result := Result{RepoSlug: repoSlug}
+				result.fail(err)
+				return result
+			}
+		}
+		return bc.create(workspace, repoSlug, branchName, source, source)
+	})
+}
+
+// CreateBranchesAt creates a branch in multiple repos concurrently, pointing
+// at the commit ref (a tag or commit hash) resolves to in each repo.
+func (bc *BranchCreator) CreateBranchesAt(workspace string, repos []string, branchName, ref string) []Result {
+	return bc.forEachRepo(workspace, repos, func(workspace, repoSlug string) Result {
+		hash, err := bc.client.ResolveCommit(workspace, repoSlug, ref)
+		if err != nil {
+			result := Result{RepoSlug: repoSlug, Source: ref}
+			result.fail(err)
+			return result
+		}
+		return bc.create(workspace, repoSlug, branchName, hash, ref)
+	})
+}
+
+// Target is the commit a planned branch starts at in one repo.
+type Target struct {
+	Repo   string // entry as given, e.g. "repo" or "workspace/repo"
+	Source string // branch or tag the commit was resolved from, reported as the source
+	Commit string
+}
+
+// CreateBranchesFromTargets creates a branch in multiple repos concurrently,
+// each at the commit of its target, so the branches start exactly where a
+// plan said they would even when the source has moved on since.
+func (bc *BranchCreator) CreateBranchesFromTargets(workspace string, targets []Target, branchName string) []Result {
+	byRepo := make(map[string]Target, len(targets))
+	repos := make([]string, len(targets))
+	for i, t := range targets {
+		ws, slug := provider.SplitRepo(workspace, t.Repo)
+		byRepo[ws+"/"+slug] = t
+		repos[i] = t.Repo
+	}
+	return bc.forEachRepo(workspace, repos, func(workspace, repoSlug string) Result {
+		t := byRepo[workspace+"/"+repoSlug]
+		return bc.create(workspace, repoSlug, branchName, t.Commit, t.Source)
+	})
+}
+
+// create creates one branch from target (a branch name or commit hash);
+// label is what the result reports as the source.
+func (bc *BranchCreator) create(workspace, repoSlug, branchName, target, label string) Result {
+	branch, err := bc.createBranch(workspace, repoSlug, branchName, target)
+
+	result := Result{RepoSlug: repoSlug, Source: label}
+	if err != nil {
+		result.fail(err)
+	} else {
+		result.Success = true
+		result.BranchURL = bc.client.BranchURL(workspace, repoSlug, branchName)
+		// Show short hash (first 7 chars)
+		if len(branch.Target.Hash) > 7 {
+			result.CommitHash = branch.Target.Hash[:7]
+		} else {
+			result.CommitHash = branch.Target.Hash
+		}
+	}
+	return result
+}
+
+// createBranch sends the creation request. After a transient failure the
+// request may still have gone through, so it reads the branch before sending
+// it once more, and reads it again if that attempt reports a conflict. An
+// existing branch counts as created only when it points at the commit target
+// resolves to; otherwise the conflict is reported.
+func (bc *BranchCreator) createBranch(workspace, repoSlug, branchName, target string) (*bitbucket.Branch, error) {
+	branch, err := bc.client.CreateBranch(workspace, repoSlug, branchName, target)
+	if err == nil || !provider.Transient(err) {
+		return branch, err
+	}
+	want, resolveErr := bc.client.ResolveCommit(workspace, repoSlug, target)
+	created := func() (*bitbucket.Branch, bool) {
+		existing, getErr := bc.client.GetBranch(workspace, repoSlug, branchName)
+		return existing, getErr == nil && resolveErr == nil && want != "" && existing.Target.Hash == want
+	}
+
+	if existing, ok := created(); ok {
+		return existing, nil
+	}
+	branch, err = bc.client.CreateBranch(workspace, repoSlug, branchName, target)
+	if provider.Classify(err) == provider.ErrConflict {
+		if existing, ok := created(); ok {
+			return existing, nil
+		}
+	}
+	return branch, err
+}
+
+// forEachRepo runs fn for every repo concurrently, timing each call, and
+// returns results sorted by slug. fn gets the workspace and slug of each
+// entry; results keep the entry as given.
+func (bc *BranchCreator) forEachRepo(workspace string, repos []string, fn func(workspace, repoSlug string) Result) []Result {
 	var (
 		wg      sync.WaitGroup
 		mu      sync.Mutex
@@ -41,22 +171,11 @@ func (bc *BranchCreator) CreateBranches(workspace string, repos []string, branch
 		go func(repoSlug string) {
 			defer wg.Done()
 
-			branch, err := bc.client.CreateBranch(workspace, repoSlug, branchName, sourceBranch)
-
-			result := Result{RepoSlug: repoSlug}
-			if err != nil {
-				result.Success = false
-				result.Error = err.Error()
-			} else {
-				result.Success = true
-				result.BranchURL = fmt.Sprintf("https://bitbucket.org/%s/%s/branch/%s", workspace, repoSlug, branchName)
-				// Show short hash (first 7 chars)
-				if len(branch.Target.Hash) > 7 {
-					result.CommitHash = branch.Target.Hash[:7]
-				} else {
-					result.CommitHash = branch.Target.Hash
-				}
-			}
+			start := time.Now()
+			result := fn(provider.SplitRepo(workspace, repoSlug))
+			result.RepoSlug = repoSlug
+			result.finish(start)
+			tracing.RecordRepo("create branch", repoSlug, result.StartedAt, result.FinishedAt, result.Error)
 
 			mu.Lock()
 			results = append(results, result)
@@ -74,33 +193,23 @@ func (bc *BranchCreator) CreateBranches(workspace string, repos []string, branch
 	return results
 }
 
-// PrintResults displays a colored summary table of results.
+// PrintResults displays a summary of results in the current output mode.
 func PrintResults(results []Result) {
-	green := color.New(color.FgGreen).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	cyan := color.New(color.FgCyan).SprintFunc()
-	bold := color.New(color.Bold).SprintFunc()
-
-	succeeded := 0
-	failed := 0
-
-	fmt.Println()
-	for _, r := range results {
-		if r.Success {
-			succeeded++
-			fmt.Printf("  %s %-30s created (%s)\n", green("✓"), r.RepoSlug, r.CommitHash)
-			if r.BranchURL != "" {
-				fmt.Printf("    %s\n", cyan(r.BranchURL))
-			}
-		} else {
-			failed++
-			fmt.Printf("  %s %-30s %s\n", red("✗"), r.RepoSlug, r.Error)
-		}
+	rows := make([]render.Row, len(results))
+	for i, r := range results {
+		rows[i] = Row(r)
 	}
+	render.Print(render.Report{Rows: rows, Data: results})
+}
 
-	fmt.Printf("\n%s %s succeeded, %s failed\n",
-		bold("Summary:"),
-		green(fmt.Sprintf("%d", succeeded)),
-		red(fmt.Sprintf("%d", failed)),
-	)
+// Row renders one branch creation result.
+func Row(r Result) render.Row {
+	if !r.Success {
+		return render.Row{Repo: r.RepoSlug, Status: render.Failed, Message: r.Error, Category: r.ErrorCategory}
+	}
+	return render.Row{
+		Repo:    r.RepoSlug,
+		Message: fmt.Sprintf("created from %s (%s)", r.Source, r.CommitHash),
+		Link:    r.BranchURL,
+	}
 }
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
 )
 
 // mockBBServer builds an httptest.Server that handles branch creation requests.
@@ -112,6 +115,9 @@ func TestCreateBranches_AllSuccess(t *testing.T) {
 		if len(r.CommitHash) > 7 {
 			t.Errorf("repo %q CommitHash length = %d, want ≤7", r.RepoSlug, len(r.CommitHash))
 		}
+		if r.StartedAt.IsZero() || r.FinishedAt.Before(r.StartedAt) || r.Duration != r.FinishedAt.Sub(r.StartedAt) {
+			t.Errorf("repo %q timing = %v → %v (%v)", r.RepoSlug, r.StartedAt, r.FinishedAt, r.Duration)
+		}
 		// BranchURL should contain workspace, repo slug, and branch name
 		wantURL := fmt.Sprintf("https://bitbucket.org/my-workspace/%s/branch/feature/test", r.RepoSlug)
 		if r.BranchURL != wantURL {
@@ -179,6 +185,9 @@ func TestCreateBranches_PartialFailure(t *testing.T) {
 			if r.BranchURL != "" {
 				t.Errorf("failed result %q should have empty BranchURL, got %q", r.RepoSlug, r.BranchURL)
 			}
+			if r.StatusCode != http.StatusConflict || r.ErrorCategory != provider.ErrConflict {
+				t.Errorf("failed result %q status = %d, category = %q", r.RepoSlug, r.StatusCode, r.ErrorCategory)
+			}
 		}
 	}
 	if succeeded != 2 {
@@ -299,3 +308,283 @@ func TestNewBranchCreator_NotNil(t *testing.T) {
 		t.Fatal("NewBranchCreator returned nil")
 	}
 }
+
+func TestCreateBranches_EmptySourceUsesDevelopmentBranch(t *testing.T) {
+	var gotSource atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(bitbucket.BranchingModel{
+				Development: bitbucket.BranchingModelBranch{Name: "develop", Branch: &bitbucket.BranchRef{Name: "develop"}},
+			})
+			return
+		}
+		var body struct {
+			Target struct {
+				Hash string `json:"hash"`
+			} `json:"target"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		gotSource.Store(body.Target.Hash)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: "abc1234def"}})
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("ws", []string{"repo-a"}, "feature/x", "")
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Source != "develop" || gotSource.Load() != "develop" {
+		t.Errorf("Source = %q, request target = %v, want develop", results[0].Source, gotSource.Load())
+	}
+}
+
+func TestCreateBranches_EmptySourceFallsBackToMainBranch(t *testing.T) {
+	var gotSource atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/effective-branching-model"):
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "not found"}})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: "repo-a", MainBranch: &bitbucket.BranchRef{Name: "main"}})
+		default:
+			var body struct {
+				Target struct {
+					Hash string `json:"hash"`
+				} `json:"target"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotSource.Store(body.Target.Hash)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: "abc1234def"}})
+		}
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("ws", []string{"repo-a"}, "feature/x", "")
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Source != "main" || gotSource.Load() != "main" {
+		t.Errorf("Source = %q, request target = %v, want main", results[0].Source, gotSource.Load())
+	}
+}
+
+// ---------- CreateBranchesAt ----------
+
+func TestCreateBranchesAt_ResolvesRefPerRepo(t *testing.T) {
+	var gotTarget atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repo-a/commit/v1.2.0"):
+			json.NewEncoder(w).Encode(bitbucket.Commit{Hash: "0123456789abcdef"})
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "Commit not found"}})
+		default:
+			var body struct {
+				Target struct {
+					Hash string `json:"hash"`
+				} `json:"target"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotTarget.Store(body.Target.Hash)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "release/1.2", Target: bitbucket.BranchTarget{Hash: body.Target.Hash}})
+		}
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranchesAt("ws", []string{"repo-a", "repo-b"}, "release/1.2", "v1.2.0")
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if !results[0].Success || results[0].Source != "v1.2.0" || results[0].CommitHash != "0123456" {
+		t.Errorf("repo-a = %+v", results[0])
+	}
+	if gotTarget.Load() != "0123456789abcdef" {
+		t.Errorf("request target = %v, want resolved hash", gotTarget.Load())
+	}
+	if results[1].Success || !strings.Contains(results[1].Error, `failed to resolve "v1.2.0"`) {
+		t.Errorf("repo-b = %+v, want resolve error", results[1])
+	}
+}
+
+func TestCreateBranchesFromTargets_UsesPlannedCommits(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		targets = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected %s %s: planned commits must not be resolved again", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body struct {
+			Target struct {
+				Hash string `json:"hash"`
+			} `json:"target"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		slug := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[3]
+		mu.Lock()
+		targets[slug] = body.Target.Hash
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: body.Target.Hash}})
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranchesFromTargets("ws", []Target{
+		{Repo: "repo-b", Source: "develop", Commit: "bbbbbbbbbbbb"},
+		{Repo: "repo-a", Source: "main", Commit: "aaaaaaaaaaaa"},
+	}, "feature/x")
+
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[0].RepoSlug != "repo-a" || !results[0].Success || results[0].Source != "main" || results[0].CommitHash != "aaaaaaa" {
+		t.Errorf("repo-a = %+v", results[0])
+	}
+	if results[1].RepoSlug != "repo-b" || results[1].Source != "develop" {
+		t.Errorf("repo-b = %+v", results[1])
+	}
+	if targets["repo-a"] != "aaaaaaaaaaaa" || targets["repo-b"] != "bbbbbbbbbbbb" {
+		t.Errorf("request targets = %v, want the planned commits", targets)
+	}
+}
+
+func TestCreateBranches_QualifiedRepoUsesItsWorkspace(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: "abc1234"}})
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("acme", []string{"api", "ops/infra"}, "feature/x", "main")
+
+	if len(results) != 2 || results[0].RepoSlug != "api" || results[1].RepoSlug != "ops/infra" {
+		t.Fatalf("results = %+v, want api and ops/infra", results)
+	}
+	if want := "https://bitbucket.org/ops/infra/branch/feature/x"; results[1].BranchURL != want {
+		t.Errorf("BranchURL = %q, want %q", results[1].BranchURL, want)
+	}
+	sort.Strings(paths)
+	want := []string{"/2.0/repositories/acme/api/refs/branches", "/2.0/repositories/ops/infra/refs/branches"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestCreateBranches_TransientFailureFindsCreatedBranch(t *testing.T) {
+	var posts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost:
+			// The branch is created but the response is lost on the way back
+			posts.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		case strings.Contains(r.URL.Path, "/commit/"):
+			json.NewEncoder(w).Encode(bitbucket.Commit{Hash: "abc1234def"})
+		default:
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: "abc1234def"}})
+		}
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("ws", []string{"api"}, "feature/x", "main")
+
+	if !results[0].Success || results[0].CommitHash != "abc1234" {
+		t.Errorf("result = %+v, want success with the existing branch", results[0])
+	}
+	if got := posts.Load(); got != 1 {
+		t.Errorf("POST requests = %d, want 1", got)
+	}
+}
+
+func TestCreateBranches_TransientFailureKeepsOthersBranch(t *testing.T) {
+	var posts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && posts.Add(1) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "already exists"}})
+		case strings.Contains(r.URL.Path, "/commit/"):
+			json.NewEncoder(w).Encode(bitbucket.Commit{Hash: "abc1234def"})
+		default:
+			// Someone else's branch of the same name, at another commit
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: "fff0000aaa"}})
+		}
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("ws", []string{"api"}, "feature/x", "main")
+
+	if results[0].Success || results[0].ErrorCategory != provider.ErrConflict {
+		t.Errorf("result = %+v, want the existing branch reported as a conflict", results[0])
+	}
+}
+
+func TestCreateBranches_TransientFailureRetriesOnce(t *testing.T) {
+	var posts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case posts.Add(1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "feature/x", Target: bitbucket.BranchTarget{Hash: "abc1234def"}})
+		}
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("ws", []string{"api"}, "feature/x", "main")
+
+	if !results[0].Success {
+		t.Errorf("result = %+v, want success on the second attempt", results[0])
+	}
+	if got := posts.Load(); got != 2 {
+		t.Errorf("POST requests = %d, want 2", got)
+	}
+}
+
+func TestCreateBranches_ConflictIsNotRetried(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "already exists"}})
+	}))
+	defer srv.Close()
+
+	results := newCreatorForServer(srv).CreateBranches("ws", []string{"api"}, "feature/x", "main")
+
+	if results[0].Success {
+		t.Errorf("result = %+v, want conflict", results[0])
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
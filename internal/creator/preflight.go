@@ -0,0 +1,110 @@
+package creator
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+// PlanItem is the preflight outcome for one repo, with the source the branch
+// would be created from.
+type PlanItem struct {
+	render.PlanItem
+	Source string
+	Commit string // the source resolves to; empty when it does not
+}
+
+// Plan returns the printable part of each item, for render.PrintPlan.
+func Plan(items []PlanItem) []render.PlanItem {
+	plan := make([]render.PlanItem, len(items))
+	for i, item := range items {
+		plan[i] = item.PlanItem
+	}
+	return plan
+}
+
+// Preflight checks with read-only API calls that each repo exists, that the
+// source (a branch, tag or commit) resolves, and that branchName does not
+// exist yet. An empty sourceBranch means each repo's development branch.
+func (bc *BranchCreator) Preflight(workspace string, repos []string, branchName, sourceBranch string) []PlanItem {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		items []PlanItem
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			item := bc.preflightRepo(ws, slug, branchName, sourceBranch)
+			item.RepoSlug = repoSlug
+			if item.Source != "" {
+				item.Detail = "from " + item.Source
+			}
+
+			mu.Lock()
+			items = append(items, item)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].RepoSlug < items[j].RepoSlug
+	})
+
+	return items
+}
+
+func (bc *BranchCreator) preflightRepo(workspace, repoSlug, branchName, sourceBranch string) PlanItem {
+	item := PlanItem{PlanItem: render.PlanItem{RepoSlug: repoSlug}, Source: sourceBranch}
+
+	if _, err := bc.client.GetRepository(workspace, repoSlug); err != nil {
+		if isNotFound(err) {
+			item.Warn("repository not found")
+		} else {
+			item.Warn("could not read repository: %v", err)
+		}
+		return item
+	}
+
+	if item.Source == "" {
+		source, err := provider.DevelopmentBranch(bc.client, workspace, repoSlug)
+		if err != nil {
+			item.Warn("%v", err)
+			return item
+		}
+		item.Source = source
+	}
+	hash, err := bc.client.ResolveCommit(workspace, repoSlug, item.Source)
+	switch {
+	case err == nil:
+		item.Commit = hash
+	case isNotFound(err):
+		item.Warn("source %q not found", item.Source)
+	default:
+		item.Warn("could not check source %q: %v", item.Source, err)
+	}
+
+	if _, err := bc.client.ResolveCommit(workspace, repoSlug, branchName); err == nil {
+		item.Warn("branch %q already exists", branchName)
+		if pr, err := bc.client.FindPRByBranch(workspace, repoSlug, branchName, "OPEN"); err == nil && pr != nil {
+			item.Warn("open PR #%d already exists for %q", pr.ID, branchName)
+		}
+	} else if !isNotFound(err) {
+		item.Warn("could not check branch %q: %v", branchName, err)
+	}
+
+	return item
+}
+
+// isNotFound reports whether err is a 404 from the hosting API.
+func isNotFound(err error) bool {
+	return provider.Classify(err) == provider.ErrNotFound
+}
@@ -0,0 +1,176 @@
+package dashboard
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// Approval states of an aged PR.
+const (
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes requested"
+	ReviewWaiting          = "waiting"
+	ReviewNoReviewers      = "no reviewers"
+)
+
+// AgedPR is one open PR of the aging report.
+type AgedPR struct {
+	Repo      string    `json:"repo"`
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	CreatedOn time.Time `json:"created_on"`
+	AgeDays   int       `json:"age_days"`
+	Review    string    `json:"review"` // one of the Review* constants
+	Approvals int       `json:"approvals"`
+	Reviewers int       `json:"reviewers"`
+	URL       string    `json:"url"`
+}
+
+// AgePRs returns the open PRs in results created more than olderThan before
+// now, oldest first. PRs without a creation date are left out.
+func AgePRs(results []RepoPRs, olderThan time.Duration, now time.Time) []AgedPR {
+	var aged []AgedPR
+	for _, r := range results {
+		for _, pr := range r.PRs {
+			created := bitbucket.ParseTime(pr.CreatedOn)
+			if created.IsZero() || now.Sub(created) < olderThan {
+				continue
+			}
+			author := pr.Author.DisplayName
+			if author == "" {
+				author = pr.Author.Nickname
+			}
+			aged = append(aged, AgedPR{
+				Repo:      r.RepoSlug,
+				ID:        pr.ID,
+				Title:     pr.Title,
+				Author:    author,
+				CreatedOn: created,
+				AgeDays:   int(now.Sub(created).Hours() / 24),
+				Review:    reviewState(pr),
+				Approvals: countApprovals(pr),
+				Reviewers: countReviewers(pr),
+				URL:       pr.Links.HTML.Href,
+			})
+		}
+	}
+	sort.SliceStable(aged, func(i, j int) bool {
+		return aged[i].CreatedOn.Before(aged[j].CreatedOn)
+	})
+	return aged
+}
+
+// reviewState sums up where a PR's review stands.
+func reviewState(pr bitbucket.PullRequest) string {
+	s := summarizeReview(pr)
+	switch {
+	case len(s.changesRequested) > 0:
+		return ReviewChangesRequested
+	case countReviewers(pr) == 0:
+		return ReviewNoReviewers
+	case len(s.waiting) == 0:
+		return ReviewApproved
+	}
+	return ReviewWaiting
+}
+
+// PrintAgingReport displays the aged PRs, oldest first, followed by a tally
+// per author.
+func PrintAgingReport(prs []AgedPR) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold)
+
+	if len(prs) == 0 {
+		fmt.Println("  No open pull requests found.")
+		return
+	}
+
+	fmt.Println()
+	bold.Printf("  %-5s %-25s %-6s %-40s %-20s %s\n", "AGE", "REPO", "PR", "TITLE", "AUTHOR", "REVIEW")
+	byAuthor := make(map[string]int)
+	for _, pr := range prs {
+		review := fmt.Sprintf("%s %d/%d", pr.Review, pr.Approvals, pr.Reviewers)
+		switch pr.Review {
+		case ReviewApproved:
+			review = green(review)
+		case ReviewChangesRequested:
+			review = red(review)
+		default:
+			review = yellow(review)
+		}
+		fmt.Printf("  %-5s %-25s #%-5d %-40s %s %s\n",
+			fmt.Sprintf("%dd", pr.AgeDays),
+			truncate(pr.Repo, 25),
+			pr.ID,
+			truncate(pr.Title, 40),
+			cyan(fmt.Sprintf("%-20s", truncate(pr.Author, 20))),
+			review,
+		)
+		byAuthor[pr.Author]++
+	}
+
+	authors := make([]string, 0, len(byAuthor))
+	for a := range byAuthor {
+		authors = append(authors, a)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if byAuthor[authors[i]] != byAuthor[authors[j]] {
+			return byAuthor[authors[i]] > byAuthor[authors[j]]
+		}
+		return authors[i] < authors[j]
+	})
+	fmt.Printf("\n%s %d PRs, oldest %dd\n", bold.Sprint("Summary:"), len(prs), prs[0].AgeDays)
+	for _, a := range authors {
+		fmt.Printf("  %-20s %d\n", a, byAuthor[a])
+	}
+}
+
+// WriteAgingCSV writes the aged PRs as CSV, one row per PR.
+func WriteAgingCSV(w io.Writer, prs []AgedPR) error {
+	out := csv.NewWriter(w)
+	header := []string{"repo", "id", "title", "author", "created", "age_days", "review", "approvals", "reviewers", "url"}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		record := []string{
+			pr.Repo,
+			strconv.Itoa(pr.ID),
+			pr.Title,
+			pr.Author,
+			pr.CreatedOn.Format("2006-01-02"),
+			strconv.Itoa(pr.AgeDays),
+			pr.Review,
+			strconv.Itoa(pr.Approvals),
+			strconv.Itoa(pr.Reviewers),
+			pr.URL,
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteAgingJSON writes the aged PRs as an indented JSON array.
+func WriteAgingJSON(w io.Writer, prs []AgedPR) error {
+	if prs == nil {
+		prs = []AgedPR{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(prs)
+}
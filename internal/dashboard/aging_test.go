@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestAgePRs(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	reviewer := func(name string, approved bool, state string) bitbucket.PRParticipant {
+		return bitbucket.PRParticipant{User: bitbucket.PRAuthor{DisplayName: name}, Role: "REVIEWER", Approved: approved, State: state}
+	}
+	results := []RepoPRs{
+		{RepoSlug: "api", PRs: []bitbucket.PullRequest{
+			{ID: 1, Title: "Old", Author: bitbucket.PRAuthor{DisplayName: "Jane"}, CreatedOn: "2026-03-01T12:00:00+00:00",
+				Participants: []bitbucket.PRParticipant{reviewer("Bob", false, "changes_requested")}},
+			{ID: 2, Title: "Fresh", CreatedOn: "2026-03-19T12:00:00+00:00"},
+		}},
+		{RepoSlug: "web", PRs: []bitbucket.PullRequest{
+			{ID: 5, Title: "Older", Author: bitbucket.PRAuthor{Nickname: "john"}, CreatedOn: "2026-02-20T12:00:00+00:00",
+				Participants: []bitbucket.PRParticipant{reviewer("Alice", true, "approved")}},
+			{ID: 6, Title: "No date"},
+		}},
+		{RepoSlug: "broken", Error: "forbidden"},
+	}
+
+	got := AgePRs(results, 7*24*time.Hour, now)
+	if len(got) != 2 {
+		t.Fatalf("AgePRs() = %+v, want 2 PRs", got)
+	}
+	if got[0].Repo != "web" || got[0].AgeDays != 28 || got[0].Author != "john" || got[0].Review != ReviewApproved || got[0].Approvals != 1 {
+		t.Errorf("oldest = %+v", got[0])
+	}
+	if got[1].ID != 1 || got[1].AgeDays != 19 || got[1].Review != ReviewChangesRequested {
+		t.Errorf("second = %+v", got[1])
+	}
+
+	if all := AgePRs(results, 0, now); len(all) != 3 || all[2].Review != ReviewNoReviewers {
+		t.Errorf("AgePRs(0) = %+v, want every dated PR", all)
+	}
+}
+
+func TestWriteAgingCSV(t *testing.T) {
+	prs := []AgedPR{{
+		Repo: "api", ID: 1, Title: "Fix, then ship", Author: "Jane",
+		CreatedOn: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), AgeDays: 19,
+		Review: ReviewWaiting, Approvals: 1, Reviewers: 2, URL: "https://bitbucket.org/ws/api/pull-requests/1",
+	}}
+	var buf bytes.Buffer
+	if err := WriteAgingCSV(&buf, prs); err != nil {
+		t.Fatal(err)
+	}
+	want := "repo,id,title,author,created,age_days,review,approvals,reviewers,url\n" +
+		`api,1,"Fix, then ship",Jane,2026-03-01,19,waiting,1,2,https://bitbucket.org/ws/api/pull-requests/1` + "\n"
+	if buf.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteAgingJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAgingJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("JSON = %q, want []", buf.String())
+	}
+}
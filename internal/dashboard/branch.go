@@ -0,0 +1,189 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// BranchStatus is one repo's row of the branch/PR/build matrix.
+type BranchStatus struct {
+	RepoSlug     string
+	BranchExists bool
+	PR           *bitbucket.PullRequest  // open PR from the branch, else the last merged or declined one
+	Builds       []bitbucket.BuildStatus // builds of an open PR
+	Error        string
+}
+
+// Merged reports whether the branch's PR has been merged.
+func (s BranchStatus) Merged() bool {
+	return s.PR != nil && s.PR.State == "MERGED"
+}
+
+// AllMerged reports whether every repo's PR has been merged.
+func AllMerged(rows []BranchStatus) bool {
+	for _, r := range rows {
+		if !r.Merged() {
+			return false
+		}
+	}
+	return len(rows) > 0
+}
+
+// FetchBranchStatus fetches, for each repo concurrently, whether the branch
+// exists, its PR and that PR's builds.
+func (f *Fetcher) FetchBranchStatus(workspace string, repos []string, branchName string) []BranchStatus {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		rows []BranchStatus
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			row := f.branchStatus(ws, slug, branchName)
+			row.RepoSlug = repoSlug
+
+			mu.Lock()
+			rows = append(rows, row)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].RepoSlug < rows[j].RepoSlug
+	})
+	return rows
+}
+
+func (f *Fetcher) branchStatus(workspace, repoSlug, branchName string) BranchStatus {
+	var row BranchStatus
+
+	_, err := f.client.GetBranch(workspace, repoSlug, branchName)
+	switch {
+	case err == nil:
+		row.BranchExists = true
+	case provider.Classify(err) != provider.ErrNotFound:
+		row.Error = err.Error()
+		return row
+	}
+
+	// A merged branch is often deleted, so look for its PR either way
+	for _, state := range []string{"OPEN", "MERGED", "DECLINED"} {
+		pr, err := f.client.FindPRByBranch(workspace, repoSlug, branchName, state)
+		if err != nil {
+			if errors.Is(err, bitbucket.ErrNoPR) {
+				continue
+			}
+			row.Error = err.Error()
+			return row
+		}
+		row.PR = pr
+		break
+	}
+
+	if row.PR != nil && row.PR.State == "OPEN" {
+		builds, err := f.client.ListPRBuildStatuses(workspace, repoSlug, row.PR.ID)
+		if err != nil {
+			row.Error = err.Error()
+			return row
+		}
+		row.Builds = builds
+	}
+	return row
+}
+
+// PrintBranchStatus displays one line per repo with the branch, its PR and
+// builds, followed by a tally.
+func PrintBranchStatus(rows []BranchStatus) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	bold := color.New(color.Bold)
+
+	fmt.Println()
+	bold.Printf("  %-30s %-8s %-16s %s\n", "REPO", "BRANCH", "PR", "BUILDS")
+
+	merged, open, failing, errCount := 0, 0, 0, 0
+	for _, r := range rows {
+		if r.Error != "" {
+			errCount++
+			fmt.Printf("  %-30s %s\n", r.RepoSlug, red("✗ "+r.Error))
+			continue
+		}
+
+		branch := yellow(fmt.Sprintf("%-8s", "missing"))
+		if r.BranchExists {
+			branch = green(fmt.Sprintf("%-8s", "exists"))
+		}
+
+		pr := fmt.Sprintf("%-16s", "none")
+		switch {
+		case r.PR == nil:
+		case r.Merged():
+			merged++
+			pr = green(fmt.Sprintf("%-16s", fmt.Sprintf("#%d merged", r.PR.ID)))
+		case r.PR.State == "OPEN":
+			open++
+			pr = yellow(fmt.Sprintf("%-16s", fmt.Sprintf("#%d open", r.PR.ID)))
+		default:
+			pr = red(fmt.Sprintf("%-16s", fmt.Sprintf("#%d %s", r.PR.ID, strings.ToLower(r.PR.State))))
+		}
+
+		builds, failed := formatBuilds(r.Builds, green, yellow, red)
+		if failed {
+			failing++
+		}
+		fmt.Printf("  %-30s %s %s %s\n", r.RepoSlug, branch, pr, builds)
+	}
+
+	fmt.Printf("\n%s %s merged, %s open, %s with failed builds, %s errors\n",
+		bold.Sprint("Summary:"),
+		green(fmt.Sprintf("%d/%d", merged, len(rows))),
+		yellow(fmt.Sprintf("%d", open)),
+		red(fmt.Sprintf("%d", failing)),
+		red(fmt.Sprintf("%d", errCount)),
+	)
+}
+
+// formatBuilds summarizes build statuses, e.g. "2 passed, 1 running", and
+// reports whether any build failed.
+func formatBuilds(builds []bitbucket.BuildStatus, green, yellow, red func(a ...interface{}) string) (string, bool) {
+	if len(builds) == 0 {
+		return "-", false
+	}
+	passed, running, failed := 0, 0, 0
+	for _, b := range builds {
+		switch b.State {
+		case bitbucket.BuildSuccessful:
+			passed++
+		case bitbucket.BuildInProgress:
+			running++
+		default:
+			failed++
+		}
+	}
+	var parts []string
+	if passed > 0 {
+		parts = append(parts, green(fmt.Sprintf("%d passed", passed)))
+	}
+	if running > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("%d running", running)))
+	}
+	if failed > 0 {
+		parts = append(parts, red(fmt.Sprintf("%d failed", failed)))
+	}
+	return strings.Join(parts, ", "), failed > 0
+}
@@ -0,0 +1,86 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestFetchBranchStatus(t *testing.T) {
+	// repo-a: branch and open PR with builds; repo-b: branch deleted after
+	// merging; repo-c: branch without PR
+	prs := map[string]bitbucket.PullRequest{
+		"repo-a/OPEN":   {ID: 3, State: "OPEN"},
+		"repo-b/MERGED": {ID: 9, State: "MERGED"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		slug := parts[3]
+		switch {
+		case strings.Contains(r.URL.Path, "/refs/branches/"):
+			if slug == "repo-b" {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "Branch not found"}})
+				return
+			}
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "release/1.4"})
+		case strings.HasSuffix(r.URL.Path, "/pullrequests/3/statuses"):
+			json.NewEncoder(w).Encode(bitbucket.PaginatedBuildStatuses{Values: []bitbucket.BuildStatus{
+				{Name: "unit", State: bitbucket.BuildSuccessful},
+				{Name: "e2e", State: bitbucket.BuildInProgress},
+			}})
+		case strings.HasSuffix(r.URL.Path, "/pullrequests"):
+			page := bitbucket.PaginatedPullRequests{}
+			if pr, ok := prs[slug+"/"+r.URL.Query().Get("state")]; ok {
+				page.Values = []bitbucket.PullRequest{pr}
+			}
+			json.NewEncoder(w).Encode(page)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	rows := newFetcherForServer(srv).FetchBranchStatus("ws", []string{"repo-c", "repo-a", "repo-b"}, "release/1.4")
+	if len(rows) != 3 {
+		t.Fatalf("len(rows) = %d, want 3", len(rows))
+	}
+	a, b, c := rows[0], rows[1], rows[2]
+	if a.RepoSlug != "repo-a" || !a.BranchExists || a.PR == nil || a.PR.ID != 3 || len(a.Builds) != 2 || a.Error != "" {
+		t.Errorf("repo-a = %+v", a)
+	}
+	if b.BranchExists || !b.Merged() || b.Builds != nil || b.Error != "" {
+		t.Errorf("repo-b = %+v, want merged PR without branch", b)
+	}
+	if !c.BranchExists || c.PR != nil || c.Error != "" {
+		t.Errorf("repo-c = %+v, want branch without PR", c)
+	}
+	if AllMerged(rows) {
+		t.Error("AllMerged() = true with an open PR")
+	}
+	if !AllMerged(rows[1:2]) {
+		t.Error("AllMerged() = false with only merged PRs")
+	}
+}
+
+func TestFormatBuilds(t *testing.T) {
+	plain := func(a ...interface{}) string { return a[0].(string) }
+	got, failed := formatBuilds([]bitbucket.BuildStatus{
+		{State: bitbucket.BuildSuccessful},
+		{State: bitbucket.BuildFailed},
+		{State: bitbucket.BuildInProgress},
+		{State: bitbucket.BuildSuccessful},
+	}, plain, plain, plain)
+	if got != "2 passed, 1 running, 1 failed" || !failed {
+		t.Errorf("formatBuilds() = %q, %v", got, failed)
+	}
+	if got, failed := formatBuilds(nil, plain, plain, plain); got != "-" || failed {
+		t.Errorf("formatBuilds(nil) = %q, %v", got, failed)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
 )
 
 // PRFilters controls which PRs to include.
@@ -23,11 +24,11 @@ type RepoPRs struct {
 
 // Fetcher concurrently fetches PRs across repos.
 type Fetcher struct {
-	client *bitbucket.Client
+	client provider.Provider
 }
 
 // NewFetcher creates a new dashboard fetcher.
-func NewFetcher(client *bitbucket.Client) *Fetcher {
+func NewFetcher(client provider.Provider) *Fetcher {
 	return &Fetcher{client: client}
 }
 
@@ -57,13 +58,17 @@ func (f *Fetcher) FetchAllPRs(workspace string, repos []string, filters PRFilter
 			if state == "" {
 				state = "OPEN"
 			}
-			prs, err := f.client.ListPullRequests(workspace, repoSlug, state)
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			prs, err := f.client.ListPullRequests(ws, slug, state)
 
 			result := RepoPRs{RepoSlug: repoSlug}
 			if err != nil {
 				result.Error = err.Error()
 			} else {
 				result.PRs = filterPRs(prs, filters, currentUser)
+				if state == "OPEN" {
+					f.fillParticipants(ws, slug, result.PRs)
+				}
 			}
 
 			mu.Lock()
@@ -81,6 +86,17 @@ func (f *Fetcher) FetchAllPRs(workspace string, repos []string, filters PRFilter
 	return results
 }
 
+// fillParticipants fetches reviewers and approvals for each PR, since PR lists
+// leave them out. A PR whose participants cannot be fetched keeps what it has.
+func (f *Fetcher) fillParticipants(workspace, repoSlug string, prs []bitbucket.PullRequest) {
+	for i := range prs {
+		participants, err := f.client.ListPRParticipants(workspace, repoSlug, prs[i].ID)
+		if err == nil {
+			prs[i].Participants = participants
+		}
+	}
+}
+
 // filterPRs applies author/mine filters to a PR list.
 func filterPRs(prs []bitbucket.PullRequest, filters PRFilters, currentUserUUID string) []bitbucket.PullRequest {
 	if filters.Author == "" && !filters.Mine {
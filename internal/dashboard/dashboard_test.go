@@ -2,6 +2,7 @@ package dashboard
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -48,6 +49,18 @@ func mockDashboardServer(t *testing.T, prsByRepo map[string][]bitbucket.PullRequ
 			return
 		}
 
+		// GET /2.0/repositories/{ws}/{slug}/pullrequests/{id} (participants)
+		if len(parts) == 6 && parts[4] == "pullrequests" {
+			for _, pr := range prsByRepo[parts[3]] {
+				if fmt.Sprint(pr.ID) == parts[5] {
+					json.NewEncoder(w).Encode(pr)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
 		// GET /2.0/repositories/{ws}/{slug}/pullrequests
 		if len(parts) >= 5 && parts[4] == "pullrequests" {
 			slug := parts[3]
@@ -206,3 +219,50 @@ func TestFilterPRs_NoFilters(t *testing.T) {
 		t.Errorf("len = %d, want 2", len(got))
 	}
 }
+
+func TestFetchAllPRs_FillsParticipants(t *testing.T) {
+	participants := []bitbucket.PRParticipant{
+		{User: bitbucket.PRAuthor{DisplayName: "Alice"}, Role: "REVIEWER", Approved: true, State: "approved"},
+		{User: bitbucket.PRAuthor{DisplayName: "Bob"}, Role: "REVIEWER", State: "changes_requested"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/pullrequests/7") {
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 7, Participants: participants})
+			return
+		}
+		// The list endpoint leaves participants out
+		json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: []bitbucket.PullRequest{{ID: 7}}})
+	}))
+	defer srv.Close()
+
+	f := newFetcherForServer(srv)
+	results := f.FetchAllPRs("ws", []string{"repo-a"}, PRFilters{})
+
+	if len(results) != 1 || len(results[0].PRs) != 1 {
+		t.Fatalf("results = %+v", results)
+	}
+	if got := results[0].PRs[0].Participants; len(got) != 2 || got[1].State != "changes_requested" {
+		t.Errorf("Participants = %+v", got)
+	}
+}
+
+func TestSummarizeReview(t *testing.T) {
+	pr := bitbucket.PullRequest{Participants: []bitbucket.PRParticipant{
+		{User: bitbucket.PRAuthor{DisplayName: "Alice"}, Role: "REVIEWER", Approved: true},
+		{User: bitbucket.PRAuthor{DisplayName: "Bob"}, Role: "REVIEWER", State: "changes_requested"},
+		{User: bitbucket.PRAuthor{Nickname: "carol"}, Role: "REVIEWER"},
+		{User: bitbucket.PRAuthor{DisplayName: "Dan"}, Role: "PARTICIPANT"},
+	}}
+
+	s := summarizeReview(pr)
+	if len(s.approved) != 1 || s.approved[0] != "Alice" {
+		t.Errorf("approved = %v", s.approved)
+	}
+	if len(s.changesRequested) != 1 || s.changesRequested[0] != "Bob" {
+		t.Errorf("changesRequested = %v", s.changesRequested)
+	}
+	if len(s.waiting) != 1 || s.waiting[0] != "carol" {
+		t.Errorf("waiting = %v", s.waiting)
+	}
+}
@@ -18,6 +18,7 @@ func PrintDashboard(results []RepoPRs) {
 
 	totalPRs := 0
 	totalApproved := 0
+	totalBlocked := 0
 	totalErrors := 0
 
 	for _, r := range results {
@@ -38,6 +39,11 @@ func PrintDashboard(results []RepoPRs) {
 			reviewerCount := countReviewers(pr)
 			approvalStr := formatApprovals(approvals, reviewerCount, green, yellow, red)
 			statusIcon := prStatusIcon(approvals, reviewerCount, green, yellow)
+			review := summarizeReview(pr)
+			if len(review.changesRequested) > 0 {
+				statusIcon = red("✗")
+				totalBlocked++
+			}
 
 			fmt.Printf("    %s #%-4d %-50s %s  %s\n",
 				statusIcon,
@@ -46,6 +52,9 @@ func PrintDashboard(results []RepoPRs) {
 				cyan(pr.Author.DisplayName),
 				approvalStr,
 			)
+			if line := review.format(green, yellow, red); line != "" {
+				fmt.Printf("           %s\n", line)
+			}
 
 			if approvals == reviewerCount && reviewerCount > 0 {
 				totalApproved++
@@ -58,14 +67,56 @@ func PrintDashboard(results []RepoPRs) {
 		return
 	}
 
-	fmt.Printf("\n%s %s open, %s approved, %s errors\n",
+	fmt.Printf("\n%s %s open, %s approved, %s changes requested, %s errors\n",
 		bold.Sprint("Summary:"),
 		green(fmt.Sprintf("%d", totalPRs)),
 		yellow(fmt.Sprintf("%d", totalApproved)),
+		red(fmt.Sprintf("%d", totalBlocked)),
 		red(fmt.Sprintf("%d", totalErrors)),
 	)
 }
 
+// reviewSummary lists participants by review state.
+type reviewSummary struct {
+	approved         []string
+	changesRequested []string
+	waiting          []string // reviewers who have not approved or requested changes
+}
+
+func summarizeReview(pr bitbucket.PullRequest) reviewSummary {
+	var s reviewSummary
+	for _, p := range pr.Participants {
+		name := p.User.DisplayName
+		if name == "" {
+			name = p.User.Nickname
+		}
+		switch {
+		case p.State == "changes_requested":
+			s.changesRequested = append(s.changesRequested, name)
+		case p.Approved:
+			s.approved = append(s.approved, name)
+		case p.Role == "REVIEWER":
+			s.waiting = append(s.waiting, name)
+		}
+	}
+	return s
+}
+
+// format renders the summary as one line, leaving out empty groups.
+func (s reviewSummary) format(green, yellow, red func(a ...interface{}) string) string {
+	var parts []string
+	if len(s.approved) > 0 {
+		parts = append(parts, green("approved: ")+strings.Join(s.approved, ", "))
+	}
+	if len(s.changesRequested) > 0 {
+		parts = append(parts, red("changes requested: ")+strings.Join(s.changesRequested, ", "))
+	}
+	if len(s.waiting) > 0 {
+		parts = append(parts, yellow("waiting on: ")+strings.Join(s.waiting, ", "))
+	}
+	return strings.Join(parts, "  ")
+}
+
 func countApprovals(pr bitbucket.PullRequest) int {
 	count := 0
 	for _, p := range pr.Participants {
@@ -0,0 +1,573 @@
+// Package github implements the provider API on top of the GitHub REST API.
+// Responses are converted into the bitbucket package's types.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/httpx"
+	"github.com/chinhstringee/buck/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultBaseURL is the public GitHub API. GitHub Enterprise uses https://<host>/api/v3.
+const DefaultBaseURL = "https://api.github.com"
+
+// Client wraps the GitHub REST API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	maxPages   int // page cap for ListRepositories
+}
+
+// NewClient creates a new GitHub API client. An empty baseURL uses DefaultBaseURL.
+func NewClient(baseURL, token string) *Client {
+	return NewClientWithHTTPClient(&http.Client{Timeout: 30 * time.Second}, baseURL, token)
+}
+
+// NewClientWithHTTPClient creates a GitHub API client with a custom http.Client.
+func NewClientWithHTTPClient(httpClient *http.Client, baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		maxPages:   httpx.DefaultMaxPages,
+	}
+}
+
+// SetMaxPages sets how many pages ListRepositories fetches; n <= 0 keeps the current cap.
+func (c *Client) SetMaxPages(n int) {
+	if n > 0 {
+		c.maxPages = n
+	}
+}
+
+// ListRepositories returns all repos owned by an organization (handles pagination).
+// When owner is not an organization it lists the authenticated user's own repos,
+// private ones included, keeping those owned by owner.
+func (c *Client) ListRepositories(owner string) ([]bitbucket.Repository, error) {
+	repos, err := c.listRepos(fmt.Sprintf("%s/orgs/%s/repos?per_page=100", c.baseURL, url.PathEscape(owner)), "")
+	if err != nil && bitbucket.StatusCode(err) == http.StatusNotFound {
+		repos, err = c.listRepos(c.baseURL+"/user/repos?affiliation=owner&per_page=100", owner)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	return repos, nil
+}
+
+// listRepos pages through nextURL. A non-empty owner keeps only repos owned by that login.
+func (c *Client) listRepos(nextURL, owner string) ([]bitbucket.Repository, error) {
+	var all []bitbucket.Repository
+
+	for i := 0; nextURL != "" && i < c.maxPages; i++ {
+		var page []repository
+		next, err := c.doRequest("GET", nextURL, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			if owner != "" && !strings.EqualFold(r.Owner.Login, owner) {
+				continue
+			}
+			all = append(all, r.toBitbucket())
+		}
+		nextURL = next
+	}
+	if nextURL != "" {
+		httpx.WarnTruncated(c.maxPages, len(all))
+	}
+	return all, nil
+}
+
+// GetRepository returns a single repository.
+func (c *Client) GetRepository(owner, repo string) (*bitbucket.Repository, error) {
+	var r repository
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, ""), nil, &r); err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %w", repo, err)
+	}
+	converted := r.toBitbucket()
+	return &converted, nil
+}
+
+// ForkRepository forks a repository into targetOwner, an organization or the
+// authenticated user. GitHub creates forks asynchronously; the returned
+// repository may take a moment to become usable.
+func (c *Client) ForkRepository(owner, repo, targetOwner string) (*bitbucket.Repository, error) {
+	body := map[string]any{}
+	if u, err := c.GetCurrentUser(); err != nil || !strings.EqualFold(u.Username, targetOwner) {
+		body["organization"] = targetOwner
+	}
+	var r repository
+	if _, err := c.doRequest("POST", c.repoURL(owner, repo, "/forks"), body, &r); err != nil {
+		return nil, fmt.Errorf("failed to fork %s: %w", repo, err)
+	}
+	converted := r.toBitbucket()
+	return &converted, nil
+}
+
+// ArchiveRepository makes a repository read-only.
+func (c *Client) ArchiveRepository(owner, repo string) error {
+	if _, err := c.doRequest("PATCH", c.repoURL(owner, repo, ""), map[string]any{"archived": true}, nil); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", repo, err)
+	}
+	return nil
+}
+
+// DeleteRepository permanently deletes a repository.
+func (c *Client) DeleteRepository(owner, repo string) error {
+	if _, err := c.doRequest("DELETE", c.repoURL(owner, repo, ""), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", repo, err)
+	}
+	return nil
+}
+
+// CreateBranch creates a branch from a source branch name or commit SHA.
+func (c *Client) CreateBranch(owner, repo, branchName, source string) (*bitbucket.Branch, error) {
+	sha := source
+	if !shaPattern.MatchString(source) {
+		var ref gitRef
+		refURL := c.repoURL(owner, repo, "/git/ref/heads/"+escapeRef(source))
+		if _, err := c.doRequest("GET", refURL, nil, &ref); err != nil {
+			return nil, fmt.Errorf("failed to resolve source branch %q: %w", source, err)
+		}
+		sha = ref.Object.SHA
+	}
+
+	body := map[string]string{"ref": "refs/heads/" + branchName, "sha": sha}
+	var created gitRef
+	if _, err := c.doRequest("POST", c.repoURL(owner, repo, "/git/refs"), body, &created); err != nil {
+		return nil, err
+	}
+
+	return &bitbucket.Branch{
+		Name:   branchName,
+		Target: bitbucket.BranchTarget{Hash: created.Object.SHA},
+	}, nil
+}
+
+// CreatePullRequest opens a pull request.
+func (c *Client) CreatePullRequest(owner, repo string, pr bitbucket.CreatePullRequestRequest) (*bitbucket.PullRequest, error) {
+	body := map[string]string{
+		"title": pr.Title,
+		"body":  pr.Description,
+		"head":  pr.Source.Branch.Name,
+		"base":  pr.Destination.Branch.Name,
+	}
+
+	var result pullRequest
+	if _, err := c.doRequest("POST", c.repoURL(owner, repo, "/pulls"), body, &result); err != nil {
+		return nil, err
+	}
+	converted := result.toBitbucket()
+	return &converted, nil
+}
+
+// ResolveCommit returns the commit SHA a tag, branch or (short) SHA points to.
+func (c *Client) ResolveCommit(owner, repo, ref string) (string, error) {
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, "/commits/"+escapeRef(ref)), nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", ref, err)
+	}
+	return commit.SHA, nil
+}
+
+// ListCommits returns commits reachable from include but not from exclude, newest first.
+func (c *Client) ListCommits(owner, repo, include, exclude string) ([]bitbucket.Commit, error) {
+	compareURL := c.repoURL(owner, repo, "/compare/"+escapeRef(exclude)+"..."+escapeRef(include))
+
+	var cmp comparison
+	if _, err := c.doRequest("GET", compareURL, nil, &cmp); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	// GitHub lists oldest first; Bitbucket (and the description builder) expect newest first
+	commits := make([]bitbucket.Commit, 0, len(cmp.Commits))
+	for i := len(cmp.Commits) - 1; i >= 0; i-- {
+		commits = append(commits, bitbucket.Commit{
+			Hash:    cmp.Commits[i].SHA,
+			Message: cmp.Commits[i].Commit.Message,
+			Date:    cmp.Commits[i].Commit.Committer.Date,
+			Author:  bitbucket.CommitAuthor{Raw: cmp.Commits[i].Commit.Author.Name},
+			Links:   bitbucket.CommitLinks{HTML: bitbucket.LinkRef{Href: cmp.Commits[i].HTMLURL}},
+		})
+	}
+	return commits, nil
+}
+
+// DeleteBranch deletes a branch from a repository.
+func (c *Client) DeleteBranch(owner, repo, branchName string) error {
+	_, err := c.doRequest("DELETE", c.repoURL(owner, repo, "/git/refs/heads/"+escapeRef(branchName)), nil, nil)
+	return err
+}
+
+// GetBranch returns a single branch, including the date of its tip commit.
+func (c *Client) GetBranch(owner, repo, branchName string) (*bitbucket.Branch, error) {
+	var b branch
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, "/branches/"+escapeRef(branchName)), nil, &b); err != nil {
+		return nil, fmt.Errorf("failed to get branch %q: %w", branchName, err)
+	}
+	return &bitbucket.Branch{Name: b.Name, Target: bitbucket.BranchTarget{Hash: b.Commit.SHA, Date: b.Commit.Commit.Committer.Date}}, nil
+}
+
+// ListBranches returns all branches in a repository (handles pagination).
+func (c *Client) ListBranches(owner, repo string) ([]bitbucket.Branch, error) {
+	const maxPages = 50
+	var all []bitbucket.Branch
+
+	nextURL := c.repoURL(owner, repo, "/branches?per_page=100")
+	for i := 0; nextURL != "" && i < maxPages; i++ {
+		var page []branch
+		next, err := c.doRequest("GET", nextURL, nil, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+		for _, b := range page {
+			all = append(all, bitbucket.Branch{Name: b.Name, Target: bitbucket.BranchTarget{Hash: b.Commit.SHA}})
+		}
+		nextURL = next
+	}
+	return all, nil
+}
+
+// ListPullRequests returns PRs for a repo filtered by Bitbucket-style state (default: OPEN).
+// MERGED and DECLINED are both "closed" on GitHub and are told apart by merged_at.
+func (c *Client) ListPullRequests(owner, repo, state string) ([]bitbucket.PullRequest, error) {
+	prs, err := c.listPulls(owner, repo, url.Values{}, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	return prs, nil
+}
+
+// FindPRByBranch finds a PR by source branch name and state (default: OPEN).
+func (c *Client) FindPRByBranch(owner, repo, branchName, state string) (*bitbucket.PullRequest, error) {
+	if state == "" {
+		state = "OPEN"
+	}
+	prs, err := c.listPulls(owner, repo, url.Values{"head": {owner + ":" + branchName}}, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR for branch %q: %w", branchName, err)
+	}
+	if len(prs) == 0 {
+		return nil, bitbucket.NoPRError(state, branchName)
+	}
+	return &prs[0], nil
+}
+
+func (c *Client) listPulls(owner, repo string, query url.Values, state string) ([]bitbucket.PullRequest, error) {
+	const maxPages = 10
+	if state == "" {
+		state = "OPEN"
+	}
+	query.Set("state", "closed")
+	if state == "OPEN" {
+		query.Set("state", "open")
+	}
+	query.Set("per_page", "50")
+
+	var all []bitbucket.PullRequest
+	nextURL := c.repoURL(owner, repo, "/pulls?"+query.Encode())
+	for i := 0; nextURL != "" && i < maxPages; i++ {
+		var page []pullRequest
+		next, err := c.doRequest("GET", nextURL, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			if converted := p.toBitbucket(); converted.State == state {
+				all = append(all, converted)
+			}
+		}
+		nextURL = next
+	}
+	return all, nil
+}
+
+// mergeMethods maps Bitbucket merge strategies to GitHub merge methods.
+var mergeMethods = map[string]string{
+	"merge_commit": "merge",
+	"squash":       "squash",
+	"fast_forward": "rebase",
+}
+
+// MergePR merges a pull request. CloseSourceBranch deletes the head branch afterwards.
+func (c *Client) MergePR(owner, repo string, prID int, req bitbucket.MergePRRequest) error {
+	body := map[string]string{}
+	if req.MergeStrategy != "" {
+		method, ok := mergeMethods[req.MergeStrategy]
+		if !ok {
+			return fmt.Errorf("merge strategy %q is not supported on GitHub", req.MergeStrategy)
+		}
+		body["merge_method"] = method
+	}
+	if req.Message != "" {
+		body["commit_message"] = req.Message
+	}
+
+	var pr pullRequest
+	if req.CloseSourceBranch {
+		if _, err := c.doRequest("GET", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d", prID)), nil, &pr); err != nil {
+			return err
+		}
+	}
+	if _, err := c.doRequest("PUT", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d/merge", prID)), body, nil); err != nil {
+		return err
+	}
+	if req.CloseSourceBranch && pr.Head.Ref != "" {
+		return c.DeleteBranch(owner, repo, pr.Head.Ref)
+	}
+	return nil
+}
+
+// DeclinePR closes a pull request without merging.
+func (c *Client) DeclinePR(owner, repo string, prID int) error {
+	body := map[string]string{"state": "closed"}
+	_, err := c.doRequest("PATCH", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d", prID)), body, nil)
+	return err
+}
+
+// ApprovePR submits an approving review.
+func (c *Client) ApprovePR(owner, repo string, prID int) error {
+	body := map[string]string{"event": "APPROVE"}
+	_, err := c.doRequest("POST", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d/reviews", prID)), body, nil)
+	return err
+}
+
+// ListPRParticipants returns the reviewers of a pull request with their latest
+// review state, plus requested reviewers who have not reviewed yet.
+func (c *Client) ListPRParticipants(owner, repo string, prID int) ([]bitbucket.PRParticipant, error) {
+	var reviews []review
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d/reviews?per_page=100", prID)), nil, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to list reviews of PR #%d: %w", prID, err)
+	}
+	var pending requestedReviewers
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d/requested_reviewers", prID)), nil, &pending); err != nil {
+		return nil, fmt.Errorf("failed to list requested reviewers of PR #%d: %w", prID, err)
+	}
+	return participantsFromReviews(reviews, pending.Users), nil
+}
+
+// ListPRBuildStatuses returns the commit statuses and check runs of a pull
+// request's head commit, mapped to Bitbucket's build states.
+func (c *Client) ListPRBuildStatuses(owner, repo string, prID int) ([]bitbucket.BuildStatus, error) {
+	var pr pullRequest
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d", prID)), nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get PR #%d: %w", prID, err)
+	}
+	sha := url.PathEscape(pr.Head.SHA)
+
+	var combined combinedStatus
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, "/commits/"+sha+"/status"), nil, &combined); err != nil {
+		return nil, fmt.Errorf("failed to get commit status of PR #%d: %w", prID, err)
+	}
+	var checks checkRuns
+	if _, err := c.doRequest("GET", c.repoURL(owner, repo, "/commits/"+sha+"/check-runs?per_page=100"), nil, &checks); err != nil {
+		return nil, fmt.Errorf("failed to list check runs of PR #%d: %w", prID, err)
+	}
+
+	statuses := make([]bitbucket.BuildStatus, 0, len(combined.Statuses)+len(checks.CheckRuns))
+	for _, s := range combined.Statuses {
+		statuses = append(statuses, s.toBitbucket())
+	}
+	for _, r := range checks.CheckRuns {
+		statuses = append(statuses, r.toBitbucket())
+	}
+	return statuses, nil
+}
+
+// CommentPR posts a comment on a pull request's conversation.
+func (c *Client) CommentPR(owner, repo string, prID int, text string) error {
+	body := map[string]string{"body": text}
+	_, err := c.doRequest("POST", c.repoURL(owner, repo, fmt.Sprintf("/issues/%d/comments", prID)), body, nil)
+	return err
+}
+
+// UpdatePR updates a pull request's title and description and requests reviewers.
+// Reviewers are identified by login (PRReviewer.AccountID).
+func (c *Client) UpdatePR(owner, repo string, prID int, req bitbucket.PRUpdateRequest) (*bitbucket.PullRequest, error) {
+	var result pullRequest
+	prURL := c.repoURL(owner, repo, fmt.Sprintf("/pulls/%d", prID))
+
+	if req.Title != "" || req.Description != "" || req.Destination != nil {
+		body := map[string]string{}
+		if req.Title != "" {
+			body["title"] = req.Title
+		}
+		if req.Description != "" {
+			body["body"] = req.Description
+		}
+		if req.Destination != nil {
+			body["base"] = req.Destination.Branch.Name
+		}
+		if _, err := c.doRequest("PATCH", prURL, body, &result); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(req.Reviewers) > 0 {
+		logins := make([]string, 0, len(req.Reviewers))
+		for _, r := range req.Reviewers {
+			if r.AccountID != "" {
+				logins = append(logins, r.AccountID)
+			}
+		}
+		body := map[string][]string{"reviewers": logins}
+		if _, err := c.doRequest("POST", prURL+"/requested_reviewers", body, &result); err != nil {
+			return nil, err
+		}
+	}
+
+	converted := result.toBitbucket()
+	return &converted, nil
+}
+
+// ListMergedPRBranches returns source branch names from merged PRs.
+func (c *Client) ListMergedPRBranches(owner, repo string) ([]string, error) {
+	prs, err := c.ListPullRequests(owner, repo, "MERGED")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var branches []string
+	for _, pr := range prs {
+		name := pr.Source.Branch.Name
+		if name != "" && !seen[name] {
+			branches = append(branches, name)
+			seen[name] = true
+		}
+	}
+	return branches, nil
+}
+
+// GetCurrentUser returns the authenticated user.
+func (c *Client) GetCurrentUser() (*bitbucket.User, error) {
+	var u user
+	if _, err := c.doRequest("GET", c.baseURL+"/user", nil, &u); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+	converted := u.toBitbucket()
+	return &converted, nil
+}
+
+// BranchURL returns the web URL of a branch.
+func (c *Client) BranchURL(owner, repo, branchName string) string {
+	return fmt.Sprintf("%s/%s/%s/tree/%s", c.webURL(), owner, repo, branchName)
+}
+
+// webURL derives the web host from the API base URL.
+func (c *Client) webURL() string {
+	if c.baseURL == DefaultBaseURL {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(c.baseURL, "/api/v3")
+}
+
+func (c *Client) repoURL(owner, repo, suffix string) string {
+	return fmt.Sprintf("%s/repos/%s/%s%s", c.baseURL, url.PathEscape(owner), url.PathEscape(repo), suffix)
+}
+
+// escapeRef path-escapes each segment of a ref so "feature/x" keeps its slash.
+func escapeRef(ref string) string {
+	parts := strings.Split(ref, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+var (
+	shaPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	linkNextRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// doRequest performs an authenticated request, decodes the JSON response and
+// returns the rel="next" pagination URL, if any.
+func (c *Client) doRequest(method, reqURL string, body any, result any) (next string, err error) {
+	var bodyReader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, reqURL, bodyReader)
+	if err != nil {
+		return "", err
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	requestID := httpx.NewRequestID()
+	req.Header.Set(httpx.RequestIDHeader, requestID)
+
+	span := tracing.StartRequest(req, requestID)
+	defer func() { tracing.End(span, err) }()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed (request id %s): %w", requestID, err)
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode == http.StatusNoContent {
+		return "", nil
+	}
+
+	if resp.StatusCode >= 400 {
+		respBody := httpx.ReadErrorBody(resp.Body)
+
+		statusErr := &bitbucket.StatusError{StatusCode: resp.StatusCode, Message: httpx.TruncateMessage(respBody)}
+		var apiErr apiError
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			statusErr = formatAPIError(resp.StatusCode, apiErr)
+		}
+		statusErr.RequestID = requestID
+		statusErr.ServerRequestID = httpx.ServerRequestID(resp)
+		return "", statusErr
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(httpx.LimitBody(resp.Body)).Decode(result); err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	if m := linkNextRe.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+		next = m[1]
+	}
+	return next, nil
+}
+
+// formatAPIError creates a user-friendly error from a GitHub error response.
+func formatAPIError(statusCode int, apiErr apiError) *bitbucket.StatusError {
+	msg := apiErr.Message
+	for _, e := range apiErr.Errors {
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+	}
+	return &bitbucket.StatusError{StatusCode: statusCode, Message: msg}
+}
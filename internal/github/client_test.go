@@ -0,0 +1,555 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestListRepositories_Pagination(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/repos" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/acme/repos?per_page=100&page=2>; rel="next"`, srvURL))
+			json.NewEncoder(w).Encode([]repository{{Name: "api", DefaultBranch: "main", Description: "API"}})
+			return
+		}
+		json.NewEncoder(w).Encode([]repository{{Name: "web", DefaultBranch: "master"}})
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	c := NewClient(srv.URL, "tok")
+	repos, err := c.ListRepositories("acme")
+	if err != nil {
+		t.Fatalf("ListRepositories error: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("len(repos) = %d, want 2", len(repos))
+	}
+	if repos[0].Slug != "api" || repos[0].MainBranch == nil || repos[0].MainBranch.Name != "main" {
+		t.Errorf("repos[0] = %+v", repos[0])
+	}
+	if repos[0].Description != "API" {
+		t.Errorf("Description = %q, want API", repos[0].Description)
+	}
+}
+
+func TestListRepositories_FallsBackToUser(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/user/repos":
+			gotQuery = r.URL.RawQuery
+			own := repository{Name: "dotfiles"}
+			own.Owner.Login = "Someone"
+			other := repository{Name: "shared"}
+			other.Owner.Login = "someone-else"
+			json.NewEncoder(w).Encode([]repository{own, other})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(apiError{Message: "Not Found"})
+		}
+	}))
+	defer srv.Close()
+
+	repos, err := NewClient(srv.URL, "tok").ListRepositories("someone")
+	if err != nil {
+		t.Fatalf("ListRepositories error: %v", err)
+	}
+	if !strings.Contains(gotQuery, "affiliation=owner") {
+		t.Errorf("query = %q, want affiliation=owner so private repos are listed", gotQuery)
+	}
+	if len(repos) != 1 || repos[0].Slug != "dotfiles" {
+		t.Errorf("repos = %+v, want only the repo owned by someone", repos)
+	}
+}
+
+func TestCreateBranch_ResolvesSourceBranch(t *testing.T) {
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/api/git/ref/heads/release/1.0":
+			json.NewEncoder(w).Encode(map[string]any{"object": map[string]string{"sha": "abc123"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/api/git/refs":
+			json.NewDecoder(r.Body).Decode(&gotBody)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]any{"ref": gotBody["ref"], "object": map[string]string{"sha": gotBody["sha"]}})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	branch, err := NewClient(srv.URL, "tok").CreateBranch("acme", "api", "feature/x", "release/1.0")
+	if err != nil {
+		t.Fatalf("CreateBranch error: %v", err)
+	}
+	if gotBody["ref"] != "refs/heads/feature/x" || gotBody["sha"] != "abc123" {
+		t.Errorf("request body = %v", gotBody)
+	}
+	if branch.Name != "feature/x" || branch.Target.Hash != "abc123" {
+		t.Errorf("branch = %+v", branch)
+	}
+}
+
+func TestCreateBranch_FromSHA(t *testing.T) {
+	sha := strings.Repeat("a", 40)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			t.Error("source SHA should not be resolved")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"object": map[string]string{"sha": sha}})
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "tok").CreateBranch("acme", "api", "feature/x", sha); err != nil {
+		t.Fatalf("CreateBranch error: %v", err)
+	}
+}
+
+func TestCreateBranch_AlreadyExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]any{"object": map[string]string{"sha": "abc"}})
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(apiError{Message: "Reference already exists"})
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "tok").CreateBranch("acme", "api", "feature/x", "main")
+	if err == nil || !strings.Contains(err.Error(), "API error (422): Reference already exists") {
+		t.Errorf("err = %v, want 422 Reference already exists", err)
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var gotBody map[string]string
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"number": 42, "state": "open", "html_url": "https://github.com/acme/api/pull/42"})
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "secret")
+	req := prRequest("Title", "Body", "feature/x", "main")
+	pr, err := c.CreatePullRequest("acme", "api", req)
+	if err != nil {
+		t.Fatalf("CreatePullRequest error: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotBody["head"] != "feature/x" || gotBody["base"] != "main" || gotBody["title"] != "Title" || gotBody["body"] != "Body" {
+		t.Errorf("request body = %v", gotBody)
+	}
+	if pr.ID != 42 || pr.State != "OPEN" || pr.Links.HTML.Href != "https://github.com/acme/api/pull/42" {
+		t.Errorf("pr = %+v", pr)
+	}
+}
+
+func TestListCommits_NewestFirst(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/api/compare/main...feature/x" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"commits":[{"sha":"1","commit":{"message":"first"}},{"sha":"2","commit":{"message":"second"}}]}`))
+	}))
+	defer srv.Close()
+
+	commits, err := NewClient(srv.URL, "tok").ListCommits("acme", "api", "feature/x", "main")
+	if err != nil {
+		t.Fatalf("ListCommits error: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Message != "second" || commits[1].Message != "first" {
+		t.Errorf("commits = %+v", commits)
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/api/commits/v1.2.0" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"sha":"0123456789abcdef"}`))
+	}))
+	defer srv.Close()
+
+	sha, err := NewClient(srv.URL, "tok").ResolveCommit("acme", "api", "v1.2.0")
+	if err != nil || sha != "0123456789abcdef" {
+		t.Errorf("ResolveCommit = %q, %v", sha, err)
+	}
+}
+
+func TestForkRepository(t *testing.T) {
+	for _, tt := range []struct {
+		target, wantOrg string
+	}{
+		{"octocat", ""}, // the authenticated user: no organization
+		{"acme-forks", "acme-forks"},
+	} {
+		var gotBody map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/user" {
+				w.Write([]byte(`{"login":"octocat"}`))
+				return
+			}
+			if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/api/forks" {
+				t.Errorf("%s %s", r.Method, r.URL.Path)
+			}
+			json.NewDecoder(r.Body).Decode(&gotBody)
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"name":"api","full_name":"` + tt.target + `/api"}`))
+		}))
+
+		repo, err := NewClient(srv.URL, "tok").ForkRepository("acme", "api", tt.target)
+		srv.Close()
+		if err != nil {
+			t.Fatalf("ForkRepository(%s) error: %v", tt.target, err)
+		}
+		if org, _ := gotBody["organization"].(string); org != tt.wantOrg {
+			t.Errorf("%s: organization = %q, want %q", tt.target, org, tt.wantOrg)
+		}
+		if repo.FullName != tt.target+"/api" {
+			t.Errorf("%s: repo = %+v", tt.target, repo)
+		}
+	}
+}
+
+func TestArchiveRepository(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/repos/acme/api" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"api","archived":true}`))
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "tok").ArchiveRepository("acme", "api"); err != nil {
+		t.Fatalf("ArchiveRepository error: %v", err)
+	}
+	if gotBody["archived"] != true {
+		t.Errorf("body = %v", gotBody)
+	}
+}
+
+func TestGetBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/api/branches/release/1.x" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"release/1.x","commit":{"sha":"abc","commit":{"committer":{"date":"2024-03-01T10:00:00Z"}}}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewClient(srv.URL, "tok").GetBranch("acme", "api", "release/1.x")
+	if err != nil {
+		t.Fatalf("GetBranch error: %v", err)
+	}
+	if b.Name != "release/1.x" || b.Target.Hash != "abc" || b.Target.Date != "2024-03-01T10:00:00Z" {
+		t.Errorf("branch = %+v", b)
+	}
+}
+
+func TestBranchURL(t *testing.T) {
+	if got := NewClient("", "").BranchURL("acme", "api", "feature/x"); got != "https://github.com/acme/api/tree/feature/x" {
+		t.Errorf("BranchURL = %q", got)
+	}
+	if got := NewClient("https://ghe.example.com/api/v3", "").BranchURL("acme", "api", "x"); got != "https://ghe.example.com/acme/api/tree/x" {
+		t.Errorf("BranchURL (GHE) = %q", got)
+	}
+}
+
+func prRequest(title, desc, source, dest string) bitbucket.CreatePullRequestRequest {
+	return bitbucket.CreatePullRequestRequest{
+		Title:       title,
+		Description: desc,
+		Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: source}},
+		Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: dest}},
+	}
+}
+
+func TestListPullRequests_MergedFiltersClosed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("state") != "closed" {
+			t.Errorf("state = %q, want closed", r.URL.Query().Get("state"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"number":1,"state":"closed","merged_at":"2024-01-01T00:00:00Z","head":{"ref":"feature/a"},"user":{"id":7,"login":"octo"}},
+			{"number":2,"state":"closed","merged_at":null,"head":{"ref":"feature/b"}}
+		]`))
+	}))
+	defer srv.Close()
+
+	prs, err := NewClient(srv.URL, "tok").ListPullRequests("acme", "api", "MERGED")
+	if err != nil {
+		t.Fatalf("ListPullRequests error: %v", err)
+	}
+	if len(prs) != 1 || prs[0].ID != 1 || prs[0].State != "MERGED" {
+		t.Fatalf("prs = %+v", prs)
+	}
+	if prs[0].Author.UUID != "7" || prs[0].Author.Nickname != "octo" {
+		t.Errorf("Author = %+v", prs[0].Author)
+	}
+}
+
+func TestFindPRByBranch_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("head") != "acme:feature/x" {
+			t.Errorf("head = %q", r.URL.Query().Get("head"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "tok").FindPRByBranch("acme", "api", "feature/x", "")
+	if err == nil || !strings.Contains(err.Error(), `no OPEN PR found for branch "feature/x"`) {
+		t.Errorf("err = %v", err)
+	}
+	if !errors.Is(err, bitbucket.ErrNoPR) {
+		t.Errorf("err = %v, want it to match bitbucket.ErrNoPR", err)
+	}
+}
+
+func TestMergePR_StrategyAndCloseBranch(t *testing.T) {
+	var gotMerge map[string]string
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/api/pulls/5":
+			w.Write([]byte(`{"number":5,"head":{"ref":"feature/x"}}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/acme/api/pulls/5/merge":
+			json.NewDecoder(r.Body).Decode(&gotMerge)
+			w.Write([]byte(`{"merged":true}`))
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	req := bitbucket.MergePRRequest{MergeStrategy: "squash", CloseSourceBranch: true}
+	if err := NewClient(srv.URL, "tok").MergePR("acme", "api", 5, req); err != nil {
+		t.Fatalf("MergePR error: %v", err)
+	}
+	if gotMerge["merge_method"] != "squash" {
+		t.Errorf("merge body = %v", gotMerge)
+	}
+	if deleted != "/repos/acme/api/git/refs/heads/feature/x" {
+		t.Errorf("deleted = %q", deleted)
+	}
+}
+
+func TestUpdatePR_RequestsReviewersByLogin(t *testing.T) {
+	var gotBody map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/api/pulls/3/requested_reviewers" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number":3,"requested_reviewers":[{"login":"alice"}]}`))
+	}))
+	defer srv.Close()
+
+	req := bitbucket.PRUpdateRequest{Reviewers: []bitbucket.PRReviewer{{AccountID: "alice"}}}
+	pr, err := NewClient(srv.URL, "tok").UpdatePR("acme", "api", 3, req)
+	if err != nil {
+		t.Fatalf("UpdatePR error: %v", err)
+	}
+	if len(gotBody["reviewers"]) != 1 || gotBody["reviewers"][0] != "alice" {
+		t.Errorf("body = %v", gotBody)
+	}
+	if len(pr.Reviewers) != 1 || pr.Reviewers[0].AccountID != "alice" {
+		t.Errorf("Reviewers = %+v", pr.Reviewers)
+	}
+}
+
+func TestUpdatePR_RetargetsBase(t *testing.T) {
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/repos/acme/api/pulls/3" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number":3,"base":{"ref":"develop"}}`))
+	}))
+	defer srv.Close()
+
+	req := bitbucket.PRUpdateRequest{Destination: &bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: "develop"}}}
+	pr, err := NewClient(srv.URL, "tok").UpdatePR("acme", "api", 3, req)
+	if err != nil {
+		t.Fatalf("UpdatePR error: %v", err)
+	}
+	if gotBody["base"] != "develop" || pr.Destination.Branch.Name != "develop" {
+		t.Errorf("body = %v, destination = %q", gotBody, pr.Destination.Branch.Name)
+	}
+}
+
+func TestCommentPR_PostsIssueComment(t *testing.T) {
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/api/issues/3/comments" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "tok").CommentPR("acme", "api", 3, "ping"); err != nil {
+		t.Fatalf("CommentPR error: %v", err)
+	}
+	if gotBody["body"] != "ping" {
+		t.Errorf("body = %v", gotBody)
+	}
+}
+
+func TestListPRParticipants_LatestReviewWins(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/acme/api/pulls/3/reviews":
+			w.Write([]byte(`[
+				{"user":{"login":"alice"},"state":"CHANGES_REQUESTED"},
+				{"user":{"login":"bob"},"state":"APPROVED"},
+				{"user":{"login":"alice"},"state":"APPROVED"},
+				{"user":{"login":"bob"},"state":"COMMENTED"},
+				{"user":{"login":"carol"},"state":"CHANGES_REQUESTED"}
+			]`))
+		case "/repos/acme/api/pulls/3/requested_reviewers":
+			w.Write([]byte(`{"users":[{"login":"dave"}]}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "tok").ListPRParticipants("acme", "api", 3)
+	if err != nil {
+		t.Fatalf("ListPRParticipants error: %v", err)
+	}
+	want := []struct {
+		login    string
+		approved bool
+		state    string
+	}{
+		{"alice", true, "approved"},
+		{"bob", true, "approved"},
+		{"carol", false, "changes_requested"},
+		{"dave", false, ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("participants = %+v", got)
+	}
+	for i, w := range want {
+		if got[i].User.Nickname != w.login || got[i].Approved != w.approved || got[i].State != w.state || got[i].Role != "REVIEWER" {
+			t.Errorf("participant %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestListPRBuildStatuses_StatusesAndCheckRuns(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/acme/api/pulls/3":
+			w.Write([]byte(`{"number":3,"head":{"ref":"feature/x","sha":"abc123"}}`))
+		case "/repos/acme/api/commits/abc123/status":
+			w.Write([]byte(`{"statuses":[{"context":"jenkins","state":"pending"}]}`))
+		case "/repos/acme/api/commits/abc123/check-runs":
+			w.Write([]byte(`{"check_runs":[
+				{"name":"lint","status":"completed","conclusion":"success"},
+				{"name":"test","status":"completed","conclusion":"timed_out"}
+			]}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "tok").ListPRBuildStatuses("acme", "api", 3)
+	if err != nil {
+		t.Fatalf("ListPRBuildStatuses error: %v", err)
+	}
+	want := []string{bitbucket.BuildInProgress, bitbucket.BuildSuccessful, bitbucket.BuildFailed}
+	if len(got) != len(want) {
+		t.Fatalf("statuses = %+v", got)
+	}
+	for i, state := range want {
+		if got[i].State != state {
+			t.Errorf("status %d (%s) = %q, want %q", i, got[i].Name, got[i].State, state)
+		}
+	}
+}
+
+func TestDoRequest_RecordsSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	defer tracing.UseTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "tok").GetRepository("acme", "gone"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "HTTP GET" {
+		t.Fatalf("spans = %v, want one HTTP GET", spans)
+	}
+	attrs := make(map[string]string)
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["http.response.status_code"] != "404" || !strings.HasSuffix(attrs["url.full"], "/repos/acme/gone") {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("status = %v, want error", spans[0].Status())
+	}
+}
@@ -0,0 +1,242 @@
+package github
+
+import (
+	"strconv"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// repository is a GitHub repository response.
+type repository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	UpdatedAt     string `json:"updated_at"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+func (r repository) toBitbucket() bitbucket.Repository {
+	repo := bitbucket.Repository{
+		Slug:        r.Name,
+		Name:        r.Name,
+		FullName:    r.FullName,
+		Description: r.Description,
+		UpdatedOn:   bitbucket.ParseTime(r.UpdatedAt),
+	}
+	if r.DefaultBranch != "" {
+		repo.MainBranch = &bitbucket.BranchRef{Name: r.DefaultBranch, Type: "branch"}
+	}
+	return repo
+}
+
+// gitRef is a GitHub git reference response.
+type gitRef struct {
+	Ref    string `json:"ref"`
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+}
+
+// branch is a GitHub branch list entry.
+type branch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Committer struct {
+				Date string `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"` // only in single-branch responses
+	} `json:"commit"`
+}
+
+// user is a GitHub user. The numeric ID stands in for Bitbucket's UUID
+// and the login for the account ID used to request reviewers.
+type user struct {
+	ID    int    `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+func (u user) toAuthor() bitbucket.PRAuthor {
+	name := u.Name
+	if name == "" {
+		name = u.Login
+	}
+	return bitbucket.PRAuthor{DisplayName: name, UUID: strconv.Itoa(u.ID), Nickname: u.Login, AccountID: u.Login}
+}
+
+func (u user) toBitbucket() bitbucket.User {
+	a := u.toAuthor()
+	return bitbucket.User{DisplayName: a.DisplayName, UUID: a.UUID, Nickname: a.Nickname, AccountID: a.AccountID, Username: u.Login}
+}
+
+// pullRequest is a GitHub pull request response.
+type pullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	User               user   `json:"user"`
+	RequestedReviewers []user `json:"requested_reviewers"`
+	MergedAt           string `json:"merged_at"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+func (p pullRequest) toBitbucket() bitbucket.PullRequest {
+	state := "OPEN"
+	switch {
+	case p.MergedAt != "":
+		state = "MERGED"
+	case p.State == "closed":
+		state = "DECLINED"
+	}
+	reviewers := make([]bitbucket.PRReviewer, 0, len(p.RequestedReviewers))
+	for _, r := range p.RequestedReviewers {
+		reviewers = append(reviewers, bitbucket.PRReviewer{AccountID: r.Login})
+	}
+	return bitbucket.PullRequest{
+		ID:          p.Number,
+		Title:       p.Title,
+		State:       state,
+		Description: p.Body,
+		Author:      p.User.toAuthor(),
+		Reviewers:   reviewers,
+		Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: p.Head.Ref}},
+		Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: p.Base.Ref}},
+		Links:       bitbucket.PRLinks{HTML: bitbucket.LinkRef{Href: p.HTMLURL}},
+		CreatedOn:   p.CreatedAt,
+		UpdatedOn:   p.UpdatedAt,
+	}
+}
+
+// combinedStatus is a GitHub combined commit status response.
+type combinedStatus struct {
+	Statuses []commitStatus `json:"statuses"`
+}
+
+// commitStatus is a status reported through the GitHub statuses API.
+type commitStatus struct {
+	Context   string `json:"context"`
+	State     string `json:"state"` // success, failure, error, pending
+	TargetURL string `json:"target_url"`
+}
+
+func (s commitStatus) toBitbucket() bitbucket.BuildStatus {
+	state := bitbucket.BuildInProgress
+	switch s.State {
+	case "success":
+		state = bitbucket.BuildSuccessful
+	case "failure", "error":
+		state = bitbucket.BuildFailed
+	}
+	return bitbucket.BuildStatus{Key: s.Context, Name: s.Context, State: state, URL: s.TargetURL}
+}
+
+// checkRuns is a GitHub check runs response.
+type checkRuns struct {
+	CheckRuns []checkRun `json:"check_runs"`
+}
+
+// checkRun is a GitHub Actions (or other app) check run.
+type checkRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`     // queued, in_progress, completed
+	Conclusion string `json:"conclusion"` // success, failure, neutral, cancelled, skipped, timed_out, action_required
+	HTMLURL    string `json:"html_url"`
+}
+
+func (r checkRun) toBitbucket() bitbucket.BuildStatus {
+	state := bitbucket.BuildInProgress
+	if r.Status == "completed" {
+		switch r.Conclusion {
+		case "success", "neutral", "skipped":
+			state = bitbucket.BuildSuccessful
+		case "cancelled":
+			state = bitbucket.BuildStopped
+		default:
+			state = bitbucket.BuildFailed
+		}
+	}
+	return bitbucket.BuildStatus{Key: r.Name, Name: r.Name, State: state, URL: r.HTMLURL}
+}
+
+// review is a GitHub pull request review.
+type review struct {
+	User  user   `json:"user"`
+	State string `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED
+}
+
+// requestedReviewers is a GitHub requested reviewers response.
+type requestedReviewers struct {
+	Users []user `json:"users"`
+}
+
+// participantsFromReviews converts reviews (oldest first) into participants,
+// keeping each user's latest approving, blocking or dismissed review. Comments
+// do not change a reviewer's state. Pending reviewers are appended.
+func participantsFromReviews(reviews []review, pending []user) []bitbucket.PRParticipant {
+	var participants []bitbucket.PRParticipant
+	index := make(map[string]int)
+	add := func(u user) *bitbucket.PRParticipant {
+		if i, ok := index[u.Login]; ok {
+			return &participants[i]
+		}
+		index[u.Login] = len(participants)
+		participants = append(participants, bitbucket.PRParticipant{User: u.toAuthor(), Role: "REVIEWER"})
+		return &participants[len(participants)-1]
+	}
+
+	for _, r := range reviews {
+		p := add(r.User)
+		switch r.State {
+		case "APPROVED":
+			p.Approved, p.State = true, "approved"
+		case "CHANGES_REQUESTED":
+			p.Approved, p.State = false, "changes_requested"
+		case "DISMISSED":
+			p.Approved, p.State = false, ""
+		}
+	}
+	for _, u := range pending {
+		add(u)
+	}
+	return participants
+}
+
+// comparison is a GitHub compare response.
+type comparison struct {
+	Commits []struct {
+		SHA     string `json:"sha"`
+		HTMLURL string `json:"html_url"`
+		Commit  struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+			Committer struct {
+				Date string `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	} `json:"commits"`
+}
+
+// apiError is a GitHub error response.
+type apiError struct {
+	Message string `json:"message"`
+	Errors  []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
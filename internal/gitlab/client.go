@@ -0,0 +1,494 @@
+// Package gitlab implements the provider API on top of the GitLab REST API (v4).
+// Responses are converted into the bitbucket package's types.
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/httpx"
+	"github.com/chinhstringee/buck/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultBaseURL is the gitlab.com API. Self-managed instances use https://<host>/api/v4.
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+// Client wraps the GitLab REST API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	maxPages   int // page cap for ListRepositories
+}
+
+// NewClient creates a new GitLab API client. An empty baseURL uses DefaultBaseURL.
+func NewClient(baseURL, token string) *Client {
+	return NewClientWithHTTPClient(&http.Client{Timeout: 30 * time.Second}, baseURL, token)
+}
+
+// NewClientWithHTTPClient creates a GitLab API client with a custom http.Client.
+func NewClientWithHTTPClient(httpClient *http.Client, baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		maxPages:   httpx.DefaultMaxPages,
+	}
+}
+
+// SetMaxPages sets how many pages ListRepositories fetches; n <= 0 keeps the current cap.
+func (c *Client) SetMaxPages(n int) {
+	if n > 0 {
+		c.maxPages = n
+	}
+}
+
+// ListRepositories returns all projects in a group, including subgroups (handles pagination).
+// Projects of a subgroup are returned by their full path ("group/sub/repo"), which
+// provider.SplitRepo resolves to the subgroup. Falls back to the user endpoint when
+// the namespace is not a group.
+func (c *Client) ListRepositories(group string) ([]bitbucket.Repository, error) {
+	repos, err := c.listProjects(group, fmt.Sprintf("%s/groups/%s/projects?per_page=100&include_subgroups=true", c.baseURL, url.PathEscape(group)))
+	if err != nil && bitbucket.StatusCode(err) == http.StatusNotFound {
+		repos, err = c.listProjects(group, fmt.Sprintf("%s/users/%s/projects?per_page=100", c.baseURL, url.PathEscape(group)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	return repos, nil
+}
+
+func (c *Client) listProjects(group, nextURL string) ([]bitbucket.Repository, error) {
+	var all []bitbucket.Repository
+
+	for i := 0; nextURL != "" && i < c.maxPages; i++ {
+		var page []project
+		next, err := c.doRequest("GET", nextURL, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			repo := p.toBitbucket()
+			repo.Slug = p.slugIn(group)
+			all = append(all, repo)
+		}
+		nextURL = next
+	}
+	if nextURL != "" {
+		httpx.WarnTruncated(c.maxPages, len(all))
+	}
+	return all, nil
+}
+
+// GetRepository returns a single project.
+func (c *Client) GetRepository(group, repo string) (*bitbucket.Repository, error) {
+	var p project
+	if _, err := c.doRequest("GET", c.projectURL(group, repo, ""), nil, &p); err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %w", repo, err)
+	}
+	converted := p.toBitbucket()
+	return &converted, nil
+}
+
+// ForkRepository forks a project into the targetGroup namespace (a group or user).
+func (c *Client) ForkRepository(group, repo, targetGroup string) (*bitbucket.Repository, error) {
+	body := map[string]any{"namespace_path": targetGroup}
+	var p project
+	if _, err := c.doRequest("POST", c.projectURL(group, repo, "/fork"), body, &p); err != nil {
+		return nil, fmt.Errorf("failed to fork %s: %w", repo, err)
+	}
+	converted := p.toBitbucket()
+	return &converted, nil
+}
+
+// ArchiveRepository makes a project read-only.
+func (c *Client) ArchiveRepository(group, repo string) error {
+	if _, err := c.doRequest("POST", c.projectURL(group, repo, "/archive"), nil, nil); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", repo, err)
+	}
+	return nil
+}
+
+// DeleteRepository deletes a project. Depending on instance settings GitLab
+// may only mark it for deletion and remove it after a delay.
+func (c *Client) DeleteRepository(group, repo string) error {
+	if _, err := c.doRequest("DELETE", c.projectURL(group, repo, ""), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", repo, err)
+	}
+	return nil
+}
+
+// CreateBranch creates a branch from a source branch, tag or commit SHA.
+func (c *Client) CreateBranch(group, repo, branchName, source string) (*bitbucket.Branch, error) {
+	q := url.Values{"branch": {branchName}, "ref": {source}}
+	reqURL := c.projectURL(group, repo, "/repository/branches?"+q.Encode())
+
+	var b branch
+	if _, err := c.doRequest("POST", reqURL, nil, &b); err != nil {
+		return nil, err
+	}
+	return &bitbucket.Branch{
+		Name:   b.Name,
+		Target: bitbucket.BranchTarget{Hash: b.Commit.ID},
+	}, nil
+}
+
+// CreatePullRequest opens a merge request.
+func (c *Client) CreatePullRequest(group, repo string, pr bitbucket.CreatePullRequestRequest) (*bitbucket.PullRequest, error) {
+	body := map[string]any{
+		"title":                pr.Title,
+		"description":          pr.Description,
+		"source_branch":        pr.Source.Branch.Name,
+		"target_branch":        pr.Destination.Branch.Name,
+		"remove_source_branch": pr.CloseSourceBranch,
+	}
+
+	var mr mergeRequest
+	if _, err := c.doRequest("POST", c.projectURL(group, repo, "/merge_requests"), body, &mr); err != nil {
+		return nil, err
+	}
+	converted := mr.toBitbucket()
+	return &converted, nil
+}
+
+// ResolveCommit returns the commit SHA a tag, branch or (short) SHA points to.
+func (c *Client) ResolveCommit(group, repo, ref string) (string, error) {
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if _, err := c.doRequest("GET", c.projectURL(group, repo, "/repository/commits/"+url.PathEscape(ref)), nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", ref, err)
+	}
+	return commit.ID, nil
+}
+
+// ListCommits returns commits reachable from include but not from exclude, newest first.
+func (c *Client) ListCommits(group, repo, include, exclude string) ([]bitbucket.Commit, error) {
+	q := url.Values{"from": {exclude}, "to": {include}, "straight": {"false"}}
+	reqURL := c.projectURL(group, repo, "/repository/compare?"+q.Encode())
+
+	var cmp comparison
+	if _, err := c.doRequest("GET", reqURL, nil, &cmp); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	// GitLab lists oldest first; Bitbucket (and the description builder) expect newest first
+	commits := make([]bitbucket.Commit, 0, len(cmp.Commits))
+	for i := len(cmp.Commits) - 1; i >= 0; i-- {
+		commits = append(commits, bitbucket.Commit{
+			Hash:    cmp.Commits[i].ID,
+			Message: cmp.Commits[i].Message,
+			Date:    cmp.Commits[i].CommittedDate,
+			Author:  bitbucket.CommitAuthor{Raw: cmp.Commits[i].AuthorName},
+			Links:   bitbucket.CommitLinks{HTML: bitbucket.LinkRef{Href: cmp.Commits[i].WebURL}},
+		})
+	}
+	return commits, nil
+}
+
+// DeleteBranch deletes a branch from a project.
+func (c *Client) DeleteBranch(group, repo, branchName string) error {
+	_, err := c.doRequest("DELETE", c.projectURL(group, repo, "/repository/branches/"+url.PathEscape(branchName)), nil, nil)
+	return err
+}
+
+// GetBranch returns a single branch, including the date of its tip commit.
+func (c *Client) GetBranch(group, repo, branchName string) (*bitbucket.Branch, error) {
+	var b branch
+	if _, err := c.doRequest("GET", c.projectURL(group, repo, "/repository/branches/"+url.PathEscape(branchName)), nil, &b); err != nil {
+		return nil, fmt.Errorf("failed to get branch %q: %w", branchName, err)
+	}
+	return &bitbucket.Branch{Name: b.Name, Target: bitbucket.BranchTarget{Hash: b.Commit.ID, Date: b.Commit.CommittedDate}}, nil
+}
+
+// ListBranches returns all branches in a project (handles pagination).
+func (c *Client) ListBranches(group, repo string) ([]bitbucket.Branch, error) {
+	const maxPages = 50
+	var all []bitbucket.Branch
+
+	nextURL := c.projectURL(group, repo, "/repository/branches?per_page=100")
+	for i := 0; nextURL != "" && i < maxPages; i++ {
+		var page []branch
+		next, err := c.doRequest("GET", nextURL, nil, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+		for _, b := range page {
+			all = append(all, bitbucket.Branch{Name: b.Name, Target: bitbucket.BranchTarget{Hash: b.Commit.ID, Date: b.Commit.CommittedDate}})
+		}
+		nextURL = next
+	}
+	return all, nil
+}
+
+// mrStates maps Bitbucket PR states to GitLab merge request states.
+var mrStates = map[string]string{
+	"OPEN":     "opened",
+	"MERGED":   "merged",
+	"DECLINED": "closed",
+}
+
+// ListPullRequests returns merge requests filtered by Bitbucket-style state (default: OPEN).
+func (c *Client) ListPullRequests(group, repo, state string) ([]bitbucket.PullRequest, error) {
+	prs, err := c.listMergeRequests(group, repo, url.Values{}, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	return prs, nil
+}
+
+// FindPRByBranch finds a merge request by source branch name and state (default: OPEN).
+func (c *Client) FindPRByBranch(group, repo, branchName, state string) (*bitbucket.PullRequest, error) {
+	if state == "" {
+		state = "OPEN"
+	}
+	prs, err := c.listMergeRequests(group, repo, url.Values{"source_branch": {branchName}}, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR for branch %q: %w", branchName, err)
+	}
+	if len(prs) == 0 {
+		return nil, bitbucket.NoPRError(state, branchName)
+	}
+	return &prs[0], nil
+}
+
+func (c *Client) listMergeRequests(group, repo string, query url.Values, state string) ([]bitbucket.PullRequest, error) {
+	const maxPages = 10
+	if state == "" {
+		state = "OPEN"
+	}
+	glState, ok := mrStates[state]
+	if !ok {
+		return nil, fmt.Errorf("state %q is not supported on GitLab", state)
+	}
+	query.Set("state", glState)
+	query.Set("per_page", "50")
+
+	var all []bitbucket.PullRequest
+	nextURL := c.projectURL(group, repo, "/merge_requests?"+query.Encode())
+	for i := 0; nextURL != "" && i < maxPages; i++ {
+		var page []mergeRequest
+		next, err := c.doRequest("GET", nextURL, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, mr := range page {
+			all = append(all, mr.toBitbucket())
+		}
+		nextURL = next
+	}
+	return all, nil
+}
+
+// MergePR merges a merge request. The "squash" strategy squashes; other strategies
+// follow the project's merge method.
+func (c *Client) MergePR(group, repo string, prID int, req bitbucket.MergePRRequest) error {
+	body := map[string]any{
+		"squash":                      req.MergeStrategy == "squash",
+		"should_remove_source_branch": req.CloseSourceBranch,
+	}
+	if req.Message != "" {
+		body["merge_commit_message"] = req.Message
+	}
+	_, err := c.doRequest("PUT", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d/merge", prID)), body, nil)
+	return err
+}
+
+// DeclinePR closes a merge request without merging.
+func (c *Client) DeclinePR(group, repo string, prID int) error {
+	body := map[string]string{"state_event": "close"}
+	_, err := c.doRequest("PUT", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d", prID)), body, nil)
+	return err
+}
+
+// ApprovePR approves a merge request.
+func (c *Client) ApprovePR(group, repo string, prID int) error {
+	_, err := c.doRequest("POST", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d/approve", prID)), nil, nil)
+	return err
+}
+
+// ListPRParticipants returns the reviewers of a merge request with their
+// review state, plus approvers who are not assigned as reviewers.
+func (c *Client) ListPRParticipants(group, repo string, prID int) ([]bitbucket.PRParticipant, error) {
+	var reviewers []mrReviewer
+	if _, err := c.doRequest("GET", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d/reviewers", prID)), nil, &reviewers); err != nil {
+		return nil, fmt.Errorf("failed to list reviewers of merge request !%d: %w", prID, err)
+	}
+	var approvals mrApprovals
+	if _, err := c.doRequest("GET", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d/approvals", prID)), nil, &approvals); err != nil {
+		return nil, fmt.Errorf("failed to get approvals of merge request !%d: %w", prID, err)
+	}
+	return participantsFromApprovals(reviewers, approvals), nil
+}
+
+// ListPRBuildStatuses returns the head pipeline of a merge request as a build
+// status, or nothing when no pipeline has run.
+func (c *Client) ListPRBuildStatuses(group, repo string, prID int) ([]bitbucket.BuildStatus, error) {
+	var mr mergeRequest
+	if _, err := c.doRequest("GET", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d", prID)), nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get merge request !%d: %w", prID, err)
+	}
+	if mr.HeadPipeline == nil {
+		return nil, nil
+	}
+	return []bitbucket.BuildStatus{mr.HeadPipeline.toBitbucket()}, nil
+}
+
+// CommentPR posts a note on a merge request.
+func (c *Client) CommentPR(group, repo string, prID int, text string) error {
+	body := map[string]string{"body": text}
+	_, err := c.doRequest("POST", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d/notes", prID)), body, nil)
+	return err
+}
+
+// UpdatePR updates a merge request's title, description and reviewers.
+// Reviewers are identified by numeric user ID (PRReviewer.AccountID).
+func (c *Client) UpdatePR(group, repo string, prID int, req bitbucket.PRUpdateRequest) (*bitbucket.PullRequest, error) {
+	body := map[string]any{}
+	if req.Title != "" {
+		body["title"] = req.Title
+	}
+	if req.Description != "" {
+		body["description"] = req.Description
+	}
+	if req.Destination != nil {
+		body["target_branch"] = req.Destination.Branch.Name
+	}
+	if len(req.Reviewers) > 0 {
+		ids := make([]int, 0, len(req.Reviewers))
+		for _, r := range req.Reviewers {
+			id, err := strconv.Atoi(r.AccountID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GitLab reviewer %q: expected a numeric user ID", r.AccountID+r.UUID)
+			}
+			ids = append(ids, id)
+		}
+		body["reviewer_ids"] = ids
+	}
+
+	var mr mergeRequest
+	if _, err := c.doRequest("PUT", c.projectURL(group, repo, fmt.Sprintf("/merge_requests/%d", prID)), body, &mr); err != nil {
+		return nil, err
+	}
+	converted := mr.toBitbucket()
+	return &converted, nil
+}
+
+// ListMergedPRBranches returns source branch names from merged merge requests.
+func (c *Client) ListMergedPRBranches(group, repo string) ([]string, error) {
+	prs, err := c.ListPullRequests(group, repo, "MERGED")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var branches []string
+	for _, pr := range prs {
+		name := pr.Source.Branch.Name
+		if name != "" && !seen[name] {
+			branches = append(branches, name)
+			seen[name] = true
+		}
+	}
+	return branches, nil
+}
+
+// GetCurrentUser returns the authenticated user.
+func (c *Client) GetCurrentUser() (*bitbucket.User, error) {
+	var u user
+	if _, err := c.doRequest("GET", c.baseURL+"/user", nil, &u); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+	converted := u.toBitbucket()
+	return &converted, nil
+}
+
+// BranchURL returns the web URL of a branch.
+func (c *Client) BranchURL(group, repo, branchName string) string {
+	return fmt.Sprintf("%s/%s/%s/-/tree/%s", strings.TrimSuffix(c.baseURL, "/api/v4"), group, repo, branchName)
+}
+
+// projectURL addresses a project by its URL-encoded "group/repo" path.
+func (c *Client) projectURL(group, repo, suffix string) string {
+	return fmt.Sprintf("%s/projects/%s%s", c.baseURL, url.PathEscape(group+"/"+repo), suffix)
+}
+
+var linkNextRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// doRequest performs an authenticated request, decodes the JSON response and
+// returns the rel="next" pagination URL, if any.
+func (c *Client) doRequest(method, reqURL string, body any, result any) (next string, err error) {
+	var bodyReader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, reqURL, bodyReader)
+	if err != nil {
+		return "", err
+	}
+
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	requestID := httpx.NewRequestID()
+	req.Header.Set(httpx.RequestIDHeader, requestID)
+
+	span := tracing.StartRequest(req, requestID)
+	defer func() { tracing.End(span, err) }()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed (request id %s): %w", requestID, err)
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode == http.StatusNoContent {
+		return "", nil
+	}
+
+	if resp.StatusCode >= 400 {
+		respBody := httpx.ReadErrorBody(resp.Body)
+
+		statusErr := &bitbucket.StatusError{StatusCode: resp.StatusCode, Message: httpx.TruncateMessage(respBody)}
+		var apiErr apiError
+		if json.Unmarshal(respBody, &apiErr) == nil {
+			if msg := apiErr.text(); msg != "" {
+				statusErr.Message = msg
+			}
+		}
+		statusErr.RequestID = requestID
+		statusErr.ServerRequestID = httpx.ServerRequestID(resp)
+		return "", statusErr
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(httpx.LimitBody(resp.Body)).Decode(result); err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	if m := linkNextRe.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+		next = m[1]
+	}
+	return next, nil
+}
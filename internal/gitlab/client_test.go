@@ -0,0 +1,405 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestListRepositories_PaginationAndSubgroups(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/groups/acme%2Fplatform/projects" {
+			t.Errorf("unexpected path %q", r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("include_subgroups") != "true" {
+			t.Error("include_subgroups not requested")
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			t.Errorf("PRIVATE-TOKEN = %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/groups/acme%%2Fplatform/projects?per_page=100&include_subgroups=true&page=2>; rel="next"`, srvURL))
+			w.Write([]byte(`[{"path":"api","name":"API","description":"Public API","default_branch":"main","namespace":{"path":"platform","name":"Platform"}}]`))
+			return
+		}
+		w.Write([]byte(`[{"path":"web","name":"Web","default_branch":"master","path_with_namespace":"acme/platform/web"},` +
+			`{"path":"ledger","path_with_namespace":"acme/platform/payments/ledger"}]`))
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	repos, err := NewClient(srv.URL, "tok").ListRepositories("acme/platform")
+	if err != nil {
+		t.Fatalf("ListRepositories error: %v", err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("len(repos) = %d, want 3", len(repos))
+	}
+	if repos[0].Slug != "api" || repos[0].MainBranch == nil || repos[0].MainBranch.Name != "main" {
+		t.Errorf("repos[0] = %+v", repos[0])
+	}
+	if repos[0].Project == nil || repos[0].Project.Name != "Platform" {
+		t.Errorf("repos[0].Project = %+v", repos[0].Project)
+	}
+	if repos[1].Slug != "web" {
+		t.Errorf("repos[1].Slug = %q, want %q", repos[1].Slug, "web")
+	}
+	// A subgroup's project keeps its full path, which SplitRepo sends to the subgroup
+	if repos[2].Slug != "acme/platform/payments/ledger" {
+		t.Errorf("repos[2].Slug = %q, want the full path", repos[2].Slug)
+	}
+}
+
+func TestListRepositories_FallsBackToUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/groups/") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Group Not Found"}`))
+			return
+		}
+		w.Write([]byte(`[{"path":"dotfiles"}]`))
+	}))
+	defer srv.Close()
+
+	repos, err := NewClient(srv.URL, "tok").ListRepositories("someone")
+	if err != nil {
+		t.Fatalf("ListRepositories error: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Slug != "dotfiles" {
+		t.Errorf("repos = %+v", repos)
+	}
+}
+
+func TestCreateBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/projects/acme%2Fapi/repository/branches" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("branch") != "feature/x" || r.URL.Query().Get("ref") != "main" {
+			t.Errorf("query = %v", r.URL.Query())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"feature/x","commit":{"id":"abc123"}}`))
+	}))
+	defer srv.Close()
+
+	branch, err := NewClient(srv.URL, "tok").CreateBranch("acme", "api", "feature/x", "main")
+	if err != nil {
+		t.Fatalf("CreateBranch error: %v", err)
+	}
+	if branch.Name != "feature/x" || branch.Target.Hash != "abc123" {
+		t.Errorf("branch = %+v", branch)
+	}
+}
+
+func TestCreateBranch_AlreadyExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Branch already exists"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "tok").CreateBranch("acme", "api", "feature/x", "main")
+	if err == nil || !strings.Contains(err.Error(), "API error (400): Branch already exists") {
+		t.Errorf("err = %v, want 400 Branch already exists", err)
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/projects/acme%2Fapi/merge_requests" {
+			t.Errorf("path = %q", r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"iid":7,"state":"opened","web_url":"https://gitlab.com/acme/api/-/merge_requests/7"}`))
+	}))
+	defer srv.Close()
+
+	req := bitbucket.CreatePullRequestRequest{
+		Title:       "Title",
+		Description: "Body",
+		Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: "feature/x"}},
+		Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: "main"}},
+	}
+	pr, err := NewClient(srv.URL, "tok").CreatePullRequest("acme", "api", req)
+	if err != nil {
+		t.Fatalf("CreatePullRequest error: %v", err)
+	}
+	if gotBody["source_branch"] != "feature/x" || gotBody["target_branch"] != "main" || gotBody["title"] != "Title" {
+		t.Errorf("request body = %v", gotBody)
+	}
+	if pr.ID != 7 || pr.State != "OPEN" || pr.Links.HTML.Href != "https://gitlab.com/acme/api/-/merge_requests/7" {
+		t.Errorf("pr = %+v", pr)
+	}
+}
+
+func TestCreatePullRequest_FieldErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":["Another open merge request already exists for this source branch: !3"]}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "tok").CreatePullRequest("acme", "api", bitbucket.CreatePullRequestRequest{})
+	if err == nil || !strings.Contains(err.Error(), "API error (409): Another open merge request already exists") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestListCommits_NewestFirst(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "main" || r.URL.Query().Get("to") != "feature/x" {
+			t.Errorf("query = %v", r.URL.Query())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"commits":[{"id":"1","message":"first"},{"id":"2","message":"second"}]}`))
+	}))
+	defer srv.Close()
+
+	commits, err := NewClient(srv.URL, "tok").ListCommits("acme", "api", "feature/x", "main")
+	if err != nil {
+		t.Fatalf("ListCommits error: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Message != "second" || commits[1].Message != "first" {
+		t.Errorf("commits = %+v", commits)
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.EscapedPath(), "/repository/commits/release%2F1.x") {
+			t.Errorf("path = %q", r.URL.EscapedPath())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"0123456789abcdef"}`))
+	}))
+	defer srv.Close()
+
+	sha, err := NewClient(srv.URL, "tok").ResolveCommit("acme", "api", "release/1.x")
+	if err != nil || sha != "0123456789abcdef" {
+		t.Errorf("ResolveCommit = %q, %v", sha, err)
+	}
+}
+
+func TestForkRepository(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.EscapedPath(), "/projects/acme%2Fapi/fork") {
+			t.Errorf("%s %s", r.Method, r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"path":"api","path_with_namespace":"me/api"}`))
+	}))
+	defer srv.Close()
+
+	repo, err := NewClient(srv.URL, "tok").ForkRepository("acme", "api", "me")
+	if err != nil {
+		t.Fatalf("ForkRepository error: %v", err)
+	}
+	if gotBody["namespace_path"] != "me" {
+		t.Errorf("body = %v", gotBody)
+	}
+	if repo.Slug != "api" || repo.FullName != "me/api" {
+		t.Errorf("repo = %+v", repo)
+	}
+}
+
+func TestArchiveRepository(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.EscapedPath(), "/projects/acme%2Fapi/archive") {
+			t.Errorf("%s %s", r.Method, r.URL.EscapedPath())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"path":"api","archived":true}`))
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "tok").ArchiveRepository("acme", "api"); err != nil {
+		t.Fatalf("ArchiveRepository error: %v", err)
+	}
+}
+
+func TestGetBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.EscapedPath(), "/repository/branches/release%2F1.x") {
+			t.Errorf("path = %q", r.URL.EscapedPath())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"release/1.x","commit":{"id":"abc","committed_date":"2024-03-01T10:00:00.000+01:00"}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewClient(srv.URL, "tok").GetBranch("acme", "api", "release/1.x")
+	if err != nil {
+		t.Fatalf("GetBranch error: %v", err)
+	}
+	if b.Target.Hash != "abc" || b.Target.Date != "2024-03-01T10:00:00.000+01:00" {
+		t.Errorf("branch = %+v", b)
+	}
+}
+
+func TestBranchURL(t *testing.T) {
+	if got := NewClient("", "").BranchURL("acme", "api", "feature/x"); got != "https://gitlab.com/acme/api/-/tree/feature/x" {
+		t.Errorf("BranchURL = %q", got)
+	}
+	if got := NewClient("https://git.example.com/api/v4", "").BranchURL("acme", "api", "x"); got != "https://git.example.com/acme/api/-/tree/x" {
+		t.Errorf("BranchURL (self-managed) = %q", got)
+	}
+}
+
+func TestFindPRByBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("source_branch") != "feature/x" || q.Get("state") != "opened" {
+			t.Errorf("query = %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"iid":4,"state":"opened","source_branch":"feature/x","author":{"id":9,"username":"dev"},"reviewers":[{"id":12}]}]`))
+	}))
+	defer srv.Close()
+
+	pr, err := NewClient(srv.URL, "tok").FindPRByBranch("acme", "api", "feature/x", "OPEN")
+	if err != nil {
+		t.Fatalf("FindPRByBranch error: %v", err)
+	}
+	if pr.ID != 4 || pr.Author.UUID != "9" || len(pr.Reviewers) != 1 || pr.Reviewers[0].AccountID != "12" {
+		t.Errorf("pr = %+v", pr)
+	}
+}
+
+func TestMergePR(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.EscapedPath() != "/projects/acme%2Fapi/merge_requests/4/merge" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"iid":4,"state":"merged"}`))
+	}))
+	defer srv.Close()
+
+	req := bitbucket.MergePRRequest{MergeStrategy: "squash", CloseSourceBranch: true}
+	if err := NewClient(srv.URL, "tok").MergePR("acme", "api", 4, req); err != nil {
+		t.Fatalf("MergePR error: %v", err)
+	}
+	if gotBody["squash"] != true || gotBody["should_remove_source_branch"] != true {
+		t.Errorf("body = %v", gotBody)
+	}
+}
+
+func TestUpdatePR_RejectsNonNumericReviewer(t *testing.T) {
+	req := bitbucket.PRUpdateRequest{Reviewers: []bitbucket.PRReviewer{{AccountID: "alice"}}}
+	_, err := NewClient("http://unused", "tok").UpdatePR("acme", "api", 4, req)
+	if err == nil || !strings.Contains(err.Error(), "numeric user ID") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestListMergedPRBranches(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("state") != "merged" {
+			t.Errorf("state = %q", r.URL.Query().Get("state"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"iid":1,"state":"merged","source_branch":"a"},{"iid":2,"state":"merged","source_branch":"a"},{"iid":3,"state":"merged","source_branch":"b"}]`))
+	}))
+	defer srv.Close()
+
+	branches, err := NewClient(srv.URL, "tok").ListMergedPRBranches("acme", "api")
+	if err != nil {
+		t.Fatalf("ListMergedPRBranches error: %v", err)
+	}
+	if strings.Join(branches, ",") != "a,b" {
+		t.Errorf("branches = %v", branches)
+	}
+}
+
+func TestCommentPR_PostsNote(t *testing.T) {
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/projects/acme%2Fapi/merge_requests/4/notes" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "tok").CommentPR("acme", "api", 4, "ping"); err != nil {
+		t.Fatalf("CommentPR error: %v", err)
+	}
+	if gotBody["body"] != "ping" {
+		t.Errorf("body = %v", gotBody)
+	}
+}
+
+func TestListPRParticipants_MergesReviewersAndApprovals(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/projects/acme%2Fapi/merge_requests/4/reviewers":
+			w.Write([]byte(`[
+				{"user":{"id":1,"name":"Alice"},"state":"unreviewed"},
+				{"user":{"id":2,"name":"Bob"},"state":"requested_changes"}
+			]`))
+		case "/projects/acme%2Fapi/merge_requests/4/approvals":
+			w.Write([]byte(`{"approved_by":[{"user":{"id":1,"name":"Alice"}},{"user":{"id":3,"name":"Carol"}}]}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.EscapedPath())
+		}
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "tok").ListPRParticipants("acme", "api", 4)
+	if err != nil {
+		t.Fatalf("ListPRParticipants error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("participants = %+v", got)
+	}
+	if !got[0].Approved || got[0].Role != "REVIEWER" {
+		t.Errorf("Alice = %+v, want approved reviewer", got[0])
+	}
+	if got[1].Approved || got[1].State != "changes_requested" {
+		t.Errorf("Bob = %+v, want changes requested", got[1])
+	}
+	if !got[2].Approved || got[2].Role != "PARTICIPANT" || got[2].User.DisplayName != "Carol" {
+		t.Errorf("Carol = %+v, want approving participant", got[2])
+	}
+}
+
+func TestListPRBuildStatuses_HeadPipeline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"iid":4,"head_pipeline":{"id":99,"status":"running","web_url":"https://gitlab.example.com/p/99"}}`))
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "tok").ListPRBuildStatuses("acme", "api", 4)
+	if err != nil {
+		t.Fatalf("ListPRBuildStatuses error: %v", err)
+	}
+	if len(got) != 1 || got[0].State != bitbucket.BuildInProgress || got[0].Name != "pipeline #99" {
+		t.Errorf("statuses = %+v", got)
+	}
+}
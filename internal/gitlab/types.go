@@ -0,0 +1,231 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// project is a GitLab project response.
+type project struct {
+	Path              string `json:"path"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	DefaultBranch     string `json:"default_branch"`
+	LastActivityAt    string `json:"last_activity_at"`
+	Namespace         struct {
+		Path string `json:"path"`
+		Name string `json:"name"`
+	} `json:"namespace"`
+}
+
+func (p project) toBitbucket() bitbucket.Repository {
+	repo := bitbucket.Repository{
+		Slug:        p.Path,
+		Name:        p.Name,
+		FullName:    p.PathWithNamespace,
+		Description: p.Description,
+		UpdatedOn:   bitbucket.ParseTime(p.LastActivityAt),
+	}
+	if p.Namespace.Path != "" {
+		repo.Project = &bitbucket.ProjectRef{Key: p.Namespace.Path, Name: p.Namespace.Name}
+	}
+	if p.DefaultBranch != "" {
+		repo.MainBranch = &bitbucket.BranchRef{Name: p.DefaultBranch, Type: "branch"}
+	}
+	return repo
+}
+
+// slugIn returns the entry naming the project when listed under group: its
+// path when it sits directly in group, else its full path, so a project of a
+// subgroup is looked up in that subgroup.
+func (p project) slugIn(group string) string {
+	if p.PathWithNamespace == "" || p.PathWithNamespace == group+"/"+p.Path {
+		return p.Path
+	}
+	return p.PathWithNamespace
+}
+
+// branch is a GitLab branch response.
+type branch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID            string `json:"id"`
+		CommittedDate string `json:"committed_date"`
+	} `json:"commit"`
+}
+
+// user is a GitLab user. The numeric ID serves as both Bitbucket's UUID
+// and the account ID used to assign reviewers.
+type user struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+func (u user) toAuthor() bitbucket.PRAuthor {
+	id := strconv.Itoa(u.ID)
+	return bitbucket.PRAuthor{DisplayName: u.Name, UUID: id, Nickname: u.Username, AccountID: id}
+}
+
+func (u user) toBitbucket() bitbucket.User {
+	a := u.toAuthor()
+	return bitbucket.User{DisplayName: a.DisplayName, UUID: a.UUID, Nickname: a.Nickname, AccountID: a.AccountID, Username: u.Username}
+}
+
+// mergeRequest is a GitLab merge request response.
+type mergeRequest struct {
+	IID          int       `json:"iid"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	State        string    `json:"state"` // opened, closed, merged, locked
+	WebURL       string    `json:"web_url"`
+	SourceBranch string    `json:"source_branch"`
+	TargetBranch string    `json:"target_branch"`
+	Author       user      `json:"author"`
+	Reviewers    []user    `json:"reviewers"`
+	HeadPipeline *pipeline `json:"head_pipeline"`
+	CreatedAt    string    `json:"created_at"`
+	UpdatedAt    string    `json:"updated_at"`
+}
+
+// pipeline is a GitLab CI pipeline.
+type pipeline struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"` // created, pending, running, success, failed, canceled, skipped, manual, ...
+	WebURL string `json:"web_url"`
+}
+
+func (p pipeline) toBitbucket() bitbucket.BuildStatus {
+	state := bitbucket.BuildInProgress
+	switch p.Status {
+	case "success", "skipped":
+		state = bitbucket.BuildSuccessful
+	case "failed":
+		state = bitbucket.BuildFailed
+	case "canceled":
+		state = bitbucket.BuildStopped
+	}
+	id := strconv.Itoa(p.ID)
+	return bitbucket.BuildStatus{Key: id, Name: "pipeline #" + id, State: state, URL: p.WebURL}
+}
+
+// mrReviewer is a GitLab merge request reviewer with their review state.
+type mrReviewer struct {
+	User  user   `json:"user"`
+	State string `json:"state"` // unreviewed, reviewed, requested_changes, approved, unapproved
+}
+
+// mrApprovals is a GitLab merge request approvals response.
+type mrApprovals struct {
+	ApprovedBy []struct {
+		User user `json:"user"`
+	} `json:"approved_by"`
+}
+
+// participantsFromApprovals merges reviewers and approvers into participants.
+// Approvers who are not reviewers are listed as plain participants.
+func participantsFromApprovals(reviewers []mrReviewer, approvals mrApprovals) []bitbucket.PRParticipant {
+	approved := make(map[int]bool, len(approvals.ApprovedBy))
+	for _, a := range approvals.ApprovedBy {
+		approved[a.User.ID] = true
+	}
+
+	participants := make([]bitbucket.PRParticipant, 0, len(reviewers)+len(approvals.ApprovedBy))
+	seen := make(map[int]bool, len(reviewers))
+	for _, r := range reviewers {
+		seen[r.User.ID] = true
+		p := bitbucket.PRParticipant{User: r.User.toAuthor(), Role: "REVIEWER", Approved: approved[r.User.ID]}
+		switch {
+		case p.Approved || r.State == "approved":
+			p.Approved, p.State = true, "approved"
+		case r.State == "requested_changes":
+			p.State = "changes_requested"
+		}
+		participants = append(participants, p)
+	}
+	for _, a := range approvals.ApprovedBy {
+		if !seen[a.User.ID] {
+			participants = append(participants, bitbucket.PRParticipant{User: a.User.toAuthor(), Role: "PARTICIPANT", Approved: true, State: "approved"})
+		}
+	}
+	return participants
+}
+
+func (m mergeRequest) toBitbucket() bitbucket.PullRequest {
+	state := "OPEN"
+	switch m.State {
+	case "merged":
+		state = "MERGED"
+	case "closed":
+		state = "DECLINED"
+	}
+	reviewers := make([]bitbucket.PRReviewer, 0, len(m.Reviewers))
+	for _, r := range m.Reviewers {
+		reviewers = append(reviewers, bitbucket.PRReviewer{AccountID: strconv.Itoa(r.ID)})
+	}
+	return bitbucket.PullRequest{
+		ID:          m.IID,
+		Title:       m.Title,
+		State:       state,
+		Description: m.Description,
+		Author:      m.Author.toAuthor(),
+		Reviewers:   reviewers,
+		Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: m.SourceBranch}},
+		Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: m.TargetBranch}},
+		Links:       bitbucket.PRLinks{HTML: bitbucket.LinkRef{Href: m.WebURL}},
+		CreatedOn:   m.CreatedAt,
+		UpdatedOn:   m.UpdatedAt,
+	}
+}
+
+// comparison is a GitLab repository compare response.
+type comparison struct {
+	Commits []struct {
+		ID            string `json:"id"`
+		Message       string `json:"message"`
+		CommittedDate string `json:"committed_date"`
+		AuthorName    string `json:"author_name"`
+		WebURL        string `json:"web_url"`
+	} `json:"commits"`
+}
+
+// apiError is a GitLab error response. "message" may be a string, a list,
+// or a map of field → messages.
+type apiError struct {
+	Message json.RawMessage `json:"message"`
+	Error   string          `json:"error"`
+}
+
+// text flattens the error into a single line.
+func (e apiError) text() string {
+	if len(e.Message) > 0 {
+		var s string
+		if json.Unmarshal(e.Message, &s) == nil {
+			return s
+		}
+		var list []string
+		if json.Unmarshal(e.Message, &list) == nil {
+			return strings.Join(list, "; ")
+		}
+		var fields map[string][]string
+		if json.Unmarshal(e.Message, &fields) == nil {
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			parts := make([]string, 0, len(keys))
+			for _, k := range keys {
+				parts = append(parts, fmt.Sprintf("%s %s", k, strings.Join(fields[k], ", ")))
+			}
+			return strings.Join(parts, "; ")
+		}
+	}
+	return e.Error
+}
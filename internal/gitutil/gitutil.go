@@ -2,32 +2,78 @@ package gitutil
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
 var (
-	sshRemoteRe   = regexp.MustCompile(`git@bitbucket\.org:([^/]+)/(.+?)(?:\.git)?$`)
-	httpsRemoteRe = regexp.MustCompile(`https?://(?:[^@]+@)?bitbucket\.org/([^/]+)/(.+?)(?:\.git)?$`)
+	sshRemoteRe   = regexp.MustCompile(`^git@bitbucket\.org:([^/]+)/(.+?)(?:\.git)?$`)
+	httpsRemoteRe = regexp.MustCompile(`(?:https?|ssh)://(?:[^@]+@)?bitbucket\.org(?::\d+)?/([^/]+)/(.+?)(?:\.git)?$`)
 )
 
-// CurrentBranch returns the current git branch name.
+// CurrentBranch returns the current git branch name. It reads HEAD from the
+// repository's git directory, so it works without the git binary and on a
+// branch with no commits yet.
 // Returns an error if not in a git repo or in detached HEAD state.
 func CurrentBranch() (string, error) {
-	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	gitDir, err := findGitDir()
 	if err != nil {
-		return "", fmt.Errorf("not a git repository or git not installed")
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
 	}
 
-	branch := strings.TrimSpace(string(out))
-	if branch == "HEAD" {
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if !ok {
 		return "", fmt.Errorf("detached HEAD state — checkout a branch first")
 	}
 
 	return branch, nil
 }
 
+// findGitDir returns the git directory of the repository containing the
+// working directory: $GIT_DIR if set, else the first .git found walking up.
+// In a worktree or submodule .git is a file naming the real directory.
+func findGitDir() (string, error) {
+	if dir := os.Getenv("GIT_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dotGit, nil
+			}
+			data, err := os.ReadFile(dotGit)
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s: %w", dotGit, err)
+			}
+			target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+			if !ok {
+				return "", fmt.Errorf("%s is not a git directory or gitdir file", dotGit)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			return target, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not a git repository")
+		}
+		dir = parent
+	}
+}
+
 // ParseBitbucketRemote parses the origin remote URL and extracts workspace and repo slug.
 func ParseBitbucketRemote() (workspace, repoSlug string, err error) {
 	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
@@ -40,7 +86,7 @@ func ParseBitbucketRemote() (workspace, repoSlug string, err error) {
 }
 
 // ParseRemoteURL extracts workspace and repo slug from a Bitbucket remote URL.
-// Supports SSH and HTTPS formats.
+// Supports the SSH (git@bitbucket.org:ws/repo, ssh://) and HTTPS formats.
 func ParseRemoteURL(url string) (workspace, repoSlug string, err error) {
 	if m := sshRemoteRe.FindStringSubmatch(url); m != nil {
 		return m[1], m[2], nil
@@ -52,3 +98,73 @@ func ParseRemoteURL(url string) (workspace, repoSlug string, err error) {
 
 	return "", "", fmt.Errorf("not a Bitbucket remote URL: %s", url)
 }
+
+// UnignoredRepo returns the root of the git work tree containing path when git
+// does not ignore path, so that 'git add -A' would commit it, and whether the
+// file is already tracked. The root is "" when path is outside any work tree,
+// is ignored, or git is not installed. path need not exist yet.
+func UnignoredRepo(path string) (root string, tracked bool) {
+	dir, file := filepath.Split(resolvePath(path))
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", false
+	}
+	root = strings.TrimSpace(string(out))
+
+	if exec.Command("git", "-C", dir, "ls-files", "--error-unmatch", "--", file).Run() == nil {
+		return root, true
+	}
+	// check-ignore exits 0 when the path is ignored, 1 when it is not
+	if exec.Command("git", "-C", dir, "check-ignore", "-q", "--", file).Run() == nil {
+		return "", false
+	}
+	return root, false
+}
+
+// AddToGitignore appends path, relative to the work tree root, to the
+// .gitignore at the root. The file is created if missing.
+func AddToGitignore(root, path string) error {
+	path = resolvePath(path)
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("%s is not inside %s", path, root)
+	}
+
+	ignore := filepath.Join(root, ".gitignore")
+	existing, err := os.ReadFile(ignore)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+	line := "/" + filepath.ToSlash(rel) + "\n"
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		line = "\n" + line
+	}
+
+	f, err := os.OpenFile(ignore, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+	return nil
+}
+
+// resolvePath follows symlinks in path, e.g. a config linked from a dotfiles
+// repo. When path does not exist yet, only its directory is resolved.
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		return filepath.Join(dir, filepath.Base(path))
+	}
+	return path
+}
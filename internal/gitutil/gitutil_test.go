@@ -3,6 +3,8 @@ package gitutil
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -44,6 +46,18 @@ func TestParseRemoteURL(t *testing.T) {
 			wantWS:   "myworkspace",
 			wantRepo: "my-repo",
 		},
+		{
+			name:     "SSH URL format",
+			url:      "ssh://git@bitbucket.org/myworkspace/my-repo.git",
+			wantWS:   "myworkspace",
+			wantRepo: "my-repo",
+		},
+		{
+			name:     "SSH URL with port",
+			url:      "ssh://git@bitbucket.org:22/myworkspace/my-repo",
+			wantWS:   "myworkspace",
+			wantRepo: "my-repo",
+		},
 		{
 			name:      "GitHub URL",
 			url:       "git@github.com:user/repo.git",
@@ -107,16 +121,43 @@ func TestCurrentBranch(t *testing.T) {
 	}
 
 	run("git", "init")
-	run("git", "checkout", "-b", "test-branch")
-	// Need at least one commit for rev-parse to work
-	run("git", "commit", "--allow-empty", "-m", "init")
+	run("git", "checkout", "-b", "feature/test-branch")
 
+	// Before the first commit, and from a subdirectory
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	os.Chdir(filepath.Join(dir, "sub"))
 	branch, err := CurrentBranch()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if branch != "test-branch" {
-		t.Errorf("got %q, want %q", branch, "test-branch")
+	if branch != "feature/test-branch" {
+		t.Errorf("got %q, want %q", branch, "feature/test-branch")
+	}
+
+	run("git", "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--allow-empty", "-m", "init")
+	run("git", "checkout", "--detach")
+	if _, err := CurrentBranch(); err == nil || !strings.Contains(err.Error(), "detached HEAD") {
+		t.Errorf("detached: err = %v, want detached HEAD", err)
+	}
+}
+
+func TestCurrentBranch_Worktree(t *testing.T) {
+	dir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(oldDir) })
+
+	// A linked worktree's .git is a file pointing at its git directory
+	gitDir := filepath.Join(dir, "main.git", "worktrees", "wt")
+	os.MkdirAll(gitDir, 0755)
+	os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/release/1.4\n"), 0644)
+	work := filepath.Join(dir, "wt")
+	os.Mkdir(work, 0755)
+	os.WriteFile(filepath.Join(work, ".git"), []byte("gitdir: ../main.git/worktrees/wt\n"), 0644)
+
+	os.Chdir(work)
+	branch, err := CurrentBranch()
+	if err != nil || branch != "release/1.4" {
+		t.Errorf("CurrentBranch() = %q, %v; want release/1.4", branch, err)
 	}
 }
 
@@ -132,3 +173,44 @@ func TestCurrentBranch_NotGitRepo(t *testing.T) {
 		t.Error("expected error for non-git directory")
 	}
 }
+
+func TestUnignoredRepo(t *testing.T) {
+	dir := t.TempDir()
+	if root, _ := UnignoredRepo(filepath.Join(dir, ".buck.yaml")); root != "" {
+		t.Errorf("outside a repo: root = %q, want empty", root)
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	run("init")
+	path := filepath.Join(dir, ".buck.yaml")
+
+	root, tracked := UnignoredRepo(path)
+	if root == "" || tracked {
+		t.Fatalf("UnignoredRepo() = %q, %v; want the repo root, untracked", root, tracked)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log"), 0o644)
+	if err := AddToGitignore(root, path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if string(data) != "*.log\n/.buck.yaml\n" {
+		t.Errorf(".gitignore = %q", data)
+	}
+	if root, _ := UnignoredRepo(path); root != "" {
+		t.Errorf("after AddToGitignore: root = %q, want empty", root)
+	}
+
+	os.WriteFile(path, []byte("workspace: acme\n"), 0o600)
+	run("add", "-f", ".buck.yaml")
+	if root, tracked := UnignoredRepo(path); root == "" || !tracked {
+		t.Errorf("tracked file: UnignoredRepo() = %q, %v; want root, tracked", root, tracked)
+	}
+}
@@ -0,0 +1,145 @@
+// Package hooks runs user-defined commands and webhooks before and after
+// mutating operations (create, pr, merge).
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/config"
+)
+
+// Payload describes the run a hook is invoked for. Script hooks receive it as
+// JSON on stdin (and its main fields as BUCK_* env vars); URL hooks receive it
+// as a JSON POST body.
+type Payload struct {
+	Event       string   `json:"event"`   // e.g. "pre_create", "post_pr"
+	Command     string   `json:"command"` // create, pr or merge
+	Workspace   string   `json:"workspace"`
+	Branch      string   `json:"branch"`
+	Source      string   `json:"source,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	Repos       []string `json:"repos"`
+	Results     []Result `json:"results,omitempty"` // post hooks only
+}
+
+// Result is the per-repo outcome passed to post hooks.
+type Result struct {
+	Repo          string        `json:"repo"`
+	Success       bool          `json:"success"`
+	Error         string        `json:"error,omitempty"`
+	ErrorCategory string        `json:"error_category,omitempty"`
+	StatusCode    int           `json:"status_code,omitempty"`
+	URL           string        `json:"url,omitempty"`
+	StartedAt     time.Time     `json:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	Duration      time.Duration `json:"duration_ns"`
+}
+
+// HTTPClient sends URL hooks. Replace it to apply proxy and CA settings.
+var HTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Run executes hooks in order and stops at the first failure.
+func Run(hooks []config.Hook, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	for _, h := range hooks {
+		switch {
+		case h.Run != "":
+			if err := runCommand(h.Run, p, body); err != nil {
+				return fmt.Errorf("hook %q failed: %w", h.Run, err)
+			}
+		case h.URL != "":
+			if err := postURL(h.URL, body); err != nil {
+				return fmt.Errorf("hook %s failed: %w", redactURL(h.URL), err)
+			}
+		}
+	}
+	return nil
+}
+
+// runCommand runs a shell command with the payload on stdin.
+func runCommand(command string, p Payload, body []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env(p)...)
+	return cmd.Run()
+}
+
+// env returns the BUCK_* variables describing the run.
+func env(p Payload) []string {
+	vars := []string{
+		"BUCK_EVENT=" + p.Event,
+		"BUCK_COMMAND=" + p.Command,
+		"BUCK_WORKSPACE=" + p.Workspace,
+		"BUCK_BRANCH=" + p.Branch,
+		"BUCK_SOURCE=" + p.Source,
+		"BUCK_DESTINATION=" + p.Destination,
+		"BUCK_REPOS=" + strings.Join(p.Repos, ","),
+	}
+	if p.Results != nil {
+		succeeded := 0
+		for _, r := range p.Results {
+			if r.Success {
+				succeeded++
+			}
+		}
+		vars = append(vars,
+			"BUCK_SUCCEEDED="+strconv.Itoa(succeeded),
+			"BUCK_FAILED="+strconv.Itoa(len(p.Results)-succeeded),
+		)
+	}
+	return vars
+}
+
+// postURL sends the payload as a JSON POST; any non-2xx response is an error.
+func postURL(rawURL string, body []byte) error {
+	resp, err := HTTPClient.Post(rawURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The error repeats the URL, which may carry a token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(urlErr.URL)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// redactURL returns the URL for messages without its password, query and
+// fragment, where tokens expanded from ${VAR} usually sit.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	u.RawQuery, u.Fragment = "", ""
+	return u.Redacted()
+}
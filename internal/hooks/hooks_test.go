@@ -0,0 +1,127 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/config"
+)
+
+func testPayload() Payload {
+	return Payload{
+		Event:     "post_create",
+		Command:   "create",
+		Workspace: "acme",
+		Branch:    "feature/x",
+		Source:    "main",
+		Repos:     []string{"api", "web"},
+		Results: []Result{
+			{Repo: "api", Success: true},
+			{Repo: "web", Error: "already exists"},
+		},
+	}
+}
+
+func TestRun_CommandGetsEnvAndStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	envOut := filepath.Join(dir, "env")
+	stdinOut := filepath.Join(dir, "stdin")
+
+	hook := config.Hook{Run: `echo "$BUCK_EVENT $BUCK_BRANCH $BUCK_REPOS $BUCK_SUCCEEDED $BUCK_FAILED" > ` + envOut + ` && cat > ` + stdinOut}
+	if err := Run([]config.Hook{hook}, testPayload()); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	env, _ := os.ReadFile(envOut)
+	if got := strings.TrimSpace(string(env)); got != "post_create feature/x api,web 1 1" {
+		t.Errorf("env = %q", got)
+	}
+
+	var p Payload
+	stdin, _ := os.ReadFile(stdinOut)
+	if err := json.Unmarshal(stdin, &p); err != nil {
+		t.Fatalf("stdin is not JSON: %v", err)
+	}
+	if p.Workspace != "acme" || len(p.Results) != 2 {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestRun_StopsAtFirstFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	hooks := []config.Hook{{Run: "exit 3"}, {Run: "touch " + marker}}
+
+	err := Run(hooks, testPayload())
+	if err == nil || !strings.Contains(err.Error(), `hook "exit 3" failed`) {
+		t.Errorf("err = %v", err)
+	}
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Error("second hook should not run after a failure")
+	}
+}
+
+func TestRun_URLPostsPayload(t *testing.T) {
+	var got Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if err := Run([]config.Hook{{URL: srv.URL}}, testPayload()); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if got.Event != "post_create" || got.Branch != "feature/x" {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestRun_URLErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := Run([]config.Hook{{URL: srv.URL}}, testPayload())
+	if err == nil || !strings.Contains(err.Error(), "status 404: ticket not found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRun_URLErrorHidesSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close() // refuse the connection, so the client error repeats the URL
+
+	hookURL := strings.Replace(srv.URL, "http://", "http://bot:s3cret@", 1) + "/notify?token=s3cret"
+	err := Run([]config.Hook{{URL: hookURL}}, testPayload())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error leaks the secret: %v", err)
+	}
+	if !strings.Contains(err.Error(), "/notify") {
+		t.Errorf("error does not name the hook: %v", err)
+	}
+}
+
+func TestRun_NoHooks(t *testing.T) {
+	if err := Run(nil, testPayload()); err != nil {
+		t.Errorf("Run(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,187 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Interaction is one recorded API request and the response it got. A
+// cassette file holds one per line, as JSON.
+type Interaction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+// key identifies the request an interaction answers.
+func (i Interaction) key() string {
+	return i.Method + " " + i.URL + "\n" + i.RequestBody
+}
+
+// recordTransport appends every API request it sends, with the response,
+// to a cassette file. Only requests carrying a request ID are recorded: API
+// requests do, OAuth token requests don't, so tokens stay out of cassettes.
+// Request headers, which hold the credentials, are never recorded.
+type recordTransport struct {
+	base http.RoundTripper
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newRecordTransport(base http.RoundTripper, path string) (*recordTransport, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+	return &recordTransport{base: base, path: path, file: f}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(RequestIDHeader) == "" {
+		return t.base.RoundTrip(req)
+	}
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseBytes+1))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	t.write(Interaction{
+		Method:      req.Method,
+		URL:         req.URL.Redacted(),
+		RequestBody: string(requestBody),
+		Status:      resp.StatusCode,
+		Header:      header,
+		Body:        string(body),
+	})
+	return resp, nil
+}
+
+// write appends i to the cassette. A failed write only warns: the run
+// itself went through.
+func (t *recordTransport) write(i Interaction) {
+	line, err := json.Marshal(i)
+	if err == nil {
+		t.mu.Lock()
+		_, err = t.file.Write(append(line, '\n'))
+		t.mu.Unlock()
+	}
+	if err != nil {
+		fmt.Fprintf(Warnings, "Warning: failed to record %s %s to %s: %v\n", i.Method, i.URL, t.path, err)
+	}
+}
+
+// readRequestBody returns req's body without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// replayTransport answers requests from a cassette without sending them.
+// Identical requests get the recorded responses in order; once those run
+// out, the last one is repeated.
+type replayTransport struct {
+	path string
+
+	mu      sync.Mutex
+	pending map[string][]Interaction
+}
+
+func newReplayTransport(path string) (*replayTransport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	t := &replayTransport{path: path, pending: make(map[string][]Interaction)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, MaxResponseBytes*2)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("cassette %s line %d: %w", path, n, err)
+		}
+		t.pending[i.key()] = append(t.pending[i.key()], i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	wanted := Interaction{Method: req.Method, URL: req.URL.Redacted(), RequestBody: string(requestBody)}
+
+	t.mu.Lock()
+	queue := t.pending[wanted.key()]
+	if len(queue) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no response recorded for %s %s in %s", req.Method, wanted.URL, t.path)
+	}
+	i := queue[0]
+	if len(queue) > 1 {
+		t.pending[wanted.key()] = queue[1:]
+	}
+	t.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		StatusCode:    i.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        i.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(i.Body))),
+		ContentLength: int64(len(i.Body)),
+		Request:       req,
+	}, nil
+}
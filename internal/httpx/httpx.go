@@ -0,0 +1,500 @@
+// Package httpx holds the HTTP plumbing shared by the hosting clients:
+// request IDs for correlating failures with support tickets, verbose request
+// logging, timeouts, proxy and CA settings, response size limits, and
+// recording and replaying API traffic.
+package httpx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RequestIDHeader carries the ID buck generates for every API request.
+const RequestIDHeader = "X-Correlation-Id"
+
+// serverRequestIDHeaders are the response headers in which Bitbucket,
+// GitLab and GitHub report their own request ID.
+var serverRequestIDHeaders = []string{"X-Request-Id", "X-GitHub-Request-Id"}
+
+// NewRequestID returns a random 16-character hex ID.
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ServerRequestID returns the hosting service's request ID from a response, or "".
+func ServerRequestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	for _, h := range serverRequestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// LoggingTransport writes one line per request and response to Out,
+// including both request IDs.
+type LoggingTransport struct {
+	Base http.RoundTripper
+	Out  io.Writer
+}
+
+// RoundTrip logs req, sends it with Base and logs the outcome.
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := req.Header.Get(RequestIDHeader)
+	fmt.Fprintf(t.Out, "→ %s %s [%s]\n", req.Method, req.URL.Redacted(), id)
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.Out, "← %s %s failed after %s [%s]: %v\n", req.Method, req.URL.Redacted(), elapsed, id, err)
+		return nil, err
+	}
+	if server := ServerRequestID(resp); server != "" {
+		id += ", server " + server
+	}
+	fmt.Fprintf(t.Out, "← %d %s %s in %s [%s]\n", resp.StatusCode, req.Method, req.URL.Redacted(), elapsed, id)
+	return resp, nil
+}
+
+// maxIdleConns is the number of idle keep-alive connections kept per host.
+const maxIdleConns = 100
+
+// Options configures the HTTP client built by NewClient.
+type Options struct {
+	Timeout      time.Duration // per request, including reading the body; 0: none
+	TotalTimeout time.Duration // for all requests of the command together; 0: none
+	Proxy        string        // proxy URL; empty: HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment
+	CABundle     string        // PEM file of CA certificates trusted in addition to the system's
+	Log          io.Writer     // if set, every request is logged to it
+	RateLimit    float64       // requests per second across all goroutines; 0: no limit
+	BreakAfter   int           // stop sending once this many first requests failed alike; 0: never
+	Retry        RetryPolicy   // retries of failed GET requests; zero: none
+	Stats        *Stats        // if set, every request sent is recorded in it
+	Record       string        // cassette file that API traffic is written to
+	Replay       string        // cassette file that answers requests instead of the network
+}
+
+// RetryPolicy says how often and how soon a failed GET request is sent again.
+// Other methods are never retried here: creating something twice is worse
+// than reporting the failure.
+type RetryPolicy struct {
+	MaxAttempts int           // per request, including the first; 0 or 1: no retries
+	BaseDelay   time.Duration // before the first retry, doubling for each further one
+	Jitter      float64       // fraction of each delay that is randomized, 0 to 1
+	StatusCodes []int         // responses that are retried; network errors always are
+}
+
+// maxRetryDelay caps the delay before a retry, including one asked for
+// with Retry-After.
+const maxRetryDelay = 30 * time.Second
+
+// DefaultBreakAfter is how many identical failures of the first requests
+// make NewClient's breaker fail the rest without sending them.
+const DefaultBreakAfter = 5
+
+// NewClient builds the HTTP client shared by the hosting backends.
+func NewClient(opts Options) (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	// Commands run one goroutine per repo against the same API host; keep
+	// enough idle connections for all of them to be reused instead of
+	// paying a TLS handshake per repo (the default keeps 2 per host).
+	base.MaxIdleConns = maxIdleConns
+	base.MaxIdleConnsPerHost = maxIdleConns
+	base.IdleConnTimeout = 90 * time.Second
+	base.ForceAttemptHTTP2 = true // also when a custom TLS config is set
+	base.DisableKeepAlives = false
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid http.proxy %q: expected a URL like http://proxy.example.com:8080", opts.Proxy)
+		}
+		base.Proxy = http.ProxyURL(proxyURL)
+	}
+	if opts.CABundle != "" {
+		pool, err := loadCABundle(opts.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		base.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	var transport http.RoundTripper = &tlsHintTransport{base: base}
+	switch {
+	case opts.Replay != "":
+		replay, err := newReplayTransport(opts.Replay)
+		if err != nil {
+			return nil, err
+		}
+		transport = replay
+	case opts.Record != "":
+		record, err := newRecordTransport(transport, opts.Record)
+		if err != nil {
+			return nil, err
+		}
+		transport = record
+	}
+	if opts.Stats != nil {
+		// Below the rate limit and retries: each attempt counts, waits don't
+		transport = &statsTransport{base: transport, stats: opts.Stats}
+	}
+	if opts.RateLimit > 0 {
+		transport = newRateLimitTransport(transport, opts.RateLimit)
+	}
+	if opts.Retry.MaxAttempts > 1 {
+		// Wraps the rate limit, so every attempt waits for its own token
+		transport = &retryTransport{base: transport, policy: opts.Retry, log: opts.Log}
+	}
+	if opts.TotalTimeout > 0 {
+		transport = &deadlineTransport{base: transport, deadline: time.Now().Add(opts.TotalTimeout), budget: opts.TotalTimeout}
+	}
+	if opts.BreakAfter > 0 {
+		transport = &breakerTransport{base: transport, threshold: opts.BreakAfter}
+	}
+	if opts.Log != nil {
+		transport = &LoggingTransport{Base: transport, Out: opts.Log}
+	}
+	return &http.Client{Timeout: opts.Timeout, Transport: transport}, nil
+}
+
+// loadCABundle returns the system roots plus the certificates in path.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read http.ca_bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("http.ca_bundle %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// tlsHintTransport explains certificate verification failures, which
+// usually mean a TLS-intercepting proxy whose CA is not trusted.
+type tlsHintTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tlsHintTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return nil, fmt.Errorf("%w\n  The certificate of %s is not trusted. Behind a TLS-intercepting proxy, "+
+			"set http.ca_bundle in .buck.yaml to a PEM file with the proxy's CA certificate", err, req.URL.Host)
+	}
+	return resp, err
+}
+
+// deadlineTransport fails every request that would run past deadline, so a
+// command as a whole cannot exceed its time budget.
+type deadlineTransport struct {
+	base     http.RoundTripper
+	deadline time.Time
+	budget   time.Duration
+}
+
+func (t *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !time.Now().Before(t.deadline) {
+		return nil, fmt.Errorf("total timeout of %s exceeded", t.budget)
+	}
+	ctx, cancel := context.WithDeadline(req.Context(), t.deadline)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded && req.Context().Err() == nil {
+			return nil, fmt.Errorf("total timeout of %s exceeded: %w", t.budget, err)
+		}
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// rateLimitTransport is a token bucket shared by every goroutine using the
+// client: requests go out at most rate per second on average, with bursts of
+// up to one second's worth after a pause.
+type rateLimitTransport struct {
+	base  http.RoundTripper
+	rate  float64 // tokens added per second
+	burst float64 // bucket size
+
+	mu     sync.Mutex
+	tokens float64 // negative while requests wait for their turn
+	last   time.Time
+}
+
+func newRateLimitTransport(base http.RoundTripper, rate float64) *rateLimitTransport {
+	burst := math.Max(1, math.Ceil(rate))
+	return &rateLimitTransport{base: base, rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// wait takes a token, sleeping until it is due when the bucket is empty.
+func (t *rateLimitTransport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	t.last = now
+	t.tokens--
+	delay := time.Duration(-t.tokens / t.rate * float64(time.Second))
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return sleep(ctx, delay)
+}
+
+// retryTransport sends GET requests again after a network error or one of
+// the policy's status codes, backing off exponentially with jitter.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	log    io.Writer // if set, every retry is logged to it
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			io.Copy(io.Discard, io.LimitReader(resp.Body, MaxErrorBytes))
+			resp.Body.Close()
+		}
+		if t.log != nil {
+			fmt.Fprintf(t.log, "↻ %s %s: %s; retry %d/%d in %s\n", req.Method, req.URL.Redacted(), reason,
+				attempt, t.policy.MaxAttempts-1, delay.Round(time.Millisecond))
+		}
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether a request outcome may change when sent again.
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && !errors.Is(err, ErrCircuitOpen)
+	}
+	for _, code := range t.policy.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before the retry following attempt: the
+// server's Retry-After when given, otherwise BaseDelay doubled per attempt,
+// with up to Jitter of it randomized.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, maxRetryDelay)
+		}
+	}
+	d := min(float64(t.policy.BaseDelay)*math.Pow(2, float64(attempt-1)), float64(maxRetryDelay))
+	if j := math.Min(t.policy.Jitter, 1); j > 0 {
+		d -= d * j * mathrand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ErrCircuitOpen fails requests after the breaker tripped.
+var ErrCircuitOpen = errors.New("not sent")
+
+// breakerTransport watches the first requests of a command. When threshold of
+// them fail in the same way that no repo can succeed with (a network error or
+// 401 Unauthorized), it fails the remaining requests at once rather than let
+// every repo wait for the same failure. One success or a different failure
+// disarms it for the rest of the command.
+type breakerTransport struct {
+	base      http.RoundTripper
+	threshold int
+
+	mu       sync.Mutex
+	failures int
+	reason   string // the failure seen so far
+	disarmed bool
+	open     error // set once tripped
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	open := t.open
+	t.mu.Unlock()
+	if open != nil {
+		return nil, open
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	t.record(systemicFailure(resp, err))
+	return resp, err
+}
+
+// record counts a request outcome; reason is empty for a success or a
+// failure specific to one repo.
+func (t *breakerTransport) record(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.disarmed || t.open != nil {
+		return
+	}
+	if reason == "" || (t.reason != "" && reason != t.reason) {
+		t.disarmed = true
+		return
+	}
+	t.reason = reason
+	t.failures++
+	if t.failures >= t.threshold {
+		t.open = fmt.Errorf("%w: the first %d requests all failed with %s", ErrCircuitOpen, t.failures, reason)
+		fmt.Fprintf(Warnings, "Stopping: the first %d API requests all failed with %s; skipping the rest\n", t.failures, reason)
+	}
+}
+
+// systemicFailure describes a request outcome that would repeat for every
+// repo, or returns "" for anything else.
+func systemicFailure(resp *http.Response, err error) string {
+	switch {
+	case err != nil:
+		if errors.Is(err, context.Canceled) {
+			return ""
+		}
+		return err.Error()
+	case resp.StatusCode == http.StatusUnauthorized:
+		return "401 Unauthorized (check your credentials)"
+	}
+	return ""
+}
+
+// cancelOnClose releases a request's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Response size limits: a runaway or hostile response must not exhaust memory.
+const (
+	MaxResponseBytes = 32 << 20 // decoded success responses
+	MaxErrorBytes    = 64 << 10 // error responses, read for their message
+	maxMessageBytes  = 1000     // raw error bodies quoted in messages
+)
+
+// ErrBodyTooLarge is returned when a response exceeds MaxResponseBytes.
+var ErrBodyTooLarge = fmt.Errorf("response body exceeds %d MiB", MaxResponseBytes>>20)
+
+// LimitBody returns a reader over r that fails with ErrBodyTooLarge once
+// more than MaxResponseBytes have been read.
+func LimitBody(r io.Reader) io.Reader {
+	return &limitedReader{r: r, remaining: MaxResponseBytes}
+}
+
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	// Read one byte past the limit to tell "exactly at" from "over"
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n - 1, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// ReadErrorBody reads at most MaxErrorBytes of an error response.
+func ReadErrorBody(r io.Reader) []byte {
+	body, _ := io.ReadAll(io.LimitReader(r, MaxErrorBytes))
+	return body
+}
+
+// TruncateMessage shortens a raw response body quoted in an error message.
+func TruncateMessage(body []byte) string {
+	if len(body) <= maxMessageBytes {
+		return string(body)
+	}
+	cut := maxMessageBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s… (%d more bytes)", body[:cut], len(body)-cut)
+}
+
+// DefaultMaxPages is how many pages repository listings fetch by default.
+const DefaultMaxPages = 50
+
+// Warnings receives warnings about incomplete results.
+var Warnings io.Writer = os.Stderr
+
+// WarnTruncated reports a listing that stopped at its page cap with more
+// results left.
+func WarnTruncated(pages, count int) {
+	fmt.Fprintf(Warnings, "Warning: results truncated at %d pages (%d repos); raise http.max_pages to list more\n", pages, count)
+}
@@ -0,0 +1,534 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestNewRequestID(t *testing.T) {
+	a, b := NewRequestID(), NewRequestID()
+	if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(a) || a == b {
+		t.Errorf("ids = %q, %q", a, b)
+	}
+}
+
+func TestLoggingTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "GH:42")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	client := &http.Client{Transport: &LoggingTransport{Base: http.DefaultTransport, Out: &out}}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/repos/x", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log = %q", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "→ GET "+srv.URL+"/repos/x") || !strings.HasSuffix(lines[0], "[abc]") {
+		t.Errorf("request line = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "← 404 GET ") || !strings.HasSuffix(lines[1], "[abc, server GH:42]") {
+		t.Errorf("response line = %q", lines[1])
+	}
+}
+
+func TestNewClient_TotalTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(Options{Timeout: time.Second, TotalTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL + "/fast")
+	if err != nil {
+		t.Fatalf("fast request: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("body = %q", body)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(srv.URL + "/slow"); err == nil || !strings.Contains(err.Error(), "total timeout of 100ms exceeded") {
+		t.Errorf("slow request error = %v", err)
+	}
+	if _, err := client.Get(srv.URL + "/fast"); err == nil || !strings.Contains(err.Error(), "total timeout of 100ms exceeded") {
+		t.Errorf("request after deadline error = %v", err)
+	}
+}
+
+func TestNewClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(Options{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://api.example.invalid/2.0/user")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://api.example.invalid/2.0/user" {
+		t.Errorf("proxy saw %q", proxied)
+	}
+
+	if _, err := NewClient(Options{Proxy: "not a url"}); err == nil {
+		t.Error("expected error for invalid proxy")
+	}
+}
+
+func TestNewClient_CABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	plain, _ := NewClient(Options{})
+	_, err := plain.Get(srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "set http.ca_bundle") {
+		t.Errorf("untrusted server error = %v", err)
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	trusting, err := NewClient(Options{CABundle: bundle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := trusting.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("trusted request: %v", err)
+	}
+	resp.Body.Close()
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("nothing here"), 0o600)
+	if _, err := NewClient(Options{CABundle: empty}); err == nil {
+		t.Error("expected error for bundle without certificates")
+	}
+}
+
+func TestNewClient_ReusesConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client, err := NewClient(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(srv.URL)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+	}
+
+	batch()
+	first := conns.Load()
+	time.Sleep(20 * time.Millisecond) // let connections return to the idle pool
+	batch()
+	if opened := conns.Load() - first; opened > 3 {
+		t.Errorf("second batch opened %d new connections after %d, want them reused", opened, first)
+	}
+}
+
+func TestNewClient_RateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(Options{RateLimit: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of 50 goes out at once; the 10 after it wait 20ms each
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("60 requests at 50/s took %s, want at least 200ms", elapsed)
+	}
+}
+
+func TestRateLimitTransport_WaitHonorsContext(t *testing.T) {
+	rl := newRateLimitTransport(http.DefaultTransport, 1)
+	rl.tokens = -5 // five requests already queued
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rl.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("wait = %v, want deadline exceeded", err)
+	}
+}
+
+func TestNewClient_RetriesGetOnStatusCodes(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var log bytes.Buffer
+	client, err := NewClient(Options{Log: &log, Retry: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, StatusCodes: []int{503}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" || hits.Load() != 3 {
+		t.Errorf("status = %d, body = %q after %d requests", resp.StatusCode, body, hits.Load())
+	}
+	if !strings.Contains(log.String(), "503 Service Unavailable; retry 2/2") {
+		t.Errorf("log = %q", log.String())
+	}
+}
+
+func TestNewClient_RetryGivesUpAndSkipsOtherMethods(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(Options{Retry: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, StatusCodes: []int{502}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || hits.Load() != 2 {
+		t.Errorf("GET: status = %d after %d requests, want 502 after 2", resp.StatusCode, hits.Load())
+	}
+
+	hits.Store(0)
+	resp, err = client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if hits.Load() != 1 {
+		t.Errorf("POST sent %d times, want once", hits.Load())
+	}
+}
+
+func TestRetryTransport_Delay(t *testing.T) {
+	rt := &retryTransport{policy: RetryPolicy{BaseDelay: time.Second}}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: maxRetryDelay} {
+		if got := rt.delay(attempt, nil); got != want {
+			t.Errorf("delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"7"}}}
+	if got := rt.delay(1, resp); got != 7*time.Second {
+		t.Errorf("Retry-After delay = %s, want 7s", got)
+	}
+
+	rt.policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := rt.delay(2, nil); got <= time.Second || got > 2*time.Second {
+			t.Fatalf("jittered delay = %s, want within (1s, 2s]", got)
+		}
+	}
+}
+
+func TestNewClient_BreakerStopsAfterIdenticalFailures(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	var warnings bytes.Buffer
+	old := Warnings
+	Warnings = &warnings
+	t.Cleanup(func() { Warnings = old })
+
+	client, err := NewClient(Options{BreakAfter: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err = client.Get(srv.URL)
+	if !errors.Is(err, ErrCircuitOpen) || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Errorf("err = %v, want the breaker's error", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("server hits = %d, want 3", hits.Load())
+	}
+	if !strings.Contains(warnings.String(), "first 3 API requests all failed") {
+		t.Errorf("warning = %q", warnings.String())
+	}
+}
+
+func TestBreakerTransport_DisarmedBySuccessOrDifferentFailure(t *testing.T) {
+	for name, outcomes := range map[string][]string{
+		"success":           {"dial tcp: connection refused", "", "dial tcp: connection refused", "dial tcp: connection refused"},
+		"different failure": {"dial tcp: connection refused", "401 Unauthorized", "dial tcp: connection refused", "dial tcp: connection refused"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := &breakerTransport{threshold: 3}
+			for _, reason := range outcomes {
+				b.record(reason)
+			}
+			if b.open != nil {
+				t.Errorf("breaker tripped: %v", b.open)
+			}
+		})
+	}
+}
+
+func TestNewClient_Stats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusConflict)
+		}
+		w.Write([]byte(strings.Repeat("x", 1024)))
+	}))
+	defer srv.Close()
+
+	stats := NewStats()
+	client, err := NewClient(Options{Stats: stats})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"name":"x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	var out bytes.Buffer
+	stats.Write(&out, 1500*time.Millisecond)
+	for _, want := range []string{
+		"Wall clock:  1.5s",
+		"API calls:   4 (GET 3, POST 1), 1 failed",
+		"Transferred: 4.0 KB received, 12 B sent",
+		"Latency:     p50 ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("summary missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 20; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	if got := percentile(sorted, 50); got != 10*time.Millisecond {
+		t.Errorf("p50 = %s, want 10ms", got)
+	}
+	if got := percentile(sorted, 95); got != 19*time.Millisecond {
+		t.Errorf("p95 = %s, want 19ms", got)
+	}
+	if got := percentile(sorted[:1], 95); got != time.Millisecond {
+		t.Errorf("p95 of one = %s, want 1ms", got)
+	}
+}
+
+func TestNewClient_RecordThenReplay(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		fmt.Fprintf(w, `{"call":%d,"got":%q}`, n, body)
+	}))
+	defer srv.Close()
+
+	cassette := filepath.Join(t.TempDir(), "run.jsonl")
+	send := func(client *http.Client, method, body string, withID bool) string {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+"/2.0/repositories/ws/api", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		if withID {
+			req.Header.Set(RequestIDHeader, NewRequestID())
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		got, _ := io.ReadAll(resp.Body)
+		return string(got)
+	}
+
+	recorder, err := NewClient(Options{Record: cassette})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := send(recorder, http.MethodGet, "", true)
+	second := send(recorder, http.MethodGet, "", true)
+	created := send(recorder, http.MethodPost, `{"name":"x"}`, true)
+	send(recorder, http.MethodPost, "grant_type=refresh_token", false) // a token request
+
+	data, _ := os.ReadFile(cassette)
+	if n := strings.Count(string(data), "\n"); n != 3 {
+		t.Errorf("cassette has %d interactions, want 3:\n%s", n, data)
+	}
+	for _, secret := range []string{"s3cret", "session=secret", "refresh_token"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	srv.Close()
+	replayer, err := NewClient(Options{Replay: cassette})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := send(replayer, http.MethodGet, "", true); got != first {
+		t.Errorf("first GET = %s, want %s", got, first)
+	}
+	if got := send(replayer, http.MethodGet, "", true); got != second {
+		t.Errorf("second GET = %s, want %s", got, second)
+	}
+	if got := send(replayer, http.MethodGet, "", true); got != second {
+		t.Errorf("third GET = %s, want the last response repeated", got)
+	}
+	if got := send(replayer, http.MethodPost, `{"name":"x"}`, true); got != created {
+		t.Errorf("POST = %s, want %s", got, created)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/2.0/repositories/ws/api", strings.NewReader(`{"name":"y"}`))
+	if _, err := replayer.Do(req); err == nil || !strings.Contains(err.Error(), "no response recorded for POST") {
+		t.Errorf("unrecorded request error = %v", err)
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	exact := &limitedReader{r: strings.NewReader("12345"), remaining: 5}
+	if b, err := io.ReadAll(exact); err != nil || string(b) != "12345" {
+		t.Errorf("at limit: %q, %v", b, err)
+	}
+	over := &limitedReader{r: strings.NewReader("123456"), remaining: 5}
+	if b, err := io.ReadAll(over); err != ErrBodyTooLarge || string(b) != "12345" {
+		t.Errorf("over limit: %q, %v", b, err)
+	}
+}
+
+func TestReadErrorBodyAndTruncate(t *testing.T) {
+	body := ReadErrorBody(strings.NewReader(strings.Repeat("x", MaxErrorBytes+100)))
+	if len(body) != MaxErrorBytes {
+		t.Errorf("read %d bytes, want %d", len(body), MaxErrorBytes)
+	}
+	msg := TruncateMessage(body)
+	if want := strings.Repeat("x", 1000) + fmt.Sprintf("… (%d more bytes)", MaxErrorBytes-1000); msg != want {
+		t.Errorf("message has %d bytes", len(msg))
+	}
+	if got := TruncateMessage([]byte("short")); got != "short" {
+		t.Errorf("short message = %q", got)
+	}
+	// Never cut inside a multi-byte rune
+	if got := TruncateMessage([]byte(strings.Repeat("é", 600))); !utf8.ValidString(got) {
+		t.Errorf("truncated message is not valid UTF-8")
+	}
+}
@@ -0,0 +1,119 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats collects what a command's API requests cost: how many were sent,
+// how many bytes they moved and how long the server took to answer.
+type Stats struct {
+	mu        sync.Mutex
+	methods   map[string]int
+	failed    int
+	latencies []time.Duration
+	sent      int64
+	received  atomic.Int64 // counted as response bodies are read
+}
+
+// NewStats returns an empty Stats.
+func NewStats() *Stats {
+	return &Stats{methods: make(map[string]int)}
+}
+
+func (s *Stats) record(method string, latency time.Duration, sent int64, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods[method]++
+	s.latencies = append(s.latencies, latency)
+	s.sent += max(sent, 0)
+	if failed {
+		s.failed++
+	}
+}
+
+// Write prints a summary of the requests recorded so far, with wall as the
+// duration of the whole run.
+func (s *Stats) Write(w io.Writer, wall time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintln(w, "Run stats:")
+	fmt.Fprintf(w, "  Wall clock:  %s\n", wall.Round(time.Millisecond))
+	if len(s.latencies) == 0 {
+		fmt.Fprintln(w, "  API calls:   0")
+		return
+	}
+
+	methods := make([]string, 0, len(s.methods))
+	for m := range s.methods {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	counts := make([]string, len(methods))
+	for i, m := range methods {
+		counts[i] = fmt.Sprintf("%s %d", m, s.methods[m])
+	}
+	fmt.Fprintf(w, "  API calls:   %d (%s), %d failed\n", len(s.latencies), strings.Join(counts, ", "), s.failed)
+	fmt.Fprintf(w, "  Transferred: %s received, %s sent\n", formatBytes(s.received.Load()), formatBytes(s.sent))
+
+	sorted := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	fmt.Fprintf(w, "  Latency:     p50 %s, p95 %s, max %s\n",
+		percentile(sorted, 50).Round(time.Millisecond),
+		percentile(sorted, 95).Round(time.Millisecond),
+		sorted[len(sorted)-1].Round(time.Millisecond))
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// formatBytes renders n in B, KB or MB (powers of 1024).
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// statsTransport records every request it sends in stats. Latency is the
+// time until the response headers arrived.
+type statsTransport struct {
+	base  http.RoundTripper
+	stats *Stats
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.stats.record(req.Method, time.Since(start), req.ContentLength, err != nil || resp.StatusCode >= 400)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &t.stats.received}
+	return resp, nil
+}
+
+// countingBody adds the bytes read from a response body to n.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
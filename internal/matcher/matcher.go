@@ -1,53 +1,333 @@
 package matcher
 
-import "strings"
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// regexPrefix marks a pattern as a regular expression, e.g. "re:^cogover-(api|web)-".
+	regexPrefix = "re:"
+	// negatePrefix marks a pattern as an exclusion, e.g. "!legacy".
+	negatePrefix = "!"
+)
 
 // MatchResult holds the outcome of matching patterns against repo slugs.
 type MatchResult struct {
-	Matched   []string // deduplicated slugs that matched at least one pattern
-	Unmatched []string // patterns that matched zero slugs
+	Matched   []string       // deduplicated slugs that matched at least one pattern, best first per pattern
+	Unmatched []string       // patterns that matched zero slugs
+	Patterns  []PatternMatch // per inclusion pattern, the slugs it matched ranked by score
+}
+
+// PatternMatch lists the slugs one pattern matched, best match first.
+type PatternMatch struct {
+	Pattern string
+	Slugs   []string
 }
 
+// Candidate is a repo that patterns are matched against.
+// Besides the slug, patterns also hit the display name, description and project.
+type Candidate struct {
+	Slug        string
+	Name        string
+	Description string
+	ProjectKey  string
+	ProjectName string
+}
+
+// Match scores, highest first. A pattern's score is that of its weakest term.
+const (
+	scoreExact       = 100 // term equals the slug
+	scorePrefix      = 90  // slug starts with the term
+	scoreWordInSlug  = 75  // term starts at a word boundary in the slug
+	scoreGlobOrRegex = 70  // glob or regex hit on the slug
+	scoreInSlug      = 60  // term appears anywhere in the slug
+	scoreName        = 40  // term appears in the display name
+	scoreProject     = 30  // term appears in the project key or name
+	scoreDescription = 20  // term appears in the description
+)
+
 // Match checks each pattern against all slugs using case-insensitive substring matching.
-// Space-separated terms within a pattern use AND logic (all must appear in slug).
+// Space-separated terms within a pattern use AND logic (all must appear in slug);
+// "|" separates alternatives with OR logic, e.g. "api|web" or "cogover api|dashboard".
+// Terms containing glob metacharacters (*, ?, [) must match the whole slug instead,
+// e.g. "payment-*" or "*-service".
+// Patterns prefixed with "re:" are case-insensitive regular expressions matched against the slug;
+// invalid expressions match nothing (use Validate to report them).
+// Patterns prefixed with "!" exclude matching slugs after the inclusion pass; when only
+// exclusions are given, they are applied to all slugs. Exclusions match the slug or
+// display name only, never the description or project.
 func Match(slugs []string, patterns []string) MatchResult {
+	candidates := make([]Candidate, len(slugs))
+	for i, s := range slugs {
+		candidates[i] = Candidate{Slug: s}
+	}
+	return MatchRepos(candidates, patterns)
+}
+
+// MatchRepos is Match over richer repo metadata: each term or expression may hit
+// any of the candidate's fields (slug, name, description, project key or name).
+// Matches are ranked so that slug hits outrank name, project and description hits.
+func MatchRepos(candidates []Candidate, patterns []string) MatchResult {
 	seen := make(map[string]bool)
-	var matched []string
+	var matched []Candidate
 	var unmatched []string
+	var perPattern []PatternMatch
 
+	var positives, negatives []string
 	for _, pattern := range patterns {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
 			continue
 		}
+		if IsNegative(pattern) {
+			if neg := strings.TrimSpace(strings.TrimPrefix(pattern, negatePrefix)); neg != "" {
+				negatives = append(negatives, neg)
+			}
+			continue
+		}
+		positives = append(positives, pattern)
+	}
+
+	if len(positives) == 0 && len(negatives) > 0 {
+		for _, c := range candidates {
+			if !seen[c.Slug] {
+				seen[c.Slug] = true
+				matched = append(matched, c)
+			}
+		}
+	}
 
-		terms := strings.Fields(strings.ToLower(pattern))
-		found := false
+	for _, pattern := range positives {
+		score := compilePattern(pattern)
 
-		for _, slug := range slugs {
-			if matchTerms(strings.ToLower(slug), terms) {
-				if !seen[slug] {
-					seen[slug] = true
-					matched = append(matched, slug)
-				}
-				found = true
+		type hit struct {
+			c     Candidate
+			score int
+		}
+		var hits []hit
+		for _, c := range candidates {
+			if sc := score(c); sc > 0 {
+				hits = append(hits, hit{c, sc})
 			}
 		}
 
-		if !found {
+		if len(hits) == 0 {
 			unmatched = append(unmatched, pattern)
+			continue
 		}
+
+		sort.SliceStable(hits, func(i, j int) bool {
+			if hits[i].score != hits[j].score {
+				return hits[i].score > hits[j].score
+			}
+			return hits[i].c.Slug < hits[j].c.Slug
+		})
+
+		pm := PatternMatch{Pattern: pattern}
+		for _, h := range hits {
+			pm.Slugs = append(pm.Slugs, h.c.Slug)
+			if !seen[h.c.Slug] {
+				seen[h.c.Slug] = true
+				matched = append(matched, h.c)
+			}
+		}
+		perPattern = append(perPattern, pm)
+	}
+
+	// Exclusion pass, against slug and name only: a word in a description
+	// should not drop a repo the user asked for
+	excluded := make(map[string]bool)
+	for _, pattern := range negatives {
+		score := compilePattern(pattern)
+		kept := matched[:0]
+		for _, c := range matched {
+			if score(Candidate{Slug: c.Slug, Name: c.Name}) > 0 {
+				excluded[c.Slug] = true
+			} else {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == len(matched) {
+			unmatched = append(unmatched, negatePrefix+pattern)
+		}
+		matched = kept
+	}
+	if len(excluded) > 0 {
+		for i := range perPattern {
+			kept := perPattern[i].Slugs[:0]
+			for _, slug := range perPattern[i].Slugs {
+				if !excluded[slug] {
+					kept = append(kept, slug)
+				}
+			}
+			perPattern[i].Slugs = kept
+		}
+	}
+
+	var slugs []string
+	for _, c := range matched {
+		slugs = append(slugs, c.Slug)
 	}
 
-	return MatchResult{Matched: matched, Unmatched: unmatched}
+	return MatchResult{Matched: slugs, Unmatched: unmatched, Patterns: perPattern}
 }
 
-// matchTerms returns true if all terms are substrings of slug.
-func matchTerms(slug string, terms []string) bool {
-	for _, t := range terms {
-		if !strings.Contains(slug, t) {
-			return false
+// Validate returns an error describing the first pattern that cannot be compiled.
+func Validate(patterns []string) error {
+	for _, p := range patterns {
+		p = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(p), negatePrefix))
+		if !strings.HasPrefix(p, regexPrefix) {
+			continue
+		}
+		if _, err := compileRegex(p); err != nil {
+			return fmt.Errorf("invalid regex pattern %q: %w", p, err)
 		}
 	}
-	return true
+	return nil
+}
+
+// IsExplicit reports whether a pattern is a regex, glob, alternation or exclusion rather than a plain name.
+func IsExplicit(pattern string) bool {
+	pattern = strings.TrimSpace(pattern)
+	return strings.HasPrefix(pattern, regexPrefix) || IsNegative(pattern) || isGlob(pattern) || strings.Contains(pattern, "|")
+}
+
+// IsNegative reports whether a pattern is an exclusion ("!pattern").
+func IsNegative(pattern string) bool {
+	return strings.HasPrefix(strings.TrimSpace(pattern), negatePrefix)
+}
+
+// compilePattern turns a pattern into a scoring function; 0 means no match.
+func compilePattern(pattern string) func(c Candidate) int {
+	if strings.HasPrefix(pattern, regexPrefix) {
+		re, err := compileRegex(pattern)
+		if err != nil {
+			return func(Candidate) int { return 0 }
+		}
+		return func(c Candidate) int {
+			if re.MatchString(c.Slug) {
+				return scoreGlobOrRegex
+			}
+			for _, f := range []string{c.Name, c.Description, c.ProjectKey, c.ProjectName} {
+				if f != "" && re.MatchString(f) {
+					return scoreDescription
+				}
+			}
+			return 0
+		}
+	}
+
+	// "api|web" — score of the best alternative
+	if strings.Contains(pattern, "|") {
+		var alts []func(Candidate) int
+		for _, alt := range strings.Split(pattern, "|") {
+			if alt = strings.TrimSpace(alt); alt != "" {
+				alts = append(alts, compilePattern(alt))
+			}
+		}
+		return func(c Candidate) int {
+			best := 0
+			for _, score := range alts {
+				if sc := score(c); sc > best {
+					best = sc
+				}
+			}
+			return best
+		}
+	}
+
+	terms := strings.Fields(strings.ToLower(pattern))
+	return func(c Candidate) int {
+		lowered := Candidate{
+			Slug:        strings.ToLower(c.Slug),
+			Name:        strings.ToLower(c.Name),
+			Description: strings.ToLower(c.Description),
+			ProjectKey:  strings.ToLower(c.ProjectKey),
+			ProjectName: strings.ToLower(c.ProjectName),
+		}
+		score := 0
+		for i, t := range terms {
+			sc := scoreTerm(lowered, t)
+			if sc == 0 {
+				return 0
+			}
+			if i == 0 || sc < score {
+				score = sc
+			}
+		}
+		return score
+	}
+}
+
+// scoreTerm returns the best score of a single lowercased term against a lowercased candidate.
+func scoreTerm(c Candidate, term string) int {
+	if isGlob(term) {
+		switch {
+		case matchGlob(term, c.Slug):
+			return scoreGlobOrRegex
+		case matchGlob(term, c.Name):
+			return scoreName
+		case matchGlob(term, c.ProjectKey), matchGlob(term, c.ProjectName):
+			return scoreProject
+		case matchGlob(term, c.Description):
+			return scoreDescription
+		}
+		return 0
+	}
+
+	switch {
+	case c.Slug == term:
+		return scoreExact
+	case strings.HasPrefix(c.Slug, term):
+		return scorePrefix
+	case containsAtWordStart(c.Slug, term):
+		return scoreWordInSlug
+	case strings.Contains(c.Slug, term):
+		return scoreInSlug
+	case strings.Contains(c.Name, term):
+		return scoreName
+	case strings.Contains(c.ProjectKey, term), strings.Contains(c.ProjectName, term):
+		return scoreProject
+	case strings.Contains(c.Description, term):
+		return scoreDescription
+	}
+	return 0
+}
+
+// containsAtWordStart reports whether term occurs in s right after a separator.
+func containsAtWordStart(s, term string) bool {
+	for i := strings.Index(s, term); i >= 0; {
+		if i > 0 && strings.ContainsRune("-_./ ", rune(s[i-1])) {
+			return true
+		}
+		next := strings.Index(s[i+1:], term)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return false
+}
+
+// compileRegex compiles a "re:" pattern case-insensitively.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + strings.TrimPrefix(pattern, regexPrefix))
+}
+
+// matchGlob matches a glob term against the whole field.
+func matchGlob(term, field string) bool {
+	if field == "" {
+		return false
+	}
+	ok, err := path.Match(term, field)
+	return err == nil && ok
+}
+
+// isGlob reports whether term contains shell-style glob metacharacters.
+func isGlob(term string) bool {
+	return strings.ContainsAny(term, "*?[")
 }
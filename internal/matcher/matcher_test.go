@@ -1,6 +1,7 @@
 package matcher
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -98,3 +99,305 @@ func TestPatternMatchesMultipleRepos(t *testing.T) {
 		t.Errorf("expected 3 repos matching 'cogover', got %v", result.Matched)
 	}
 }
+
+func TestGlobPrefix(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover-*"})
+	if len(result.Matched) != 3 {
+		t.Errorf("expected 3 repos matching 'cogover-*', got %v", result.Matched)
+	}
+}
+
+func TestGlobSuffix(t *testing.T) {
+	result := Match(testSlugs, []string{"*-app"})
+	if len(result.Matched) != 1 || result.Matched[0] != "cogover-subscription-app" {
+		t.Errorf("expected [cogover-subscription-app], got %v", result.Matched)
+	}
+}
+
+func TestGlobAnchorsWholeSlug(t *testing.T) {
+	// "api*" must match from the start, unlike substring "api"
+	result := Match(testSlugs, []string{"api*"})
+	if len(result.Matched) != 1 || result.Matched[0] != "api.stringeex.com" {
+		t.Errorf("expected [api.stringeex.com], got %v", result.Matched)
+	}
+}
+
+func TestGlobCombinedWithTerm(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover-* web"})
+	if len(result.Matched) != 1 || result.Matched[0] != "cogover-web-admin" {
+		t.Errorf("expected [cogover-web-admin], got %v", result.Matched)
+	}
+}
+
+func TestGlobCaseInsensitive(t *testing.T) {
+	result := Match(testSlugs, []string{"STRINGEEX-*"})
+	if len(result.Matched) != 1 || result.Matched[0] != "stringeex-dashboard" {
+		t.Errorf("expected [stringeex-dashboard], got %v", result.Matched)
+	}
+}
+
+func TestGlobInvalidPattern(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover-["})
+	if len(result.Matched) != 0 {
+		t.Errorf("expected no matches for invalid glob, got %v", result.Matched)
+	}
+	if len(result.Unmatched) != 1 {
+		t.Errorf("expected invalid glob reported as unmatched, got %v", result.Unmatched)
+	}
+}
+
+func TestRegexPattern(t *testing.T) {
+	result := Match(testSlugs, []string{"re:^cogover-(api|web)-"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 regex matches, got %v", result.Matched)
+	}
+}
+
+func TestRegexCaseInsensitive(t *testing.T) {
+	result := Match(testSlugs, []string{"re:DASHBOARD$"})
+	if len(result.Matched) != 1 || result.Matched[0] != "stringeex-dashboard" {
+		t.Errorf("expected [stringeex-dashboard], got %v", result.Matched)
+	}
+}
+
+func TestRegexKeepsSpaces(t *testing.T) {
+	// Spaces inside a regex are literal, not AND separators
+	result := Match(testSlugs, []string{"re:cogover api"})
+	if len(result.Matched) != 0 {
+		t.Errorf("expected no matches, got %v", result.Matched)
+	}
+}
+
+func TestRegexInvalidIsUnmatched(t *testing.T) {
+	result := Match(testSlugs, []string{"re:(unclosed"})
+	if len(result.Matched) != 0 || len(result.Unmatched) != 1 {
+		t.Errorf("expected invalid regex to be unmatched, got %+v", result)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{"api", "cogover-*", "re:^api"}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+	err := Validate([]string{"api", "re:(unclosed"})
+	if err == nil {
+		t.Fatal("expected error for invalid regex")
+	}
+	if !strings.Contains(err.Error(), "re:(unclosed") {
+		t.Errorf("error %q should name the pattern", err)
+	}
+}
+
+func TestIsExplicit(t *testing.T) {
+	tests := map[string]bool{
+		"repo-api":  false,
+		"api web":   false,
+		"payment-*": true,
+		"re:^api":   true,
+		" re:^api ": true,
+		"repo-?":    true,
+	}
+	for pattern, want := range tests {
+		if got := IsExplicit(pattern); got != want {
+			t.Errorf("IsExplicit(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+func TestNegativePattern(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover", "!web"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 repos after exclusion, got %v", result.Matched)
+	}
+	for _, s := range result.Matched {
+		if s == "cogover-web-admin" {
+			t.Errorf("excluded repo %q still matched", s)
+		}
+	}
+}
+
+func TestNegativeOnlyStartsFromAll(t *testing.T) {
+	result := Match(testSlugs, []string{"!cogover"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 non-cogover repos, got %v", result.Matched)
+	}
+}
+
+func TestNegativeGlobAndRegex(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover", "!*-app", "!re:gateway$"})
+	if len(result.Matched) != 1 || result.Matched[0] != "cogover-web-admin" {
+		t.Errorf("expected [cogover-web-admin], got %v", result.Matched)
+	}
+}
+
+func TestNegativeExcludingNothingIsUnmatched(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover", "!legacy"})
+	if len(result.Matched) != 3 {
+		t.Errorf("expected 3 matches, got %v", result.Matched)
+	}
+	if len(result.Unmatched) != 1 || result.Unmatched[0] != "!legacy" {
+		t.Errorf("expected [!legacy] unmatched, got %v", result.Unmatched)
+	}
+}
+
+func TestValidateNegativeRegex(t *testing.T) {
+	if err := Validate([]string{"!re:(bad"}); err == nil {
+		t.Error("expected error for invalid negated regex")
+	}
+}
+
+var testRepos = []Candidate{
+	{Slug: "svc-pay-01", Name: "Payment Gateway", Description: "Handles card payments", ProjectKey: "PAY", ProjectName: "Payments"},
+	{Slug: "svc-ntf-02", Name: "Notifier", Description: "Email and SMS notifications", ProjectKey: "CORE", ProjectName: "Core Platform"},
+	{Slug: "legacy-pay", Name: "Old Payment", ProjectKey: "PAY"},
+}
+
+func TestMatchRepos_ByName(t *testing.T) {
+	result := MatchRepos(testRepos, []string{"gateway"})
+	if len(result.Matched) != 1 || result.Matched[0] != "svc-pay-01" {
+		t.Errorf("expected [svc-pay-01], got %v", result.Matched)
+	}
+}
+
+func TestMatchRepos_ByDescription(t *testing.T) {
+	result := MatchRepos(testRepos, []string{"sms"})
+	if len(result.Matched) != 1 || result.Matched[0] != "svc-ntf-02" {
+		t.Errorf("expected [svc-ntf-02], got %v", result.Matched)
+	}
+}
+
+func TestMatchRepos_ByProjectKey(t *testing.T) {
+	result := MatchRepos(testRepos, []string{"PAY"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 repos in PAY project, got %v", result.Matched)
+	}
+}
+
+func TestMatchRepos_TermsAcrossFields(t *testing.T) {
+	// "core" hits the project, "notif" hits the name
+	result := MatchRepos(testRepos, []string{"core notif"})
+	if len(result.Matched) != 1 || result.Matched[0] != "svc-ntf-02" {
+		t.Errorf("expected [svc-ntf-02], got %v", result.Matched)
+	}
+}
+
+func TestMatchRepos_ExclusionUsesMetadata(t *testing.T) {
+	result := MatchRepos(testRepos, []string{"pay", "!old"})
+	if len(result.Matched) != 1 || result.Matched[0] != "svc-pay-01" {
+		t.Errorf("expected [svc-pay-01], got %v", result.Matched)
+	}
+}
+
+func TestMatchRepos_ExclusionIgnoresDescriptionAndProject(t *testing.T) {
+	// "card" is only in svc-pay-01's description, "core" only in svc-ntf-02's project
+	result := MatchRepos(testRepos, []string{"svc", "!card", "!core"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected both svc repos kept, got %v", result.Matched)
+	}
+}
+
+func TestMatchRepos_RegexOnName(t *testing.T) {
+	result := MatchRepos(testRepos, []string{"re:^payment "})
+	if len(result.Matched) != 1 || result.Matched[0] != "svc-pay-01" {
+		t.Errorf("expected [svc-pay-01], got %v", result.Matched)
+	}
+}
+
+func TestRanking_SlugHitsFirst(t *testing.T) {
+	repos := []Candidate{
+		{Slug: "docs-site", Description: "Documentation for the api"},
+		{Slug: "billing-api"},
+		{Slug: "api"},
+		{Slug: "apigw"},
+		{Slug: "rapid"},
+	}
+	result := MatchRepos(repos, []string{"api"})
+
+	want := []string{"api", "apigw", "billing-api", "rapid", "docs-site"}
+	if len(result.Matched) != len(want) {
+		t.Fatalf("Matched = %v, want %v", result.Matched, want)
+	}
+	for i := range want {
+		if result.Matched[i] != want[i] {
+			t.Errorf("Matched[%d] = %q, want %q (full: %v)", i, result.Matched[i], want[i], result.Matched)
+		}
+	}
+}
+
+func TestPatterns_PerPatternRankedSlugs(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover", "dashboard", "nothing"})
+	if len(result.Patterns) != 2 {
+		t.Fatalf("len(Patterns) = %d, want 2", len(result.Patterns))
+	}
+	if result.Patterns[0].Pattern != "cogover" || len(result.Patterns[0].Slugs) != 3 {
+		t.Errorf("Patterns[0] = %+v", result.Patterns[0])
+	}
+	if result.Patterns[1].Pattern != "dashboard" || len(result.Patterns[1].Slugs) != 1 {
+		t.Errorf("Patterns[1] = %+v", result.Patterns[1])
+	}
+}
+
+func TestPatterns_ExclusionsRemoved(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover", "!web"})
+	for _, s := range result.Patterns[0].Slugs {
+		if s == "cogover-web-admin" {
+			t.Errorf("excluded slug %q still listed for pattern", s)
+		}
+	}
+}
+
+func TestContainsAtWordStart(t *testing.T) {
+	tests := []struct {
+		s, term string
+		want    bool
+	}{
+		{"billing-api", "api", true},
+		{"rapid-api", "api", true},
+		{"rapid", "api", false},
+		{"api", "api", false}, // prefix is scored separately
+	}
+	for _, tc := range tests {
+		if got := containsAtWordStart(tc.s, tc.term); got != tc.want {
+			t.Errorf("containsAtWordStart(%q, %q) = %v, want %v", tc.s, tc.term, got, tc.want)
+		}
+	}
+}
+
+func TestOrAlternation(t *testing.T) {
+	result := Match(testSlugs, []string{"subscription|dashboard"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 repos for OR pattern, got %v", result.Matched)
+	}
+	if len(result.Unmatched) != 0 {
+		t.Errorf("expected no unmatched, got %v", result.Unmatched)
+	}
+}
+
+func TestOrAlternationWithAndTerms(t *testing.T) {
+	// (cogover AND web) OR dashboard
+	result := Match(testSlugs, []string{"cogover web|dashboard"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 repos, got %v", result.Matched)
+	}
+}
+
+func TestOrAlternationIgnoresEmpty(t *testing.T) {
+	result := Match(testSlugs, []string{"|dashboard|"})
+	if len(result.Matched) != 1 || result.Matched[0] != "stringeex-dashboard" {
+		t.Errorf("expected [stringeex-dashboard], got %v", result.Matched)
+	}
+}
+
+func TestOrAlternationNotAppliedToRegex(t *testing.T) {
+	result := Match(testSlugs, []string{"re:^(stringeex|api)"})
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 regex matches, got %v", result.Matched)
+	}
+}
+
+func TestNegatedOrAlternation(t *testing.T) {
+	result := Match(testSlugs, []string{"cogover", "!web|gateway"})
+	if len(result.Matched) != 1 || result.Matched[0] != "cogover-subscription-app" {
+		t.Errorf("expected [cogover-subscription-app], got %v", result.Matched)
+	}
+}
@@ -0,0 +1,88 @@
+// Package plan stores a bulk branch creation, resolved and checked ahead of
+// time, in a file that can be reviewed before 'buck apply' carries it out.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Version is the plan file format written by this build.
+const Version = 1
+
+// Plan is a branch creation across repos with every input resolved: the
+// repos, the commit each branch starts at and, with PRs, their destinations.
+type Plan struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Provider  string    `json:"provider"`
+	Workspace string    `json:"workspace"`
+	Branch    string    `json:"branch"`
+	WithPR    bool      `json:"with_pr,omitempty"`
+	Repos     []Repo    `json:"repos"`
+}
+
+// Repo is one repo's part of a plan.
+type Repo struct {
+	Repo        string `json:"repo"`                  // entry as selected, e.g. "repo" or "workspace/repo"
+	Source      string `json:"source"`                // branch or tag the commit was resolved from
+	Commit      string `json:"commit"`                // the new branch starts here
+	Destination string `json:"destination,omitempty"` // PR destination, with with_pr
+}
+
+// Write saves p to path as indented JSON.
+func Write(path string, p *Plan) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// Read loads and validates the plan at path.
+func Read(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *Plan) validate() error {
+	if p.Version != Version {
+		return fmt.Errorf("format version %d is not supported (want %d)", p.Version, Version)
+	}
+	if p.Workspace == "" || p.Branch == "" {
+		return fmt.Errorf("workspace and branch are required")
+	}
+	if len(p.Repos) == 0 {
+		return fmt.Errorf("no repos")
+	}
+	seen := make(map[string]bool, len(p.Repos))
+	for _, r := range p.Repos {
+		switch {
+		case r.Repo == "":
+			return fmt.Errorf("a repo entry has no repo")
+		case seen[r.Repo]:
+			return fmt.Errorf("repo %q is listed twice", r.Repo)
+		case r.Commit == "":
+			return fmt.Errorf("repo %q has no commit", r.Repo)
+		case p.WithPR && r.Destination == "":
+			return fmt.Errorf("repo %q has no PR destination", r.Repo)
+		}
+		seen[r.Repo] = true
+	}
+	return nil
+}
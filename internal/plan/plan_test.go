@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buck.plan.json")
+	want := &Plan{
+		Version:   Version,
+		CreatedAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		Provider:  "bitbucket",
+		Workspace: "acme",
+		Branch:    "feature/x",
+		WithPR:    true,
+		Repos: []Repo{
+			{Repo: "api", Source: "develop", Commit: "0123456789ab", Destination: "develop"},
+			{Repo: "other/web", Source: "main", Commit: "ba9876543210", Destination: "main"},
+		},
+	}
+	if err := Write(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.Branch != want.Branch || !got.WithPR || len(got.Repos) != 2 || got.Repos[1] != want.Repos[1] {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := []struct {
+		name, data, wantErr string
+	}{
+		{"not json", `{`, "failed to parse"},
+		{"version", `{"version": 2, "workspace": "acme", "branch": "x", "repos": [{"repo": "a", "commit": "c"}]}`, "version 2"},
+		{"no branch", `{"version": 1, "workspace": "acme", "repos": [{"repo": "a", "commit": "c"}]}`, "branch are required"},
+		{"no repos", `{"version": 1, "workspace": "acme", "branch": "x", "repos": []}`, "no repos"},
+		{"duplicate", `{"version": 1, "workspace": "acme", "branch": "x", "repos": [{"repo": "a", "commit": "c"}, {"repo": "a", "commit": "d"}]}`, "listed twice"},
+		{"no commit", `{"version": 1, "workspace": "acme", "branch": "x", "repos": [{"repo": "a"}]}`, "no commit"},
+		{"no destination", `{"version": 1, "workspace": "acme", "branch": "x", "with_pr": true, "repos": [{"repo": "a", "commit": "c"}]}`, "no PR destination"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plan.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Read(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
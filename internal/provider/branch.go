@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// FallbackBranch is used when a repo has no main branch set.
+const FallbackBranch = "master"
+
+// DevelopmentBranch returns the branch new work in a repo starts from and is
+// merged back into: the branching model's development branch when the backend
+// has one, otherwise the repository's main branch. It returns FallbackBranch
+// only when the repository has no main branch set; when the repository cannot
+// be read (forbidden, network error) the error is returned, so nothing is
+// branched from or merged into a guessed branch.
+func DevelopmentBranch(p Provider, workspace, repoSlug string) (string, error) {
+	return DevelopmentBranchWith(p, workspace, repoSlug, p.GetRepository)
+}
+
+// DevelopmentBranchWith is DevelopmentBranch reading the repository through
+// getRepo, so callers can serve it from a cache.
+func DevelopmentBranchWith(p Provider, workspace, repoSlug string, getRepo func(workspace, repoSlug string) (*bitbucket.Repository, error)) (string, error) {
+	// The branching model is optional; a repo without one uses its main branch
+	if bm, ok := p.(BranchingModelService); ok {
+		if model, err := bm.GetBranchingModel(workspace, repoSlug); err == nil {
+			if b := model.Development.Branch; b != nil && b.Name != "" {
+				return b.Name, nil
+			}
+		}
+	}
+
+	repo, err := getRepo(workspace, repoSlug)
+	if err != nil {
+		return "", fmt.Errorf("could not determine the development branch: %w", err)
+	}
+	if repo.MainBranch != nil && repo.MainBranch.Name != "" {
+		return repo.MainBranch.Name, nil
+	}
+	return FallbackBranch, nil
+}
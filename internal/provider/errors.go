@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/httpx"
+)
+
+// ErrorCategory groups API failures by cause, for summaries and JSON output.
+type ErrorCategory string
+
+// Error categories reported on results.
+const (
+	ErrConflict    ErrorCategory = "conflict"
+	ErrNotFound    ErrorCategory = "not-found"
+	ErrForbidden   ErrorCategory = "forbidden"
+	ErrRateLimited ErrorCategory = "rate-limited"
+	ErrNetwork     ErrorCategory = "network"
+	ErrAborted     ErrorCategory = "aborted" // not sent after the first requests all failed alike
+	ErrOther       ErrorCategory = "other"
+)
+
+// Classify returns the category of err, or "" when err is nil. Backends
+// report an existing branch or PR as 400 or 422 rather than 409, so those
+// count as conflicts when the message says so.
+func Classify(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, httpx.ErrCircuitOpen) {
+		return ErrAborted
+	}
+	switch bitbucket.StatusCode(err) {
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		msg := strings.ReplaceAll(strings.ToLower(err.Error()), "_", " ")
+		if strings.Contains(msg, "already exist") {
+			return ErrConflict
+		}
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case 0:
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return ErrNetwork
+		}
+	}
+	return ErrOther
+}
+
+// Transient reports whether err may go away when the request is sent again:
+// a network error, rate limiting or a 5xx response.
+func Transient(err error) bool {
+	switch Classify(err) {
+	case ErrNetwork, ErrRateLimited:
+		return true
+	case ErrOther:
+		return bitbucket.StatusCode(err) >= 500
+	}
+	return false
+}
+
+// categoryLabels describe a count of failures: singular, plural.
+var categoryLabels = map[ErrorCategory][2]string{
+	ErrConflict:    {"already exists", "already exist"},
+	ErrNotFound:    {"not found", "not found"},
+	ErrForbidden:   {"forbidden", "forbidden"},
+	ErrRateLimited: {"rate-limited", "rate-limited"},
+	ErrNetwork:     {"network error", "network errors"},
+	ErrAborted:     {"not attempted", "not attempted"},
+	ErrOther:       {"other error", "other errors"},
+}
+
+// FailureSummary counts failures by category, e.g. "3 repos failed: 2 already
+// exist, 1 forbidden", most frequent first. An empty category counts as other.
+// It returns "" when there are no failures.
+func FailureSummary(categories []ErrorCategory) string {
+	if len(categories) == 0 {
+		return ""
+	}
+	counts := make(map[ErrorCategory]int)
+	for _, c := range categories {
+		counts[known(c)]++
+	}
+
+	order := byFrequency(counts)
+	parts := make([]string, len(order))
+	for i, c := range order {
+		label := categoryLabels[c][1]
+		if counts[c] == 1 {
+			label = categoryLabels[c][0]
+		}
+		parts[i] = fmt.Sprintf("%d %s", counts[c], label)
+	}
+	noun := "repos"
+	if len(categories) == 1 {
+		noun = "repo"
+	}
+	return fmt.Sprintf("%d %s failed: %s", len(categories), noun, strings.Join(parts, ", "))
+}
+
+// FailureGroups lists the failed repos under their category, one line per
+// category in the order of FailureSummary, e.g. "already exists: repo-a,
+// repo-b". repos and categories are parallel; an empty category counts as
+// other.
+func FailureGroups(repos []string, categories []ErrorCategory) []string {
+	counts := make(map[ErrorCategory]int)
+	members := make(map[ErrorCategory][]string)
+	for i, c := range categories {
+		c = known(c)
+		counts[c]++
+		members[c] = append(members[c], repos[i])
+	}
+
+	order := byFrequency(counts)
+	lines := make([]string, len(order))
+	for i, c := range order {
+		lines[i] = categoryLabels[c][0] + ": " + strings.Join(members[c], ", ")
+	}
+	return lines
+}
+
+// known returns c, or ErrOther for a category without a label.
+func known(c ErrorCategory) ErrorCategory {
+	if _, ok := categoryLabels[c]; !ok {
+		return ErrOther
+	}
+	return c
+}
+
+// byFrequency returns the categories of counts, most frequent first.
+func byFrequency(counts map[ErrorCategory]int) []ErrorCategory {
+	order := make([]ErrorCategory, 0, len(counts))
+	for c := range counts {
+		order = append(order, c)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if counts[order[i]] != counts[order[j]] {
+			return counts[order[i]] > counts[order[j]]
+		}
+		return order[i] < order[j]
+	})
+	return order
+}
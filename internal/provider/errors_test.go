@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/httpx"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorCategory
+	}{
+		{nil, ""},
+		{&bitbucket.StatusError{StatusCode: 409, Message: "conflict"}, ErrConflict},
+		{&bitbucket.StatusError{StatusCode: 400, Message: "BRANCH_ALREADY_EXISTS"}, ErrConflict},
+		{&bitbucket.StatusError{StatusCode: 422, Message: "Validation Failed: A pull request already exists"}, ErrConflict},
+		{&bitbucket.StatusError{StatusCode: 400, Message: "bad destination"}, ErrOther},
+		{&bitbucket.StatusError{StatusCode: 404, Message: "not found"}, ErrNotFound},
+		{&bitbucket.StatusError{StatusCode: 403, Message: "denied"}, ErrForbidden},
+		{&bitbucket.StatusError{StatusCode: 401, Message: "denied"}, ErrForbidden},
+		{&bitbucket.StatusError{StatusCode: 429, Message: "slow down"}, ErrRateLimited},
+		{fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), ErrNetwork},
+		{fmt.Errorf("request failed: %w", fmt.Errorf("%w: the first 5 requests all failed with 401", httpx.ErrCircuitOpen)), ErrAborted},
+		{errors.New("failed to decode response"), ErrOther},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("request failed: %w", &net.OpError{Op: "read", Err: errors.New("connection reset")}), true},
+		{&bitbucket.StatusError{StatusCode: 429, Message: "slow down"}, true},
+		{&bitbucket.StatusError{StatusCode: 502, Message: "bad gateway"}, true},
+		{&bitbucket.StatusError{StatusCode: 409, Message: "conflict"}, false},
+		{&bitbucket.StatusError{StatusCode: 400, Message: "bad destination"}, false},
+		{fmt.Errorf("%w: breaker open", httpx.ErrCircuitOpen), false},
+	}
+	for _, tt := range tests {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFailureSummary(t *testing.T) {
+	if got := FailureSummary(nil); got != "" {
+		t.Errorf("no failures = %q", got)
+	}
+	got := FailureSummary([]ErrorCategory{ErrForbidden, ErrConflict, ErrConflict, ""})
+	if want := "4 repos failed: 2 already exist, 1 forbidden, 1 other error"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := FailureSummary([]ErrorCategory{ErrNetwork}), "1 repo failed: 1 network error"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFailureGroups(t *testing.T) {
+	got := FailureGroups(
+		[]string{"repo-a", "repo-c", "repo-b", "repo-d"},
+		[]ErrorCategory{ErrConflict, ErrForbidden, ErrConflict, ""},
+	)
+	want := []string{"already exists: repo-a, repo-b", "forbidden: repo-c", "other error: repo-d"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
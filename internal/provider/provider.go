@@ -0,0 +1,179 @@
+// Package provider defines the hosting-service API used by the orchestrators.
+// The bitbucket package's types (Repository, Branch, PullRequest, ...) serve as
+// the shared data model, so each backend converts its responses into them.
+package provider
+
+import (
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/github"
+	"github.com/chinhstringee/buck/internal/gitlab"
+)
+
+// Supported provider names for the config "provider" key.
+const (
+	Bitbucket = "bitbucket"
+	GitHub    = "github"
+	GitLab    = "gitlab"
+)
+
+// Provider is implemented by each hosting backend (Bitbucket Cloud, GitHub, GitLab).
+// "workspace" is the Bitbucket workspace or the equivalent org (GitHub) or group (GitLab).
+// Orchestrators depend on this interface only, so a new backend needs no changes to them.
+type Provider interface {
+	RepositoryService
+	BranchService
+	PullRequestService
+
+	// GetCurrentUser returns the authenticated user.
+	GetCurrentUser() (*bitbucket.User, error)
+}
+
+// RepositoryService lists and reads repositories.
+type RepositoryService interface {
+	ListRepositories(workspace string) ([]bitbucket.Repository, error)
+	GetRepository(workspace, repoSlug string) (*bitbucket.Repository, error)
+}
+
+// BranchService manages branches and the commits between them.
+type BranchService interface {
+	CreateBranch(workspace, repoSlug, branchName, sourceBranch string) (*bitbucket.Branch, error)
+	DeleteBranch(workspace, repoSlug, branchName string) error
+	GetBranch(workspace, repoSlug, branchName string) (*bitbucket.Branch, error)
+	ListBranches(workspace, repoSlug string) ([]bitbucket.Branch, error)
+	ListCommits(workspace, repoSlug, include, exclude string) ([]bitbucket.Commit, error)
+	ResolveCommit(workspace, repoSlug, ref string) (string, error)
+	BranchURL(workspace, repoSlug, branchName string) string
+}
+
+// PullRequestService manages pull requests (merge requests on GitLab).
+// States use Bitbucket's names: OPEN, MERGED, DECLINED.
+type PullRequestService interface {
+	CreatePullRequest(workspace, repoSlug string, pr bitbucket.CreatePullRequestRequest) (*bitbucket.PullRequest, error)
+	ListPullRequests(workspace, repoSlug, state string) ([]bitbucket.PullRequest, error)
+	FindPRByBranch(workspace, repoSlug, branchName, state string) (*bitbucket.PullRequest, error)
+	MergePR(workspace, repoSlug string, prID int, req bitbucket.MergePRRequest) error
+	DeclinePR(workspace, repoSlug string, prID int) error
+	ApprovePR(workspace, repoSlug string, prID int) error
+	CommentPR(workspace, repoSlug string, prID int, text string) error
+	ListPRParticipants(workspace, repoSlug string, prID int) ([]bitbucket.PRParticipant, error)
+	ListPRBuildStatuses(workspace, repoSlug string, prID int) ([]bitbucket.BuildStatus, error)
+	UpdatePR(workspace, repoSlug string, prID int, req bitbucket.PRUpdateRequest) (*bitbucket.PullRequest, error)
+	ListMergedPRBranches(workspace, repoSlug string) ([]string, error)
+}
+
+// Optional capabilities. Commands type-assert a Provider to these and report
+// the feature as unsupported when the backend lacks it.
+
+// BranchRestrictionService manages branch permissions (Bitbucket).
+type BranchRestrictionService interface {
+	ListBranchRestrictions(workspace, repoSlug string) ([]bitbucket.BranchRestriction, error)
+	CreateBranchRestriction(workspace, repoSlug string, r bitbucket.BranchRestriction) (*bitbucket.BranchRestriction, error)
+	UpdateBranchRestriction(workspace, repoSlug string, id int, r bitbucket.BranchRestriction) (*bitbucket.BranchRestriction, error)
+}
+
+// RepositorySettingsService reads and updates repository settings (Bitbucket).
+type RepositorySettingsService interface {
+	GetRepositorySettings(workspace, repoSlug string) (*bitbucket.RepositorySettings, error)
+	UpdateRepositorySettings(workspace, repoSlug string, settings bitbucket.RepositorySettings) error
+}
+
+// BranchingModelService reads a repository's branching model (Bitbucket).
+type BranchingModelService interface {
+	GetBranchingModel(workspace, repoSlug string) (*bitbucket.BranchingModel, error)
+}
+
+// DefaultBranchService changes a repository's default (main) branch (Bitbucket).
+type DefaultBranchService interface {
+	SetDefaultBranch(workspace, repoSlug, branchName string) error
+}
+
+// RepositoryCreationService creates repositories and commits files to them (Bitbucket).
+type RepositoryCreationService interface {
+	CreateRepository(workspace string, repo bitbucket.NewRepository) (*bitbucket.Repository, error)
+	CommitFiles(workspace, repoSlug, branch, message string, files map[string][]byte) error
+}
+
+// ForkService forks repositories into another workspace (org or group).
+type ForkService interface {
+	ForkRepository(workspace, repoSlug, targetWorkspace string) (*bitbucket.Repository, error)
+}
+
+// RepositoryArchiveService makes repositories read-only (GitHub, GitLab;
+// Bitbucket Cloud has no archive API).
+type RepositoryArchiveService interface {
+	ArchiveRepository(workspace, repoSlug string) error
+}
+
+// RepositoryDeletionService deletes repositories.
+type RepositoryDeletionService interface {
+	DeleteRepository(workspace, repoSlug string) error
+}
+
+// PermissionService reads explicit user and group permissions on repositories (Bitbucket).
+type PermissionService interface {
+	ListRepoPermissions(workspace, repoSlug string) ([]bitbucket.RepoPermission, error)
+}
+
+// PipelineVariableService manages repository-level CI variables (Bitbucket Pipelines).
+type PipelineVariableService interface {
+	ListPipelineVariables(workspace, repoSlug string) ([]bitbucket.PipelineVariable, error)
+	CreatePipelineVariable(workspace, repoSlug string, v bitbucket.PipelineVariable) (*bitbucket.PipelineVariable, error)
+	UpdatePipelineVariable(workspace, repoSlug, variableUUID string, v bitbucket.PipelineVariable) (*bitbucket.PipelineVariable, error)
+	DeletePipelineVariable(workspace, repoSlug, variableUUID string) error
+}
+
+// DeployKeyService manages repository deploy keys.
+type DeployKeyService interface {
+	ListDeployKeys(workspace, repoSlug string) ([]bitbucket.DeployKey, error)
+	AddDeployKey(workspace, repoSlug string, key bitbucket.DeployKey) (*bitbucket.DeployKey, error)
+	DeleteDeployKey(workspace, repoSlug string, keyID int) error
+}
+
+// WebhookService manages repository webhooks.
+type WebhookService interface {
+	ListWebhooks(workspace, repoSlug string) ([]bitbucket.Webhook, error)
+	CreateWebhook(workspace, repoSlug string, hook bitbucket.Webhook) (*bitbucket.Webhook, error)
+	UpdateWebhook(workspace, repoSlug, hookUUID string, hook bitbucket.Webhook) (*bitbucket.Webhook, error)
+	DeleteWebhook(workspace, repoSlug, hookUUID string) error
+}
+
+// PRTaskService adds tasks to pull requests (Bitbucket). Other backends get
+// the checklist appended to the PR description instead.
+type PRTaskService interface {
+	CreatePRTask(workspace, repoSlug string, prID int, content string) (*bitbucket.PRTask, error)
+}
+
+// CommitCountService counts commits between two refs without reading them all
+// (Bitbucket, whose commit list is paginated). atLeast stops the count once it
+// is exceeded; capped reports that n is a lower bound.
+type CommitCountService interface {
+	CountCommits(workspace, repoSlug, include, exclude string, atLeast int) (n int, capped bool, err error)
+}
+
+var (
+	_ Provider = (*bitbucket.Client)(nil)
+	_ Provider = (*github.Client)(nil)
+	_ Provider = (*gitlab.Client)(nil)
+
+	_ BranchRestrictionService  = (*bitbucket.Client)(nil)
+	_ RepositorySettingsService = (*bitbucket.Client)(nil)
+	_ BranchingModelService     = (*bitbucket.Client)(nil)
+	_ DefaultBranchService      = (*bitbucket.Client)(nil)
+	_ RepositoryCreationService = (*bitbucket.Client)(nil)
+	_ PipelineVariableService   = (*bitbucket.Client)(nil)
+	_ DeployKeyService          = (*bitbucket.Client)(nil)
+	_ WebhookService            = (*bitbucket.Client)(nil)
+	_ PRTaskService             = (*bitbucket.Client)(nil)
+	_ PermissionService         = (*bitbucket.Client)(nil)
+	_ CommitCountService        = (*bitbucket.Client)(nil)
+
+	_ ForkService = (*bitbucket.Client)(nil)
+	_ ForkService = (*github.Client)(nil)
+	_ ForkService = (*gitlab.Client)(nil)
+
+	_ RepositoryArchiveService  = (*github.Client)(nil)
+	_ RepositoryArchiveService  = (*gitlab.Client)(nil)
+	_ RepositoryDeletionService = (*bitbucket.Client)(nil)
+	_ RepositoryDeletionService = (*github.Client)(nil)
+	_ RepositoryDeletionService = (*gitlab.Client)(nil)
+)
@@ -0,0 +1,43 @@
+package provider
+
+import "strings"
+
+// SplitRepo splits a --repos or group entry into the workspace and slug to
+// call the API with. A fully-qualified "workspace/repo" entry names its own
+// workspace; a plain slug belongs to the default workspace. The last slash
+// separates the two, so GitLab subgroups ("group/sub/repo") work too.
+func SplitRepo(workspace, repo string) (string, string) {
+	if i := strings.LastIndex(repo, "/"); i > 0 && i < len(repo)-1 {
+		return repo[:i], repo[i+1:]
+	}
+	return workspace, repo
+}
+
+// QualifyRepo returns the entry naming repoSlug in workspace: the bare slug
+// in the default workspace, "workspace/slug" in any other.
+func QualifyRepo(defaultWorkspace, workspace, repoSlug string) string {
+	if workspace == "" || workspace == defaultWorkspace {
+		return repoSlug
+	}
+	return workspace + "/" + repoSlug
+}
+
+// SplitPattern splits a --repos or group entry that may be a pattern into
+// the workspace it names and the pattern to match there, keeping a leading
+// "!". A regex is scoped with "workspace/re:...", since its own slashes
+// must not be taken for a workspace prefix.
+func SplitPattern(defaultWorkspace, entry string) (string, string) {
+	entry = strings.TrimSpace(entry)
+	negate := ""
+	if strings.HasPrefix(entry, "!") {
+		negate, entry = "!", strings.TrimSpace(strings.TrimPrefix(entry, "!"))
+	}
+	if i := strings.Index(entry, "/re:"); i > 0 {
+		return entry[:i], negate + entry[i+1:]
+	}
+	if strings.HasPrefix(entry, "re:") {
+		return defaultWorkspace, negate + entry
+	}
+	ws, pattern := SplitRepo(defaultWorkspace, entry)
+	return ws, negate + pattern
+}
@@ -0,0 +1,49 @@
+package provider
+
+import "testing"
+
+func TestSplitRepo(t *testing.T) {
+	tests := []struct {
+		repo, wantWS, wantSlug string
+	}{
+		{"api", "acme", "api"},
+		{"ops/api", "ops", "api"},
+		{"acme/platform/api", "acme/platform", "api"},
+		{"/api", "acme", "/api"},
+		{"ops/", "acme", "ops/"},
+	}
+	for _, tt := range tests {
+		ws, slug := SplitRepo("acme", tt.repo)
+		if ws != tt.wantWS || slug != tt.wantSlug {
+			t.Errorf("SplitRepo(%q) = %q, %q, want %q, %q", tt.repo, ws, slug, tt.wantWS, tt.wantSlug)
+		}
+	}
+}
+
+func TestSplitPattern(t *testing.T) {
+	tests := []struct {
+		entry, wantWS, wantPattern string
+	}{
+		{"api", "acme", "api"},
+		{"ops/api-*", "ops", "api-*"},
+		{"!ops/legacy", "ops", "!legacy"},
+		{"re:^api/v[0-9]", "acme", "re:^api/v[0-9]"},
+		{"ops/re:^infra-", "ops", "re:^infra-"},
+		{"group/sub/api", "group/sub", "api"},
+	}
+	for _, tt := range tests {
+		ws, pattern := SplitPattern("acme", tt.entry)
+		if ws != tt.wantWS || pattern != tt.wantPattern {
+			t.Errorf("SplitPattern(%q) = %q, %q, want %q, %q", tt.entry, ws, pattern, tt.wantWS, tt.wantPattern)
+		}
+	}
+}
+
+func TestQualifyRepo(t *testing.T) {
+	if got := QualifyRepo("acme", "acme", "api"); got != "api" {
+		t.Errorf("default workspace = %q, want api", got)
+	}
+	if got := QualifyRepo("acme", "ops", "api"); got != "ops/api" {
+		t.Errorf("other workspace = %q, want ops/api", got)
+	}
+}
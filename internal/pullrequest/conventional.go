@@ -0,0 +1,150 @@
+package pullrequest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// conventionalPattern matches a conventional commit subject: type(scope)!: description.
+var conventionalPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]+)\))?(!)?: (.+)$`)
+
+// conventionalCommit is a commit whose subject follows the conventional commits format.
+type conventionalCommit struct {
+	commit      bitbucket.Commit
+	kind        string // lowercased type, e.g. "feat"
+	scope       string
+	breaking    bool
+	description string
+}
+
+// conventionalSections lists the description sections in order, keyed by commit type.
+// Types not listed here (refactor, docs, ci, ...) go under Chores.
+var conventionalSections = []struct {
+	heading string
+	kind    string
+}{
+	{"Features", "feat"},
+	{"Fixes", "fix"},
+	{"Chores", ""},
+}
+
+// parseConventional parses the subject of a commit. ok is false when it does
+// not follow the conventional commits format.
+func parseConventional(c bitbucket.Commit) (conventionalCommit, bool) {
+	subject, body, _ := strings.Cut(c.Message, "\n")
+	m := conventionalPattern.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return conventionalCommit{}, false
+	}
+	return conventionalCommit{
+		commit:      c,
+		kind:        strings.ToLower(m[1]),
+		scope:       m[2],
+		breaking:    m[3] == "!" || strings.Contains(body, "BREAKING CHANGE:"),
+		description: m[4],
+	}, true
+}
+
+// parseAllConventional parses every commit, ignoring merge commits. It returns
+// nil unless all remaining commits follow the conventional commits format.
+func parseAllConventional(commits []bitbucket.Commit) []conventionalCommit {
+	var parsed []conventionalCommit
+	for _, c := range commits {
+		if strings.HasPrefix(c.Message, "Merge ") {
+			continue
+		}
+		cc, ok := parseConventional(c)
+		if !ok {
+			return nil
+		}
+		parsed = append(parsed, cc)
+	}
+	return parsed
+}
+
+// buildConventionalDescription groups conventional commits into Features,
+// Fixes and Chores sections. Empty sections are left out.
+func buildConventionalDescription(commits []conventionalCommit, opts DescriptionOptions) string {
+	var sections []string
+	for _, s := range conventionalSections {
+		var group []bitbucket.Commit
+		for _, cc := range commits {
+			if sectionKind(cc.kind) != s.kind {
+				continue
+			}
+			c := cc.commit
+			// Drop the type prefix; the section heading already says it
+			_, body, _ := strings.Cut(c.Message, "\n")
+			c.Message = cc.summary() + "\n" + body
+			group = append(group, c)
+		}
+		if len(group) > 0 {
+			sections = append(sections, fmt.Sprintf("### %s\n\n%s", s.heading, strings.Join(commitLines(group, opts), "\n")))
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// summary renders a commit's description with its scope and breaking marker.
+func (cc conventionalCommit) summary() string {
+	s := cc.description
+	if cc.scope != "" {
+		s = fmt.Sprintf("**%s:** %s", cc.scope, s)
+	}
+	if cc.breaking {
+		s += " (breaking)"
+	}
+	return s
+}
+
+// sectionKind maps a commit type to its section key.
+func sectionKind(kind string) string {
+	if kind == "feat" || kind == "fix" {
+		return kind
+	}
+	return ""
+}
+
+// conventionalTitle derives a conventional PR title. A single commit keeps its
+// own subject; otherwise the most significant type (feat, then fix, then the
+// first commit's type) is combined with the shared scope, if any, and the
+// branch name without its prefix directory.
+// Example: feat + fix commits on "feature/SPT-1298-increase-api-limit" → "feat: SPT-1298 increase api limit"
+func conventionalTitle(commits []conventionalCommit, branchName string) string {
+	if len(commits) == 1 {
+		subject, _, _ := strings.Cut(commits[0].commit.Message, "\n")
+		return strings.TrimSpace(subject)
+	}
+
+	kind := commits[0].kind
+	scope := commits[0].scope
+	breaking := false
+	for _, cc := range commits {
+		switch {
+		case cc.kind == "feat":
+			kind = "feat"
+		case cc.kind == "fix" && kind != "feat":
+			kind = "fix"
+		}
+		if cc.scope != scope {
+			scope = ""
+		}
+		breaking = breaking || cc.breaking
+	}
+
+	title := kind
+	if scope != "" {
+		title += "(" + scope + ")"
+	}
+	if breaking {
+		title += "!"
+	}
+	name := branchName
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return title + ": " + formatBranchTitle(name)
+}
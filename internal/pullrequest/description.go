@@ -0,0 +1,116 @@
+package pullrequest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// DescriptionOptions controls how commits are listed in a generated PR description.
+// The zero value lists the first line of each commit.
+type DescriptionOptions struct {
+	Hashes        bool // prefix each commit with its short hash, linked to the commit
+	Authors       bool // append the author's name to each commit
+	Bodies        bool // include the full commit message below the first line
+	GroupByAuthor bool // one section per author, in order of their newest commit
+}
+
+// ParseDescriptionOptions parses a comma-separated list of options:
+// hashes, authors, bodies, by-author.
+func ParseDescriptionOptions(s string) (DescriptionOptions, error) {
+	var opts DescriptionOptions
+	for _, part := range strings.Split(s, ",") {
+		switch strings.TrimSpace(part) {
+		case "":
+		case "hashes":
+			opts.Hashes = true
+		case "authors":
+			opts.Authors = true
+		case "bodies":
+			opts.Bodies = true
+		case "by-author":
+			opts.GroupByAuthor = true
+		default:
+			return DescriptionOptions{}, fmt.Errorf("unknown description option %q (valid: hashes, authors, bodies, by-author)", strings.TrimSpace(part))
+		}
+	}
+	return opts, nil
+}
+
+// buildDescription creates a markdown unordered list from commit messages.
+// Conventional commits are grouped into Features, Fixes and Chores sections
+// unless grouping by author was asked for.
+func buildDescription(commits []bitbucket.Commit, opts DescriptionOptions) string {
+	if !opts.GroupByAuthor {
+		if conventional := parseAllConventional(commits); len(conventional) > 0 {
+			return buildConventionalDescription(conventional, opts)
+		}
+		return strings.Join(commitLines(commits, opts), "\n")
+	}
+
+	// Group by author, keeping authors in order of first appearance
+	var order []string
+	byAuthor := make(map[string][]bitbucket.Commit)
+	for _, c := range commits {
+		name := c.Author.Name()
+		if name == "" {
+			name = "Unknown author"
+		}
+		if _, seen := byAuthor[name]; !seen {
+			order = append(order, name)
+		}
+		byAuthor[name] = append(byAuthor[name], c)
+	}
+
+	// The section heading already names the author
+	opts.Authors = false
+	sections := make([]string, 0, len(order))
+	for _, name := range order {
+		sections = append(sections, fmt.Sprintf("**%s**\n\n%s", name, strings.Join(commitLines(byAuthor[name], opts), "\n")))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// FormatCommits renders commits as markdown the way PR descriptions list
+// them, for other documents such as changelogs.
+func FormatCommits(commits []bitbucket.Commit, opts DescriptionOptions) string {
+	return buildDescription(commits, opts)
+}
+
+// commitLines formats one bullet per commit.
+func commitLines(commits []bitbucket.Commit, opts DescriptionOptions) []string {
+	lines := make([]string, 0, len(commits))
+	for _, c := range commits {
+		subject, body, _ := strings.Cut(c.Message, "\n")
+
+		line := "* "
+		if opts.Hashes && c.Hash != "" {
+			line += formatHash(c) + " "
+		}
+		line += subject
+		if name := c.Author.Name(); opts.Authors && name != "" {
+			line += fmt.Sprintf(" (%s)", name)
+		}
+		lines = append(lines, line)
+
+		if body = strings.TrimSpace(body); opts.Bodies && body != "" {
+			for _, b := range strings.Split(body, "\n") {
+				lines = append(lines, strings.TrimRight("  "+b, " "))
+			}
+		}
+	}
+	return lines
+}
+
+// formatHash returns the short hash, as a markdown link when the commit has one.
+func formatHash(c bitbucket.Commit) string {
+	short := c.Hash
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	if c.Links.HTML.Href == "" {
+		return short
+	}
+	return fmt.Sprintf("[%s](%s)", short, c.Links.HTML.Href)
+}
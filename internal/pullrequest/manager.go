@@ -4,17 +4,20 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/tracing"
 )
 
-// PRManager orchestrates PR operations (merge, decline, approve, reviewers) across repos.
+// PRManager orchestrates PR operations (merge, decline, approve, comment, reviewers) across repos.
 type PRManager struct {
-	client *bitbucket.Client
+	client provider.Provider
 }
 
 // NewPRManager creates a new PR manager.
-func NewPRManager(client *bitbucket.Client) *PRManager {
+func NewPRManager(client provider.Provider) *PRManager {
 	return &PRManager{client: client}
 }
 
@@ -39,6 +42,13 @@ func (m *PRManager) ApprovePRs(workspace string, repos []string, branchName stri
 	})
 }
 
+// CommentPRs posts the same comment on PRs by branch name across repos concurrently.
+func (m *PRManager) CommentPRs(workspace string, repos []string, branchName, text string) []Result {
+	return m.forEachRepo(workspace, repos, branchName, func(ws, slug string, pr *bitbucket.PullRequest) error {
+		return m.client.CommentPR(ws, slug, pr.ID, text)
+	})
+}
+
 // AddReviewers adds reviewers to PRs by branch name across repos concurrently.
 func (m *PRManager) AddReviewers(workspace string, repos []string, branchName string, reviewers []bitbucket.PRReviewer) []Result {
 	return m.forEachRepo(workspace, repos, branchName, func(ws, slug string, pr *bitbucket.PullRequest) error {
@@ -77,11 +87,15 @@ func (m *PRManager) forEachRepo(workspace string, repos []string, branchName str
 		go func(repoSlug string) {
 			defer wg.Done()
 
+			start := time.Now()
 			result := Result{RepoSlug: repoSlug}
 
-			pr, err := m.client.FindPRByBranch(workspace, repoSlug, branchName, "OPEN")
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			pr, err := m.client.FindPRByBranch(ws, slug, branchName, "OPEN")
 			if err != nil {
-				result.Error = err.Error()
+				result.fail(err)
+				result.finish(start)
+				tracing.RecordRepo("update pull request", repoSlug, result.StartedAt, result.FinishedAt, result.Error)
 				mu.Lock()
 				results = append(results, result)
 				mu.Unlock()
@@ -91,11 +105,13 @@ func (m *PRManager) forEachRepo(workspace string, repos []string, branchName str
 			result.PRID = pr.ID
 			result.PRURL = pr.Links.HTML.Href
 
-			if err := action(workspace, repoSlug, pr); err != nil {
-				result.Error = err.Error()
+			if err := action(ws, slug, pr); err != nil {
+				result.fail(err)
 			} else {
 				result.Success = true
 			}
+			result.finish(start)
+			tracing.RecordRepo("update pull request", repoSlug, result.StartedAt, result.FinishedAt, result.Error)
 
 			mu.Lock()
 			results = append(results, result)
@@ -114,34 +130,14 @@ func (m *PRManager) forEachRepo(workspace string, repos []string, branchName str
 
 // PrintActionResults displays results for merge/decline/approve operations.
 func PrintActionResults(action string, results []Result) {
-	fmt.Println()
-	printResultLines(results, func(r Result) string {
+	printRows(results, func(r Result) string {
 		return fmt.Sprintf("%sd PR #%d", action, r.PRID)
 	})
 }
 
-// printResultLines is the shared result printer with a custom success message formatter.
-func printResultLines(results []Result, successMsg func(Result) string) {
-	green := colorGreen()
-	red := colorRed()
-	bold := colorBold()
-
-	succeeded := 0
-	failed := 0
-
-	for _, r := range results {
-		if r.Success {
-			succeeded++
-			fmt.Printf("  %s %-30s %s\n", green("✓"), r.RepoSlug, successMsg(r))
-		} else {
-			failed++
-			fmt.Printf("  %s %-30s %s\n", red("✗"), r.RepoSlug, r.Error)
-		}
-	}
-
-	fmt.Printf("\n%s %s succeeded, %s failed\n",
-		bold("Summary:"),
-		green(fmt.Sprintf("%d", succeeded)),
-		red(fmt.Sprintf("%d", failed)),
-	)
+// PrintCommentResults displays results for comment operations.
+func PrintCommentResults(results []Result) {
+	printRows(results, func(r Result) string {
+		return fmt.Sprintf("Commented on PR #%d", r.PRID)
+	})
 }
@@ -192,6 +192,43 @@ func TestApprovePRs_AllSuccess(t *testing.T) {
 	}
 }
 
+// ---------- CommentPRs ----------
+
+func TestCommentPRs_PostsCommentAndReportsMissingPR(t *testing.T) {
+	var gotPath, gotRaw string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			var body struct {
+				Content struct {
+					Raw string `json:"raw"`
+				} `json:"content"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotPath, gotRaw = r.URL.Path, body.Content.Raw
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+			return
+		}
+		if strings.Contains(r.URL.Path, "/repo-a/") {
+			json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: []bitbucket.PullRequest{{ID: 10}}})
+			return
+		}
+		json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{})
+	}))
+	defer srv.Close()
+
+	mgr := newManagerForServer(srv)
+	results := mgr.CommentPRs("ws", []string{"repo-a", "repo-b"}, "feature/x", "Code freeze starts at 5pm")
+
+	if !results[0].Success || results[1].Success {
+		t.Fatalf("results = %+v, want repo-a success and repo-b failure", results)
+	}
+	if gotPath != "/2.0/repositories/ws/repo-a/pullrequests/10/comments" || gotRaw != "Code freeze starts at 5pm" {
+		t.Errorf("comment = %q on %q", gotRaw, gotPath)
+	}
+}
+
 // ---------- AddReviewers ----------
 
 func TestAddReviewers_Success(t *testing.T) {
@@ -0,0 +1,79 @@
+package pullrequest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+// Preflight checks with read-only API calls that branchName and the resolved
+// destination exist in each repo and that no open PR exists for branchName.
+// Destinations resolve as in CreatePRs.
+func (pc *PRCreator) Preflight(workspace string, repos []string, branchName, destination string) []render.PlanItem {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		items []render.PlanItem
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			item := pc.preflightRepo(workspace, repoSlug, branchName, destination)
+
+			mu.Lock()
+			items = append(items, item)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].RepoSlug < items[j].RepoSlug
+	})
+
+	return items
+}
+
+func (pc *PRCreator) preflightRepo(workspace, repoSlug, branchName, destination string) render.PlanItem {
+	ws, slug := provider.SplitRepo(workspace, repoSlug)
+	item := render.PlanItem{RepoSlug: repoSlug}
+
+	dest, err := pc.destinationFor(workspace, repoSlug, destination)
+	if err != nil {
+		item.Warn("%v", err)
+		return item
+	}
+	item.Detail = "to " + dest
+
+	if _, err := pc.client.ResolveCommit(ws, slug, branchName); err != nil {
+		if provider.Classify(err) == provider.ErrNotFound {
+			item.Warn("branch %q not found", branchName)
+		} else {
+			item.Warn("could not check branch %q: %v", branchName, err)
+		}
+		return item
+	}
+
+	if dest == branchName {
+		item.Warn("destination is the branch itself")
+	} else if _, err := pc.client.ResolveCommit(ws, slug, dest); err != nil {
+		if provider.Classify(err) == provider.ErrNotFound {
+			item.Warn("destination %q not found", dest)
+		} else {
+			item.Warn("could not check destination %q: %v", dest, err)
+		}
+	}
+
+	// FindPRByBranch reports "none open" as an error, so only a hit is a warning
+	if pr, err := pc.client.FindPRByBranch(ws, slug, branchName, "OPEN"); err == nil && pr != nil {
+		item.Warn("open PR #%d already exists for %q", pr.ID, branchName)
+	}
+
+	return item
+}
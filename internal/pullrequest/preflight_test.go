@@ -0,0 +1,91 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// mockPreflightServer serves the refs in each repo; openPRs maps repoSlug →
+// ID of the open PR for any branch. Repos without refs do not exist.
+func mockPreflightServer(t *testing.T, refs map[string][]string, openPRs map[string]int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("preflight made a %s request to %s", r.Method, r.URL.Path)
+		}
+		// parts: [2.0, repositories, {ws}, {slug}, ...]
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		slug := parts[3]
+		w.Header().Set("Content-Type", "application/json")
+
+		notFound := func() {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "not found"}})
+		}
+		known, exists := refs[slug]
+		switch {
+		case !exists || len(parts) < 5:
+			notFound()
+		case parts[4] == "commit":
+			ref := strings.Join(parts[5:], "/")
+			for _, k := range known {
+				if k == ref {
+					json.NewEncoder(w).Encode(bitbucket.Commit{Hash: "abc1234"})
+					return
+				}
+			}
+			notFound()
+		case parts[4] == "pullrequests":
+			var page bitbucket.PaginatedPullRequests
+			if id, ok := openPRs[slug]; ok {
+				page.Values = []bitbucket.PullRequest{{ID: id}}
+			}
+			json.NewEncoder(w).Encode(page)
+		default:
+			notFound()
+		}
+	}))
+}
+
+func TestPreflight(t *testing.T) {
+	refs := map[string][]string{
+		"repo-a": {"main", "feature/x"},
+		"repo-b": {"main", "feature/x"},
+		"repo-d": {"develop", "feature/x"},
+		"repo-e": {"master", "feature/x"},
+	}
+	srv := mockPreflightServer(t, refs, map[string]int{"repo-b": 7})
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	pc.opts.Destinations = map[string]string{"repo-e": "master"}
+	items := pc.Preflight("ws", []string{"repo-e", "repo-d", "repo-c", "repo-b", "repo-a"}, "feature/x", "main")
+	if len(items) != 5 {
+		t.Fatalf("len(items) = %d, want 5", len(items))
+	}
+
+	want := map[string][]string{
+		"repo-a": nil,
+		"repo-b": {`open PR #7 already exists for "feature/x"`},
+		"repo-c": {`branch "feature/x" not found`},
+		"repo-d": {`destination "main" not found`},
+		"repo-e": nil,
+	}
+	for i, slug := range []string{"repo-a", "repo-b", "repo-c", "repo-d", "repo-e"} {
+		item := items[i]
+		if item.RepoSlug != slug {
+			t.Fatalf("items[%d].RepoSlug = %q, want %q", i, item.RepoSlug, slug)
+		}
+		if strings.Join(item.Warnings, "|") != strings.Join(want[slug], "|") {
+			t.Errorf("%s warnings = %q, want %q", slug, item.Warnings, want[slug])
+		}
+	}
+	if items[4].Detail != "to master" {
+		t.Errorf("repo-e detail = %q, want the master override", items[4].Detail)
+	}
+}
@@ -6,40 +6,146 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
-	"github.com/fatih/color"
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+	"github.com/chinhstringee/buck/internal/tracing"
 )
 
 // Result holds the outcome of a PR creation for one repo.
 type Result struct {
-	RepoSlug string
-	Success  bool
-	Error    string
-	PRURL    string
-	PRID     int
+	RepoSlug      string                 `json:"repo"`
+	Success       bool                   `json:"success"`
+	Error         string                 `json:"error,omitempty"`
+	ErrorCategory provider.ErrorCategory `json:"error_category,omitempty"`
+	StatusCode    int                    `json:"status_code,omitempty"` // HTTP status of the failed request
+	PRURL         string                 `json:"url,omitempty"`
+	PRID          int                    `json:"id,omitempty"`
+	Warnings      []string               `json:"warnings,omitempty"` // non-fatal problems after the PR was created
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    time.Time              `json:"finished_at"`
+	Duration      time.Duration          `json:"duration_ns"`
+}
+
+// fail records err on the result.
+func (r *Result) fail(err error) {
+	r.Success = false
+	r.Error = err.Error()
+	r.ErrorCategory = provider.Classify(err)
+	r.StatusCode = bitbucket.StatusCode(err)
+}
+
+// finish stamps the result with its start time and the time elapsed since.
+func (r *Result) finish(start time.Time) {
+	r.StartedAt = start
+	r.FinishedAt = time.Now()
+	r.Duration = r.FinishedAt.Sub(start)
+}
+
+// Options configures how PRCreator builds pull requests.
+type Options struct {
+	Description  DescriptionOptions // how the branch's commits are listed
+	Tasks        []string           // checklist items added to every PR
+	Destinations map[string]string  // per-repo destination branches, keyed by lowercased repo slug
+}
+
+// ParseDestinations parses a --destination value: a default branch and/or
+// repo:branch overrides, comma-separated (e.g. "develop,legacy-app:master").
+// The default is empty when only overrides are given.
+func ParseDestinations(s string) (string, map[string]string, error) {
+	var def string
+	overrides := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		repo, branch, ok := strings.Cut(part, ":")
+		if !ok {
+			if def != "" && def != part {
+				return "", nil, fmt.Errorf("more than one default destination: %q and %q (use repo:branch for per-repo destinations)", def, part)
+			}
+			def = part
+			continue
+		}
+		repo, branch = strings.TrimSpace(repo), strings.TrimSpace(branch)
+		if repo == "" || branch == "" {
+			return "", nil, fmt.Errorf("invalid destination %q: expected repo:branch", part)
+		}
+		overrides[repo] = branch
+	}
+	return def, overrides, nil
 }
 
 // PRCreator orchestrates parallel pull request creation across repos.
 type PRCreator struct {
-	client *bitbucket.Client
+	client provider.Provider
+	opts   Options
+
+	mu    sync.Mutex
+	repos map[string]*repoLookup // GetRepository responses by "workspace/slug"
 }
 
-const defaultDestinationBranch = "master"
+// repoLookup is one memoized GetRepository call; once makes concurrent
+// callers for the same repo share a single request.
+type repoLookup struct {
+	once sync.Once
+	repo *bitbucket.Repository
+	err  error
+}
 
 // NewPRCreator creates a new PR orchestrator.
-func NewPRCreator(client *bitbucket.Client) *PRCreator {
-	return &PRCreator{client: client}
+func NewPRCreator(client provider.Provider, opts Options) *PRCreator {
+	return &PRCreator{client: client, opts: opts, repos: make(map[string]*repoLookup)}
+}
+
+// repository returns the repository, fetching it at most once per PRCreator
+// so steps of the same run (drafts, preflight, creation) share the lookup.
+func (pc *PRCreator) repository(workspace, repoSlug string) (*bitbucket.Repository, error) {
+	key := strings.ToLower(workspace + "/" + repoSlug)
+	pc.mu.Lock()
+	lookup, ok := pc.repos[key]
+	if !ok {
+		lookup = &repoLookup{}
+		pc.repos[key] = lookup
+	}
+	pc.mu.Unlock()
+
+	lookup.once.Do(func() {
+		lookup.repo, lookup.err = pc.client.GetRepository(workspace, repoSlug)
+	})
+	return lookup.repo, lookup.err
+}
+
+// Draft holds the computed fields of a pull request for one repo, before it is created.
+type Draft struct {
+	RepoSlug    string
+	Title       string
+	Description string
+	Destination string
+	Err         error // the destination could not be determined; no PR is created unless one is set
 }
 
 // CreatePRs creates pull requests in multiple repos concurrently.
-// If destination is empty, "master" is used.
+// A repo listed in Options.Destinations uses that branch; otherwise destination
+// is used, or the repo's development branch if it is empty.
 func (pc *PRCreator) CreatePRs(workspace string, repos []string, branchName, destination string) []Result {
+	return pc.forEachRepo(repos, func(repoSlug string) Result {
+		draft := pc.buildDraft(workspace, repoSlug, branchName, destination)
+		return pc.createFromDraft(workspace, branchName, draft)
+	})
+}
+
+// PrepareDrafts computes title, description and destination for each repo concurrently
+// without creating anything, so the caller can review or edit them first.
+func (pc *PRCreator) PrepareDrafts(workspace string, repos []string, branchName, destination string) []Draft {
 	var (
-		wg      sync.WaitGroup
-		mu      sync.Mutex
-		results []Result
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		drafts []Draft
 	)
 
 	for _, repo := range repos {
@@ -47,35 +153,193 @@ func (pc *PRCreator) CreatePRs(workspace string, repos []string, branchName, des
 		go func(repoSlug string) {
 			defer wg.Done()
 
-			dest := strings.TrimSpace(destination)
-			if dest == "" {
-				dest = defaultDestinationBranch
-			}
+			draft := pc.buildDraft(workspace, repoSlug, branchName, destination)
 
-			// Build description from commits (fallback to static text on error)
-			description := "Automated PR created by buck"
-			commits, err := pc.client.ListCommits(workspace, repoSlug, branchName, dest)
-			if err == nil && len(commits) > 0 {
-				description = buildDescription(commits)
-			}
+			mu.Lock()
+			drafts = append(drafts, draft)
+			mu.Unlock()
+		}(repo)
+	}
 
-			req := bitbucket.CreatePullRequestRequest{
-				Title:       formatBranchTitle(branchName),
-				Description: description,
-				Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: branchName}},
-				Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: dest}},
-			}
+	wg.Wait()
 
-			pr, err := pc.client.CreatePullRequest(workspace, repoSlug, req)
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].RepoSlug < drafts[j].RepoSlug
+	})
 
-			result := Result{RepoSlug: repoSlug}
-			if err != nil {
-				result.Error = err.Error()
-			} else {
-				result.Success = true
-				result.PRURL = pr.Links.HTML.Href
-				result.PRID = pr.ID
-			}
+	return drafts
+}
+
+// CreateFromDrafts creates one pull request per draft concurrently.
+func (pc *PRCreator) CreateFromDrafts(workspace, branchName string, drafts []Draft) []Result {
+	byRepo := make(map[string]Draft, len(drafts))
+	repos := make([]string, 0, len(drafts))
+	for _, d := range drafts {
+		byRepo[d.RepoSlug] = d
+		repos = append(repos, d.RepoSlug)
+	}
+
+	return pc.forEachRepo(repos, func(repoSlug string) Result {
+		return pc.createFromDraft(workspace, branchName, byRepo[repoSlug])
+	})
+}
+
+// buildDraft computes the PR fields for one repo.
+func (pc *PRCreator) buildDraft(workspace, repoSlug, branchName, destination string) Draft {
+	ws, slug := provider.SplitRepo(workspace, repoSlug)
+	dest, err := pc.destinationFor(workspace, repoSlug, destination)
+	if err != nil {
+		return Draft{RepoSlug: repoSlug, Title: formatBranchTitle(branchName), Err: err}
+	}
+
+	// Build description from commits (fallback to static text on error)
+	title := formatBranchTitle(branchName)
+	description := "Automated PR created by buck"
+	commits, err := pc.client.ListCommits(ws, slug, branchName, dest)
+	if err == nil && len(commits) > 0 {
+		description = buildDescription(commits, pc.opts.Description)
+		if conventional := parseAllConventional(commits); len(conventional) > 0 {
+			title = conventionalTitle(conventional, branchName)
+		}
+	}
+
+	// Without a task API the checklist goes into the description
+	if _, ok := pc.client.(provider.PRTaskService); !ok && len(pc.opts.Tasks) > 0 {
+		description += "\n\n" + checklist(pc.opts.Tasks)
+	}
+
+	return Draft{
+		RepoSlug:    repoSlug,
+		Title:       title,
+		Description: description,
+		Destination: dest,
+	}
+}
+
+// DestinationFor returns the branch a PR in repoSlug would target; see
+// CreatePRs for how an empty destination is resolved.
+func (pc *PRCreator) DestinationFor(workspace, repoSlug, destination string) (string, error) {
+	return pc.destinationFor(workspace, repoSlug, destination)
+}
+
+// destinationFor returns the branch a PR in repoSlug targets: its override,
+// else destination, else the repo's development branch. An override may be
+// keyed by the entry as given or, for a "workspace/repo" entry, by the bare slug.
+func (pc *PRCreator) destinationFor(workspace, repoSlug, destination string) (string, error) {
+	ws, slug := provider.SplitRepo(workspace, repoSlug)
+	if override := pc.opts.Destinations[strings.ToLower(repoSlug)]; override != "" {
+		return override, nil
+	}
+	if override := pc.opts.Destinations[strings.ToLower(slug)]; override != "" {
+		return override, nil
+	}
+	if dest := strings.TrimSpace(destination); dest != "" {
+		return dest, nil
+	}
+	return provider.DevelopmentBranchWith(pc.client, ws, slug, pc.repository)
+}
+
+// createFromDraft posts a single pull request built from a draft.
+func (pc *PRCreator) createFromDraft(workspace, branchName string, draft Draft) Result {
+	if draft.Err != nil && draft.Destination == "" {
+		result := Result{RepoSlug: draft.RepoSlug}
+		result.fail(draft.Err)
+		return result
+	}
+	req := bitbucket.CreatePullRequestRequest{
+		Title:       draft.Title,
+		Description: draft.Description,
+		Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: branchName}},
+		Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: draft.Destination}},
+	}
+
+	ws, slug := provider.SplitRepo(workspace, draft.RepoSlug)
+	pr, err := pc.createPR(ws, slug, req)
+
+	result := Result{RepoSlug: draft.RepoSlug}
+	if err != nil {
+		result.fail(err)
+	} else {
+		result.Success = true
+		result.PRURL = pr.Links.HTML.Href
+		result.PRID = pr.ID
+		result.Warnings = pc.addTasks(ws, slug, pr.ID)
+	}
+	return result
+}
+
+// createPR sends the creation request. After a transient failure the request
+// may still have gone through, so it looks for the PR before sending it once
+// more, and looks again if that attempt reports a conflict.
+func (pc *PRCreator) createPR(workspace, repoSlug string, req bitbucket.CreatePullRequestRequest) (*bitbucket.PullRequest, error) {
+	pr, err := pc.client.CreatePullRequest(workspace, repoSlug, req)
+	if err == nil || !provider.Transient(err) {
+		return pr, err
+	}
+	if existing := pc.findCreated(workspace, repoSlug, req); existing != nil {
+		return existing, nil
+	}
+	pr, err = pc.client.CreatePullRequest(workspace, repoSlug, req)
+	if provider.Classify(err) == provider.ErrConflict {
+		if existing := pc.findCreated(workspace, repoSlug, req); existing != nil {
+			return existing, nil
+		}
+	}
+	return pr, err
+}
+
+// findCreated returns the open PR matching req, as an earlier attempt would
+// have created it, or nil.
+func (pc *PRCreator) findCreated(workspace, repoSlug string, req bitbucket.CreatePullRequestRequest) *bitbucket.PullRequest {
+	pr, err := pc.client.FindPRByBranch(workspace, repoSlug, req.Source.Branch.Name, "OPEN")
+	if err != nil || pr == nil || pr.Title != req.Title || pr.Destination.Branch.Name != req.Destination.Branch.Name {
+		return nil
+	}
+	return pr
+}
+
+// addTasks creates the configured tasks on a PR when the backend supports them.
+// Failures are returned as warnings since the PR itself was created.
+func (pc *PRCreator) addTasks(workspace, repoSlug string, prID int) []string {
+	service, ok := pc.client.(provider.PRTaskService)
+	if !ok {
+		return nil
+	}
+	var warnings []string
+	for _, task := range pc.opts.Tasks {
+		if _, err := service.CreatePRTask(workspace, repoSlug, prID, task); err != nil {
+			warnings = append(warnings, fmt.Sprintf("task %q not added: %v", task, err))
+		}
+	}
+	return warnings
+}
+
+// checklist renders tasks as a markdown task list.
+func checklist(tasks []string) string {
+	lines := make([]string, len(tasks))
+	for i, task := range tasks {
+		lines[i] = "- [ ] " + task
+	}
+	return strings.Join(lines, "\n")
+}
+
+// forEachRepo runs fn for every repo concurrently and returns results sorted by slug.
+func (pc *PRCreator) forEachRepo(repos []string, fn func(repoSlug string) Result) []Result {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []Result
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			start := time.Now()
+			result := fn(repoSlug)
+			result.finish(start)
+			tracing.RecordRepo("create pull request", repoSlug, result.StartedAt, result.FinishedAt, result.Error)
 
 			mu.Lock()
 			results = append(results, result)
@@ -92,42 +356,47 @@ func (pc *PRCreator) CreatePRs(workspace string, repos []string, branchName, des
 	return results
 }
 
-// PrintResults displays a colored summary of PR creation results.
-func PrintResults(results []Result) {
-	green := colorGreen()
-	red := colorRed()
-	bold := colorBold()
+// URLs returns the links of the PRs in results that succeeded, in order.
+func URLs(results []Result) []string {
+	var urls []string
+	for _, r := range results {
+		if r.Success && r.PRURL != "" {
+			urls = append(urls, r.PRURL)
+		}
+	}
+	return urls
+}
 
-	succeeded := 0
-	failed := 0
+// PrintResults displays a summary of PR creation results in the current output mode.
+func PrintResults(results []Result) {
+	printRows(results, func(r Result) string { return fmt.Sprintf("PR #%d", r.PRID) })
+}
 
-	fmt.Println()
-	for _, r := range results {
-		if r.Success {
-			succeeded++
-			fmt.Printf("  %s %-30s %s\n", green("✓"), r.RepoSlug, r.PRURL)
-		} else {
-			failed++
-			// Indent multiline errors (e.g. permission scope details)
-			lines := strings.Split(r.Error, "\n")
-			fmt.Printf("  %s %-30s %s\n", red("✗"), r.RepoSlug, lines[0])
-			for _, line := range lines[1:] {
-				fmt.Printf("    %-30s %s\n", "", line)
-			}
+// printRows renders results with successMsg describing each successful repo.
+func printRows(results []Result, successMsg func(Result) string) {
+	rows := make([]render.Row, len(results))
+	for i, r := range results {
+		rows[i] = render.Row{Repo: r.RepoSlug, Message: successMsg(r), Link: r.PRURL, Notes: WarningNotes(r)}
+		if !r.Success {
+			rows[i] = FailedRow(r)
 		}
 	}
+	render.Print(render.Report{Rows: rows, Data: results, LinkBlock: true})
+}
 
-	fmt.Printf("\n%s %s succeeded, %s failed\n",
-		bold("Summary:"),
-		green(fmt.Sprintf("%d", succeeded)),
-		red(fmt.Sprintf("%d", failed)),
-	)
+// FailedRow renders a failed result.
+func FailedRow(r Result) render.Row {
+	return render.Row{Repo: r.RepoSlug, Status: render.Failed, Message: r.Error, Category: r.ErrorCategory}
 }
 
-// Shared color helpers.
-func colorGreen() func(a ...interface{}) string { return color.New(color.FgGreen).SprintFunc() }
-func colorRed() func(a ...interface{}) string   { return color.New(color.FgRed).SprintFunc() }
-func colorBold() func(a ...interface{}) string   { return color.New(color.Bold).SprintFunc() }
+// WarningNotes formats a result's warnings as notes under its row.
+func WarningNotes(r Result) []string {
+	notes := make([]string, len(r.Warnings))
+	for i, w := range r.Warnings {
+		notes[i] = "warning: " + w
+	}
+	return notes
+}
 
 // ticketPattern matches JIRA-style ticket numbers like SPT-1298, PROJ-42.
 var ticketPattern = regexp.MustCompile(`([A-Z]+)-(\d+)`)
@@ -148,13 +417,3 @@ func formatBranchTitle(branchName string) string {
 	}
 	return string(runes)
 }
-
-// buildDescription creates a markdown unordered list from commit messages.
-func buildDescription(commits []bitbucket.Commit) string {
-	lines := make([]string, 0, len(commits))
-	for _, c := range commits {
-		msg := strings.SplitN(c.Message, "\n", 2)[0] // first line only
-		lines = append(lines, fmt.Sprintf("* %s", msg))
-	}
-	return strings.Join(lines, "\n")
-}
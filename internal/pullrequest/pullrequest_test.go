@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 
 	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
 )
 
 // mockPRServer builds an httptest.Server that handles:
@@ -100,7 +103,7 @@ func newPRCreatorForServer(srv *httptest.Server) *PRCreator {
 	httpClient := &http.Client{Transport: transport}
 	authApplier := bitbucket.BearerAuth(func() (string, error) { return "test-token", nil })
 	client := bitbucket.NewClientWithHTTPClient(httpClient, authApplier)
-	return NewPRCreator(client)
+	return NewPRCreator(client, Options{})
 }
 
 // ---------- CreatePRs ----------
@@ -289,9 +292,64 @@ func TestCreatePRs_Concurrency(t *testing.T) {
 	if len(results) != 20 {
 		t.Errorf("len(results) = %d, want 20", len(results))
 	}
-	// Each repo makes 2 requests: GET commits + POST PR = 40 total
-	if int(requestCount.Load()) != 40 {
-		t.Errorf("HTTP request count = %d, want 40", requestCount.Load())
+	// Each repo makes 4 requests: branching model + repository (destination),
+	// GET commits and POST PR = 80 total
+	if int(requestCount.Load()) != 80 {
+		t.Errorf("HTTP request count = %d, want 80", requestCount.Load())
+	}
+}
+
+func TestCreatePRs_BranchingModelSkipsRepositoryLookup(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	var destinations []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		kind := "repository"
+		if len(parts) >= 5 {
+			kind = parts[4]
+		}
+		mu.Lock()
+		requests[r.Method+" "+kind]++
+		mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost:
+			var req bitbucket.CreatePullRequestRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			destinations = append(destinations, req.Destination.Branch.Name)
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 1})
+		case kind == "effective-branching-model":
+			json.NewEncoder(w).Encode(bitbucket.BranchingModel{
+				Development: bitbucket.BranchingModelBranch{Branch: &bitbucket.BranchRef{Name: "develop"}},
+			})
+		case kind == "commits":
+			json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{
+				Values: []bitbucket.Commit{{Hash: "abc123", Message: "test commit"}},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	pc.CreatePRs("ws", []string{"api", "web", "worker"}, "feature/x", "")
+
+	want := map[string]int{"GET effective-branching-model": 3, "GET commits": 3, "POST pullrequests": 3}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	for _, d := range destinations {
+		if d != "develop" {
+			t.Errorf("destination = %q, want develop", d)
+		}
 	}
 }
 
@@ -339,7 +397,7 @@ func TestCreatePRs_DestinationOverride(t *testing.T) {
 	}
 }
 
-func TestCreatePRs_DefaultDestinationMaster(t *testing.T) {
+func TestCreatePRs_DefaultDestinationFromBranchingModel(t *testing.T) {
 	var getRepoCalled atomic.Int64
 	var gotBody bitbucket.CreatePullRequestRequest
 
@@ -352,11 +410,14 @@ func TestCreatePRs_DefaultDestinationMaster(t *testing.T) {
 				json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{})
 				return
 			}
+			if len(parts) >= 5 && parts[4] == "effective-branching-model" {
+				json.NewEncoder(w).Encode(bitbucket.BranchingModel{
+					Development: bitbucket.BranchingModelBranch{Branch: &bitbucket.BranchRef{Name: "develop"}},
+				})
+				return
+			}
 			getRepoCalled.Add(1)
-			json.NewEncoder(w).Encode(bitbucket.Repository{
-				Slug:       "test",
-				MainBranch: &bitbucket.BranchRef{Name: "develop"},
-			})
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: "test", MainBranch: &bitbucket.BranchRef{Name: "main"}})
 			return
 		}
 
@@ -372,21 +433,57 @@ func TestCreatePRs_DefaultDestinationMaster(t *testing.T) {
 	pc := newPRCreatorForServer(srv)
 	results := pc.CreatePRs("ws", []string{"repo-a"}, "feature/x", "")
 
-	if len(results) != 1 {
-		t.Fatalf("len(results) = %d, want 1", len(results))
-	}
-	if !results[0].Success {
-		t.Errorf("expected success, got error: %s", results[0].Error)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
 	}
-	if gotBody.Destination.Branch.Name != "master" {
-		t.Errorf("destination = %q, want %q (default destination)", gotBody.Destination.Branch.Name, "master")
+	if gotBody.Destination.Branch.Name != "develop" {
+		t.Errorf("destination = %q, want %q (development branch)", gotBody.Destination.Branch.Name, "develop")
 	}
-	// When no destination, repository details should not be requested
+	// The branching model answers, so the repository is not requested
 	if getRepoCalled.Load() != 0 {
 		t.Errorf("GetRepository called %d times, want 0", getRepoCalled.Load())
 	}
 }
 
+func TestCreatePRs_DefaultDestinationFallsBackToMainBranch(t *testing.T) {
+	var gotBody bitbucket.CreatePullRequestRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+		if r.Method == http.MethodGet {
+			switch {
+			case len(parts) >= 5 && parts[4] == "commits":
+				json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{})
+			case len(parts) >= 5:
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				json.NewEncoder(w).Encode(bitbucket.Repository{Slug: "test", MainBranch: &bitbucket.BranchRef{Name: "main"}})
+			}
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(bitbucket.PullRequest{
+			ID:    1,
+			Links: bitbucket.PRLinks{HTML: bitbucket.LinkRef{Href: "https://bb.org/pr/1"}},
+		})
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	results := pc.CreatePRs("ws", []string{"repo-a"}, "feature/x", "")
+
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if gotBody.Destination.Branch.Name != "main" {
+		t.Errorf("destination = %q, want %q (main branch fallback)", gotBody.Destination.Branch.Name, "main")
+	}
+}
+
 func TestCreatePRs_EmptyDestinationWhitespaceUsesMaster(t *testing.T) {
 	var gotBody bitbucket.CreatePullRequestRequest
 
@@ -399,6 +496,11 @@ func TestCreatePRs_EmptyDestinationWhitespaceUsesMaster(t *testing.T) {
 				json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{})
 				return
 			}
+			if len(parts) == 4 {
+				// A repo without a main branch set
+				json.NewEncoder(w).Encode(bitbucket.Repository{Slug: parts[3]})
+				return
+			}
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
@@ -426,6 +528,198 @@ func TestCreatePRs_EmptyDestinationWhitespaceUsesMaster(t *testing.T) {
 	}
 }
 
+func TestCreatePRs_UnreadableRepoDoesNotGuessDestination(t *testing.T) {
+	created := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			created = true
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	results := newPRCreatorForServer(srv).CreatePRs("ws", []string{"test-repo"}, "feature/x", "")
+
+	if len(results) != 1 || results[0].Success {
+		t.Fatalf("results = %+v, want one failure", results)
+	}
+	if !strings.Contains(results[0].Error, "development branch") {
+		t.Errorf("error = %q, want it to say the development branch is unknown", results[0].Error)
+	}
+	if created {
+		t.Error("a PR was created into a guessed branch")
+	}
+}
+
+func TestCreatePRs_PerRepoDestinations(t *testing.T) {
+	var mu sync.Mutex
+	gotDest := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			var body bitbucket.CreatePullRequestRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			gotDest[strings.Split(r.URL.Path, "/")[4]] = body.Destination.Branch.Name
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 1})
+			return
+		}
+		json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{})
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	pc.opts.Destinations = map[string]string{"legacy-app": "master"}
+	pc.CreatePRs("ws", []string{"api", "Legacy-App"}, "feature/x", "develop")
+
+	if gotDest["api"] != "develop" || gotDest["Legacy-App"] != "master" {
+		t.Errorf("destinations = %v, want api→develop, Legacy-App→master", gotDest)
+	}
+}
+
+// ---------- ParseDestinations ----------
+
+func TestParseDestinations(t *testing.T) {
+	def, overrides, err := ParseDestinations("develop, legacy-app:master,web:release/1.x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def != "develop" || len(overrides) != 2 || overrides["legacy-app"] != "master" || overrides["web"] != "release/1.x" {
+		t.Errorf("ParseDestinations() = %q, %v", def, overrides)
+	}
+
+	def, overrides, err = ParseDestinations("legacy-app:master")
+	if err != nil || def != "" || overrides["legacy-app"] != "master" {
+		t.Errorf("overrides only = %q, %v, %v", def, overrides, err)
+	}
+
+	for _, bad := range []string{"develop,main", "legacy-app:", ":master"} {
+		if _, _, err := ParseDestinations(bad); err == nil {
+			t.Errorf("ParseDestinations(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+// ---------- PrepareDrafts / CreateFromDrafts ----------
+
+func TestPrepareDrafts_ComputesFields(t *testing.T) {
+	srv := mockPRServer(t, nil, nil, nil)
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	drafts := pc.PrepareDrafts("ws", []string{"repo-b", "repo-a"}, "feature/SPT-1-add-login", "develop")
+
+	if len(drafts) != 2 {
+		t.Fatalf("len(drafts) = %d, want 2", len(drafts))
+	}
+	if drafts[0].RepoSlug != "repo-a" || drafts[1].RepoSlug != "repo-b" {
+		t.Errorf("drafts not sorted: %q, %q", drafts[0].RepoSlug, drafts[1].RepoSlug)
+	}
+	for _, d := range drafts {
+		if d.Title != "Feature/SPT-1 add login" {
+			t.Errorf("Title = %q", d.Title)
+		}
+		if d.Destination != "develop" {
+			t.Errorf("Destination = %q, want develop", d.Destination)
+		}
+		if !strings.Contains(d.Description, "* add new feature") {
+			t.Errorf("Description = %q, want commit list", d.Description)
+		}
+	}
+}
+
+func TestCreateFromDrafts_UsesEditedFields(t *testing.T) {
+	var gotBody bitbucket.CreatePullRequestRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 7})
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	drafts := []Draft{{RepoSlug: "repo-a", Title: "Edited", Description: "custom", Destination: "release"}}
+	results := pc.CreateFromDrafts("ws", "feature/x", drafts)
+
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v, want one success", results)
+	}
+	if gotBody.Title != "Edited" || gotBody.Description != "custom" {
+		t.Errorf("body title/description = %q/%q", gotBody.Title, gotBody.Description)
+	}
+	if gotBody.Destination.Branch.Name != "release" {
+		t.Errorf("destination = %q, want release", gotBody.Destination.Branch.Name)
+	}
+	if gotBody.Source.Branch.Name != "feature/x" {
+		t.Errorf("source = %q, want feature/x", gotBody.Source.Branch.Name)
+	}
+}
+
+func TestCreateFromDrafts_AddsTasks(t *testing.T) {
+	var tasks []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/pullrequests/7/tasks") {
+			var task bitbucket.PRTask
+			json.NewDecoder(r.Body).Decode(&task)
+			if task.Content.Raw == "notify QA" {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "forbidden"}})
+				return
+			}
+			tasks = append(tasks, task.Content.Raw)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(task)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 7})
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	pc.opts.Tasks = []string{"update changelog", "notify QA"}
+	results := pc.CreateFromDrafts("ws", "feature/x", []Draft{{RepoSlug: "repo-a", Title: "T", Destination: "master"}})
+
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v, want one success", results)
+	}
+	if len(tasks) != 1 || tasks[0] != "update changelog" {
+		t.Errorf("tasks = %v, want [update changelog]", tasks)
+	}
+	if len(results[0].Warnings) != 1 || !strings.Contains(results[0].Warnings[0], "notify QA") {
+		t.Errorf("Warnings = %v, want one for the failed task", results[0].Warnings)
+	}
+}
+
+// noTaskProvider hides the task API so the checklist fallback is used.
+type noTaskProvider struct{ provider.Provider }
+
+func TestPrepareDrafts_ChecklistWithoutTaskAPI(t *testing.T) {
+	srv := mockPRServer(t, nil, nil, nil)
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	pc.client = noTaskProvider{pc.client}
+	pc.opts.Tasks = []string{"update changelog", "notify QA"}
+	drafts := pc.PrepareDrafts("ws", []string{"repo-a"}, "feature/x", "develop")
+
+	want := "\n\n- [ ] update changelog\n- [ ] notify QA"
+	if len(drafts) != 1 || !strings.HasSuffix(drafts[0].Description, want) {
+		t.Errorf("drafts = %+v, want description ending in checklist", drafts)
+	}
+}
+
+func TestCreateFromDrafts_Empty(t *testing.T) {
+	pc := NewPRCreator(bitbucket.NewClient(nil), Options{})
+	if results := pc.CreateFromDrafts("ws", "feature/x", nil); len(results) != 0 {
+		t.Errorf("len(results) = %d, want 0", len(results))
+	}
+}
+
 // ---------- formatBranchTitle ----------
 
 func TestFormatBranchTitle(t *testing.T) {
@@ -458,7 +752,7 @@ func TestBuildDescription(t *testing.T) {
 		{Hash: "def5678901234", Message: "fix bug in handler"},
 	}
 
-	got := buildDescription(commits)
+	got := buildDescription(commits, DescriptionOptions{})
 	want := "* add new feature\n* fix bug in handler"
 	if got != want {
 		t.Errorf("buildDescription() = %q, want %q", got, want)
@@ -466,17 +760,257 @@ func TestBuildDescription(t *testing.T) {
 }
 
 func TestBuildDescription_Empty(t *testing.T) {
-	got := buildDescription(nil)
+	got := buildDescription(nil, DescriptionOptions{})
 	if got != "" {
 		t.Errorf("buildDescription(nil) = %q, want empty string", got)
 	}
 }
 
+func TestBuildDescription_HashesAuthorsBodies(t *testing.T) {
+	commits := []bitbucket.Commit{
+		{
+			Hash:    "abc1234567890",
+			Message: "add new feature\n\ndetailed body\nsecond line",
+			Author:  bitbucket.CommitAuthor{Raw: "Jane Doe <jane@example.com>"},
+			Links:   bitbucket.CommitLinks{HTML: bitbucket.LinkRef{Href: "https://example.com/c/abc1234567890"}},
+		},
+		{Hash: "def5678901234", Message: "fix bug", Author: bitbucket.CommitAuthor{User: &bitbucket.User{DisplayName: "John"}}},
+	}
+
+	got := buildDescription(commits, DescriptionOptions{Hashes: true, Authors: true, Bodies: true})
+	want := "* [abc1234](https://example.com/c/abc1234567890) add new feature (Jane Doe)\n" +
+		"  detailed body\n" +
+		"  second line\n" +
+		"* def5678 fix bug (John)"
+	if got != want {
+		t.Errorf("buildDescription() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildDescription_GroupByAuthor(t *testing.T) {
+	commits := []bitbucket.Commit{
+		{Message: "one", Author: bitbucket.CommitAuthor{Raw: "Jane <j@x>"}},
+		{Message: "two", Author: bitbucket.CommitAuthor{Raw: "John <o@x>"}},
+		{Message: "three", Author: bitbucket.CommitAuthor{Raw: "Jane <j@x>"}},
+		{Message: "four"},
+	}
+
+	got := buildDescription(commits, DescriptionOptions{GroupByAuthor: true, Authors: true})
+	want := "**Jane**\n\n* one\n* three\n\n**John**\n\n* two\n\n**Unknown author**\n\n* four"
+	if got != want {
+		t.Errorf("buildDescription() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildDescription_ConventionalSections(t *testing.T) {
+	commits := []bitbucket.Commit{
+		{Message: "fix(api): handle empty body"},
+		{Message: "Merge branch 'master' into feature/x"},
+		{Message: "feat!: drop v1 endpoints"},
+		{Message: "docs: update readme"},
+		{Message: "feat(web): add dark mode"},
+	}
+
+	got := buildDescription(commits, DescriptionOptions{})
+	want := "### Features\n\n* drop v1 endpoints (breaking)\n* **web:** add dark mode\n\n" +
+		"### Fixes\n\n* **api:** handle empty body\n\n" +
+		"### Chores\n\n* update readme"
+	if got != want {
+		t.Errorf("buildDescription() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildDescription_MixedCommitsStayFlat(t *testing.T) {
+	commits := []bitbucket.Commit{
+		{Message: "feat: add thing"},
+		{Message: "tweak something"},
+	}
+
+	got := buildDescription(commits, DescriptionOptions{})
+	want := "* feat: add thing\n* tweak something"
+	if got != want {
+		t.Errorf("buildDescription() = %q, want %q", got, want)
+	}
+}
+
+func TestConventionalTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     string
+	}{
+		{"single commit keeps subject", []string{"fix(api): handle empty body\n\nbody"}, "fix(api): handle empty body"},
+		{"feat wins over fix", []string{"fix: a", "feat: b", "chore: c"}, "feat: SPT-1298 increase api limit"},
+		{"fix wins over chores", []string{"chore: a", "fix: b"}, "fix: SPT-1298 increase api limit"},
+		{"shared scope kept", []string{"feat(api): a", "fix(api): b"}, "feat(api): SPT-1298 increase api limit"},
+		{"different scopes dropped", []string{"feat(api): a", "fix(web): b"}, "feat: SPT-1298 increase api limit"},
+		{"breaking marked", []string{"refactor!: a", "test: b"}, "refactor!: SPT-1298 increase api limit"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var commits []bitbucket.Commit
+			for _, m := range tc.messages {
+				commits = append(commits, bitbucket.Commit{Message: m})
+			}
+			got := conventionalTitle(parseAllConventional(commits), "feature/SPT-1298-increase-api-limit")
+			if got != tc.want {
+				t.Errorf("conventionalTitle() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseDescriptionOptions(t *testing.T) {
+	got, err := ParseDescriptionOptions("hashes, by-author,bodies")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := DescriptionOptions{Hashes: true, Bodies: true, GroupByAuthor: true}
+	if got != want {
+		t.Errorf("ParseDescriptionOptions() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseDescriptionOptions("hashes,emoji"); err == nil {
+		t.Error("expected error for unknown option")
+	}
+}
+
 // ---------- NewPRCreator ----------
 
 func TestNewPRCreator_NotNil(t *testing.T) {
-	pc := NewPRCreator(nil)
+	pc := NewPRCreator(nil, Options{})
 	if pc == nil {
 		t.Fatal("NewPRCreator returned nil")
 	}
 }
+
+func TestPRCreator_GetRepositoryOncePerRepo(t *testing.T) {
+	var repoGets atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 1})
+		case len(parts) == 4:
+			repoGets.Add(1)
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: parts[3], MainBranch: &bitbucket.BranchRef{Name: "main"}})
+		case len(parts) >= 5 && parts[4] == "commits":
+			json.NewEncoder(w).Encode(bitbucket.PaginatedCommits{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	repos := []string{"api", "web", "worker"}
+	// Drafts for review, then the PRs, as `buck pr --review` does
+	pc.PrepareDrafts("ws", repos, "feature/x", "")
+	results := pc.CreatePRs("ws", repos, "feature/x", "")
+
+	for _, r := range results {
+		if !r.Success {
+			t.Errorf("%s failed: %s", r.RepoSlug, r.Error)
+		}
+	}
+	if got := repoGets.Load(); got != int64(len(repos)) {
+		t.Errorf("GetRepository requests = %d, want %d", got, len(repos))
+	}
+}
+
+func TestCreateFromDrafts_TransientFailureFindsCreatedPR(t *testing.T) {
+	var posts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			// The PR is created but the request times out at the gateway
+			posts.Add(1)
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		pr := bitbucket.PullRequest{ID: 12, Title: "Feature x"}
+		pr.Destination.Branch.Name = "main"
+		json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: []bitbucket.PullRequest{pr}})
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	drafts := []Draft{{RepoSlug: "api", Title: "Feature x", Destination: "main"}}
+	results := pc.CreateFromDrafts("ws", "feature/x", drafts)
+
+	if !results[0].Success || results[0].PRID != 12 {
+		t.Errorf("result = %+v, want the existing PR #12", results[0])
+	}
+	if got := posts.Load(); got != 1 {
+		t.Errorf("POST requests = %d, want 1", got)
+	}
+}
+
+func TestCreateFromDrafts_RetryConflictFindsCreatedPR(t *testing.T) {
+	var posts atomic.Int64
+	var lists atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			if posts.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "already exists"}})
+			return
+		}
+		// The first attempt's PR only shows up after the retry
+		page := bitbucket.PaginatedPullRequests{}
+		if lists.Add(1) > 1 {
+			pr := bitbucket.PullRequest{ID: 13, Title: "Feature x"}
+			pr.Destination.Branch.Name = "main"
+			page.Values = append(page.Values, pr)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	drafts := []Draft{{RepoSlug: "api", Title: "Feature x", Destination: "main"}}
+	results := pc.CreateFromDrafts("ws", "feature/x", drafts)
+
+	if !results[0].Success || results[0].PRID != 13 {
+		t.Errorf("result = %+v, want the existing PR #13", results[0])
+	}
+	if got := posts.Load(); got != 2 {
+		t.Errorf("POST requests = %d, want 2", got)
+	}
+}
+
+func TestCreateFromDrafts_TransientFailureIgnoresOtherPR(t *testing.T) {
+	var posts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			if posts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 21})
+			return
+		}
+		// An open PR from the branch into another destination is not ours
+		pr := bitbucket.PullRequest{ID: 20, Title: "Feature x"}
+		pr.Destination.Branch.Name = "release"
+		json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: []bitbucket.PullRequest{pr}})
+	}))
+	defer srv.Close()
+
+	pc := newPRCreatorForServer(srv)
+	drafts := []Draft{{RepoSlug: "api", Title: "Feature x", Destination: "main"}}
+	results := pc.CreateFromDrafts("ws", "feature/x", drafts)
+
+	if !results[0].Success || results[0].PRID != 21 {
+		t.Errorf("result = %+v, want new PR #21", results[0])
+	}
+}
@@ -0,0 +1,122 @@
+package pullrequest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// WhenGreenOptions controls MergeWhenGreen.
+type WhenGreenOptions struct {
+	Timeout      time.Duration // give up on PRs that are not mergeable by then
+	Interval     time.Duration // wait between polls
+	MinApprovals int           // approvals required in addition to passing builds
+	BuildGrace   time.Duration // how long a PR without builds waits for one to start before it counts as green
+
+	// OnUpdate, if set, is called whenever a repo's status changes
+	// (e.g. "waiting for 1 of 3 builds", "merged"). Calls are serialized.
+	OnUpdate func(repoSlug, status string)
+}
+
+// MergeWhenGreen polls each repo's PR for the branch and merges it as soon as
+// all its builds have passed and it has enough approvals without requested
+// changes. A PR with no builds counts as green only once BuildGrace has passed,
+// so CI that has not reported yet is not skipped. A failed build fails the repo
+// immediately; a PR still waiting when the timeout expires fails with its last
+// status.
+func (m *PRManager) MergeWhenGreen(workspace string, repos []string, branchName string, req bitbucket.MergePRRequest, opts WhenGreenOptions) []Result {
+	var mu sync.Mutex
+	report := func(repoSlug, status string) {
+		if opts.OnUpdate == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		opts.OnUpdate(repoSlug, status)
+	}
+
+	start := time.Now()
+	deadline := start.Add(opts.Timeout)
+	return m.forEachRepo(workspace, repos, branchName, func(ws, slug string, pr *bitbucket.PullRequest) error {
+		entry := provider.QualifyRepo(workspace, ws, slug)
+		last := ""
+		for {
+			noBuildsOK := time.Since(start) >= opts.BuildGrace
+			ready, status, err := m.checkReadiness(ws, slug, pr.ID, opts.MinApprovals, noBuildsOK)
+			if err != nil {
+				report(entry, err.Error())
+				return err
+			}
+			if status != last {
+				report(entry, status)
+				last = status
+			}
+			if ready {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out after %s: %s", opts.Timeout, status)
+			}
+			time.Sleep(opts.Interval)
+		}
+
+		if err := m.client.MergePR(ws, slug, pr.ID, req); err != nil {
+			report(entry, "merge failed")
+			return err
+		}
+		report(entry, "merged")
+		return nil
+	})
+}
+
+// checkReadiness reports whether a PR can be merged now, and why not otherwise.
+// A PR without builds is only ready when noBuildsOK is set. err is only set for
+// conditions that waiting will not fix, such as a failed build; API errors
+// while polling are reported as a status and retried.
+func (m *PRManager) checkReadiness(workspace, repoSlug string, prID, minApprovals int, noBuildsOK bool) (ready bool, status string, err error) {
+	builds, err := m.client.ListPRBuildStatuses(workspace, repoSlug, prID)
+	if err != nil {
+		return false, "could not check builds: " + err.Error(), nil
+	}
+	if len(builds) == 0 && !noBuildsOK {
+		return false, "waiting for builds to start", nil
+	}
+	running := 0
+	for _, b := range builds {
+		switch b.State {
+		case bitbucket.BuildFailed, bitbucket.BuildStopped:
+			return false, "", fmt.Errorf("build %q %s", b.Name, strings.ToLower(b.State))
+		case bitbucket.BuildInProgress:
+			running++
+		}
+	}
+	if running > 0 {
+		return false, fmt.Sprintf("waiting for %d of %d builds", running, len(builds)), nil
+	}
+
+	participants, err := m.client.ListPRParticipants(workspace, repoSlug, prID)
+	if err != nil {
+		return false, "could not check approvals: " + err.Error(), nil
+	}
+	approvals := 0
+	var blockers []string
+	for _, p := range participants {
+		switch {
+		case p.State == "changes_requested":
+			blockers = append(blockers, p.User.DisplayName)
+		case p.Approved:
+			approvals++
+		}
+	}
+	if len(blockers) > 0 {
+		return false, "changes requested by " + strings.Join(blockers, ", "), nil
+	}
+	if approvals < minApprovals {
+		return false, fmt.Sprintf("waiting for approvals (%d/%d)", approvals, minApprovals), nil
+	}
+	return true, "ready to merge", nil
+}
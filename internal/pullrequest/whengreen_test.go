@@ -0,0 +1,157 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+// mockWhenGreenServer serves one PR per repo (ID 1) whose build statuses are
+// returned from builds[slug] in turn (the last entry repeats) and whose
+// participants come from participants[slug]. Merges are recorded.
+func mockWhenGreenServer(t *testing.T, builds map[string][][]bitbucket.BuildStatus, participants map[string][]bitbucket.PRParticipant) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		polls  = make(map[string]int)
+		merged []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		// parts: [2.0, repositories, {ws}, {slug}, pullrequests, ...]
+		slug := parts[3]
+		w.Header().Set("Content-Type", "application/json")
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case len(parts) == 5 && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: []bitbucket.PullRequest{{ID: 1}}})
+		case len(parts) == 7 && parts[6] == "statuses":
+			seq := builds[slug]
+			i := polls[slug]
+			if i >= len(seq) {
+				i = len(seq) - 1
+			}
+			polls[slug]++
+			var page bitbucket.PaginatedBuildStatuses
+			if i >= 0 {
+				page.Values = seq[i]
+			}
+			json.NewEncoder(w).Encode(page)
+		case len(parts) == 6 && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 1, Participants: participants[slug]})
+		case len(parts) == 7 && parts[6] == "merge":
+			merged = append(merged, slug)
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{ID: 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), merged...)
+	}
+}
+
+func TestMergeWhenGreen(t *testing.T) {
+	running := []bitbucket.BuildStatus{{Name: "ci", State: bitbucket.BuildInProgress}}
+	passed := []bitbucket.BuildStatus{{Name: "ci", State: bitbucket.BuildSuccessful}}
+	failed := []bitbucket.BuildStatus{{Name: "ci", State: bitbucket.BuildFailed}}
+	approved := []bitbucket.PRParticipant{{User: bitbucket.PRAuthor{DisplayName: "Alice"}, Role: "REVIEWER", Approved: true}}
+
+	builds := map[string][][]bitbucket.BuildStatus{
+		"repo-a": {running, running, passed}, // goes green, approved
+		"repo-b": {running, failed},          // build fails
+		"repo-c": {passed},                   // green but never approved
+		"repo-d": {nil},                      // no builds, approved, merged after the grace period
+	}
+	participants := map[string][]bitbucket.PRParticipant{
+		"repo-a": approved,
+		"repo-d": approved,
+	}
+	srv, merged := mockWhenGreenServer(t, builds, participants)
+	defer srv.Close()
+
+	var updates []string
+	opts := WhenGreenOptions{
+		Timeout:      200 * time.Millisecond,
+		Interval:     time.Millisecond,
+		MinApprovals: 1,
+		BuildGrace:   20 * time.Millisecond,
+		OnUpdate:     func(repo, status string) { updates = append(updates, repo+": "+status) },
+	}
+	mgr := newManagerForServer(srv)
+	results := mgr.MergeWhenGreen("ws", []string{"repo-a", "repo-b", "repo-c", "repo-d"}, "feature/x", bitbucket.MergePRRequest{}, opts)
+
+	if len(results) != 4 {
+		t.Fatalf("len(results) = %d, want 4", len(results))
+	}
+	if !results[0].Success || !results[3].Success {
+		t.Errorf("repo-a/repo-d = %+v / %+v, want merged", results[0], results[3])
+	}
+	if results[1].Success || !strings.Contains(results[1].Error, `build "ci" failed`) {
+		t.Errorf("repo-b = %+v, want failed build", results[1])
+	}
+	if results[2].Success || !strings.Contains(results[2].Error, "timed out") || !strings.Contains(results[2].Error, "waiting for approvals (0/1)") {
+		t.Errorf("repo-c = %+v, want timeout waiting for approvals", results[2])
+	}
+
+	got := merged()
+	if len(got) != 2 {
+		t.Errorf("merged = %v, want repo-a and repo-d only", got)
+	}
+
+	// Status changes are reported once each, not on every poll
+	count := 0
+	for _, u := range updates {
+		if u == "repo-a: waiting for 1 of 1 builds" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("updates = %v, want one 'waiting for 1 of 1 builds' for repo-a", updates)
+	}
+	if !strings.Contains(strings.Join(updates, "\n"), "repo-d: waiting for builds to start") {
+		t.Errorf("updates = %v, want repo-d to wait for builds before merging", updates)
+	}
+}
+
+func TestCheckReadiness_NoBuildsWaitsForGrace(t *testing.T) {
+	approved := []bitbucket.PRParticipant{{User: bitbucket.PRAuthor{DisplayName: "Alice"}, Approved: true}}
+	srv, _ := mockWhenGreenServer(t, map[string][][]bitbucket.BuildStatus{"repo-a": {nil}}, map[string][]bitbucket.PRParticipant{"repo-a": approved})
+	defer srv.Close()
+	mgr := newManagerForServer(srv)
+
+	ready, status, err := mgr.checkReadiness("ws", "repo-a", 1, 1, false)
+	if err != nil || ready || status != "waiting for builds to start" {
+		t.Errorf("within grace: checkReadiness = %v, %q, %v", ready, status, err)
+	}
+	ready, _, err = mgr.checkReadiness("ws", "repo-a", 1, 1, true)
+	if err != nil || !ready {
+		t.Errorf("after grace: checkReadiness = %v, %v, want ready", ready, err)
+	}
+}
+
+func TestCheckReadiness_ChangesRequestedBlocks(t *testing.T) {
+	participants := map[string][]bitbucket.PRParticipant{
+		"repo-a": {
+			{User: bitbucket.PRAuthor{DisplayName: "Alice"}, Approved: true},
+			{User: bitbucket.PRAuthor{DisplayName: "Bob"}, State: "changes_requested"},
+		},
+	}
+	srv, _ := mockWhenGreenServer(t, map[string][][]bitbucket.BuildStatus{"repo-a": {nil}}, participants)
+	defer srv.Close()
+
+	ready, status, err := newManagerForServer(srv).checkReadiness("ws", "repo-a", 1, 1, true)
+	if err != nil || ready || status != "changes requested by Bob" {
+		t.Errorf("checkReadiness = %v, %q, %v", ready, status, err)
+	}
+}
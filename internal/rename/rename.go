@@ -0,0 +1,159 @@
+// Package rename renames a branch across repos: it creates the new branch at the
+// old head, retargets open PRs and deletes the old branch, rolling back on error.
+package rename
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+// Result holds the outcome of a rename for one repo.
+type Result struct {
+	RepoSlug   string `json:"repo"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	Retargeted []int  `json:"retargeted,omitempty"`  // IDs of PRs moved to the new branch
+	RolledBack bool   `json:"rolled_back,omitempty"` // changes were undone after a failure
+}
+
+// Renamer orchestrates branch renames across repos.
+type Renamer struct {
+	client provider.Provider
+}
+
+// NewRenamer creates a new rename orchestrator.
+func NewRenamer(client provider.Provider) *Renamer {
+	return &Renamer{client: client}
+}
+
+// Rename renames oldName to newName in each repo concurrently.
+// Results are sorted by repo slug.
+func (rn *Renamer) Rename(workspace string, repos []string, oldName, newName string) []Result {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []Result
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			ws, slug := provider.SplitRepo(workspace, repoSlug)
+			result := rn.renameOne(ws, slug, oldName, newName)
+			result.RepoSlug = repoSlug
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+
+	return results
+}
+
+func (rn *Renamer) renameOne(workspace, repoSlug, oldName, newName string) Result {
+	result := Result{RepoSlug: repoSlug}
+
+	// Open PRs from the old branch cannot be moved to another source branch,
+	// and deleting the branch would close them, so refuse before changing anything.
+	prs, err := rn.client.ListPullRequests(workspace, repoSlug, "OPEN")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var retarget []bitbucket.PullRequest
+	for _, pr := range prs {
+		if pr.Source.Branch.Name == oldName {
+			result.Error = fmt.Sprintf("open PR #%d is from %q; merge or decline it before renaming", pr.ID, oldName)
+			return result
+		}
+		if pr.Destination.Branch.Name == oldName {
+			retarget = append(retarget, pr)
+		}
+	}
+
+	if _, err := rn.client.CreateBranch(workspace, repoSlug, newName, oldName); err != nil {
+		result.Error = fmt.Sprintf("creating %q: %s", newName, err)
+		return result
+	}
+
+	var moved []int
+	for _, pr := range retarget {
+		if _, err := rn.client.UpdatePR(workspace, repoSlug, pr.ID, destinationUpdate(newName)); err != nil {
+			result.Error = fmt.Sprintf("retargeting PR #%d: %s", pr.ID, err)
+			result.RolledBack = rn.rollback(workspace, repoSlug, oldName, newName, moved, &result)
+			return result
+		}
+		moved = append(moved, pr.ID)
+	}
+
+	if err := rn.client.DeleteBranch(workspace, repoSlug, oldName); err != nil {
+		result.Error = fmt.Sprintf("deleting %q: %s", oldName, err)
+		result.RolledBack = rn.rollback(workspace, repoSlug, oldName, newName, moved, &result)
+		return result
+	}
+
+	result.Success = true
+	result.Retargeted = moved
+	return result
+}
+
+// rollback points moved PRs back at oldName and deletes newName. Failures are
+// appended to the result's error; it reports whether everything was undone.
+func (rn *Renamer) rollback(workspace, repoSlug, oldName, newName string, moved []int, result *Result) bool {
+	var failures []string
+	for _, id := range moved {
+		if _, err := rn.client.UpdatePR(workspace, repoSlug, id, destinationUpdate(oldName)); err != nil {
+			failures = append(failures, fmt.Sprintf("PR #%d still targets %q", id, newName))
+		}
+	}
+	if err := rn.client.DeleteBranch(workspace, repoSlug, newName); err != nil {
+		failures = append(failures, fmt.Sprintf("branch %q was left behind", newName))
+	}
+	if len(failures) > 0 {
+		result.Error += "\nrollback incomplete: " + strings.Join(failures, ", ")
+		return false
+	}
+	return true
+}
+
+func destinationUpdate(branchName string) bitbucket.PRUpdateRequest {
+	return bitbucket.PRUpdateRequest{
+		Destination: &bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: branchName}},
+	}
+}
+
+// PrintResults displays a summary of rename results in the current output mode.
+func PrintResults(results []Result, oldName, newName string) {
+	rows := make([]render.Row, len(results))
+	for i, r := range results {
+		if r.Success {
+			detail := fmt.Sprintf("%s → %s", oldName, newName)
+			if n := len(r.Retargeted); n > 0 {
+				detail += fmt.Sprintf(" (%d PR(s) retargeted)", n)
+			}
+			rows[i] = render.Row{Repo: r.RepoSlug, Message: detail}
+			continue
+		}
+
+		rows[i] = render.Row{Repo: r.RepoSlug, Status: render.Failed, Message: r.Error}
+		if r.RolledBack {
+			rows[i].Notes = []string{"rolled back, " + oldName + " unchanged"}
+		}
+	}
+	render.Print(render.Report{Rows: rows, Data: results})
+}
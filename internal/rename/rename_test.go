@@ -0,0 +1,136 @@
+package rename
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+type hostRewriteTransport struct {
+	base    http.RoundTripper
+	srvHost string
+}
+
+func (t *hostRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.URL.Scheme = "http"
+	cloned.URL.Host = t.srvHost
+	return t.base.RoundTrip(cloned)
+}
+
+func newRenamerForServer(srv *httptest.Server) *Renamer {
+	transport := &hostRewriteTransport{base: http.DefaultTransport, srvHost: srv.Listener.Addr().String()}
+	authApplier := bitbucket.BearerAuth(func() (string, error) { return "test-token", nil })
+	return NewRenamer(bitbucket.NewClientWithHTTPClient(&http.Client{Transport: transport}, authApplier))
+}
+
+func pr(id int, source, dest string) bitbucket.PullRequest {
+	return bitbucket.PullRequest{
+		ID:          id,
+		Source:      bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: source}},
+		Destination: bitbucket.PRBranchRef{Branch: bitbucket.PRBranchName{Name: dest}},
+	}
+}
+
+// renameServer serves open PRs and records mutating calls. failDelete makes
+// deleting the given branch fail.
+func renameServer(t *testing.T, prs []bitbucket.PullRequest, failDelete string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(bitbucket.PaginatedPullRequests{Values: prs})
+			return
+		}
+
+		call := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/2.0/repositories/ws/api")
+		if r.Method == http.MethodPut {
+			var body bitbucket.PRUpdateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			call += " → " + body.Destination.Branch.Name
+		}
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/"+failDelete):
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"type":"error","error":{"message":"Branch is protected"}}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.Branch{Name: "develop"})
+		default:
+			json.NewEncoder(w).Encode(bitbucket.PullRequest{})
+		}
+	}))
+	return srv, &calls
+}
+
+func TestRename_RetargetsPRsAndDeletesOldBranch(t *testing.T) {
+	srv, calls := renameServer(t, []bitbucket.PullRequest{pr(7, "feature/a", "dev"), pr(8, "feature/b", "main")}, "")
+	defer srv.Close()
+
+	results := newRenamerForServer(srv).Rename("ws", []string{"api"}, "dev", "develop")
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if len(results[0].Retargeted) != 1 || results[0].Retargeted[0] != 7 {
+		t.Errorf("Retargeted = %v, want [7]", results[0].Retargeted)
+	}
+
+	want := []string{
+		"POST /refs/branches",
+		"PUT /pullrequests/7 → develop",
+		"DELETE /refs/branches/dev",
+	}
+	if strings.Join(*calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %v, want %v", *calls, want)
+	}
+}
+
+func TestRename_RollsBackWhenDeleteFails(t *testing.T) {
+	srv, calls := renameServer(t, []bitbucket.PullRequest{pr(7, "feature/a", "dev")}, "dev")
+	defer srv.Close()
+
+	results := newRenamerForServer(srv).Rename("ws", []string{"api"}, "dev", "develop")
+	r := results[0]
+	if r.Success || !r.RolledBack || !strings.Contains(r.Error, "Branch is protected") {
+		t.Fatalf("result = %+v", r)
+	}
+
+	want := []string{
+		"POST /refs/branches",
+		"PUT /pullrequests/7 → develop",
+		"DELETE /refs/branches/dev",
+		"PUT /pullrequests/7 → dev",
+		"DELETE /refs/branches/develop",
+	}
+	if strings.Join(*calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %v, want %v", *calls, want)
+	}
+}
+
+func TestRename_RefusesWithOpenPRFromOldBranch(t *testing.T) {
+	srv, calls := renameServer(t, []bitbucket.PullRequest{pr(3, "dev", "main")}, "")
+	defer srv.Close()
+
+	results := newRenamerForServer(srv).Rename("ws", []string{"api"}, "dev", "develop")
+	if results[0].Success || !strings.Contains(results[0].Error, "open PR #3") {
+		t.Fatalf("result = %+v", results[0])
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected no changes, calls = %v", *calls)
+	}
+}
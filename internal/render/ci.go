@@ -0,0 +1,148 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CI is a CI system whose pipeline UI can show per-repo results.
+type CI string
+
+const (
+	NoCI               CI = ""
+	GitHubActions      CI = "github"    // ::error workflow commands in the log
+	BitbucketPipelines CI = "bitbucket" // JUnit reports picked up from test-results/
+)
+
+// DetectCI returns the CI system the process runs in, judged by the
+// variables each one sets for its jobs.
+func DetectCI(getenv func(string) string) CI {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		return GitHubActions
+	case getenv("BITBUCKET_BUILD_NUMBER") != "":
+		return BitbucketPipelines
+	}
+	return NoCI
+}
+
+// Annotator turns reports into annotations for a CI system, so failed repos
+// show up in the pipeline UI and not only in the log.
+type Annotator struct {
+	CI        CI
+	Title     string    // names the run in annotations, e.g. "buck create"
+	Out       io.Writer // where GitHub Actions workflow commands are written
+	ReportDir string    // where Bitbucket Pipelines JUnit reports are written
+}
+
+// Annotations annotates every printed report when set; nil outside CI.
+var Annotations *Annotator
+
+// Annotate emits r in the format of a.CI.
+func (a *Annotator) Annotate(r Report) error {
+	switch a.CI {
+	case GitHubActions:
+		a.writeWorkflowCommands(r)
+	case BitbucketPipelines:
+		return a.writeJUnit(r)
+	}
+	return nil
+}
+
+// writeWorkflowCommands writes an ::error for each failed repo and a
+// ::notice for each skipped one.
+func (a *Annotator) writeWorkflowCommands(r Report) {
+	for _, row := range r.Rows {
+		level := ""
+		switch row.Status {
+		case Failed:
+			level = "error"
+		case Skipped:
+			level = "notice"
+		default:
+			continue
+		}
+		title := row.Repo
+		if a.Title != "" {
+			title = a.Title + ": " + row.Repo
+		}
+		fmt.Fprintf(a.Out, "::%s title=%s::%s\n", level, escapeProperty(title), escapeData(row.Message))
+	}
+}
+
+// escapeData escapes a workflow command message, see
+// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property such as the title.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes r as a JUnit test suite with one test case per repo.
+// Pipelines lists the failed cases in the build's Tests tab.
+func (a *Annotator) writeJUnit(r Report) error {
+	name := a.Title
+	if name == "" {
+		name = "buck"
+	}
+	suite := junitSuite{Name: name, Tests: len(r.Rows)}
+	for _, row := range r.Rows {
+		c := junitCase{Name: row.Repo, ClassName: name}
+		first, _, _ := strings.Cut(row.Message, "\n")
+		switch row.Status {
+		case Failed:
+			suite.Failures++
+			c.Failure = &junitMessage{Message: first, Text: row.Message}
+		case Skipped:
+			suite.Skipped++
+			c.Skipped = &junitMessage{Message: first}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if err := os.MkdirAll(a.ReportDir, 0o755); err != nil {
+		return fmt.Errorf("failed to write CI report: %w", err)
+	}
+	f, err := os.CreateTemp(a.ReportDir, "buck-*.xml")
+	if err != nil {
+		return fmt.Errorf("failed to write CI report: %w", err)
+	}
+	defer f.Close()
+
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	if _, err := io.WriteString(f, xml.Header); err != nil {
+		return fmt.Errorf("failed to write CI report: %w", err)
+	}
+	if err := enc.Encode(suite); err != nil {
+		return fmt.Errorf("failed to write CI report: %w", err)
+	}
+	return nil
+}
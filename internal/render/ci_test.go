@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want CI
+	}{
+		{map[string]string{"GITHUB_ACTIONS": "true"}, GitHubActions},
+		{map[string]string{"BITBUCKET_BUILD_NUMBER": "42"}, BitbucketPipelines},
+		{map[string]string{"CI": "true"}, NoCI},
+		{nil, NoCI},
+	}
+	for _, tt := range tests {
+		got := DetectCI(func(k string) string { return tt.env[k] })
+		if got != tt.want {
+			t.Errorf("DetectCI(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotate_GitHubActions(t *testing.T) {
+	var buf bytes.Buffer
+	a := &Annotator{CI: GitHubActions, Title: "buck create", Out: &buf}
+
+	if err := a.Annotate(testReport); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "::error title=buck create%3A web::already exists%0A  Fix: pick another name\n" +
+		"::notice title=buck create%3A worker::protected\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestAnnotate_BitbucketPipelinesWritesJUnit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-results")
+	a := &Annotator{CI: BitbucketPipelines, Title: "buck create", ReportDir: dir}
+
+	if err := a.Annotate(testReport); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "buck-*.xml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one report, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuite name="buck create" tests="3" failures="1" skipped="1">`,
+		`<testcase name="api" classname="buck create"></testcase>`,
+		`<failure message="already exists">already exists`,
+		`<skipped message="protected"></skipped>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("report missing %q:\n%s", want, data)
+		}
+	}
+}
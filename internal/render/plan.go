@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+// PlanItem is the preflight outcome for one repo: the branch the command
+// would work from or into and anything that would make it fail.
+type PlanItem struct {
+	RepoSlug string
+	Detail   string // shown in parentheses after the repo, e.g. "from main"
+	Warnings []string
+}
+
+// Warn records a problem found for the repo.
+func (p *PlanItem) Warn(format string, args ...any) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
+
+// PrintPlan displays a preflight plan with warnings under each repo and
+// returns the number of repos that have warnings.
+func PrintPlan(items []PlanItem) int {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	flagged := 0
+	for _, item := range items {
+		detail := ""
+		if item.Detail != "" {
+			detail = fmt.Sprintf(" (%s)", item.Detail)
+		}
+		if len(item.Warnings) == 0 {
+			fmt.Fprintf(Stdout, "  %s %s%s\n", green("✓"), bold(item.RepoSlug), detail)
+			continue
+		}
+		flagged++
+		fmt.Fprintf(Stdout, "  %s %s%s\n", yellow("!"), bold(item.RepoSlug), detail)
+		for _, w := range item.Warnings {
+			fmt.Fprintf(Stdout, "      %s\n", yellow(w))
+		}
+	}
+
+	fmt.Fprintf(Stdout, "\nPreflight: %d ready, %d with warnings\n", len(items)-flagged, flagged)
+	return flagged
+}
@@ -0,0 +1,33 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintPlan(t *testing.T) {
+	var buf bytes.Buffer
+	old := Stdout
+	Stdout = &buf
+	t.Cleanup(func() { Stdout = old })
+
+	flagged := PrintPlan([]PlanItem{
+		{RepoSlug: "api", Detail: "from main"},
+		{RepoSlug: "web", Warnings: []string{"source \"main\" not found"}},
+	})
+
+	if flagged != 1 {
+		t.Errorf("flagged = %d, want 1", flagged)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"✓ api (from main)\n",
+		"! web\n      source \"main\" not found\n",
+		"Preflight: 1 ready, 1 with warnings\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// Mode selects how Print renders a report.
+type Mode string
+
+const (
+	Table Mode = "table" // aligned rows with a summary, for people
+	JSON  Mode = "json"  // the command's results as a JSON array, for scripts
+	Quiet Mode = "quiet" // failed rows only, nothing when everything succeeded
+	URLs  Mode = "urls"  // the link of each successful row, one per line, for shell pipelines
+)
+
+// Modes lists the valid --output values.
+var Modes = []string{string(Table), string(JSON), string(Quiet)}
+
+// PorcelainModes lists the valid --porcelain values.
+var PorcelainModes = []string{string(URLs)}
+
+// Output is the mode Print uses, set from the --output flag.
+var Output = Table
+
+// Stdout receives reports. It stays the real stdout when commands send
+// their progress messages elsewhere in json and quiet modes.
+var Stdout io.Writer = os.Stdout
+
+// ParseMode validates an --output value.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if s == m {
+			return Mode(s), nil
+		}
+	}
+	return "", fmt.Errorf("invalid --output %q (valid: %s)", s, strings.Join(Modes, ", "))
+}
+
+// ParsePorcelain validates a --porcelain value.
+func ParsePorcelain(s string) (Mode, error) {
+	for _, m := range PorcelainModes {
+		if s == m {
+			return Mode(s), nil
+		}
+	}
+	return "", fmt.Errorf("invalid --porcelain %q (valid: %s)", s, strings.Join(PorcelainModes, ", "))
+}
+
+// Status is the outcome of one row.
+type Status int
+
+const (
+	OK Status = iota
+	Failed
+	Skipped
+)
+
+// Row is one repo's line in a report.
+type Row struct {
+	Repo     string
+	Ref      string // optional second column, e.g. the branch
+	Status   Status
+	Message  string                 // beside the slug; further lines are indented under it
+	Link     string                 // URL printed on its own line under the row
+	Notes    []string               // highlighted lines under the row, e.g. warnings
+	Category provider.ErrorCategory // why a failed row failed, for the summary breakdown
+}
+
+// Report is the outcome of a per-repo operation.
+type Report struct {
+	Rows []Row
+	Done string // word for successful rows in the summary; "succeeded" if empty
+	Data any    // written as-is in JSON mode; the rows if nil
+
+	// LinkBlock moves the links of successful rows out of the table into a
+	// block of bare URLs after the summary, where a triple-click copies a
+	// clean link. For reports whose links are the point, e.g. new PRs.
+	LinkBlock bool
+}
+
+// Print writes r in the current Output mode.
+func Print(r Report) {
+	if Annotations != nil {
+		if err := Annotations.Annotate(r); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	switch Output {
+	case JSON:
+		data := r.Data
+		if data == nil {
+			data = r.Rows
+		}
+		if err := WriteJSON(data); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	case URLs:
+		for _, row := range r.Rows {
+			switch {
+			case row.Status == Failed:
+				fmt.Fprintf(os.Stderr, "%s: %s\n", row.Repo, row.Message)
+			case row.Status == OK && row.Link != "":
+				fmt.Fprintln(Stdout, row.Link)
+			}
+		}
+	case Quiet:
+		var failed []Row
+		for _, row := range r.Rows {
+			if row.Status == Failed {
+				failed = append(failed, row)
+			}
+		}
+		printRows(failed)
+	default:
+		rows := r.Rows
+		if r.LinkBlock {
+			rows = make([]Row, len(r.Rows))
+			for i, row := range r.Rows {
+				row.Link = ""
+				rows[i] = row
+			}
+		}
+		fmt.Fprintln(Stdout)
+		printRows(rows)
+		printSummary(r)
+		if r.LinkBlock {
+			printLinkBlock(r.Rows)
+		}
+	}
+}
+
+// WriteJSON writes v to Stdout as indented JSON.
+func WriteJSON(v any) error {
+	enc := json.NewEncoder(Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return nil
+}
+
+func printRows(rows []Row) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	slugs := make([]string, len(rows))
+	refs := make([]string, 0, len(rows))
+	for i, row := range rows {
+		slugs[i] = row.Repo
+		if row.Ref != "" {
+			refs = append(refs, row.Ref)
+		}
+	}
+	width := SlugWidth(slugs)
+	refWidth := 0
+	if len(refs) > 0 {
+		refWidth = ColumnWidth(refs, minSlugWidth, 40)
+	}
+	indent := Blank(width)
+	if refWidth > 0 {
+		indent += " " + Blank(refWidth)
+	}
+
+	for _, row := range rows {
+		mark := green("✓")
+		switch row.Status {
+		case Failed:
+			mark = red("✗")
+		case Skipped:
+			mark = yellow("–")
+		}
+
+		cells := Cell(row.Repo, width)
+		if refWidth > 0 {
+			cells += " " + Cell(row.Ref, refWidth)
+		}
+		// Indent multiline messages (e.g. permission scope details)
+		lines := strings.Split(row.Message, "\n")
+		fmt.Fprintf(Stdout, "  %s %s %s\n", mark, cells, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(Stdout, "    %s %s\n", indent, line)
+		}
+		if row.Link != "" {
+			fmt.Fprintf(Stdout, "    %s\n", cyan(row.Link))
+		}
+		for _, note := range row.Notes {
+			fmt.Fprintf(Stdout, "    %s %s\n", indent, yellow(note))
+		}
+	}
+}
+
+// printLinkBlock writes the link of each successful row on a line of its own,
+// without color or indent.
+func printLinkBlock(rows []Row) {
+	first := true
+	for _, row := range rows {
+		if row.Status != OK || row.Link == "" {
+			continue
+		}
+		if first {
+			fmt.Fprintln(Stdout)
+			first = false
+		}
+		fmt.Fprintln(Stdout, row.Link)
+	}
+}
+
+func printSummary(r Report) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	var succeeded, failed, skipped int
+	var failures []provider.ErrorCategory
+	var failedRepos []string
+	for _, row := range r.Rows {
+		switch row.Status {
+		case OK:
+			succeeded++
+		case Failed:
+			failed++
+			if row.Category != "" {
+				failures = append(failures, row.Category)
+				failedRepos = append(failedRepos, row.Repo)
+			}
+		case Skipped:
+			skipped++
+		}
+	}
+
+	done := r.Done
+	if done == "" {
+		done = "succeeded"
+	}
+	parts := []string{green(fmt.Sprintf("%d", succeeded)) + " " + done}
+	if skipped > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("%d", skipped))+" skipped")
+	}
+	parts = append(parts, red(fmt.Sprintf("%d", failed))+" failed")
+	fmt.Fprintf(Stdout, "\n%s %s\n", bold("Summary:"), strings.Join(parts, ", "))
+
+	if s := provider.FailureSummary(failures); s != "" {
+		fmt.Fprintln(Stdout, s)
+		for _, line := range provider.FailureGroups(failedRepos, failures) {
+			fmt.Fprintf(Stdout, "  %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+func capture(t *testing.T, mode Mode, r Report) string {
+	t.Helper()
+	var buf bytes.Buffer
+	oldOut, oldMode := Stdout, Output
+	Stdout, Output = &buf, mode
+	t.Cleanup(func() { Stdout, Output = oldOut, oldMode })
+	Print(r)
+	return buf.String()
+}
+
+var testReport = Report{Rows: []Row{
+	{Repo: "api", Message: "created", Link: "https://example.com/api", Notes: []string{"warning: no reviewers"}},
+	{Repo: "web", Status: Failed, Message: "already exists\n  Fix: pick another name", Category: provider.ErrConflict},
+	{Repo: "worker", Status: Skipped, Message: "protected"},
+}}
+
+func TestPrint_Table(t *testing.T) {
+	out := capture(t, Table, testReport)
+
+	for _, want := range []string{
+		"✓ api          created\n",
+		"    https://example.com/api\n",
+		"                 warning: no reviewers\n",
+		"✗ web          already exists\n                   Fix: pick another name\n",
+		"– worker       protected\n",
+		"Summary: 1 succeeded, 1 skipped, 1 failed\n",
+		"1 repo failed: 1 already exists\n  already exists: web\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrint_TableRefColumnAndDoneWord(t *testing.T) {
+	out := capture(t, Table, Report{Done: "deleted", Rows: []Row{
+		{Repo: "api", Ref: "feature/x", Message: "deleted"},
+	}})
+
+	if !strings.Contains(out, "✓ api          feature/x    deleted\n") {
+		t.Errorf("ref column not aligned:\n%s", out)
+	}
+	if !strings.Contains(out, "Summary: 1 deleted, 0 failed") {
+		t.Errorf("summary should use the done word:\n%s", out)
+	}
+}
+
+func TestPrint_TableLinkBlock(t *testing.T) {
+	out := capture(t, Table, Report{LinkBlock: true, Rows: []Row{
+		{Repo: "api", Message: "PR #12", Link: "https://example.com/api/pull-requests/12"},
+		{Repo: "web", Message: "PR #3", Link: "https://example.com/web/pull-requests/3"},
+		{Repo: "worker", Status: Failed, Message: "forbidden", Link: "https://example.com/worker"},
+	}})
+
+	want := "\nhttps://example.com/api/pull-requests/12\nhttps://example.com/web/pull-requests/3\n"
+	if !strings.HasSuffix(out, want) {
+		t.Errorf("output should end with the bare links:\n%s", out)
+	}
+	if strings.Count(out, "https://example.com/api") != 1 || strings.Contains(out, "example.com/worker") {
+		t.Errorf("links should only be in the block:\n%s", out)
+	}
+}
+
+func TestPrint_JSON(t *testing.T) {
+	type result struct {
+		Repo string `json:"repo"`
+	}
+	out := capture(t, JSON, Report{Rows: testReport.Rows, Data: []result{{Repo: "api"}}})
+
+	var got []result
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if len(got) != 1 || got[0].Repo != "api" {
+		t.Errorf("got %+v, want the report data", got)
+	}
+}
+
+func TestPrint_QuietShowsOnlyFailures(t *testing.T) {
+	out := capture(t, Quiet, testReport)
+
+	if strings.Contains(out, "api") || strings.Contains(out, "worker") || strings.Contains(out, "Summary") {
+		t.Errorf("quiet output should only list failures:\n%s", out)
+	}
+	if !strings.Contains(out, "✗ web") {
+		t.Errorf("quiet output missing the failure:\n%s", out)
+	}
+	if out := capture(t, Quiet, Report{Rows: testReport.Rows[:1]}); out != "" {
+		t.Errorf("quiet output should be empty when nothing failed, got %q", out)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, err := ParseMode("json"); err != nil || m != JSON {
+		t.Errorf("ParseMode(json) = %q, %v", m, err)
+	}
+	if _, err := ParseMode("yaml"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestPrint_URLsListsSuccessfulLinks(t *testing.T) {
+	report := Report{Rows: []Row{
+		{Repo: "api", Message: "PR #1", Link: "https://example.com/api/pull-requests/1"},
+		{Repo: "web", Status: Failed, Message: "conflict", Link: "https://example.com/web/pull-requests/2"},
+		{Repo: "worker", Message: "PR #3", Link: "https://example.com/worker/pull-requests/3"},
+		{Repo: "docs", Status: Skipped, Message: "no changes"},
+	}}
+
+	out := capture(t, URLs, report)
+
+	want := "https://example.com/api/pull-requests/1\nhttps://example.com/worker/pull-requests/3\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestParsePorcelain(t *testing.T) {
+	if m, err := ParsePorcelain("urls"); err != nil || m != URLs {
+		t.Errorf("ParsePorcelain(urls) = %q, %v", m, err)
+	}
+	if _, err := ParsePorcelain("json"); err == nil {
+		t.Error("expected an error for an unknown porcelain format")
+	}
+}
@@ -0,0 +1,73 @@
+// Package render lays out the per-repo result tables printed by commands.
+package render
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-runewidth"
+)
+
+// minSlugWidth keeps short slug columns from looking cramped.
+const minSlugWidth = 12
+
+// Width returns the width of the terminal on stdout, else $COLUMNS, else 0
+// when unknown (e.g. output piped to a file), which disables truncation.
+func Width() int {
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+// ColumnWidth returns a width that fits the widest value, at least min
+// and at most max (0: no maximum). Widths are in terminal cells, so a CJK
+// rune or emoji counts as two.
+func ColumnWidth(values []string, min, max int) int {
+	w := min
+	for _, v := range values {
+		if n := runewidth.StringWidth(v); n > w {
+			w = n
+		}
+	}
+	if max > 0 && w > max {
+		w = max
+	}
+	return w
+}
+
+// SlugWidth returns the width of a table's repo column: wide enough for the
+// longest slug, but at most half the terminal so the details keep room.
+func SlugWidth(slugs []string) int {
+	max := 0
+	if w := Width(); w > 0 {
+		max = w / 2
+		if max < minSlugWidth {
+			max = minSlugWidth
+		}
+	}
+	return ColumnWidth(slugs, minSlugWidth, max)
+}
+
+// Cell fits s into exactly width terminal cells: padded with spaces, or cut
+// with an ellipsis when wider. A wide rune that would straddle the cut is
+// dropped and the gap padded, so following columns stay aligned.
+func Cell(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	if runewidth.StringWidth(s) > width {
+		s = runewidth.Truncate(s, width, "…")
+	}
+	return s + strings.Repeat(" ", width-runewidth.StringWidth(s))
+}
+
+// Blank returns width spaces, for continuation lines under a cell.
+func Blank(width int) string {
+	return strings.Repeat(" ", width)
+}
@@ -0,0 +1,51 @@
+package render
+
+import "testing"
+
+func TestCell(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"api", 6, "api   "},
+		{"api-gateway", 11, "api-gateway"},
+		{"api-gateway", 8, "api-gat…"},
+		{"api-gateway", 0, "api-gateway"},
+		{"dịch-vụ", 5, "dịch…"},
+		{"日本語", 8, "日本語  "},
+		{"日本語サービス", 6, "日本… "},
+		{"🚀-launch", 4, "🚀-…"},
+	}
+	for _, tt := range tests {
+		if got := Cell(tt.s, tt.width); got != tt.want {
+			t.Errorf("Cell(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestColumnWidth(t *testing.T) {
+	values := []string{"api", "a-much-longer-repository-name"}
+	if got := ColumnWidth(values, 12, 0); got != 29 {
+		t.Errorf("no max: got %d, want 29", got)
+	}
+	if got := ColumnWidth(values, 12, 20); got != 20 {
+		t.Errorf("capped: got %d, want 20", got)
+	}
+	if got := ColumnWidth([]string{"api"}, 12, 20); got != 12 {
+		t.Errorf("minimum: got %d, want 12", got)
+	}
+	if got := ColumnWidth([]string{"日本語サービス"}, 0, 0); got != 14 {
+		t.Errorf("wide runes: got %d, want 14", got)
+	}
+}
+
+func TestSlugWidth_HalfTerminal(t *testing.T) {
+	t.Setenv("COLUMNS", "40")
+	if Width() != 40 {
+		t.Skip("stdout is a terminal; $COLUMNS is not consulted")
+	}
+	if got := SlugWidth([]string{"a-much-longer-repository-name"}); got != 20 {
+		t.Errorf("got %d, want 20", got)
+	}
+}
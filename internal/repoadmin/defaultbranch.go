@@ -0,0 +1,72 @@
+package repoadmin
+
+import (
+	"fmt"
+
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// DefaultBranchSetter changes the default branch across repos after checking
+// that the target branch exists.
+type DefaultBranchSetter struct {
+	client  provider.Provider
+	service provider.DefaultBranchService
+}
+
+// NewDefaultBranchSetter creates a new default branch orchestrator.
+func NewDefaultBranchSetter(client provider.Provider, service provider.DefaultBranchService) *DefaultBranchSetter {
+	return &DefaultBranchSetter{client: client, service: service}
+}
+
+// Set makes branchName the default branch of each repo. Repos where the branch
+// does not exist fail without being changed.
+func (s *DefaultBranchSetter) Set(workspace string, repos []string, branchName string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		current, done, err := s.check(workspace, repoSlug, branchName)
+		if err != nil || done {
+			return current, err
+		}
+		if err := s.service.SetDefaultBranch(workspace, repoSlug, branchName); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s → %s", current, branchName), nil
+	})
+}
+
+// Preview runs the same checks as Set without changing anything.
+func (s *DefaultBranchSetter) Preview(workspace string, repos []string, branchName string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		current, done, err := s.check(workspace, repoSlug, branchName)
+		if err != nil || done {
+			return current, err
+		}
+		return fmt.Sprintf("would change %s → %s", current, branchName), nil
+	})
+}
+
+// check returns the current default branch, or a detail and done=true when the
+// repo already uses branchName. It fails when branchName does not exist.
+func (s *DefaultBranchSetter) check(workspace, repoSlug, branchName string) (string, bool, error) {
+	repo, err := s.client.GetRepository(workspace, repoSlug)
+	if err != nil {
+		return "", false, err
+	}
+	current := "unset"
+	if repo.MainBranch != nil && repo.MainBranch.Name != "" {
+		current = repo.MainBranch.Name
+	}
+	if current == branchName {
+		return "already " + branchName, true, nil
+	}
+
+	branches, err := s.client.ListBranches(workspace, repoSlug)
+	if err != nil {
+		return "", false, err
+	}
+	for _, b := range branches {
+		if b.Name == branchName {
+			return current, false, nil
+		}
+	}
+	return "", false, fmt.Errorf("branch %q does not exist (default stays %s)", branchName, current)
+}
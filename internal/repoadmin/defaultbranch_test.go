@@ -0,0 +1,65 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestDefaultBranchSetterSet(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		puts []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		slug := strings.Split(r.URL.Path, "/")[4]
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/refs/branches"):
+			branches := []bitbucket.Branch{{Name: "master"}}
+			if slug != "nomain" {
+				branches = append(branches, bitbucket.Branch{Name: "main"})
+			}
+			json.NewEncoder(w).Encode(bitbucket.PaginatedBranches{Values: branches})
+		case r.Method == http.MethodGet:
+			main := "master"
+			if slug == "done" {
+				main = "main"
+			}
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: slug, MainBranch: &bitbucket.BranchRef{Name: main}})
+		case r.Method == http.MethodPut:
+			var body struct {
+				MainBranch bitbucket.BranchRef `json:"mainbranch"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			puts = append(puts, slug+":"+body.MainBranch.Name)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer srv.Close()
+
+	client := newClientForServer(srv)
+	results := NewDefaultBranchSetter(client, client).Set("ws", []string{"api", "done", "nomain"}, "main")
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+	if !results[0].Success || results[0].Detail != "master → main" {
+		t.Errorf("api: %+v", results[0])
+	}
+	if !results[1].Success || results[1].Detail != "already main" {
+		t.Errorf("done: %+v", results[1])
+	}
+	if results[2].Success || !strings.Contains(results[2].Error, `branch "main" does not exist`) {
+		t.Errorf("nomain: %+v", results[2])
+	}
+	if len(puts) != 1 || puts[0] != "api:main" {
+		t.Errorf("puts = %v", puts)
+	}
+}
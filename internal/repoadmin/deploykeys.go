@@ -0,0 +1,125 @@
+package repoadmin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// DeployKeyManager adds, rotates and removes a labelled deploy key across repos.
+type DeployKeyManager struct {
+	client provider.DeployKeyService
+}
+
+// NewDeployKeyManager creates a new deploy key orchestrator.
+func NewDeployKeyManager(client provider.DeployKeyService) *DeployKeyManager {
+	return &DeployKeyManager{client: client}
+}
+
+// LoadPublicKey reads an SSH public key file and checks it looks like one.
+func LoadPublicKey(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if len(strings.Fields(key)) < 2 || strings.Contains(key, "PRIVATE KEY") {
+		return "", fmt.Errorf("%s is not an SSH public key", path)
+	}
+	return key, nil
+}
+
+// Add adds the key under label unless the same key is already present.
+func (m *DeployKeyManager) Add(workspace string, repos []string, label, key string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListDeployKeys(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+		if _, found := findDeployKey(existing, key); found {
+			return "already present", nil
+		}
+		if _, err := m.client.AddDeployKey(workspace, repoSlug, bitbucket.DeployKey{Key: key, Label: label}); err != nil {
+			return "", err
+		}
+		return "added", nil
+	})
+}
+
+// Rotate adds the new key under label, then removes every other key with that
+// label. The new key goes in first so the repo is never left without access.
+func (m *DeployKeyManager) Rotate(workspace string, repos []string, label, key string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListDeployKeys(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var c changes
+		if _, found := findDeployKey(existing, key); found {
+			c.unchanged++
+		} else {
+			if _, err := m.client.AddDeployKey(workspace, repoSlug, bitbucket.DeployKey{Key: key, Label: label}); err != nil {
+				return "", err
+			}
+			c.created++
+		}
+
+		for _, k := range existing {
+			if k.Label != label || sameKey(k.Key, key) {
+				continue
+			}
+			if err := m.client.DeleteDeployKey(workspace, repoSlug, k.ID); err != nil {
+				return "", fmt.Errorf("new key added but removing old key %d failed: %w", k.ID, err)
+			}
+			c.deleted++
+		}
+		return c.String(), nil
+	})
+}
+
+// Remove deletes every key with the given label.
+func (m *DeployKeyManager) Remove(workspace string, repos []string, label string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListDeployKeys(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var c changes
+		for _, k := range existing {
+			if k.Label != label {
+				continue
+			}
+			if err := m.client.DeleteDeployKey(workspace, repoSlug, k.ID); err != nil {
+				return "", err
+			}
+			c.deleted++
+		}
+		if c.deleted == 0 {
+			return "not present", nil
+		}
+		return c.String(), nil
+	})
+}
+
+func findDeployKey(keys []bitbucket.DeployKey, key string) (bitbucket.DeployKey, bool) {
+	for _, k := range keys {
+		if sameKey(k.Key, key) {
+			return k, true
+		}
+	}
+	return bitbucket.DeployKey{}, false
+}
+
+// sameKey compares the type and key material of two public keys, ignoring comments.
+func sameKey(a, b string) bool {
+	fa, fb := strings.Fields(a), strings.Fields(b)
+	if len(fa) < 2 || len(fb) < 2 {
+		return false
+	}
+	return fa[0] == fb[0] && fa[1] == fb[1]
+}
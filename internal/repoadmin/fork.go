@@ -0,0 +1,71 @@
+package repoadmin
+
+import (
+	"fmt"
+
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// Forker forks repos into another workspace.
+type Forker struct {
+	repos  provider.RepositoryService
+	client provider.ForkService
+}
+
+// NewForker creates a new fork orchestrator.
+func NewForker(repos provider.RepositoryService, client provider.ForkService) *Forker {
+	return &Forker{repos: repos, client: client}
+}
+
+// Fork forks each repo into target. Repos that already exist there are
+// reported as unchanged rather than failures, so re-running is safe.
+func (f *Forker) Fork(workspace string, repos []string, target string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		exists, err := f.existsIn(target, repoSlug)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return fmt.Sprintf("unchanged, %s/%s already exists", target, repoSlug), nil
+		}
+		fork, err := f.client.ForkRepository(workspace, repoSlug, target)
+		if err != nil {
+			return "", err
+		}
+		name := fork.FullName
+		if name == "" {
+			name = target + "/" + repoSlug
+		}
+		return "forked to " + name, nil
+	})
+}
+
+// Preview reports what Fork would do without forking anything.
+func (f *Forker) Preview(workspace string, repos []string, target string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		if _, err := f.repos.GetRepository(workspace, repoSlug); err != nil {
+			return "", err
+		}
+		exists, err := f.existsIn(target, repoSlug)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return fmt.Sprintf("unchanged, %s/%s already exists", target, repoSlug), nil
+		}
+		return fmt.Sprintf("would fork to %s/%s", target, repoSlug), nil
+	})
+}
+
+// existsIn reports whether target already has a repo named repoSlug.
+func (f *Forker) existsIn(target, repoSlug string) (bool, error) {
+	_, err := f.repos.GetRepository(target, repoSlug)
+	switch {
+	case err == nil:
+		return true, nil
+	case provider.Classify(err) == provider.ErrNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("could not check %s/%s: %w", target, repoSlug, err)
+	}
+}
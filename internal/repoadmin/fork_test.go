@@ -0,0 +1,67 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestForker(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		forked = make(map[string]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// parts: [2.0, repositories, {ws}, {slug}, ...]
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		ws, slug := parts[2], parts[3]
+		switch {
+		case r.Method == http.MethodGet && (ws == "upstream" || slug == "already"):
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: slug})
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "not found"}})
+		case r.Method == http.MethodPost && len(parts) == 5 && parts[4] == "forks":
+			var body struct {
+				Workspace struct {
+					Slug string `json:"slug"`
+				} `json:"workspace"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			forked[slug] = body.Workspace.Slug
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: slug, FullName: body.Workspace.Slug + "/" + slug})
+		}
+	}))
+	defer srv.Close()
+
+	client := newClientForServer(srv)
+	forker := NewForker(client, client)
+
+	preview := forker.Preview("upstream", []string{"already", "api"}, "me")
+	if preview[0].Detail != "unchanged, me/already already exists" || preview[1].Detail != "would fork to me/api" {
+		t.Errorf("preview = %+v", preview)
+	}
+	if len(forked) != 0 {
+		t.Fatalf("preview forked %v", forked)
+	}
+
+	results := forker.Fork("upstream", []string{"already", "api"}, "me")
+	if !results[0].Success || !strings.HasPrefix(results[0].Detail, "unchanged") {
+		t.Errorf("already = %+v", results[0])
+	}
+	if !results[1].Success || results[1].Detail != "forked to me/api" {
+		t.Errorf("api = %+v", results[1])
+	}
+	if len(forked) != 1 || forked["api"] != "me" {
+		t.Errorf("forked = %v, want only api into me", forked)
+	}
+}
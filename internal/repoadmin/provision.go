@@ -0,0 +1,235 @@
+package repoadmin
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// RepoSpec is one repository to create with 'buck repo create'.
+type RepoSpec struct {
+	Slug        string
+	Description string
+	Project     string // project key; empty: the workspace's default project
+	Private     bool
+	MainBranch  string            // branch the seed files are committed to
+	Seed        map[string][]byte // path → content; empty: create the repo empty
+}
+
+// repoSpecFields are the per-repo keys, also allowed under defaults.
+type repoSpecFields struct {
+	Description *string `yaml:"description"`
+	Project     *string `yaml:"project"`
+	Private     *bool   `yaml:"private"`
+	MainBranch  *string `yaml:"main_branch"`
+	Seed        *string `yaml:"seed"` // directory, relative to the spec file
+}
+
+// repoSpecFile is the YAML layout of a spec file for 'buck repo create'.
+type repoSpecFile struct {
+	Defaults repoSpecFields `yaml:"defaults"`
+	Repos    []struct {
+		Slug           string `yaml:"slug"`
+		repoSpecFields `yaml:",inline"`
+	} `yaml:"repos"`
+}
+
+// LoadRepoSpecs reads a repository spec file. Repos inherit every key they
+// do not set from defaults; repos are private and seeded onto "main" unless
+// told otherwise. Seed directories are read up front, so a bad path fails
+// before anything is created. Unknown keys are rejected.
+func LoadRepoSpecs(path string) ([]RepoSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	var f repoSpecFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid spec file %s: %w", path, err)
+	}
+	if len(f.Repos) == 0 {
+		return nil, fmt.Errorf("spec file %s lists no repos", path)
+	}
+
+	seeds := make(map[string]map[string][]byte)
+	seen := make(map[string]bool)
+	specs := make([]RepoSpec, 0, len(f.Repos))
+	for _, r := range f.Repos {
+		slug := strings.ToLower(strings.TrimSpace(r.Slug))
+		if slug == "" {
+			return nil, fmt.Errorf("spec file %s: every repo needs a slug", path)
+		}
+		if seen[slug] {
+			return nil, fmt.Errorf("spec file %s: repo %q listed twice", path, slug)
+		}
+		seen[slug] = true
+
+		spec := RepoSpec{
+			Slug:        slug,
+			Description: pick(r.Description, f.Defaults.Description, ""),
+			Project:     pick(r.Project, f.Defaults.Project, ""),
+			Private:     pick(r.Private, f.Defaults.Private, true),
+			MainBranch:  pick(r.MainBranch, f.Defaults.MainBranch, "main"),
+		}
+		if dir := pick(r.Seed, f.Defaults.Seed, ""); dir != "" {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(filepath.Dir(path), dir)
+			}
+			if _, ok := seeds[dir]; !ok {
+				files, err := readSeedDir(dir)
+				if err != nil {
+					return nil, err
+				}
+				seeds[dir] = files
+			}
+			spec.Seed = seeds[dir]
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// pick returns the repo's own value, else the default, else fallback.
+func pick[T any](own, def *T, fallback T) T {
+	switch {
+	case own != nil:
+		return *own
+	case def != nil:
+		return *def
+	}
+	return fallback
+}
+
+// readSeedDir reads every file under dir (skipping .git), keyed by slash-separated relative path.
+func readSeedDir(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("seed directory %s has no files", dir)
+	}
+	return files, nil
+}
+
+// RepoCreator provisions new repositories from specs.
+type RepoCreator struct {
+	repos    provider.RepositoryService
+	client   provider.RepositoryCreationService
+	branches provider.DefaultBranchService
+}
+
+// NewRepoCreator creates a new repository provisioning orchestrator.
+func NewRepoCreator(repos provider.RepositoryService, client provider.RepositoryCreationService, branches provider.DefaultBranchService) *RepoCreator {
+	return &RepoCreator{repos: repos, client: client, branches: branches}
+}
+
+// Create creates each repo, commits its seed files to its main branch and
+// makes that branch the default. A repo whose seeding fails is reported as
+// failed even though it was created.
+func (c *RepoCreator) Create(workspace string, specs []RepoSpec) []Result {
+	bySlug := specsBySlug(specs)
+	return forEachRepo(workspace, slugsOf(specs), func(workspace, repoSlug string) (string, error) {
+		spec := bySlug[repoSlug]
+		_, err := c.client.CreateRepository(workspace, bitbucket.NewRepository{
+			Slug:        spec.Slug,
+			Description: spec.Description,
+			ProjectKey:  spec.Project,
+			IsPrivate:   spec.Private,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(spec.Seed) == 0 {
+			return "created " + describeSpec(spec), nil
+		}
+		if err := c.client.CommitFiles(workspace, spec.Slug, spec.MainBranch, "Initial commit", spec.Seed); err != nil {
+			return "", fmt.Errorf("created, but seeding failed: %w", err)
+		}
+		if err := c.branches.SetDefaultBranch(workspace, spec.Slug, spec.MainBranch); err != nil {
+			return "", fmt.Errorf("created and seeded, but setting main branch failed: %w", err)
+		}
+		return "created " + describeSpec(spec), nil
+	})
+}
+
+// Preview reports what Create would do, failing repos that already exist.
+func (c *RepoCreator) Preview(workspace string, specs []RepoSpec) []Result {
+	bySlug := specsBySlug(specs)
+	return forEachRepo(workspace, slugsOf(specs), func(workspace, repoSlug string) (string, error) {
+		if _, err := c.repos.GetRepository(workspace, repoSlug); err == nil {
+			return "", fmt.Errorf("already exists")
+		} else if provider.Classify(err) != provider.ErrNotFound {
+			return "", err
+		}
+		return "would create " + describeSpec(bySlug[repoSlug]), nil
+	})
+}
+
+// describeSpec summarizes a spec, e.g. "private repo in PLAT, 3 files on main".
+func describeSpec(spec RepoSpec) string {
+	visibility := "public"
+	if spec.Private {
+		visibility = "private"
+	}
+	desc := visibility + " repo"
+	if spec.Project != "" {
+		desc += " in " + spec.Project
+	}
+	if len(spec.Seed) > 0 {
+		noun := "files"
+		if len(spec.Seed) == 1 {
+			noun = "file"
+		}
+		desc += fmt.Sprintf(", %d %s on %s", len(spec.Seed), noun, spec.MainBranch)
+	}
+	return desc
+}
+
+func specsBySlug(specs []RepoSpec) map[string]RepoSpec {
+	m := make(map[string]RepoSpec, len(specs))
+	for _, s := range specs {
+		m[s.Slug] = s
+	}
+	return m
+}
+
+func slugsOf(specs []RepoSpec) []string {
+	slugs := make([]string, len(specs))
+	for i, s := range specs {
+		slugs[i] = s.Slug
+	}
+	return slugs
+}
@@ -0,0 +1,181 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "template", "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "template", "README.md"), []byte("# Service\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "template", "docs", "index.md"), []byte("docs\n"), 0o644)
+	path := filepath.Join(dir, "repos.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRepoSpecs(t *testing.T) {
+	path := writeSpec(t, `
+defaults:
+  project: PLAT
+  seed: template
+repos:
+  - slug: Billing-API
+    description: Billing service
+  - slug: billing-sandbox
+    private: false
+    project: LAB
+    main_branch: develop
+`)
+	specs, err := LoadRepoSpecs(path)
+	if err != nil {
+		t.Fatalf("LoadRepoSpecs error: %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("specs = %+v", specs)
+	}
+	api, sandbox := specs[0], specs[1]
+	if api.Slug != "billing-api" || api.Project != "PLAT" || !api.Private || api.MainBranch != "main" || api.Description != "Billing service" {
+		t.Errorf("api = %+v", api)
+	}
+	if sandbox.Project != "LAB" || sandbox.Private || sandbox.MainBranch != "develop" {
+		t.Errorf("sandbox = %+v", sandbox)
+	}
+	if len(api.Seed) != 2 || string(api.Seed["docs/index.md"]) != "docs\n" {
+		t.Errorf("seed = %v", api.Seed)
+	}
+}
+
+func TestLoadRepoSpecs_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":  "repos:\n  - slug: a\n    visibility: private\n",
+		"no repos":     "defaults:\n  project: PLAT\n",
+		"missing slug": "repos:\n  - description: x\n",
+		"duplicate":    "repos:\n  - slug: a\n  - slug: A\n",
+		"missing seed": "repos:\n  - slug: a\n    seed: nowhere\n",
+	}
+	for name, content := range tests {
+		if _, err := LoadRepoSpecs(writeSpec(t, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRepoCreator(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		created  = make(map[string]map[string]any)
+		seeded   = make(map[string][]string)
+		mainSets = make(map[string]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		slug := parts[3]
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && len(parts) == 4:
+			if slug == "taken" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "Repository with this Slug and Owner already exists."}})
+				return
+			}
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			created[slug] = body
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: slug})
+		case r.Method == http.MethodPost && parts[4] == "src":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("multipart: %v", err)
+			}
+			for path := range r.MultipartForm.File {
+				seeded[slug] = append(seeded[slug], path)
+			}
+			seeded[slug] = append(seeded[slug], "branch="+r.FormValue("branch"))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut:
+			var body struct {
+				MainBranch bitbucket.BranchRef `json:"mainbranch"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			mainSets[slug] = body.MainBranch.Name
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: slug})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	specs := []RepoSpec{
+		{Slug: "api", Project: "PLAT", Private: true, MainBranch: "main", Seed: map[string][]byte{"README.md": []byte("hi")}},
+		{Slug: "empty", MainBranch: "main"},
+		{Slug: "taken", MainBranch: "main"},
+	}
+	client := newClientForServer(srv)
+	results := NewRepoCreator(client, client, client).Create("ws", specs)
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+	if !results[0].Success || results[0].Detail != "created private repo in PLAT, 1 file on main" {
+		t.Errorf("api = %+v", results[0])
+	}
+	if !results[1].Success || results[1].Detail != "created public repo" {
+		t.Errorf("empty = %+v", results[1])
+	}
+	if results[2].Success || !strings.Contains(results[2].Error, "already exists") {
+		t.Errorf("taken = %+v", results[2])
+	}
+
+	if created["api"]["is_private"] != true || created["api"]["project"].(map[string]any)["key"] != "PLAT" {
+		t.Errorf("create body = %v", created["api"])
+	}
+	if _, ok := created["empty"]["project"]; ok {
+		t.Errorf("empty project should be omitted: %v", created["empty"])
+	}
+	if strings.Join(seeded["api"], ",") != "README.md,branch=main" || len(seeded["empty"]) != 0 {
+		t.Errorf("seeded = %v", seeded)
+	}
+	if mainSets["api"] != "main" || mainSets["empty"] != "" {
+		t.Errorf("main branch set = %v", mainSets)
+	}
+}
+
+func TestRepoCreatorPreview(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("preview made a %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/exists") {
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: "exists"})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "not found"}})
+	}))
+	defer srv.Close()
+
+	client := newClientForServer(srv)
+	results := NewRepoCreator(client, client, client).Preview("ws", []RepoSpec{{Slug: "exists"}, {Slug: "new", Private: true}})
+	if results[0].Success || results[0].Error != "already exists" {
+		t.Errorf("exists = %+v", results[0])
+	}
+	if !results[1].Success || results[1].Detail != "would create private repo" {
+		t.Errorf("new = %+v", results[1])
+	}
+}
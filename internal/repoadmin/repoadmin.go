@@ -0,0 +1,94 @@
+// Package repoadmin applies repository administration changes (branch
+// restrictions, settings, keys, ...) across many repos concurrently.
+package repoadmin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/render"
+)
+
+// Result holds the outcome of an admin operation for one repo.
+type Result struct {
+	RepoSlug string `json:"repo"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	Detail   string `json:"detail,omitempty"` // what changed, e.g. "1 created, 1 unchanged"
+}
+
+// forEachRepo runs fn for every repo concurrently and returns results sorted by
+// slug. fn gets the workspace and slug of each entry, so "workspace/repo"
+// entries reach their own workspace while results keep the entry as given.
+func forEachRepo(workspace string, repos []string, fn func(workspace, repoSlug string) (string, error)) []Result {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []Result
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			result := Result{RepoSlug: repoSlug}
+			detail, err := fn(provider.SplitRepo(workspace, repoSlug))
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+				result.Detail = detail
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+
+	return results
+}
+
+// PrintResults displays a summary of admin results in the current output mode.
+func PrintResults(results []Result) {
+	rows := make([]render.Row, len(results))
+	for i, r := range results {
+		rows[i] = render.Row{Repo: r.RepoSlug, Message: r.Detail}
+		if !r.Success {
+			rows[i] = render.Row{Repo: r.RepoSlug, Status: render.Failed, Message: r.Error}
+		}
+	}
+	render.Print(render.Report{Rows: rows, Data: results})
+}
+
+// changes counts what an operation did in one repo.
+type changes struct {
+	created, updated, deleted, unchanged int
+}
+
+// String formats the non-zero counts, e.g. "2 created, 1 unchanged".
+func (c changes) String() string {
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		verb string
+	}{{c.created, "created"}, {c.updated, "updated"}, {c.deleted, "deleted"}, {c.unchanged, "unchanged"}} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", p.n, p.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing to do"
+	}
+	return strings.Join(parts, ", ")
+}
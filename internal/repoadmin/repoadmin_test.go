@@ -0,0 +1,48 @@
+package repoadmin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+type hostRewriteTransport struct {
+	base    http.RoundTripper
+	srvHost string
+}
+
+func (t *hostRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.URL.Scheme = "http"
+	cloned.URL.Host = t.srvHost
+	return t.base.RoundTrip(cloned)
+}
+
+// newClientForServer returns a Bitbucket client whose requests go to srv.
+func newClientForServer(srv *httptest.Server) *bitbucket.Client {
+	transport := &hostRewriteTransport{
+		base:    http.DefaultTransport,
+		srvHost: srv.Listener.Addr().String(),
+	}
+	httpClient := &http.Client{Transport: transport}
+	authApplier := bitbucket.BearerAuth(func() (string, error) { return "test-token", nil })
+	return bitbucket.NewClientWithHTTPClient(httpClient, authApplier)
+}
+
+func TestChangesString(t *testing.T) {
+	tests := []struct {
+		c    changes
+		want string
+	}{
+		{changes{}, "nothing to do"},
+		{changes{created: 2, unchanged: 1}, "2 created, 1 unchanged"},
+		{changes{updated: 1, deleted: 3}, "1 updated, 3 deleted"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
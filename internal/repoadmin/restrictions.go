@@ -0,0 +1,77 @@
+package repoadmin
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// Protector applies branch restrictions across repos.
+type Protector struct {
+	client provider.BranchRestrictionService
+}
+
+// NewProtector creates a new branch restriction orchestrator.
+func NewProtector(client provider.BranchRestrictionService) *Protector {
+	return &Protector{client: client}
+}
+
+// Apply ensures each rule exists in every repo. A rule with the same kind and
+// pattern as an existing restriction updates it; identical rules are left alone.
+func (p *Protector) Apply(workspace string, repos []string, rules []bitbucket.BranchRestriction) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := p.client.ListBranchRestrictions(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var c changes
+		for _, rule := range rules {
+			current := findRestriction(existing, rule)
+			switch {
+			case current == nil:
+				if _, err := p.client.CreateBranchRestriction(workspace, repoSlug, rule); err != nil {
+					return "", err
+				}
+				c.created++
+			case restrictionEqual(*current, rule):
+				c.unchanged++
+			default:
+				if _, err := p.client.UpdateBranchRestriction(workspace, repoSlug, current.ID, rule); err != nil {
+					return "", err
+				}
+				c.updated++
+			}
+		}
+		return c.String(), nil
+	})
+}
+
+// findRestriction returns the existing restriction with the rule's kind and pattern.
+func findRestriction(existing []bitbucket.BranchRestriction, rule bitbucket.BranchRestriction) *bitbucket.BranchRestriction {
+	for i, r := range existing {
+		if r.Kind == rule.Kind && r.BranchMatchKind == rule.BranchMatchKind && r.Pattern == rule.Pattern {
+			return &existing[i]
+		}
+	}
+	return nil
+}
+
+// restrictionEqual compares the settings of two restrictions with the same kind and pattern.
+func restrictionEqual(a, b bitbucket.BranchRestriction) bool {
+	if (a.Value == nil) != (b.Value == nil) || (a.Value != nil && *a.Value != *b.Value) {
+		return false
+	}
+	return slices.Equal(userUUIDs(a.Users), userUUIDs(b.Users))
+}
+
+func userUUIDs(users []bitbucket.UserRef) []string {
+	uuids := make([]string, len(users))
+	for i, u := range users {
+		uuids[i] = u.UUID
+	}
+	sort.Strings(uuids)
+	return uuids
+}
@@ -0,0 +1,94 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestProtectorApply(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/branch-restrictions"):
+			json.NewEncoder(w).Encode(bitbucket.PaginatedBranchRestrictions{Values: []bitbucket.BranchRestriction{
+				{ID: 1, Kind: "force", BranchMatchKind: "glob", Pattern: "release/*"},
+				{ID: 2, Kind: "require_approvals_to_merge", BranchMatchKind: "glob", Pattern: "release/*", Value: intPtr(1)},
+			}})
+		case r.Method == http.MethodPost:
+			var body bitbucket.BranchRestriction
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Kind != "delete" || body.ID != 0 {
+				t.Errorf("unexpected create body %+v", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(body)
+		case r.Method == http.MethodPut:
+			var body bitbucket.BranchRestriction
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Value == nil || *body.Value != 2 {
+				t.Errorf("unexpected update body %+v", body)
+			}
+			json.NewEncoder(w).Encode(body)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	rule := func(kind string) bitbucket.BranchRestriction {
+		return bitbucket.BranchRestriction{Kind: kind, BranchMatchKind: "glob", Pattern: "release/*"}
+	}
+	approvals := rule("require_approvals_to_merge")
+	approvals.Value = intPtr(2)
+	rules := []bitbucket.BranchRestriction{rule("force"), rule("delete"), approvals}
+
+	results := NewProtector(newClientForServer(srv)).Apply("ws", []string{"api"}, rules)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Detail != "1 created, 1 updated, 1 unchanged" {
+		t.Errorf("Detail = %q", results[0].Detail)
+	}
+
+	var sawUpdate bool
+	for _, c := range calls {
+		if c == "PUT /2.0/repositories/ws/api/branch-restrictions/2" {
+			sawUpdate = true
+		}
+	}
+	if !sawUpdate {
+		t.Errorf("expected update of restriction 2, calls = %v", calls)
+	}
+}
+
+func TestProtectorApply_ListError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"type":"error","error":{"message":"Forbidden"}}`))
+	}))
+	defer srv.Close()
+
+	results := NewProtector(newClientForServer(srv)).Apply("ws", []string{"b", "a"}, []bitbucket.BranchRestriction{{Kind: "force"}})
+	if len(results) != 2 || results[0].RepoSlug != "a" || results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if !strings.Contains(results[0].Error, "Forbidden") {
+		t.Errorf("Error = %q", results[0].Error)
+	}
+}
@@ -0,0 +1,73 @@
+package repoadmin
+
+import (
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// RepoArchiver makes repos read-only.
+type RepoArchiver struct {
+	repos  provider.RepositoryService
+	client provider.RepositoryArchiveService
+}
+
+// NewRepoArchiver creates a new archive orchestrator.
+func NewRepoArchiver(repos provider.RepositoryService, client provider.RepositoryArchiveService) *RepoArchiver {
+	return &RepoArchiver{repos: repos, client: client}
+}
+
+// Preview checks that each repo exists without changing anything.
+func (a *RepoArchiver) Preview(workspace string, repos []string) []Result {
+	return previewRetire(a.repos, workspace, repos, "archive")
+}
+
+// Archive makes each repo read-only.
+func (a *RepoArchiver) Archive(workspace string, repos []string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		if err := a.client.ArchiveRepository(workspace, repoSlug); err != nil {
+			return "", err
+		}
+		return "archived", nil
+	})
+}
+
+// RepoDeleter permanently deletes repos.
+type RepoDeleter struct {
+	repos  provider.RepositoryService
+	client provider.RepositoryDeletionService
+}
+
+// NewRepoDeleter creates a new delete orchestrator.
+func NewRepoDeleter(repos provider.RepositoryService, client provider.RepositoryDeletionService) *RepoDeleter {
+	return &RepoDeleter{repos: repos, client: client}
+}
+
+// Preview checks that each repo exists without changing anything.
+func (d *RepoDeleter) Preview(workspace string, repos []string) []Result {
+	return previewRetire(d.repos, workspace, repos, "delete")
+}
+
+// Delete permanently deletes each repo.
+func (d *RepoDeleter) Delete(workspace string, repos []string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		if err := d.client.DeleteRepository(workspace, repoSlug); err != nil {
+			return "", err
+		}
+		return "deleted", nil
+	})
+}
+
+// previewRetire reports "would <action>" for each repo that exists, with its
+// last update so stale repos are easy to tell apart from live ones.
+func previewRetire(client provider.RepositoryService, workspace string, repos []string, action string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		repo, err := client.GetRepository(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+		detail := "would " + action
+		if !repo.UpdatedOn.IsZero() {
+			detail += ", last updated " + repo.UpdatedOn.Format("2006-01-02")
+		}
+		return detail, nil
+	})
+}
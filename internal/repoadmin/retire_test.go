@@ -0,0 +1,58 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestRepoDeleter(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		slug := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[3]
+		if slug == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(bitbucket.APIError{Error: bitbucket.APIErrorDetail{Message: "Repository not found"}})
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(bitbucket.Repository{Slug: slug, UpdatedOn: time.Date(2023, 4, 5, 10, 11, 12, 0, time.UTC)})
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, slug)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	client := newClientForServer(srv)
+	deleter := NewRepoDeleter(client, client)
+
+	preview := deleter.Preview("ws", []string{"old-api", "gone"})
+	if !preview[1].Success || preview[1].Detail != "would delete, last updated 2023-04-05" {
+		t.Errorf("old-api preview = %+v", preview[1])
+	}
+	if preview[0].Success || !strings.Contains(preview[0].Error, "Repository not found") {
+		t.Errorf("gone preview = %+v", preview[0])
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("preview deleted %v", deleted)
+	}
+
+	results := deleter.Delete("ws", []string{"old-api"})
+	if !results[0].Success || results[0].Detail != "deleted" || len(deleted) != 1 {
+		t.Errorf("results = %+v, deleted = %v", results, deleted)
+	}
+}
@@ -0,0 +1,145 @@
+package repoadmin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// settingsFile is the YAML layout of a settings file for 'buck repo settings apply'.
+type settingsFile struct {
+	ForkPolicy           *string `yaml:"fork_policy"`
+	Private              *bool   `yaml:"private"`
+	HasIssues            *bool   `yaml:"has_issues"`
+	HasWiki              *bool   `yaml:"has_wiki"`
+	Language             *string `yaml:"language"`
+	DefaultMergeStrategy *string `yaml:"default_merge_strategy"`
+	DeleteBranchOnMerge  *bool   `yaml:"delete_branch_on_merge"`
+}
+
+var (
+	validForkPolicies    = []string{"allow_forks", "no_public_forks", "no_forks"}
+	validMergeStrategies = []string{"merge_commit", "squash", "fast_forward"}
+)
+
+// LoadSettings reads and validates a repository settings file. Unknown keys are rejected.
+func LoadSettings(path string) (bitbucket.RepositorySettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return bitbucket.RepositorySettings{}, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var f settingsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return bitbucket.RepositorySettings{}, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+
+	if f.ForkPolicy != nil && !slices.Contains(validForkPolicies, *f.ForkPolicy) {
+		return bitbucket.RepositorySettings{}, fmt.Errorf("invalid fork_policy %q (valid: %s)", *f.ForkPolicy, strings.Join(validForkPolicies, ", "))
+	}
+	if f.DefaultMergeStrategy != nil && !slices.Contains(validMergeStrategies, *f.DefaultMergeStrategy) {
+		return bitbucket.RepositorySettings{}, fmt.Errorf("invalid default_merge_strategy %q (valid: %s)", *f.DefaultMergeStrategy, strings.Join(validMergeStrategies, ", "))
+	}
+
+	s := bitbucket.RepositorySettings{
+		ForkPolicy:           f.ForkPolicy,
+		IsPrivate:            f.Private,
+		HasIssues:            f.HasIssues,
+		HasWiki:              f.HasWiki,
+		Language:             f.Language,
+		DefaultMergeStrategy: f.DefaultMergeStrategy,
+		DeleteBranchOnMerge:  f.DeleteBranchOnMerge,
+	}
+	if len(settingDiff(bitbucket.RepositorySettings{}, s)) == 0 {
+		return bitbucket.RepositorySettings{}, fmt.Errorf("settings file %s sets nothing", path)
+	}
+	return s, nil
+}
+
+// SettingsApplier pushes repository settings across repos.
+type SettingsApplier struct {
+	client provider.RepositorySettingsService
+}
+
+// NewSettingsApplier creates a new settings orchestrator.
+func NewSettingsApplier(client provider.RepositorySettingsService) *SettingsApplier {
+	return &SettingsApplier{client: client}
+}
+
+// Apply updates each repo whose current settings differ from want.
+// Detail lists the changed settings, or "unchanged".
+func (a *SettingsApplier) Apply(workspace string, repos []string, want bitbucket.RepositorySettings) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		current, err := a.client.GetRepositorySettings(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+		diff := settingDiff(*current, want)
+		if len(diff) == 0 {
+			return "unchanged", nil
+		}
+		if err := a.client.UpdateRepositorySettings(workspace, repoSlug, want); err != nil {
+			return "", err
+		}
+		return "updated " + strings.Join(diff, ", "), nil
+	})
+}
+
+// Preview reports what Apply would change in each repo without updating anything.
+func (a *SettingsApplier) Preview(workspace string, repos []string, want bitbucket.RepositorySettings) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		current, err := a.client.GetRepositorySettings(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+		diff := settingDiff(*current, want)
+		if len(diff) == 0 {
+			return "unchanged", nil
+		}
+		return "would update " + strings.Join(diff, ", "), nil
+	})
+}
+
+// settingDiff lists the settings in want that differ from current, as "name: old → new".
+func settingDiff(current, want bitbucket.RepositorySettings) []string {
+	var diff []string
+	addString := func(name string, cur, w *string) {
+		if w != nil && (cur == nil || *cur != *w) {
+			diff = append(diff, fmt.Sprintf("%s: %s → %s", name, strOrUnset(cur), *w))
+		}
+	}
+	addBool := func(name string, cur, w *bool) {
+		if w != nil && (cur == nil || *cur != *w) {
+			old := "unset"
+			if cur != nil {
+				old = fmt.Sprint(*cur)
+			}
+			diff = append(diff, fmt.Sprintf("%s: %s → %t", name, old, *w))
+		}
+	}
+
+	addString("fork_policy", current.ForkPolicy, want.ForkPolicy)
+	addBool("private", current.IsPrivate, want.IsPrivate)
+	addBool("has_issues", current.HasIssues, want.HasIssues)
+	addBool("has_wiki", current.HasWiki, want.HasWiki)
+	addString("language", current.Language, want.Language)
+	addString("default_merge_strategy", current.DefaultMergeStrategy, want.DefaultMergeStrategy)
+	addBool("delete_branch_on_merge", current.DeleteBranchOnMerge, want.DeleteBranchOnMerge)
+	return diff
+}
+
+func strOrUnset(s *string) string {
+	if s == nil || *s == "" {
+		return "unset"
+	}
+	return *s
+}
@@ -0,0 +1,94 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func writeSettings(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "settings.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSettings(t *testing.T) {
+	s, err := LoadSettings(writeSettings(t, "fork_policy: no_forks\ndelete_branch_on_merge: true\n"))
+	if err != nil {
+		t.Fatalf("LoadSettings error: %v", err)
+	}
+	if s.ForkPolicy == nil || *s.ForkPolicy != "no_forks" || s.DeleteBranchOnMerge == nil || !*s.DeleteBranchOnMerge {
+		t.Errorf("settings = %+v", s)
+	}
+	if s.HasWiki != nil {
+		t.Error("unset keys should stay nil")
+	}
+}
+
+func TestLoadSettings_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":    "forking: nope\n",
+		"fork policy":    "fork_policy: sometimes\n",
+		"merge strategy": "default_merge_strategy: octopus\n",
+		"empty":          "{}\n",
+	}
+	for name, content := range tests {
+		if _, err := LoadSettings(writeSettings(t, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSettingsApplier(t *testing.T) {
+	var updated []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		slug := filepath.Base(r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			policy := "allow_forks"
+			if slug == "done" {
+				policy = "no_forks"
+			}
+			json.NewEncoder(w).Encode(map[string]any{"fork_policy": policy, "has_wiki": false})
+		case http.MethodPut:
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["fork_policy"] != "no_forks" || len(body) != 1 {
+				t.Errorf("PUT body = %v", body)
+			}
+			updated = append(updated, slug)
+			json.NewEncoder(w).Encode(body)
+		}
+	}))
+	defer srv.Close()
+
+	policy := "no_forks"
+	want := bitbucket.RepositorySettings{ForkPolicy: &policy}
+	applier := NewSettingsApplier(newClientForServer(srv))
+
+	preview := applier.Preview("ws", []string{"api", "done"}, want)
+	if !strings.HasPrefix(preview[0].Detail, "would update fork_policy: allow_forks → no_forks") || preview[1].Detail != "unchanged" {
+		t.Errorf("preview = %+v", preview)
+	}
+	if len(updated) != 0 {
+		t.Fatalf("Preview must not update, updated = %v", updated)
+	}
+
+	results := applier.Apply("ws", []string{"api", "done"}, want)
+	if results[0].Detail != "updated fork_policy: allow_forks → no_forks" || results[1].Detail != "unchanged" {
+		t.Errorf("results = %+v", results)
+	}
+	if len(updated) != 1 || updated[0] != "api" {
+		t.Errorf("updated = %v, want [api]", updated)
+	}
+}
@@ -0,0 +1,155 @@
+package repoadmin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// VariableManager sets, lists and deletes pipeline variables across repos.
+type VariableManager struct {
+	client provider.PipelineVariableService
+}
+
+// NewVariableManager creates a new pipeline variable orchestrator.
+func NewVariableManager(client provider.PipelineVariableService) *VariableManager {
+	return &VariableManager{client: client}
+}
+
+// ParseVariables turns KEY=VALUE arguments into variables. A bare KEY takes
+// its value from the environment, so secrets can stay out of shell history.
+func ParseVariables(args []string, secured bool, lookupEnv func(string) (string, bool)) ([]bitbucket.PipelineVariable, error) {
+	vars := make([]bitbucket.PipelineVariable, 0, len(args))
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		key, value, hasValue := strings.Cut(arg, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected KEY=VALUE", arg)
+		}
+		if !hasValue {
+			v, ok := lookupEnv(key)
+			if !ok {
+				return nil, fmt.Errorf("no value for %s: pass %s=VALUE or export %s", key, key, key)
+			}
+			value = v
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("variable %s given more than once", key)
+		}
+		seen[key] = true
+		vars = append(vars, bitbucket.PipelineVariable{Key: key, Value: value, Secured: secured})
+	}
+	return vars, nil
+}
+
+// Set creates or updates each variable by key. Secured values cannot be read
+// back, so existing secured variables are always updated.
+func (m *VariableManager) Set(workspace string, repos []string, vars []bitbucket.PipelineVariable) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListPipelineVariables(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var c changes
+		for _, v := range vars {
+			cur, found := findVariable(existing, v.Key)
+			switch {
+			case !found:
+				if _, err := m.client.CreatePipelineVariable(workspace, repoSlug, v); err != nil {
+					return "", fmt.Errorf("creating %s: %w", v.Key, err)
+				}
+				c.created++
+			case !cur.Secured && !v.Secured && cur.Value == v.Value:
+				c.unchanged++
+			default:
+				if _, err := m.client.UpdatePipelineVariable(workspace, repoSlug, cur.UUID, v); err != nil {
+					return "", fmt.Errorf("updating %s: %w", v.Key, err)
+				}
+				c.updated++
+			}
+		}
+		return c.String(), nil
+	})
+}
+
+// Delete removes the variables with the given keys. Missing keys count as unchanged.
+func (m *VariableManager) Delete(workspace string, repos []string, keys []string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListPipelineVariables(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var c changes
+		for _, key := range keys {
+			cur, found := findVariable(existing, key)
+			if !found {
+				c.unchanged++
+				continue
+			}
+			if err := m.client.DeletePipelineVariable(workspace, repoSlug, cur.UUID); err != nil {
+				return "", fmt.Errorf("deleting %s: %w", key, err)
+			}
+			c.deleted++
+		}
+		return c.String(), nil
+	})
+}
+
+// RepoVariables holds the pipeline variables of one repo.
+type RepoVariables struct {
+	RepoSlug  string
+	Variables []bitbucket.PipelineVariable
+	Error     string
+}
+
+// List fetches the variables of every repo concurrently, sorted by slug and key.
+func (m *VariableManager) List(workspace string, repos []string) []RepoVariables {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []RepoVariables
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			result := RepoVariables{RepoSlug: repoSlug}
+			vars, err := m.client.ListPipelineVariables(provider.SplitRepo(workspace, repoSlug))
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				sort.Slice(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+				result.Variables = vars
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+
+	return results
+}
+
+func findVariable(vars []bitbucket.PipelineVariable, key string) (bitbucket.PipelineVariable, bool) {
+	for _, v := range vars {
+		if v.Key == key {
+			return v, true
+		}
+	}
+	return bitbucket.PipelineVariable{}, false
+}
@@ -0,0 +1,121 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestParseVariables(t *testing.T) {
+	env := map[string]string{"TOKEN": "from-env"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	vars, err := ParseVariables([]string{"REGION=eu-west-1", "TOKEN", "EMPTY=", "URL=a=b"}, true, lookup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []bitbucket.PipelineVariable{
+		{Key: "REGION", Value: "eu-west-1", Secured: true},
+		{Key: "TOKEN", Value: "from-env", Secured: true},
+		{Key: "EMPTY", Value: "", Secured: true},
+		{Key: "URL", Value: "a=b", Secured: true},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("vars = %+v", vars)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Errorf("vars[%d] = %+v, want %+v", i, vars[i], want[i])
+		}
+	}
+
+	for _, args := range [][]string{{"=x"}, {"MISSING"}, {"A=1", "A=2"}} {
+		if _, err := ParseVariables(args, false, lookup); err == nil {
+			t.Errorf("ParseVariables(%v) expected error", args)
+		}
+	}
+}
+
+func TestVariableManagerSet(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(bitbucket.PaginatedPipelineVariables{Values: []bitbucket.PipelineVariable{
+				{UUID: "{1}", Key: "REGION", Value: "eu-west-1"},
+				{UUID: "{2}", Key: "TOKEN", Secured: true},
+			}})
+		case http.MethodPost, http.MethodPut:
+			var body bitbucket.PipelineVariable
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.UUID != "" {
+				t.Errorf("request body should not carry a uuid: %+v", body)
+			}
+			json.NewEncoder(w).Encode(body)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	vars := []bitbucket.PipelineVariable{
+		{Key: "REGION", Value: "eu-west-1"},
+		{Key: "TOKEN", Value: "s3cret", Secured: true},
+		{Key: "NEW", Value: "x"},
+	}
+	results := NewVariableManager(newClientForServer(srv)).Set("ws", []string{"api"}, vars)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Detail != "1 created, 1 updated, 1 unchanged" {
+		t.Errorf("Detail = %q", results[0].Detail)
+	}
+
+	var sawUpdate bool
+	for _, c := range calls {
+		if c == "PUT /2.0/repositories/ws/api/pipelines_config/variables/{2}" {
+			sawUpdate = true
+		}
+	}
+	if !sawUpdate {
+		t.Errorf("expected update of secured variable, calls = %v", calls)
+	}
+}
+
+func TestVariableManagerDelete(t *testing.T) {
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(bitbucket.PaginatedPipelineVariables{Values: []bitbucket.PipelineVariable{
+				{UUID: "{1}", Key: "OLD"},
+			}})
+		case http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	results := NewVariableManager(newClientForServer(srv)).Delete("ws", []string{"api"}, []string{"OLD", "GONE"})
+	if len(results) != 1 || results[0].Detail != "1 deleted, 1 unchanged" {
+		t.Fatalf("results = %+v", results)
+	}
+	if len(deleted) != 1 || !strings.HasSuffix(deleted[0], "/variables/{1}") {
+		t.Errorf("deleted = %v", deleted)
+	}
+}
@@ -0,0 +1,148 @@
+package repoadmin
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/provider"
+)
+
+// WebhookManager lists, syncs and deletes webhooks across repos.
+type WebhookManager struct {
+	client provider.WebhookService
+}
+
+// NewWebhookManager creates a new webhook orchestrator.
+func NewWebhookManager(client provider.WebhookService) *WebhookManager {
+	return &WebhookManager{client: client}
+}
+
+// Sync ensures every hook exists, matching existing webhooks by URL. A webhook
+// whose events, description or active flag differ is updated in place.
+// Detail reports created, updated or already present for each hook.
+func (m *WebhookManager) Sync(workspace string, repos []string, hooks []bitbucket.Webhook) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListWebhooks(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var outcomes []string
+		for _, want := range hooks {
+			want.Active = true
+			var outcome string
+			cur, found := findWebhook(existing, want.URL)
+			switch {
+			case !found:
+				if _, err := m.client.CreateWebhook(workspace, repoSlug, want); err != nil {
+					return "", fmt.Errorf("creating %s: %w", want.URL, err)
+				}
+				outcome = "created"
+			case webhookMatches(cur, want):
+				outcome = "already present"
+			default:
+				if _, err := m.client.UpdateWebhook(workspace, repoSlug, cur.UUID, want); err != nil {
+					return "", fmt.Errorf("updating %s: %w", want.URL, err)
+				}
+				outcome = "updated"
+			}
+			if len(hooks) > 1 {
+				outcome = fmt.Sprintf("%s %s", want.URL, outcome)
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		return strings.Join(outcomes, ", "), nil
+	})
+}
+
+// Delete removes webhooks with the given URL. Repos without one report "not present".
+func (m *WebhookManager) Delete(workspace string, repos []string, hookURL string) []Result {
+	return forEachRepo(workspace, repos, func(workspace, repoSlug string) (string, error) {
+		existing, err := m.client.ListWebhooks(workspace, repoSlug)
+		if err != nil {
+			return "", err
+		}
+
+		var c changes
+		for _, h := range existing {
+			if h.URL != hookURL {
+				continue
+			}
+			if err := m.client.DeleteWebhook(workspace, repoSlug, h.UUID); err != nil {
+				return "", err
+			}
+			c.deleted++
+		}
+		if c.deleted == 0 {
+			return "not present", nil
+		}
+		return c.String(), nil
+	})
+}
+
+// RepoWebhooks holds the webhooks of one repo.
+type RepoWebhooks struct {
+	RepoSlug string
+	Webhooks []bitbucket.Webhook
+	Error    string
+}
+
+// List fetches the webhooks of every repo concurrently, sorted by slug.
+func (m *WebhookManager) List(workspace string, repos []string) []RepoWebhooks {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []RepoWebhooks
+	)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repoSlug string) {
+			defer wg.Done()
+
+			result := RepoWebhooks{RepoSlug: repoSlug}
+			hooks, err := m.client.ListWebhooks(provider.SplitRepo(workspace, repoSlug))
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Webhooks = hooks
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RepoSlug < results[j].RepoSlug
+	})
+
+	return results
+}
+
+func findWebhook(hooks []bitbucket.Webhook, hookURL string) (bitbucket.Webhook, bool) {
+	for _, h := range hooks {
+		if h.URL == hookURL {
+			return h, true
+		}
+	}
+	return bitbucket.Webhook{}, false
+}
+
+// webhookMatches reports whether cur already has want's settings. Event order is ignored.
+func webhookMatches(cur, want bitbucket.Webhook) bool {
+	if cur.Active != want.Active || cur.Description != want.Description {
+		return false
+	}
+	a, b := slices.Clone(cur.Events), slices.Clone(want.Events)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,101 @@
+package repoadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/chinhstringee/buck/internal/bitbucket"
+)
+
+func TestWebhookManagerSync(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			var hooks []bitbucket.Webhook
+			switch r.URL.Path {
+			case "/2.0/repositories/ws/present/hooks":
+				hooks = []bitbucket.Webhook{{UUID: "{1}", URL: "https://ci/hook", Description: "CI", Active: true, Events: []string{"pullrequest:created", "repo:push"}}}
+			case "/2.0/repositories/ws/stale/hooks":
+				hooks = []bitbucket.Webhook{{UUID: "{2}", URL: "https://ci/hook", Description: "CI", Active: true, Events: []string{"repo:push"}}}
+			}
+			json.NewEncoder(w).Encode(bitbucket.PaginatedWebhooks{Values: hooks})
+		case http.MethodPost, http.MethodPut:
+			var body bitbucket.Webhook
+			json.NewDecoder(r.Body).Decode(&body)
+			if !body.Active || len(body.Events) != 2 {
+				t.Errorf("unexpected body %+v", body)
+			}
+			json.NewEncoder(w).Encode(body)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	hook := bitbucket.Webhook{URL: "https://ci/hook", Description: "CI", Events: []string{"repo:push", "pullrequest:created"}}
+	results := NewWebhookManager(newClientForServer(srv)).Sync("ws", []string{"stale", "missing", "present"}, []bitbucket.Webhook{hook})
+
+	want := map[string]string{"missing": "created", "present": "already present", "stale": "updated"}
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+	for _, r := range results {
+		if !r.Success || r.Detail != want[r.RepoSlug] {
+			t.Errorf("%s: %+v, want detail %q", r.RepoSlug, r, want[r.RepoSlug])
+		}
+	}
+
+	var sawUpdate bool
+	for _, c := range calls {
+		if c == "PUT /2.0/repositories/ws/stale/hooks/{2}" {
+			sawUpdate = true
+		}
+	}
+	if !sawUpdate {
+		t.Errorf("expected update of stale webhook, calls = %v", calls)
+	}
+}
+
+func TestWebhookManagerDelete(t *testing.T) {
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(bitbucket.PaginatedWebhooks{Values: []bitbucket.Webhook{
+				{UUID: "{1}", URL: "https://old/hook"},
+				{UUID: "{2}", URL: "https://keep/hook"},
+			}})
+		case http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	m := NewWebhookManager(newClientForServer(srv))
+	results := m.Delete("ws", []string{"api"}, "https://old/hook")
+	if len(results) != 1 || results[0].Detail != "1 deleted" {
+		t.Fatalf("results = %+v", results)
+	}
+	if len(deleted) != 1 || deleted[0] != "/2.0/repositories/ws/api/hooks/{1}" {
+		t.Errorf("deleted = %v", deleted)
+	}
+
+	results = m.Delete("ws", []string{"api"}, "https://absent/hook")
+	if results[0].Detail != "not present" {
+		t.Errorf("Detail = %q", results[0].Detail)
+	}
+}
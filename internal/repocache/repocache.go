@@ -0,0 +1,75 @@
+// Package repocache remembers the repo slugs of each workspace so shell
+// completion can offer them without calling the API.
+package repocache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// filePath returns ~/.buck/repos.json
+func filePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find home directory: %w", err)
+	}
+	return filepath.Join(home, ".buck", "repos.json"), nil
+}
+
+// Slugs returns the repo slugs last listed for a workspace.
+// Returns nil if the workspace has not been listed yet or the file cannot be read.
+func Slugs(workspace string) []string {
+	all, err := load()
+	if err != nil {
+		return nil
+	}
+	return all[workspace]
+}
+
+// Save records the repo slugs of a workspace, keeping other workspaces intact.
+func Save(workspace string, slugs []string) error {
+	all, err := load()
+	if err != nil {
+		all = make(map[string][]string)
+	}
+	all[workspace] = slugs
+
+	path, err := filePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+func load() (map[string][]string, error) {
+	path, err := filePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string][]string
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	if all == nil {
+		all = make(map[string][]string)
+	}
+	return all, nil
+}
@@ -0,0 +1,32 @@
+package repocache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlugs_NoFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := Slugs("ws"); got != nil {
+		t.Errorf("Slugs() = %v, want nil", got)
+	}
+}
+
+func TestSave_RoundTripKeepsOtherWorkspaces(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := Save("ws-a", []string{"api", "web"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if err := Save("ws-b", []string{"worker"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	if got, want := Slugs("ws-a"), []string{"api", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Slugs(ws-a) = %v, want %v", got, want)
+	}
+	if got, want := Slugs("ws-b"), []string{"worker"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Slugs(ws-b) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package selection
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLast_NoFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := Last("ws"); got != nil {
+		t.Errorf("Last() = %v, want nil", got)
+	}
+}
+
+func TestSaveLast_RoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	want := []string{"repo-a", "repo-b"}
+	if err := Save("ws", want); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	if got := Last("ws"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Last() = %v, want %v", got, want)
+	}
+}
+
+func TestSave_KeepsOtherWorkspaces(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := Save("ws-a", []string{"repo-a"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if err := Save("ws-b", []string{"repo-b"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	if got := Last("ws-a"); !reflect.DeepEqual(got, []string{"repo-a"}) {
+		t.Errorf("Last(ws-a) = %v, want [repo-a]", got)
+	}
+	if got := Last("ws-b"); !reflect.DeepEqual(got, []string{"repo-b"}) {
+		t.Errorf("Last(ws-b) = %v, want [repo-b]", got)
+	}
+}
+
+func TestSave_OverwritesCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	path := filepath.Join(dir, ".buck", "selections.json")
+	os.MkdirAll(filepath.Dir(path), 0700)
+	os.WriteFile(path, []byte("not json"), 0600)
+
+	if got := Last("ws"); got != nil {
+		t.Errorf("Last() on corrupt file = %v, want nil", got)
+	}
+	if err := Save("ws", []string{"repo-a"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if got := Last("ws"); !reflect.DeepEqual(got, []string{"repo-a"}) {
+		t.Errorf("Last() = %v, want [repo-a]", got)
+	}
+}
+
+func TestSave_FilePermissions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	if err := Save("ws", []string{"repo-a"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, ".buck", "selections.json"))
+	if err != nil {
+		t.Fatalf("stat error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
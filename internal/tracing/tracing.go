@@ -0,0 +1,135 @@
+// Package tracing emits OpenTelemetry spans for a run: one for the command,
+// one per repo worked on and one per API request. It is off unless an OTLP
+// endpoint is set through the standard OTEL_EXPORTER_OTLP_* variables, so
+// runs in CI can show up in the pipeline's trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// instrumentation names the library that produced the spans.
+	instrumentation = "github.com/chinhstringee/buck"
+	// shutdownTimeout bounds how long the end of a run waits for spans to
+	// be exported.
+	shutdownTimeout = 5 * time.Second
+)
+
+var (
+	// tracer records nothing until StartRun replaces it.
+	tracer = noop.NewTracerProvider().Tracer(instrumentation)
+
+	// run carries the command's span; every other span is its child.
+	run = context.Background()
+
+	// newExporter builds the exporter from the OTEL_EXPORTER_OTLP_*
+	// variables; tests replace it.
+	newExporter = func(ctx context.Context) (sdktrace.SpanExporter, error) {
+		return otlptracehttp.New(ctx)
+	}
+)
+
+// Enabled reports whether the environment asks for traces to be exported.
+func Enabled(getenv func(string) string) bool {
+	if getenv("OTEL_SDK_DISABLED") == "true" || getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return false
+	}
+	return getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// StartRun starts the span of the command name, as a child of the trace in
+// TRACEPARENT when the pipeline passes one. The returned function ends it,
+// marked failed when err is set, and flushes all spans. Without an endpoint
+// configured nothing is recorded and ending is a no-op.
+func StartRun(name string) (end func(err error), err error) {
+	if !Enabled(os.Getenv) {
+		return func(error) {}, nil
+	}
+
+	ctx := context.Background()
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return func(error) {}, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "buck")),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+		resource.WithTelemetrySDK())
+	if err != nil {
+		return func(error) {}, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	tracer = provider.Tracer(instrumentation)
+
+	parent := propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+	})
+	var span trace.Span
+	run, span = tracer.Start(parent, name)
+
+	return func(err error) {
+		End(span, err)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to export traces: %v\n", err)
+		}
+	}, nil
+}
+
+// Start starts a span under the run's span.
+func Start(name string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := tracer.Start(run, name, trace.WithAttributes(attrs...))
+	return span
+}
+
+// StartRequest starts the span of one API request, tagged with the request
+// id sent in its header. Callers add the response status once they have it.
+func StartRequest(req *http.Request, requestID string) trace.Span {
+	return Start("HTTP "+req.Method,
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.full", req.URL.Redacted()),
+		attribute.String("buck.request_id", requestID))
+}
+
+// UseTracerProvider records spans with p until the returned function is
+// called, so tests outside this package can inspect the spans a client makes.
+func UseTracerProvider(p trace.TracerProvider) (restore func()) {
+	previous := tracer
+	tracer = p.Tracer(instrumentation)
+	return func() { tracer = previous }
+}
+
+// End ends span, marking it failed when err is set.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RecordRepo records the work on one repo, which ran from start to end, as
+// a span under the run's span. errMsg is empty when the repo succeeded.
+func RecordRepo(name, repo string, start, end time.Time, errMsg string) {
+	_, span := tracer.Start(run, name, trace.WithTimestamp(start),
+		trace.WithAttributes(attribute.String("buck.repo", repo)))
+	if errMsg != "" {
+		span.SetStatus(codes.Error, errMsg)
+	}
+	span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318"}, true},
+		{map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://collector:4318/v1/traces"}, true},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_SDK_DISABLED": "true"}, false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_TRACES_EXPORTER": "none"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := Enabled(func(k string) string { return tt.env[k] }); got != tt.want {
+			t.Errorf("Enabled(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestStartRun_Disabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	defer restore(newExporter)
+	newExporter = func(context.Context) (sdktrace.SpanExporter, error) {
+		t.Fatal("exporter built without an endpoint")
+		return nil, nil
+	}
+
+	end, err := StartRun("buck create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	end(nil)
+}
+
+func TestStartRun_ExportsSpansUnderPipelineTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	defer restore(newExporter)
+	newExporter = func(context.Context) (sdktrace.SpanExporter, error) { return keepSpans{exporter}, nil }
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	end, err := StartRun("buck create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	span := Start("HTTP POST")
+	End(span, errors.New("409 Conflict"))
+	start := time.Now().Add(-time.Second)
+	RecordRepo("create branch", "api", start, start.Add(250*time.Millisecond), "")
+	end(nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(spans))
+	}
+	byName := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	runSpan := byName["buck create"]
+	if got := runSpan.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the pipeline's", got)
+	}
+	if got := runSpan.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("run parent = %s, want the pipeline's span", got)
+	}
+	for _, name := range []string{"HTTP POST", "create branch"} {
+		if byName[name].Parent.SpanID() != runSpan.SpanContext.SpanID() {
+			t.Errorf("%q is not a child of the run span", name)
+		}
+	}
+	if byName["HTTP POST"].Status.Code != codes.Error {
+		t.Errorf("failed request status = %v, want error", byName["HTTP POST"].Status)
+	}
+	if repo := byName["create branch"]; repo.EndTime.Sub(repo.StartTime) != 250*time.Millisecond {
+		t.Errorf("repo span lasted %s, want 250ms", repo.EndTime.Sub(repo.StartTime))
+	}
+}
+
+// keepSpans keeps the exported spans readable after the run ends;
+// InMemoryExporter drops them on shutdown.
+type keepSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepSpans) Shutdown(context.Context) error { return nil }
+
+// restore resets the package state a test changed.
+func restore(exporter func(context.Context) (sdktrace.SpanExporter, error)) {
+	newExporter = exporter
+	run = context.Background()
+	tracer = noop.NewTracerProvider().Tracer(instrumentation)
+}
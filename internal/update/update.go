@@ -0,0 +1,101 @@
+// Package update compares the running version of buck with the latest
+// release published on GitHub.
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chinhstringee/buck/internal/httpx"
+)
+
+// LatestReleaseURL is the GitHub API endpoint for buck's newest release.
+var LatestReleaseURL = "https://api.github.com/repos/chinhstringee/buck/releases/latest"
+
+// Latest returns the tag of the newest published release, e.g. "v1.4.0".
+func Latest(client *http.Client) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, LatestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to check for updates: %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(httpx.LimitBody(resp.Body)).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to check for updates: %w", err)
+	}
+	if _, ok := parse(release.TagName); !ok {
+		return "", fmt.Errorf("failed to check for updates: unexpected release tag %q", release.TagName)
+	}
+	return release.TagName, nil
+}
+
+// Status describes how far current is behind latest.
+type Status int
+
+const (
+	UpToDate    Status = iota
+	PatchBehind        // only bug-fix releases are missing
+	Outdated           // a newer minor or major release is out
+	Unknown            // current is not a release version, e.g. "dev"
+)
+
+// IsRelease reports whether version is a release version such as "v1.4.0"
+// rather than a development build.
+func IsRelease(version string) bool {
+	_, ok := parse(version)
+	return ok
+}
+
+// Compare reports how current relates to latest. Versions are semantic
+// versions with an optional "v" prefix; pre-release suffixes are ignored.
+func Compare(current, latest string) Status {
+	cur, ok := parse(current)
+	if !ok {
+		return Unknown
+	}
+	lat, ok := parse(latest)
+	if !ok {
+		return Unknown
+	}
+	switch {
+	case lat[0] > cur[0] || (lat[0] == cur[0] && lat[1] > cur[1]):
+		return Outdated
+	case lat[0] == cur[0] && lat[1] == cur[1] && lat[2] > cur[2]:
+		return PatchBehind
+	}
+	return UpToDate
+}
+
+// parse splits "v1.2.3-rc.1" into major, minor and patch.
+func parse(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "-")
+	version, _, _ = strings.Cut(version, "+")
+	fields := strings.Split(version, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
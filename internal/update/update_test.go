@@ -0,0 +1,53 @@
+package update
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            Status
+	}{
+		{"v1.4.0", "v1.4.0", UpToDate},
+		{"1.4.0", "v1.4.0", UpToDate},
+		{"v1.5.0", "v1.4.2", UpToDate},
+		{"v1.4.0", "v1.4.3", PatchBehind},
+		{"v1.4.0-rc.1", "v1.4.1", PatchBehind},
+		{"v1.4.9", "v1.5.0", Outdated},
+		{"v1.9.0", "v2.0.0", Outdated},
+		{"dev", "v1.4.0", Unknown},
+		{"v1.4.0", "nightly", Unknown},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.current, tt.latest); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/chinhstringee/buck/releases/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v1.6.2","name":"v1.6.2"}`))
+	}))
+	defer srv.Close()
+	defer func(url string) { LatestReleaseURL = url }(LatestReleaseURL)
+
+	LatestReleaseURL = srv.URL + "/repos/chinhstringee/buck/releases/latest"
+	got, err := Latest(srv.Client())
+	if err != nil || got != "v1.6.2" {
+		t.Errorf("Latest() = %q, %v; want v1.6.2", got, err)
+	}
+
+	LatestReleaseURL = srv.URL + "/missing"
+	if _, err := Latest(srv.Client()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Latest() error = %v, want 404", err)
+	}
+}
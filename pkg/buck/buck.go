@@ -0,0 +1,154 @@
+// Package buck is the public Go API for creating branches and pull requests
+// across many repositories at once, the same way the buck CLI does.
+//
+// The exported identifiers in this package follow semantic versioning: they
+// are not removed or changed incompatibly within a major version. Everything
+// under internal/ may change at any time and is deliberately not re-exported;
+// results are returned as this package's own types.
+//
+//	client := buck.NewBitbucket(email, apiToken)
+//	match, err := client.ResolveRepos("my-workspace", []string{"api", "web"})
+//	if err != nil { ... }
+//	for _, r := range client.CreateBranches("my-workspace", match.Matched, "feature/x", "main") {
+//		fmt.Println(r.Repo, r.Success, r.Error)
+//	}
+package buck
+
+import (
+	"github.com/chinhstringee/buck/internal/bitbucket"
+	"github.com/chinhstringee/buck/internal/creator"
+	"github.com/chinhstringee/buck/internal/github"
+	"github.com/chinhstringee/buck/internal/gitlab"
+	"github.com/chinhstringee/buck/internal/matcher"
+	"github.com/chinhstringee/buck/internal/provider"
+	"github.com/chinhstringee/buck/internal/pullrequest"
+)
+
+// Client runs multi-repo operations against one hosting provider.
+// It is safe for concurrent use.
+type Client struct {
+	provider provider.Provider
+}
+
+// NewBitbucket returns a Client for Bitbucket Cloud using an API token
+// (Atlassian account email + token).
+func NewBitbucket(email, apiToken string) *Client {
+	return &Client{provider: bitbucket.NewClient(bitbucket.BasicAuth(email, apiToken))}
+}
+
+// NewBitbucketOAuth returns a Client for Bitbucket Cloud that calls tokenFn
+// for an OAuth access token before each request.
+func NewBitbucketOAuth(tokenFn func() (string, error)) *Client {
+	return &Client{provider: bitbucket.NewClient(bitbucket.BearerAuth(tokenFn))}
+}
+
+// NewGitHub returns a Client for GitHub. An empty baseURL uses api.github.com;
+// GitHub Enterprise uses https://<host>/api/v3.
+func NewGitHub(baseURL, token string) *Client {
+	return &Client{provider: github.NewClient(baseURL, token)}
+}
+
+// NewGitLab returns a Client for GitLab. An empty baseURL uses gitlab.com;
+// self-managed instances use https://<host>/api/v4.
+func NewGitLab(baseURL, token string) *Client {
+	return &Client{provider: gitlab.NewClient(baseURL, token)}
+}
+
+// Repository describes a repository in a workspace.
+type Repository struct {
+	Slug        string
+	Name        string
+	FullName    string
+	Description string
+	MainBranch  string // empty when the repository has no main branch
+	Project     string // project key (Bitbucket) or parent group (GitLab)
+}
+
+// MatchResult is the outcome of resolving repo patterns.
+type MatchResult struct {
+	Matched   []string // repo slugs, deduplicated, best match first per pattern
+	Unmatched []string // patterns that matched nothing
+}
+
+// BranchResult is the outcome of creating a branch in one repository.
+type BranchResult struct {
+	Repo       string
+	Success    bool
+	Error      string
+	CommitHash string // short hash the new branch points to
+	URL        string
+}
+
+// PullRequestResult is the outcome of opening a pull request in one repository.
+type PullRequestResult struct {
+	Repo    string
+	Success bool
+	Error   string
+	ID      int
+	URL     string
+}
+
+// ListRepositories returns all repositories in a workspace (org or group on GitHub/GitLab).
+func (c *Client) ListRepositories(workspace string) ([]Repository, error) {
+	repos, err := c.provider.ListRepositories(workspace)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Repository, len(repos))
+	for i, r := range repos {
+		out[i] = Repository{Slug: r.Slug, Name: r.Name, FullName: r.FullName, Description: r.Description}
+		if r.MainBranch != nil {
+			out[i].MainBranch = r.MainBranch.Name
+		}
+		if r.Project != nil {
+			out[i].Project = r.Project.Key
+		}
+	}
+	return out, nil
+}
+
+// ResolveRepos lists the workspace and matches patterns against it, using the
+// same pattern syntax as the CLI's --repos flag (substrings, "a|b", globs,
+// "re:" regular expressions and "!" exclusions).
+func (c *Client) ResolveRepos(workspace string, patterns []string) (MatchResult, error) {
+	if err := matcher.Validate(patterns); err != nil {
+		return MatchResult{}, err
+	}
+	repos, err := c.provider.ListRepositories(workspace)
+	if err != nil {
+		return MatchResult{}, err
+	}
+	candidates := make([]matcher.Candidate, len(repos))
+	for i, r := range repos {
+		candidates[i] = matcher.Candidate{Slug: r.Slug, Name: r.Name, Description: r.Description}
+		if r.Project != nil {
+			candidates[i].ProjectKey = r.Project.Key
+			candidates[i].ProjectName = r.Project.Name
+		}
+	}
+	result := matcher.MatchRepos(candidates, patterns)
+	return MatchResult{Matched: result.Matched, Unmatched: result.Unmatched}, nil
+}
+
+// CreateBranches creates branchName from sourceBranch in each repo concurrently.
+// An empty sourceBranch means each repo's development branch. Results are sorted by repo slug; a failure in one repo does not stop the others.
+func (c *Client) CreateBranches(workspace string, repos []string, branchName, sourceBranch string) []BranchResult {
+	results := creator.NewBranchCreator(c.provider).CreateBranches(workspace, repos, branchName, sourceBranch)
+	out := make([]BranchResult, len(results))
+	for i, r := range results {
+		out[i] = BranchResult{Repo: r.RepoSlug, Success: r.Success, Error: r.Error, CommitHash: r.CommitHash, URL: r.BranchURL}
+	}
+	return out
+}
+
+// CreatePullRequests opens a pull request from branchName into destination
+// (default: each repo's development branch) in each repo concurrently. Titles and descriptions are
+// generated from the branch name and its commits, as in the CLI.
+func (c *Client) CreatePullRequests(workspace string, repos []string, branchName, destination string) []PullRequestResult {
+	results := pullrequest.NewPRCreator(c.provider, pullrequest.Options{}).CreatePRs(workspace, repos, branchName, destination)
+	out := make([]PullRequestResult, len(results))
+	for i, r := range results {
+		out[i] = PullRequestResult{Repo: r.RepoSlug, Success: r.Success, Error: r.Error, ID: r.PRID, URL: r.PRURL}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package buck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newGitHubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/acme/repos":
+			w.Write([]byte(`[{"name":"api","default_branch":"main"},{"name":"web","default_branch":"main"},{"name":"legacy-api"}]`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/git/ref/heads/main"):
+			w.Write([]byte(`{"object":{"sha":"0123456789abcdef"}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/git/refs"):
+			if strings.Contains(r.URL.Path, "/web/") {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(`{"message":"Reference already exists"}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"object":{"sha":"0123456789abcdef"}}`))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/compare/"):
+			w.Write([]byte(`{"commits":[]}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/pulls"):
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"number":3,"state":"open","html_url":"https://github.com/acme/api/pull/3"}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestResolveRepos(t *testing.T) {
+	srv := newGitHubServer(t)
+	defer srv.Close()
+
+	result, err := NewGitHub(srv.URL, "tok").ResolveRepos("acme", []string{"api", "!legacy", "nope"})
+	if err != nil {
+		t.Fatalf("ResolveRepos error: %v", err)
+	}
+	if strings.Join(result.Matched, ",") != "api" {
+		t.Errorf("Matched = %v, want [api]", result.Matched)
+	}
+	if strings.Join(result.Unmatched, ",") != "nope" {
+		t.Errorf("Unmatched = %v, want [nope]", result.Unmatched)
+	}
+}
+
+func TestResolveRepos_InvalidRegex(t *testing.T) {
+	if _, err := NewGitHub("http://unused", "tok").ResolveRepos("acme", []string{"re:("}); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestCreateBranches(t *testing.T) {
+	srv := newGitHubServer(t)
+	defer srv.Close()
+
+	results := NewGitHub(srv.URL, "tok").CreateBranches("acme", []string{"web", "api"}, "feature/x", "main")
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[0].Repo != "api" || !results[0].Success || results[0].CommitHash != "0123456" {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Repo != "web" || results[1].Success || !strings.Contains(results[1].Error, "already exists") {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+}
+
+func TestCreatePullRequests(t *testing.T) {
+	srv := newGitHubServer(t)
+	defer srv.Close()
+
+	results := NewGitHub(srv.URL, "tok").CreatePullRequests("acme", []string{"api"}, "feature/x", "main")
+	if len(results) != 1 || !results[0].Success || results[0].ID != 3 || results[0].URL != "https://github.com/acme/api/pull/3" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestListRepositories(t *testing.T) {
+	srv := newGitHubServer(t)
+	defer srv.Close()
+
+	repos, err := NewGitHub(srv.URL, "tok").ListRepositories("acme")
+	if err != nil {
+		t.Fatalf("ListRepositories error: %v", err)
+	}
+	if len(repos) != 3 || repos[0].MainBranch != "main" || repos[2].MainBranch != "" {
+		t.Errorf("repos = %+v", repos)
+	}
+}